@@ -3,19 +3,25 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/danielhoward314/packet-sentry/internal/certs"
 	"github.com/danielhoward314/packet-sentry/internal/config"
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
 	psPCap "github.com/danielhoward314/packet-sentry/internal/pcap"
 	"github.com/danielhoward314/packet-sentry/internal/poll"
+	"github.com/danielhoward314/packet-sentry/internal/update"
 )
 
+// ErrRestartRequested is returned by Start once the agent stopped so its service manager starts it again
+var ErrRestartRequested = errors.New("agent restart requested")
+
 type Agent struct {
 	AgentAddr          string
 	BaseLogger         *slog.Logger
@@ -25,6 +31,8 @@ type Agent struct {
 	Ctx                context.Context
 	PollManager        poll.PollManager
 	PCapManager        psPCap.PCapManager
+	UpdateManager      update.UpdateManager
+	restartRequested   atomic.Bool
 	stopOnce           sync.Once
 }
 
@@ -75,12 +83,14 @@ func (agent *Agent) InjectDependencies(
 	certManager certs.CertificateManager,
 	pcapManager psPCap.PCapManager,
 	pollManager poll.PollManager,
+	updateManager update.UpdateManager,
 ) {
 	logger := agent.BaseLogger.With(psLog.KeyFunction, "Agent.InjectDependencies")
 	logger.Info("injecting agent dependencies")
 	agent.CertificateManager = certManager
 	agent.PollManager = pollManager
 	agent.PCapManager = pcapManager
+	agent.UpdateManager = updateManager
 }
 
 // Start is called to start the goroutines of all of the managers.
@@ -108,12 +118,21 @@ func (agent *Agent) Start() (err error) {
 		agent.PollManager.Start()
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		agent.UpdateManager.Start()
+	}()
+
 	// block until agent's context is canceled
 	<-agent.Ctx.Done()
 	logger.Info("agent context canceled, shutting down managers")
 	// Wait for all manager goroutines to exit
 	wg.Wait()
 
+	if agent.restartRequested.Load() {
+		return ErrRestartRequested
+	}
 	return nil
 }
 
@@ -128,5 +147,18 @@ func (agent *Agent) Stop() {
 		agent.CertificateManager.Stop()
 		agent.PCapManager.StopAll()
 		agent.PollManager.Stop()
+		agent.UpdateManager.Stop()
 	})
 }
+
+// Restart stops the agent so its service manager starts it again, e.g. from the binary an update swapped in.
+// Start returns ErrRestartRequested once the managers have stopped.
+func (agent *Agent) Restart() {
+	agent.restartRequested.Store(true)
+	agent.Stop()
+}
+
+// RestartRequested reports whether the agent stopped to be started again by its service manager
+func (agent *Agent) RestartRequested() bool {
+	return agent.restartRequested.Load()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+)
+
+// defaultCommandExpiryCheckInterval is how often commands are checked for a passed expiry
+const defaultCommandExpiryCheckInterval = 5 * time.Minute
+
+// expireCommands periodically marks the commands whose expiry passed while they were pending or delivered expired,
+// until the context is canceled. Commands are acked on the device's consumer once fetched, so without it a command whose agent
+// never reports a result would stay delivered forever. The update only touches unfinished commands, so several agent-api replicas can run it.
+func expireCommands(ctx context.Context, wg *sync.WaitGroup, datastore *dao.Datastore, interval time.Duration, logger *slog.Logger) {
+	defer wg.Done()
+
+	logger.Info("marking commands expired after their expiry", slog.Duration("interval", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			expired, err := datastore.Commands.ExpirePastDue()
+			if err != nil {
+				logger.Error("failed to mark commands expired", slog.Any("error", err))
+				continue
+			}
+			if expired > 0 {
+				logger.Info("marked commands expired", slog.Int64("commands", expired))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -142,6 +142,25 @@ func main() {
 	wg.Add(1)
 	go serveGRPC(ctx, &wg, mtlsServer, apiMTLSAddr, "mTLS", logger)
 
+	wg.Add(1)
+	go markOfflineDevices(
+		ctx,
+		&wg,
+		datastore,
+		getEnvDuration("DEVICE_OFFLINE_AFTER", defaultDeviceOfflineAfter),
+		getEnvDuration("DEVICE_OFFLINE_CHECK_INTERVAL", defaultDeviceOfflineCheckInterval),
+		logger,
+	)
+
+	wg.Add(1)
+	go expireCommands(
+		ctx,
+		&wg,
+		datastore,
+		getEnvDuration("COMMAND_EXPIRY_CHECK_INTERVAL", defaultCommandExpiryCheckInterval),
+		logger,
+	)
+
 	// Wait for shutdown signal
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
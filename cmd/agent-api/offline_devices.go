@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+)
+
+const (
+	// defaultDeviceOfflineAfter is how long a device may go without a heartbeat before it is marked offline,
+	// a few missed heartbeats so a slow network doesn't flap it
+	defaultDeviceOfflineAfter = 5 * time.Minute
+	// defaultDeviceOfflineCheckInterval is how often devices are checked for missed heartbeats
+	defaultDeviceOfflineCheckInterval = 1 * time.Minute
+)
+
+// markOfflineDevices periodically marks the devices whose agents haven't sent a heartbeat within offlineAfter offline,
+// until the context is canceled. The update only touches devices that are still online, so several agent-api replicas can run it.
+func markOfflineDevices(ctx context.Context, wg *sync.WaitGroup, datastore *dao.Datastore, offlineAfter, interval time.Duration, logger *slog.Logger) {
+	defer wg.Done()
+
+	logger.Info("marking devices offline after missed heartbeats", slog.Duration("offlineAfter", offlineAfter), slog.Duration("interval", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			marked, err := datastore.Devices.MarkOffline(time.Now().Add(-offlineAfter))
+			if err != nil {
+				logger.Error("failed to mark devices offline", slog.Any("error", err))
+				continue
+			}
+			if marked > 0 {
+				logger.Info("marked devices offline", slog.Int64("devices", marked))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// getEnvDuration reads a duration environment variable, e.g. "5m", or returns a default if it is unset or invalid
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	val, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || val <= 0 {
+		return defaultVal
+	}
+	return val
+}
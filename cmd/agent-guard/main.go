@@ -0,0 +1,34 @@
+//go:build darwin || linux
+
+// The agent guard is what the service manager starts in place of the agent binary.
+// It checks the update being confirmed, rolling it back if the new version keeps failing to start,
+// then replaces itself with the agent binary. It has no cgo dependencies and isn't replaced by self-updates,
+// so it runs even when the agent binary an update swapped in can't.
+package main
+
+import (
+	"log/slog"
+	"os"
+	"syscall"
+
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	"github.com/danielhoward314/packet-sentry/internal/update"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil)).With(slog.String(psLog.KeyServiceName, "agentGuard"))
+
+	err := update.CheckStart(logger)
+	if err != nil {
+		// the agent is started regardless, since a broken update state must not keep it from running
+		logger.Error("failed to check update", psLog.KeyError, err)
+	}
+
+	binaryPath := config.GetAgentBinaryPath()
+	env := append(os.Environ(), update.GuardEnv+"=1")
+	err = syscall.Exec(binaryPath, append([]string{binaryPath}, os.Args[1:]...), env)
+	// exec only returns on failure, exiting non-zero counts as a failed start the next time the service manager starts the guard
+	logger.Error("failed to exec agent binary", psLog.KeyPath, binaryPath, psLog.KeyError, err)
+	os.Exit(1)
+}
@@ -8,6 +8,7 @@ import (
 	psOS "github.com/danielhoward314/packet-sentry/internal/os"
 	psPCap "github.com/danielhoward314/packet-sentry/internal/pcap"
 	"github.com/danielhoward314/packet-sentry/internal/poll"
+	"github.com/danielhoward314/packet-sentry/internal/update"
 )
 
 func initializeAgent(psAgent *agent.Agent) error {
@@ -27,7 +28,8 @@ func initializeAgent(psAgent *agent.Agent) error {
 		agentMTLSClientBroadcaster,
 		psAgent.AgentAddr,
 	)
-	pcapManager := psPCap.NewPCapManager(psAgent.Ctx, psAgent.BaseLogger, commandsBroadcaster, agentMTLSClientBroadcaster)
+	updateManager := update.NewUpdateManager(psAgent.Ctx, psAgent.BaseLogger, commandsBroadcaster, agentMTLSClientBroadcaster, psAgent.Restart)
+	pcapManager := psPCap.NewPCapManager(psAgent.Ctx, psAgent.BaseLogger, systemInfo, commandsBroadcaster, agentMTLSClientBroadcaster, updateManager)
 	pollManager := poll.NewPollManager(psAgent.Ctx, psAgent.BaseLogger, commandsBroadcaster, agentMTLSClientBroadcaster)
 
 	logger.Info("ensuring client certificate is in place for mTLS")
@@ -42,6 +44,7 @@ func initializeAgent(psAgent *agent.Agent) error {
 		certManager,
 		pcapManager,
 		pollManager,
+		updateManager,
 	)
 
 	return nil
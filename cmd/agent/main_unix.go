@@ -3,6 +3,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -17,6 +18,8 @@ import (
 
 const (
 	pidFileName = "/var/run/packetsentryagent.pid"
+	// exitCodeRestart is the exit code of an agent that stopped to be started again, non-zero since launchd doesn't restart a successful exit
+	exitCodeRestart = 75
 )
 
 func main() {
@@ -50,7 +53,13 @@ func main() {
 		// only used for agent goroutine, as the signal one below has no cleanup associated with it
 		defer wg.Done()
 		agentStartErr := psAgent.Start()
-		if agentStartErr != nil {
+		if errors.Is(agentStartErr, agent.ErrRestartRequested) {
+			psAgent.BaseLogger.Info("agent stopped to restart")
+			select {
+			case shutdownChan <- struct{}{}:
+			default:
+			}
+		} else if agentStartErr != nil {
 			psAgent.BaseLogger.Error("failed to start agent", psLog.KeyError, agentStartErr)
 			psAgent.BaseLogger.Info("agent.Start() errored, calling agent.Stop()")
 			psAgent.Stop()
@@ -80,4 +89,7 @@ func main() {
 	// wait for the agent goroutine to be done
 	wg.Wait()
 	psAgent.BaseLogger.Info("agent shutdown complete")
+	if psAgent.RestartRequested() {
+		os.Exit(exitCodeRestart)
+	}
 }
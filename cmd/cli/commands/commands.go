@@ -9,8 +9,8 @@ import (
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "packet-sentry-cli",
-	Short: "A wrapper for github.com/pressly/goose",
-	Long:  "A wrapper for github.com/pressly/goose",
+	Short: "A wrapper for github.com/pressly/goose, tooling for the NATS dead-letter stream, offline capture file ingestion and agent release publishing",
+	Long:  "A wrapper for github.com/pressly/goose, tooling for the NATS dead-letter stream, offline capture file ingestion and agent release publishing",
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
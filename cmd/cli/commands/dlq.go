@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+)
+
+// dlqCmd is a subcommand for managing the messages dead-lettered by the worker
+var dlqCmd = &cobra.Command{
+	Use:   "dlq",
+	Short: "Parent command for [list|inspect|replay] commands for the EVENTS_DLQ dead-letter stream",
+	Long:  "Parent command for [list|inspect|replay] commands for the packet event and flow record messages the worker dead-lettered to the EVENTS_DLQ stream.",
+}
+
+// connectJetStream connects to the NATS server sourced from the NATS_URL environment variable
+func connectJetStream() (*nats.Conn, nats.JetStreamContext) {
+	natsURL := os.Getenv("NATS_URL")
+	if natsURL == "" {
+		natsURL = nats.DefaultURL
+	}
+
+	nc, err := nats.Connect(natsURL)
+	if err != nil {
+		log.Fatal("Error connecting to NATS:", err)
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		log.Fatal("Error getting JetStream context:", err)
+	}
+	return nc, js
+}
+
+// parseSequences parses the stream sequence args of the dlq subcommands
+func parseSequences(args []string) ([]uint64, error) {
+	seqs := make([]uint64, 0, len(args))
+	for _, arg := range args {
+		seq, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stream sequence %q: %w", arg, err)
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, nil
+}
+
+func init() {
+	rootCmd.AddCommand(dlqCmd)
+}
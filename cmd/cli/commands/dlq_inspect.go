@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/danielhoward314/packet-sentry/internal/streams"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// dlqInspectCmd is a subcommand that prints a dead-lettered message
+var dlqInspectCmd = &cobra.Command{
+	Use:   "inspect <seq>",
+	Short: "Prints a message of the EVENTS_DLQ stream",
+	Long:  "Prints the headers of the message at the given EVENTS_DLQ stream sequence and its packet events or flow record as JSON, or the error unmarshaling them.",
+	Args:  cobra.ExactArgs(1),
+	Run:   dlqInspect,
+}
+
+func dlqInspect(cobraCmd *cobra.Command, args []string) {
+	seqs, err := parseSequences(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nc, js := connectJetStream()
+	defer nc.Close()
+
+	msg, err := js.GetMsg(streams.StreamEventsDLQ, seqs[0])
+	if err != nil {
+		log.Fatal("Error reading dead-lettered message:", err)
+	}
+
+	fmt.Printf("Sequence: %d\nSubject: %s\nStored at: %s\nData: %d bytes\n\nHeaders:\n", msg.Sequence, msg.Subject, msg.Time, len(msg.Data))
+	keys := make([]string, 0, len(msg.Header))
+	for key := range msg.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range msg.Header.Values(key) {
+			fmt.Printf("  %s: %s\n", key, value)
+		}
+	}
+
+	var data proto.Message
+	switch {
+	case strings.HasPrefix(msg.Header.Get(streams.HeaderDLQOriginalSubject), "flows."):
+		data = &pbAgent.FlowRecord{}
+	case msg.Header.Get(streams.HeaderMessageType) == streams.MessageTypePacketEventBatch:
+		data = &pbAgent.PacketEventBatch{}
+	default:
+		data = &pbAgent.PacketEvent{}
+	}
+	err = proto.Unmarshal(msg.Data, data)
+	if err != nil {
+		fmt.Printf("\nFailed to unmarshal data: %v\n", err)
+		return
+	}
+	content, err := protojson.MarshalOptions{Multiline: true}.Marshal(data)
+	if err != nil {
+		log.Fatal("Error marshaling data to JSON:", err)
+	}
+	fmt.Printf("\nData:\n%s\n", content)
+}
+
+func init() {
+	dlqCmd.AddCommand(dlqInspectCmd)
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+
+	"github.com/danielhoward314/packet-sentry/internal/streams"
+)
+
+// dlqListCmd is a subcommand that lists dead-lettered messages
+var dlqListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the messages in the EVENTS_DLQ stream",
+	Long:  "Lists the stream sequence, time, original subject, reason, deliveries and error of the messages in the EVENTS_DLQ stream, oldest first.",
+	Run:   dlqList,
+}
+
+func dlqList(cobraCmd *cobra.Command, args []string) {
+	limit, _ := cobraCmd.Flags().GetInt("limit")
+
+	nc, js := connectJetStream()
+	defer nc.Close()
+
+	info, err := js.StreamInfo(streams.StreamEventsDLQ)
+	if err != nil {
+		log.Fatal("Error reading EVENTS_DLQ stream info:", err)
+	}
+	fmt.Printf("%d messages in %s\n", info.State.Msgs, streams.StreamEventsDLQ)
+	if info.State.Msgs == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEQ\tDEAD-LETTERED AT\tSUBJECT\tREASON\tDELIVERIES\tERROR")
+	listed := 0
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq && listed < limit; seq++ {
+		msg, err := js.GetMsg(streams.StreamEventsDLQ, seq)
+		if err != nil {
+			if errors.Is(err, nats.ErrMsgNotFound) {
+				// deleted after a replay
+				continue
+			}
+			log.Fatal("Error reading dead-lettered message:", err)
+		}
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t%s\n",
+			msg.Sequence,
+			msg.Header.Get(streams.HeaderDLQFailedAt),
+			msg.Header.Get(streams.HeaderDLQOriginalSubject),
+			msg.Header.Get(streams.HeaderDLQReason),
+			msg.Header.Get(streams.HeaderDLQNumDelivered),
+			truncate(msg.Header.Get(streams.HeaderDLQError), 80),
+		)
+		listed++
+	}
+	w.Flush()
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
+
+func init() {
+	dlqListCmd.Flags().Int("limit", 50, "Max number of messages to list.")
+	dlqCmd.AddCommand(dlqListCmd)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+
+	"github.com/danielhoward314/packet-sentry/internal/streams"
+)
+
+// dlqReplayCmd is a subcommand that republishes dead-lettered messages to their original subject
+var dlqReplayCmd = &cobra.Command{
+	Use:   "replay [seq...]",
+	Short: "Replays messages of the EVENTS_DLQ stream",
+	Long: "Republishes the messages at the given EVENTS_DLQ stream sequences, or all of them with --all, to their original subject " +
+		"so the worker processes them again, then deletes them from EVENTS_DLQ. Run it once the root cause of the failures is fixed.",
+	Run: dlqReplay,
+}
+
+func dlqReplay(cobraCmd *cobra.Command, args []string) {
+	all, _ := cobraCmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		log.Fatal("Error: pass either stream sequences or --all")
+	}
+
+	nc, js := connectJetStream()
+	defer nc.Close()
+
+	seqs, err := parseSequences(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if all {
+		info, err := js.StreamInfo(streams.StreamEventsDLQ)
+		if err != nil {
+			log.Fatal("Error reading EVENTS_DLQ stream info:", err)
+		}
+		for seq := info.State.FirstSeq; info.State.Msgs > 0 && seq <= info.State.LastSeq; seq++ {
+			seqs = append(seqs, seq)
+		}
+	}
+
+	replayed := 0
+	for _, seq := range seqs {
+		msg, err := js.GetMsg(streams.StreamEventsDLQ, seq)
+		if err != nil {
+			if all && errors.Is(err, nats.ErrMsgNotFound) {
+				continue
+			}
+			log.Fatalf("Error reading dead-lettered message %d: %v", seq, err)
+		}
+
+		originalSubject := msg.Header.Get(streams.HeaderDLQOriginalSubject)
+		if originalSubject == "" {
+			log.Fatalf("Error replaying message %d: no %s header", seq, streams.HeaderDLQOriginalSubject)
+		}
+		replayMsg := nats.NewMsg(originalSubject)
+		replayMsg.Data = msg.Data
+		for key, values := range msg.Header {
+			// the DLQ headers describe the failure, not the message
+			if strings.HasPrefix(key, streams.HeaderDLQPrefix) {
+				continue
+			}
+			for _, value := range values {
+				replayMsg.Header.Add(key, value)
+			}
+		}
+
+		_, err = js.PublishMsg(replayMsg)
+		if err != nil {
+			log.Fatalf("Error republishing message %d: %v", seq, err)
+		}
+		err = js.DeleteMsg(streams.StreamEventsDLQ, seq)
+		if err != nil {
+			log.Fatalf("Error deleting replayed message %d from EVENTS_DLQ: %v", seq, err)
+		}
+		replayed++
+	}
+	fmt.Printf("Replayed %d messages from %s.\n", replayed, streams.StreamEventsDLQ)
+}
+
+func init() {
+	dlqReplayCmd.Flags().Bool("all", false, "Replays all messages in the EVENTS_DLQ stream.")
+	dlqCmd.AddCommand(dlqReplayCmd)
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	psPCap "github.com/danielhoward314/packet-sentry/internal/pcap"
+	"github.com/danielhoward314/packet-sentry/internal/streams"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// offlineDeviceIDPrefix prefixes the synthetic device ids derived from a capture file's contents
+const offlineDeviceIDPrefix = "offline-"
+
+// deviceIDPattern matches device ids that are a single token of the `events.<id>` subject
+var deviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ingestCmd is a subcommand that publishes the packets of a capture file to the packet event pipeline
+var ingestCmd = &cobra.Command{
+	Use:   "ingest <file>",
+	Short: "Ingests a pcap or pcapng file as the packet events of a synthetic device",
+	Long: "Reads a pcap or pcapng capture file, converts each packet to a packet event with the agent's enrichment and the file's timestamps, " +
+		"and publishes the events in batches to events.<device id> on NATS, for the worker to store like live data. " +
+		"The device id defaults to one derived from the file's contents, so ingesting the same file again uses the same id.",
+	Args: cobra.ExactArgs(1),
+	Run:  ingest,
+}
+
+func ingest(cobraCmd *cobra.Command, args []string) {
+	path := args[0]
+	deviceID, _ := cobraCmd.Flags().GetString("device-id")
+	bpf, _ := cobraCmd.Flags().GetString("bpf")
+	batchSize, _ := cobraCmd.Flags().GetInt("batch-size")
+	if batchSize <= 0 {
+		log.Fatal("Error: --batch-size must be positive")
+	}
+
+	if deviceID == "" {
+		var err error
+		deviceID, err = offlineDeviceID(path)
+		if err != nil {
+			log.Fatal("Error reading capture file:", err)
+		}
+	}
+	if !deviceIDPattern.MatchString(deviceID) {
+		log.Fatalf("Error: device id %q may only contain letters, digits, '-' and '_'", deviceID)
+	}
+
+	handle, err := pcap.OpenOffline(path)
+	if err != nil {
+		log.Fatal("Error opening capture file:", err)
+	}
+	defer handle.Close()
+	if bpf != "" {
+		err = handle.SetBPFFilter(bpf)
+		if err != nil {
+			log.Fatal("Error setting BPF:", err)
+		}
+	}
+
+	nc, js := connectJetStream()
+	defer nc.Close()
+
+	subject := "events." + deviceID
+	fileName := filepath.Base(path)
+	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
+	// decode TCP payloads by port like the agent does, e.g. as TLS on 443
+	packetSource.DecodeOptions.DecodeStreamsAsDatagrams = true
+
+	batch := &pbAgent.PacketEventBatch{}
+	batchBytes := 0
+	published := 0
+	publish := func() {
+		if len(batch.Events) == 0 {
+			return
+		}
+		data, err := proto.Marshal(batch)
+		if err != nil {
+			log.Fatal("Error marshaling packet event batch:", err)
+		}
+		msg := nats.NewMsg(subject)
+		msg.Header.Set(streams.HeaderMessageType, streams.MessageTypePacketEventBatch)
+		msg.Data = data
+		// waiting on each publish ack keeps a large file from outrunning the stream
+		_, err = js.PublishMsg(msg)
+		if err != nil {
+			log.Fatalf("Error publishing packet event batch to %s after %d packet events: %v", subject, published, err)
+		}
+		published += len(batch.Events)
+		batch = &pbAgent.PacketEventBatch{}
+		batchBytes = 0
+	}
+
+	for packet := range packetSource.Packets() {
+		packetEvent := psPCap.ConvertPacketToEvent(psPCap.WrappedPacket{
+			Bpf:             bpf,
+			DeviceName:      fileName,
+			SnapLen:         int32(handle.SnapLen()),
+			PacketEventData: packet,
+		})
+		// the size of the event counts its framing in the batch, so the marshaled batch never exceeds the max
+		size := protowire.SizeTag(1) + protowire.SizeBytes(proto.Size(packetEvent))
+		if len(batch.Events) > 0 && (len(batch.Events) >= batchSize || batchBytes+size > streams.MaxMessageBytes) {
+			publish()
+		}
+		batch.Events = append(batch.Events, packetEvent)
+		batchBytes += size
+	}
+	publish()
+
+	fmt.Printf("Published %d packet events from %s to %s\n", published, fileName, subject)
+	fmt.Printf("Query them with the events API under device id %s\n", deviceID)
+}
+
+// offlineDeviceID derives a synthetic device id from the hash of the capture file's contents
+func offlineDeviceID(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+	return offlineDeviceIDPrefix + hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+func init() {
+	ingestCmd.Flags().String("device-id", "", "synthetic device id to publish the packet events under, defaults to one derived from the file's contents")
+	ingestCmd.Flags().String("bpf", "", "BPF filter applied to the file's packets, recorded as the bpf of the packet events")
+	ingestCmd.Flags().Int("batch-size", 500, "max packet events per published NATS message")
+	rootCmd.AddCommand(ingestCmd)
+}
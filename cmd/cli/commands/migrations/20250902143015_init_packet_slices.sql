@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'packet_slice_status') THEN
+        CREATE TYPE packet_slice_status AS ENUM (
+            'requested',
+            'complete',
+            'failed'
+        );
+    END IF;
+END$$;
+
+CREATE TABLE IF NOT EXISTS packet_slices (
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    device_id UUID NOT NULL,
+    CONSTRAINT fk_device
+        FOREIGN KEY(device_id)
+        REFERENCES devices(id)
+        ON DELETE CASCADE,
+    device_name TEXT NOT NULL,
+    bpf TEXT NOT NULL,
+    filter TEXT NOT NULL DEFAULT '',
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    status packet_slice_status NOT NULL DEFAULT 'requested',
+    error TEXT NOT NULL DEFAULT '',
+    -- the classic pcap file uploaded by the agent, only set once the slice is complete
+    data BYTEA,
+    packet_count BIGINT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_packet_slices_device_id_requested_at ON packet_slices(device_id, requested_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_packet_slices_device_id_requested_at;
+DROP TABLE IF EXISTS packet_slices;
+DROP TYPE IF EXISTS packet_slice_status;
+-- +goose StatementEnd
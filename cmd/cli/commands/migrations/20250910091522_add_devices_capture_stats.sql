@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE devices
+    ADD COLUMN IF NOT EXISTS capture_stats JSONB NOT NULL DEFAULT '[]'::jsonb,
+    ADD COLUMN IF NOT EXISTS events_dropped BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS capture_stats_updated_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE devices
+    DROP COLUMN IF EXISTS capture_stats_updated_at,
+    DROP COLUMN IF EXISTS events_dropped,
+    DROP COLUMN IF EXISTS capture_stats;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE devices
+    ADD COLUMN IF NOT EXISTS interface_details JSONB NOT NULL DEFAULT '[]'::jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE devices
+    DROP COLUMN IF EXISTS interface_details;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'command_status') THEN
+        CREATE TYPE command_status AS ENUM (
+            'pending',
+            'delivered',
+            'succeeded',
+            'failed',
+            'expired'
+        );
+    END IF;
+END$$;
+
+CREATE TABLE IF NOT EXISTS commands (
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    device_id UUID NOT NULL,
+    CONSTRAINT fk_device
+        FOREIGN KEY(device_id)
+        REFERENCES devices(id)
+        ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    -- the command's typed arguments, NULL for commands without arguments
+    args JSONB,
+    -- the id of the administrator who issued the command, or the component that issued it on its own
+    issued_by TEXT NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    status command_status NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_commands_device_id_issued_at ON commands(device_id, issued_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_commands_device_id_issued_at;
+DROP TABLE IF EXISTS commands;
+DROP TYPE IF EXISTS command_status;
+-- +goose StatementEnd
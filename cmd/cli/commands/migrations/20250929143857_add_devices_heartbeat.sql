@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE devices
+    ADD COLUMN IF NOT EXISTS online BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS agent_version TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS heartbeat JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_devices_online_last_seen_at ON devices(last_seen_at) WHERE online;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_devices_online_last_seen_at;
+ALTER TABLE devices
+    DROP COLUMN IF EXISTS heartbeat,
+    DROP COLUMN IF EXISTS agent_version,
+    DROP COLUMN IF EXISTS last_seen_at,
+    DROP COLUMN IF EXISTS online;
+-- +goose StatementEnd
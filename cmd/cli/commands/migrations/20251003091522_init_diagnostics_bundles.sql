@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'diagnostics_bundle_status') THEN
+        CREATE TYPE diagnostics_bundle_status AS ENUM (
+            'requested',
+            'complete',
+            'failed'
+        );
+    END IF;
+END$$;
+
+CREATE TABLE IF NOT EXISTS diagnostics_bundles (
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    device_id UUID NOT NULL,
+    CONSTRAINT fk_device
+        FOREIGN KEY(device_id)
+        REFERENCES devices(id)
+        ON DELETE CASCADE,
+    status diagnostics_bundle_status NOT NULL DEFAULT 'requested',
+    error TEXT NOT NULL DEFAULT '',
+    -- the zip file uploaded by the agent, only set once the bundle is complete
+    data BYTEA,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_diagnostics_bundles_device_id_requested_at ON diagnostics_bundles(device_id, requested_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_diagnostics_bundles_device_id_requested_at;
+DROP TABLE IF EXISTS diagnostics_bundles;
+DROP TYPE IF EXISTS diagnostics_bundle_status;
+-- +goose StatementEnd
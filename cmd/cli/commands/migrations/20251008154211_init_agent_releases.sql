@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS agent_releases (
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    version TEXT NOT NULL,
+    -- the binary's GOOS and GOARCH
+    os TEXT NOT NULL,
+    arch TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    -- the detached ed25519 signature of the binary
+    signature BYTEA NOT NULL,
+    data BYTEA NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_agent_releases_version_os_arch UNIQUE (version, os, arch)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS agent_releases;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE flows (
+    id SERIAL,
+    first_seen TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (id, first_seen),
+    last_seen TIMESTAMPTZ NOT NULL,
+    os_unique_identifier TEXT NOT NULL,
+    bpf TEXT NOT NULL,
+    interface VARCHAR(255) NOT NULL,
+    ip_version TEXT,
+    ip_src TEXT,
+    ip_dst TEXT,
+    src_port INT,
+    dst_port INT,
+    ip_protocol VARCHAR(255),
+    packets BIGINT NOT NULL,
+    bytes BIGINT NOT NULL,
+    tcp_flags INT,
+    end_reason TEXT
+);
+
+-- Make it a hypertable
+SELECT create_hypertable('flows', 'first_seen');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flows;
+-- +goose StatementEnd
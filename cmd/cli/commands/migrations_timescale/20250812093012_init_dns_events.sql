@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE dns_events (
+    id SERIAL,
+    event_time TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id, event_time),
+    os_unique_identifier TEXT NOT NULL,
+    bpf TEXT NOT NULL,
+    interface VARCHAR(255) NOT NULL,
+    ip_src TEXT,
+    ip_dst TEXT,
+    src_port INT,
+    dst_port INT,
+    dns_id INT NOT NULL,
+    response BOOLEAN NOT NULL,
+    opcode TEXT,
+    response_code TEXT,
+    authoritative BOOLEAN,
+    truncated BOOLEAN,
+    query_name TEXT,
+    query_type TEXT,
+    answers JSONB NOT NULL DEFAULT '[]'
+);
+
+-- Make it a hypertable
+SELECT create_hypertable('dns_events', 'event_time');
+
+CREATE INDEX dns_events_query_name_idx ON dns_events (query_name, event_time DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS dns_events;
+-- +goose StatementEnd
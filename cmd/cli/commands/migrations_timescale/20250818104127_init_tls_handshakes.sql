@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE tls_handshakes (
+    id SERIAL,
+    event_time TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id, event_time),
+    os_unique_identifier TEXT NOT NULL,
+    bpf TEXT NOT NULL,
+    interface VARCHAR(255) NOT NULL,
+    ip_src TEXT,
+    ip_dst TEXT,
+    src_port INT,
+    dst_port INT,
+    hello_type TEXT NOT NULL,
+    tls_version TEXT,
+    sni TEXT,
+    alpn TEXT[],
+    cipher_suites INT[],
+    extensions INT[],
+    ja3 TEXT,
+    ja3_hash TEXT,
+    ja4 TEXT,
+    ja3s TEXT,
+    ja3s_hash TEXT
+);
+
+-- Make it a hypertable
+SELECT create_hypertable('tls_handshakes', 'event_time');
+
+-- fingerprint hunting looks up known-bad fingerprints across all devices
+CREATE INDEX tls_handshakes_ja3_hash_idx ON tls_handshakes (ja3_hash, event_time DESC);
+CREATE INDEX tls_handshakes_ja4_idx ON tls_handshakes (ja4, event_time DESC);
+CREATE INDEX tls_handshakes_ja3s_hash_idx ON tls_handshakes (ja3s_hash, event_time DESC);
+CREATE INDEX tls_handshakes_sni_idx ON tls_handshakes (sni, event_time DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tls_handshakes;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE http_events (
+    id SERIAL,
+    event_time TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id, event_time),
+    os_unique_identifier TEXT NOT NULL,
+    bpf TEXT NOT NULL,
+    interface VARCHAR(255) NOT NULL,
+    ip_src TEXT,
+    ip_dst TEXT,
+    src_port INT,
+    dst_port INT,
+    response BOOLEAN NOT NULL,
+    version TEXT,
+    method TEXT,
+    host TEXT,
+    uri TEXT,
+    user_agent TEXT,
+    status_code INT,
+    content_length BIGINT
+);
+
+-- Make it a hypertable
+SELECT create_hypertable('http_events', 'event_time');
+
+CREATE INDEX http_events_host_idx ON http_events (host, event_time DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS http_events;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE packet_events
+    ADD COLUMN eth_src_mac TEXT,
+    ADD COLUMN eth_dst_mac TEXT,
+    ADD COLUMN ether_type TEXT,
+    ADD COLUMN vlan_ids INT[],
+    ADD COLUMN arp_operation TEXT,
+    ADD COLUMN arp_sender_mac TEXT,
+    ADD COLUMN arp_sender_ip TEXT,
+    ADD COLUMN arp_target_mac TEXT,
+    ADD COLUMN arp_target_ip TEXT,
+    ADD COLUMN icmp_version TEXT,
+    ADD COLUMN icmp_type INT,
+    ADD COLUMN icmp_code INT,
+    ADD COLUMN icmp_type_code TEXT,
+    ADD COLUMN icmp_id INT,
+    ADD COLUMN icmp_seq INT;
+
+-- ARP spoofing shows up as one IP claimed by more than one MAC
+CREATE INDEX packet_events_arp_sender_ip_idx ON packet_events (arp_sender_ip, event_time DESC) WHERE arp_sender_ip IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS packet_events_arp_sender_ip_idx;
+
+ALTER TABLE packet_events
+    DROP COLUMN IF EXISTS eth_src_mac,
+    DROP COLUMN IF EXISTS eth_dst_mac,
+    DROP COLUMN IF EXISTS ether_type,
+    DROP COLUMN IF EXISTS vlan_ids,
+    DROP COLUMN IF EXISTS arp_operation,
+    DROP COLUMN IF EXISTS arp_sender_mac,
+    DROP COLUMN IF EXISTS arp_sender_ip,
+    DROP COLUMN IF EXISTS arp_target_mac,
+    DROP COLUMN IF EXISTS arp_target_ip,
+    DROP COLUMN IF EXISTS icmp_version,
+    DROP COLUMN IF EXISTS icmp_type,
+    DROP COLUMN IF EXISTS icmp_code,
+    DROP COLUMN IF EXISTS icmp_type_code,
+    DROP COLUMN IF EXISTS icmp_id,
+    DROP COLUMN IF EXISTS icmp_seq;
+-- +goose StatementEnd
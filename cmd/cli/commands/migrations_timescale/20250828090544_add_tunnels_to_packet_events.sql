@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- for tunneled packets the ip columns hold the innermost packet's addresses, and these the underlay's
+ALTER TABLE packet_events
+    ADD COLUMN tunnel_types TEXT[],
+    ADD COLUMN tunnel_ids INT[],
+    ADD COLUMN outer_ip_src TEXT,
+    ADD COLUMN outer_ip_dst TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE packet_events
+    DROP COLUMN IF EXISTS tunnel_types,
+    DROP COLUMN IF EXISTS tunnel_ids,
+    DROP COLUMN IF EXISTS outer_ip_src,
+    DROP COLUMN IF EXISTS outer_ip_dst;
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+)
+
+// releaseCmd is a subcommand for managing the agent releases of the artifact registry
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Parent command for [keygen|sign|publish] commands for the agent releases of the artifact registry",
+	Long: "Parent command for [keygen|sign|publish] commands for the agent releases of the artifact registry. " +
+		"Agents only install a release whose detached ed25519 signature matches the public key they were built with.",
+}
+
+// connectApplicationDB connects to the application database sourced from the POSTGRES_* environment variables
+func connectApplicationDB() *sql.DB {
+	connStr := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		os.Getenv("POSTGRES_HOST"),
+		os.Getenv("POSTGRES_PORT"),
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_APPLICATION_DATABASE"),
+		os.Getenv("POSTGRES_SSLMODE"),
+	)
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Fatal("Error connecting to the database:", err)
+	}
+	return db
+}
+
+// readBase64File decodes the base64 content of a key or signature file
+func readBase64File(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 in %s: %w", path, err)
+	}
+	return decoded, nil
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// releaseKeygenCmd is a subcommand that generates a release signing key pair
+var releaseKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generates an ed25519 key pair to sign agent releases with",
+	Long: "Writes a base64 ed25519 private key and public key to <out>.key and <out>.pub. " +
+		"The public key is built into the agent with the RELEASE_PUBLIC_KEY environment variable of scripts/build_agent, " +
+		"the private key signs the releases and must be kept out of the repository.",
+	Run: releaseKeygen,
+}
+
+func releaseKeygen(cobraCmd *cobra.Command, args []string) {
+	out, _ := cobraCmd.Flags().GetString("out")
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal("Error generating key pair:", err)
+	}
+
+	privateKeyPath := out + ".key"
+	publicKeyPath := out + ".pub"
+	err = os.WriteFile(privateKeyPath, []byte(base64.StdEncoding.EncodeToString(privateKey)+"\n"), 0o600)
+	if err != nil {
+		log.Fatal("Error writing private key:", err)
+	}
+	err = os.WriteFile(publicKeyPath, []byte(base64.StdEncoding.EncodeToString(publicKey)+"\n"), 0o644)
+	if err != nil {
+		log.Fatal("Error writing public key:", err)
+	}
+	fmt.Printf("Wrote private key to %s and public key to %s\n", privateKeyPath, publicKeyPath)
+}
+
+func init() {
+	releaseKeygenCmd.Flags().String("out", "release_signing", "Path prefix of the key files.")
+	releaseCmd.AddCommand(releaseKeygenCmd)
+}
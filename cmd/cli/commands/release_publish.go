@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres"
+	"github.com/danielhoward314/packet-sentry/internal/update"
+)
+
+// releasePublishCmd is a subcommand that publishes a signed agent binary to the artifact registry
+var releasePublishCmd = &cobra.Command{
+	Use:   "publish <binary>",
+	Short: "Publishes a signed agent binary to the artifact registry",
+	Long: "Stores an agent binary with its SHA-256 and detached signature as the release of a version for an OS and architecture, " +
+		"for `update_agent` commands to install. The signature defaults to <binary>.sig and is checked against the release manifest and public key before publishing. " +
+		"A published release can't be replaced, publish a new version instead.",
+	Args: cobra.ExactArgs(1),
+	Run:  releasePublish,
+}
+
+func releasePublish(cobraCmd *cobra.Command, args []string) {
+	version, _ := cobraCmd.Flags().GetString("version")
+	goos, _ := cobraCmd.Flags().GetString("os")
+	goarch, _ := cobraCmd.Flags().GetString("arch")
+	signaturePath, _ := cobraCmd.Flags().GetString("signature")
+	publicKeyPath, _ := cobraCmd.Flags().GetString("public-key")
+	if signaturePath == "" {
+		signaturePath = args[0] + ".sig"
+	}
+
+	binary, err := os.ReadFile(args[0])
+	if err != nil {
+		log.Fatal("Error reading agent binary:", err)
+	}
+	signature, err := readBase64File(signaturePath)
+	if err != nil {
+		log.Fatal("Error reading signature:", err)
+	}
+	publicKey, err := readBase64File(publicKeyPath)
+	if err != nil {
+		log.Fatal("Error reading public key:", err)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		log.Fatalf("Error reading public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(publicKey))
+	}
+	// agents would refuse a release with a signature of another key or manifest, so it is never published
+	sum := sha256.Sum256(binary)
+	manifest := update.ReleaseManifest(version, goos, goarch, hex.EncodeToString(sum[:]), uint64(len(binary)))
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), manifest, signature) {
+		log.Fatal("Error verifying signature: the signature doesn't match the release manifest and public key")
+	}
+
+	release := &dao.AgentRelease{
+		Version:   version,
+		OS:        goos,
+		Arch:      goarch,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Signature: signature,
+	}
+
+	db := connectApplicationDB()
+	defer db.Close()
+
+	err = postgres.NewAgentReleases(db).Create(release, binary)
+	if err != nil {
+		log.Fatal("Error publishing release:", err)
+	}
+	fmt.Printf("Published release %s for %s/%s (%d bytes, sha256 %s)\n", release.Version, release.OS, release.Arch, release.SizeBytes, release.SHA256)
+}
+
+func init() {
+	releasePublishCmd.Flags().String("version", "", "Version of the agent binary, as reported by the agent.")
+	releasePublishCmd.Flags().String("os", "", "GOOS of the agent binary <darwin|linux>.")
+	releasePublishCmd.Flags().String("arch", "", "GOARCH of the agent binary <amd64|arm64>.")
+	releasePublishCmd.Flags().String("signature", "", "Path of the base64 detached signature, defaults to <binary>.sig.")
+	releasePublishCmd.Flags().String("public-key", "release_signing.pub", "Path of the base64 ed25519 public key agents are built with.")
+	_ = releasePublishCmd.MarkFlagRequired("version")
+	_ = releasePublishCmd.MarkFlagRequired("os")
+	_ = releasePublishCmd.MarkFlagRequired("arch")
+	releaseCmd.AddCommand(releasePublishCmd)
+}
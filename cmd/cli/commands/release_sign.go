@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/danielhoward314/packet-sentry/internal/update"
+)
+
+// releaseSignCmd is a subcommand that signs the release manifest of an agent binary
+var releaseSignCmd = &cobra.Command{
+	Use:   "sign <binary>",
+	Short: "Signs the release manifest of an agent binary with the release signing key",
+	Long: "Writes the base64 detached ed25519 signature of the manifest of the release's version, OS, architecture and the binary's SHA-256 and size to <binary>.sig, " +
+		"for `release publish` to publish along with the binary. The version, OS and architecture must be the ones the binary is published with.",
+	Args: cobra.ExactArgs(1),
+	Run:  releaseSign,
+}
+
+func releaseSign(cobraCmd *cobra.Command, args []string) {
+	privateKeyPath, _ := cobraCmd.Flags().GetString("private-key")
+	version, _ := cobraCmd.Flags().GetString("version")
+	goos, _ := cobraCmd.Flags().GetString("os")
+	goarch, _ := cobraCmd.Flags().GetString("arch")
+
+	privateKey, err := readBase64File(privateKeyPath)
+	if err != nil {
+		log.Fatal("Error reading private key:", err)
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		log.Fatalf("Error reading private key: expected %d bytes, got %d", ed25519.PrivateKeySize, len(privateKey))
+	}
+
+	binary, err := os.ReadFile(args[0])
+	if err != nil {
+		log.Fatal("Error reading agent binary:", err)
+	}
+
+	sum := sha256.Sum256(binary)
+	manifest := update.ReleaseManifest(version, goos, goarch, hex.EncodeToString(sum[:]), uint64(len(binary)))
+	signature := ed25519.Sign(ed25519.PrivateKey(privateKey), manifest)
+	signaturePath := args[0] + ".sig"
+	err = os.WriteFile(signaturePath, []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0o644)
+	if err != nil {
+		log.Fatal("Error writing signature:", err)
+	}
+	fmt.Printf("Wrote signature of release %s for %s/%s to %s\n", version, goos, goarch, signaturePath)
+}
+
+func init() {
+	releaseSignCmd.Flags().String("private-key", "release_signing.key", "Path of the base64 ed25519 private key.")
+	releaseSignCmd.Flags().String("version", "", "Version of the agent binary, as reported by the agent.")
+	releaseSignCmd.Flags().String("os", "", "GOOS of the agent binary <darwin|linux>.")
+	releaseSignCmd.Flags().String("arch", "", "GOARCH of the agent binary <amd64|arm64>.")
+	_ = releaseSignCmd.MarkFlagRequired("version")
+	_ = releaseSignCmd.MarkFlagRequired("os")
+	_ = releaseSignCmd.MarkFlagRequired("arch")
+	releaseCmd.AddCommand(releaseSignCmd)
+}
@@ -30,6 +30,8 @@ import (
 const (
 	serverCertPath = "certs/gateway_server.cert.pem"
 	serverKeyPath  = "certs/gateway_server.key.pem"
+	// maxRecvMsgSize is large enough for the pcap files of packet slice downloads
+	maxRecvMsgSize = 80 * 1024 * 1024
 )
 
 func main() {
@@ -50,7 +52,11 @@ func main() {
 	}
 	apiAddr := apiHost + ":" + apiPort
 	// TODO: service mesh / envoy sidecar for mTLS between gateway and web-api
-	conn, err := grpc.NewClient(apiAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(
+		apiAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxRecvMsgSize)),
+	)
 	if err != nil {
 		log.Fatalf("could not connect to hello service: %v", err)
 	}
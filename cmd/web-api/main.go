@@ -23,6 +23,7 @@ import (
 
 	psPostgres "github.com/danielhoward314/packet-sentry/dao/postgres"
 	psRedis "github.com/danielhoward314/packet-sentry/dao/redis"
+	"github.com/danielhoward314/packet-sentry/internal/bpf"
 	pbAccounts "github.com/danielhoward314/packet-sentry/protogen/golang/accounts"
 	pbAdministrators "github.com/danielhoward314/packet-sentry/protogen/golang/administrators"
 	pbAuth "github.com/danielhoward314/packet-sentry/protogen/golang/auth"
@@ -195,6 +196,8 @@ func main() {
 	devicesSvc := services.NewDevicesService(
 		datastore,
 		js,
+		tokenDatastore,
+		bpf.Validate,
 		logger,
 	)
 
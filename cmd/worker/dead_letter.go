@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/danielhoward314/packet-sentry/internal/streams"
+)
+
+// deadLetterer retries messages that failed to be processed, and dead-letters them to EVENTS_DLQ
+// once they can't be processed or have failed on their last delivery
+type deadLetterer struct {
+	js         nats.JetStreamContext
+	logger     *slog.Logger
+	nakDelay   time.Duration
+	maxDeliver int
+}
+
+// retryOrDeadLetter naks the message for redelivery, with a delay that grows with each delivery,
+// or dead-letters it if this was its last delivery before the consumer's max deliver
+func (d *deadLetterer) retryOrDeadLetter(msg *nats.Msg, err error) {
+	var numDelivered uint64 = 1
+	metadata, metadataErr := msg.Metadata()
+	if metadataErr == nil {
+		numDelivered = metadata.NumDelivered
+	}
+
+	if numDelivered >= uint64(d.maxDeliver) {
+		d.deadLetter(msg, streams.DLQReasonWrite, err)
+		return
+	}
+	_ = msg.NakWithDelay(d.nakDelay * time.Duration(numDelivered))
+}
+
+// deadLetter republishes the message to the EVENTS_DLQ stream with error metadata headers, then terminates its delivery.
+// If the republish fails, the message is nak'ed instead so it isn't lost.
+func (d *deadLetterer) deadLetter(msg *nats.Msg, reason string, err error) {
+	logger := d.logger.With("function", "deadLetterer.deadLetter")
+
+	dlqMsg := nats.NewMsg(streams.SubjectPrefixEventsDLQ + msg.Subject)
+	dlqMsg.Data = msg.Data
+	for key, values := range msg.Header {
+		for _, value := range values {
+			dlqMsg.Header.Add(key, value)
+		}
+	}
+	dlqMsg.Header.Set(streams.HeaderDLQReason, reason)
+	dlqMsg.Header.Set(streams.HeaderDLQError, err.Error())
+	dlqMsg.Header.Set(streams.HeaderDLQOriginalSubject, msg.Subject)
+	dlqMsg.Header.Set(streams.HeaderDLQFailedAt, time.Now().UTC().Format(time.RFC3339))
+	metadata, metadataErr := msg.Metadata()
+	if metadataErr == nil {
+		dlqMsg.Header.Set(streams.HeaderDLQOriginalSequence, strconv.FormatUint(metadata.Sequence.Stream, 10))
+		dlqMsg.Header.Set(streams.HeaderDLQNumDelivered, strconv.FormatUint(metadata.NumDelivered, 10))
+	}
+
+	_, publishErr := d.js.PublishMsg(dlqMsg)
+	if publishErr != nil {
+		logger.Error("failed to publish message to dead-letter stream, nak'ing it", "error", publishErr, "subject", msg.Subject)
+		_ = msg.NakWithDelay(d.nakDelay)
+		return
+	}
+	logger.Warn("dead-lettered message", "subject", msg.Subject, "reason", reason, "error", err)
+	_ = msg.Term()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// dnsEventColumns are the dns_events columns written by COPY, in the order of the values of dnsEventRow
+var dnsEventColumns = []string{
+	"os_unique_identifier", "bpf", "interface", "ip_src", "ip_dst",
+	"src_port", "dst_port", "dns_id", "response", "opcode",
+	"response_code", "authoritative", "truncated", "query_name", "query_type",
+	"answers", "event_time",
+}
+
+// dnsAnswer is the JSON of each element of the answers column of dns_events
+type dnsAnswer struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Class string `json:"class"`
+	TTL   uint32 `json:"ttl"`
+	Data  string `json:"data"`
+}
+
+// dnsEventRow returns the dns_events column values of a packet event with a DNS layer, in the order of dnsEventColumns
+func dnsEventRow(osUniqueIdentifier string, packetEvent *pbAgent.PacketEvent) []any {
+	dns := packetEvent.Layers.DnsLayer
+
+	eventTime := time.Now()
+	if packetEvent.CaptureTime != nil {
+		eventTime = packetEvent.CaptureTime.AsTime()
+	}
+
+	var srcIP, dstIP string
+	if packetEvent.Layers.IpLayer != nil {
+		srcIP = packetEvent.Layers.IpLayer.SrcIp
+		dstIP = packetEvent.Layers.IpLayer.DstIp
+	}
+
+	// DNS is mostly over UDP, but falls back to TCP for large responses
+	var srcPort, dstPort int32
+	if packetEvent.Layers.UdpLayer != nil {
+		srcPort = int32(packetEvent.Layers.UdpLayer.SrcPort)
+		dstPort = int32(packetEvent.Layers.UdpLayer.DstPort)
+	} else if packetEvent.Layers.TcpLayer != nil {
+		srcPort = int32(packetEvent.Layers.TcpLayer.SrcPort)
+		dstPort = int32(packetEvent.Layers.TcpLayer.DstPort)
+	}
+
+	// almost all DNS messages have exactly one question
+	var queryName, queryType string
+	if len(dns.Questions) > 0 {
+		queryName = dns.Questions[0].Name
+		queryType = dns.Questions[0].Type
+	}
+
+	answers := make([]dnsAnswer, 0, len(dns.Answers))
+	for _, answer := range dns.Answers {
+		answers = append(answers, dnsAnswer{
+			Name:  answer.Name,
+			Type:  answer.Type,
+			Class: answer.Class,
+			TTL:   answer.Ttl,
+			Data:  answer.Data,
+		})
+	}
+	// marshaling a slice of structs of strings and ints can't fail
+	answersJSON, _ := json.Marshal(answers)
+
+	return []any{
+		osUniqueIdentifier, packetEvent.Bpf, packetEvent.DeviceName, srcIP, dstIP,
+		srcPort, dstPort, int32(dns.Id), dns.Response, dns.Opcode,
+		dns.ResponseCode, dns.Authoritative, dns.Truncated, queryName, queryType,
+		string(answersJSON), eventTime,
+	}
+}
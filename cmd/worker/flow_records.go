@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/danielhoward314/packet-sentry/internal/streams"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// flowColumns are the flows columns written by COPY, in the order of the values of flowRow
+var flowColumns = []string{
+	"first_seen", "last_seen", "os_unique_identifier", "bpf", "interface",
+	"ip_version", "ip_src", "ip_dst", "src_port", "dst_port",
+	"ip_protocol", "packets", "bytes", "tcp_flags", "end_reason",
+}
+
+// flowRecordWriter pulls batches of messages from the FLOWS stream and writes their flow records
+// in a single COPY transaction, the same way the packetEventWriter writes packet events.
+// Messages are acked only once the transaction commits, and nak'ed with a delay for redelivery if it fails.
+// Messages that can't be unmarshaled, or still fail to be written on their last delivery, are dead-lettered to EVENTS_DLQ.
+type flowRecordWriter struct {
+	*deadLetterer
+	db        *sql.DB
+	logger    *slog.Logger
+	sub       *nats.Subscription
+	batchSize int
+	fetchWait time.Duration
+}
+
+// run fetches and writes batches until the context is canceled.
+// A batch in flight when the context is canceled is still written, acked or nak'ed before run returns.
+func (w *flowRecordWriter) run(ctx context.Context) {
+	logger := w.logger.With("function", "flowRecordWriter.run")
+	writeCtx := context.WithoutCancel(ctx)
+
+	for ctx.Err() == nil {
+		msgs, err := w.sub.Fetch(w.batchSize, nats.MaxWait(w.fetchWait))
+		if err != nil {
+			if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+				// no messages within the fetch wait
+				continue
+			}
+			logger.Error("failed to fetch flow record messages", "error", err)
+			time.Sleep(w.fetchWait)
+			continue
+		}
+		w.writeBatch(writeCtx, msgs)
+	}
+}
+
+// pendingFlowRecord is a fetched message with the row of its flow record
+type pendingFlowRecord struct {
+	msg *nats.Msg
+	row []any
+}
+
+// writeBatch writes the flow records of the messages, then acks all of them together.
+// If the batch fails, the messages are written one at a time, so a poison message doesn't hold back the rest.
+func (w *flowRecordWriter) writeBatch(ctx context.Context, msgs []*nats.Msg) {
+	logger := w.logger.With("function", "flowRecordWriter.writeBatch")
+
+	rows := make([][]any, 0, len(msgs))
+	pending := make([]pendingFlowRecord, 0, len(msgs))
+	for _, msg := range msgs {
+		var flowRecord pbAgent.FlowRecord
+		err := proto.Unmarshal(msg.Data, &flowRecord)
+		if err != nil {
+			// redelivering a message that can't be unmarshaled won't fix it
+			logger.Error("failed to unmarshal protobuf message, dead-lettering it", "error", err, "subject", msg.Subject)
+			w.deadLetter(msg, streams.DLQReasonUnmarshal, err)
+			continue
+		}
+
+		row := flowRow(osUniqueIdentifierFromSubject(msg.Subject), &flowRecord)
+		rows = append(rows, row)
+		pending = append(pending, pendingFlowRecord{msg: msg, row: row})
+	}
+
+	if len(rows) > 0 {
+		err := writeFlows(ctx, w.db, rows)
+		if err != nil {
+			logger.Error("failed to write flow records, writing messages one at a time", "error", err, "messages", len(pending))
+			w.writeEach(ctx, pending)
+			return
+		}
+		logger.Info("wrote flow records", "messages", len(pending))
+	}
+
+	for _, p := range pending {
+		w.ack(p.msg)
+	}
+}
+
+// writeEach writes the flow record of each message in its own transaction,
+// acking the messages that are written and retrying or dead-lettering the rest
+func (w *flowRecordWriter) writeEach(ctx context.Context, pending []pendingFlowRecord) {
+	logger := w.logger.With("function", "flowRecordWriter.writeEach")
+
+	for _, p := range pending {
+		err := writeFlows(ctx, w.db, [][]any{p.row})
+		if err != nil {
+			logger.Error("failed to write flow record of message", "error", err, "subject", p.msg.Subject)
+			w.retryOrDeadLetter(p.msg, err)
+			continue
+		}
+		w.ack(p.msg)
+	}
+}
+
+func (w *flowRecordWriter) ack(msg *nats.Msg) {
+	err := msg.Ack()
+	if err != nil {
+		// the rows are committed, so a redelivery after a failed ack duplicates them rather than losing them
+		w.logger.Error("failed to ack flow record message", "error", err, "subject", msg.Subject)
+	}
+}
+
+// writeFlows writes the rows to the flows table with COPY in a single transaction
+func writeFlows(ctx context.Context, db *sql.DB, rows [][]any) error {
+	txn, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	err = copyRows(ctx, txn, "flows", flowColumns, rows)
+	if err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+// flowRow returns the flows column values of the flow record, in the order of flowColumns
+func flowRow(osUniqueIdentifier string, flowRecord *pbAgent.FlowRecord) []any {
+	return []any{
+		flowRecord.FirstSeen.AsTime(), flowRecord.LastSeen.AsTime(), osUniqueIdentifier, flowRecord.Bpf, flowRecord.DeviceName,
+		flowRecord.IpVersion, flowRecord.SrcIp, flowRecord.DstIp, int32(flowRecord.SrcPort), int32(flowRecord.DstPort),
+		getProtocolName(flowRecord.Protocol), int64(flowRecord.Packets), int64(flowRecord.Bytes), int32(flowRecord.TcpFlags), flowRecord.EndReason,
+	}
+}
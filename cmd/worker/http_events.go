@@ -0,0 +1,54 @@
+package main
+
+import (
+	"time"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// httpEventColumns are the http_events columns written by COPY, in the order of the values of the http event rows
+var httpEventColumns = []string{
+	"os_unique_identifier", "bpf", "interface", "ip_src", "ip_dst",
+	"src_port", "dst_port", "response", "version", "method",
+	"host", "uri", "user_agent", "status_code", "content_length",
+	"event_time",
+}
+
+// httpEventRows returns an http_events row for each HTTP message of a packet event with an HTTP layer, in the order of httpEventColumns
+func httpEventRows(osUniqueIdentifier string, packetEvent *pbAgent.PacketEvent) [][]any {
+	eventTime := time.Now()
+	if packetEvent.CaptureTime != nil {
+		eventTime = packetEvent.CaptureTime.AsTime()
+	}
+
+	var srcIP, dstIP string
+	if packetEvent.Layers.IpLayer != nil {
+		srcIP = packetEvent.Layers.IpLayer.SrcIp
+		dstIP = packetEvent.Layers.IpLayer.DstIp
+	}
+
+	var srcPort, dstPort int32
+	if packetEvent.Layers.TcpLayer != nil {
+		srcPort = int32(packetEvent.Layers.TcpLayer.SrcPort)
+		dstPort = int32(packetEvent.Layers.TcpLayer.DstPort)
+	}
+
+	rows := make([][]any, 0, len(packetEvent.Layers.HttpLayer.Messages))
+	for _, message := range packetEvent.Layers.HttpLayer.Messages {
+		var statusCode any
+		if message.Response {
+			statusCode = message.StatusCode
+		}
+		var contentLength any
+		if message.ContentLength >= 0 {
+			contentLength = message.ContentLength
+		}
+		rows = append(rows, []any{
+			osUniqueIdentifier, packetEvent.Bpf, packetEvent.DeviceName, srcIP, dstIP,
+			srcPort, dstPort, message.Response, message.Version, message.Method,
+			message.Host, message.Uri, message.UserAgent, statusCode, contentLength,
+			eventTime,
+		})
+	}
+	return rows
+}
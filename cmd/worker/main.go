@@ -3,20 +3,21 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/nats-io/nats.go"
-	"google.golang.org/protobuf/proto"
 
-	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+	"github.com/danielhoward314/packet-sentry/internal/streams"
 )
 
 var protocolNames = []string{
@@ -192,11 +193,13 @@ func main() {
 	}
 
 	natsURL := getEnv("NATS_URL", nats.DefaultURL)
-	nc, err := nats.Connect(natsURL)
+	natsClosed := make(chan struct{})
+	nc, err := nats.Connect(natsURL, nats.ClosedHandler(func(*nats.Conn) {
+		close(natsClosed)
+	}))
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer nc.Drain()
 
 	js, err := nc.JetStream()
 	if err != nil {
@@ -211,6 +214,23 @@ func main() {
 		log.Fatal("AddStream error:", err)
 	}
 
+	// the dead-letter stream is updated if it already exists, since earlier workers created it without the flows subjects
+	err = ensureStream(js, &nats.StreamConfig{
+		Name:     streams.StreamEventsDLQ,
+		Subjects: []string{streams.SubjectPrefixEventsDLQ + "events.*", streams.SubjectPrefixEventsDLQ + "flows.*"},
+	})
+	if err != nil {
+		log.Fatal("AddStream error:", err)
+	}
+
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:     "FLOWS",
+		Subjects: []string{"flows.*"},
+	})
+	if err != nil && err != nats.ErrStreamNameAlreadyInUse && !strings.Contains(err.Error(), "already in use") {
+		log.Fatal("AddStream error:", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -218,161 +238,155 @@ func main() {
 		c := make(chan os.Signal, 1)
 		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 		<-c
-		logger.Info("Received shutdown signal, exiting...")
+		logger.Info("Received shutdown signal, draining...")
 		cancel()
 	}()
 
-	sub, err := js.Subscribe("events.*", func(msg *nats.Msg) {
-		handlePacketEvent(ctx, logger, db, msg)
-	}, nats.Durable("worker-durable"), nats.ManualAck())
+	// the consumers are shared by all worker replicas, so they are created or updated here and bound to,
+	// rather than created by the subscriptions, which would delete them when a replica shuts down
+	// messages still failing on their last delivery are dead-lettered by the packet event and flow record writers
+	maxDeliver := getEnvInt("WORKER_MAX_DELIVER", 10)
+	ackWait := getEnvDuration("WORKER_ACK_WAIT", 30*time.Second)
+	err = ensureConsumer(js, "EVENTS", &nats.ConsumerConfig{
+		Durable:       "worker-events",
+		FilterSubject: "events.*",
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxDeliver:    maxDeliver,
+		MaxAckPending: getEnvInt("WORKER_MAX_ACK_PENDING", 1000),
+	})
 	if err != nil {
-		log.Fatal("Error subscribing to JetStream:", err)
+		log.Fatal("Error creating JetStream consumer:", err)
 	}
-	defer sub.Unsubscribe()
-
-	logger.Info("Worker started, listening for packet events...")
-
-	<-ctx.Done()
-}
 
-func handlePacketEvent(ctx context.Context, logger *slog.Logger, db *sql.DB, msg *nats.Msg) {
-	var packetEvent pbAgent.PacketEvent
-	err := proto.Unmarshal(msg.Data, &packetEvent)
+	// packet events are pulled in batches by the packet event writer, replacing the push consumer `worker-durable`,
+	// which would otherwise keep its interest in the stream and pile up unacked messages
+	err = deleteConsumer(js, "EVENTS", "worker-durable")
 	if err != nil {
-		logger.Info("Failed to unmarshal protobuf message:", "error", err)
-		_ = msg.Ack()
-		return
+		log.Fatal("Error deleting replaced JetStream consumer:", err)
 	}
-
-	// the NATS subject should be "events.id" where the id is the device row's os_unique_identifier
-	osUniqueIdentifier := ""
-	parts := strings.SplitN(msg.Subject, ".", 2)
-	if len(parts) == 2 {
-		osUniqueIdentifier = parts[1]
+	sub, err := js.PullSubscribe("events.*", "worker-events", nats.Bind("EVENTS", "worker-events"))
+	if err != nil {
+		log.Fatal("Error subscribing to JetStream:", err)
 	}
-	logger.Info("derived os_unique_identifier from subject", "os_unique_identifier", osUniqueIdentifier)
-
-	// capture config
-	bpf := packetEvent.Bpf
-	interfaceName := packetEvent.DeviceName
-	promiscuous := packetEvent.Promiscuous
-	snapLen := packetEvent.SnapLen
-
-	// capture metadata
-	captureLen := int32(packetEvent.CaptureLength)
-	originalLen := int32(packetEvent.OriginalLength)
-	truncated := packetEvent.Truncated
-	interfaceIndex := packetEvent.InterfaceIndex
 
-	// ipLayer
-	var dstIP, ipVersion, ipProtocol, srcIP string
-	var ipHopLimit, ipTTL int32
-
-	// tcpLayer
-	var tcpAckFlag, tcpFin, tcpPsh, tcpRst, tcpSyn, tcpUrg bool
-	var dstPortTCP, srcPortTCP, tcpWindow int32
-	var tcpAck, tcpSeq int64
-
-	var dstPortUDP, srcPortUDP, udpLen int32
-	var tlsRecordsCount int
+	dlq := &deadLetterer{
+		js:         js,
+		logger:     logger,
+		nakDelay:   getEnvDuration("WORKER_NAK_DELAY", 5*time.Second),
+		maxDeliver: maxDeliver,
+	}
+	writer := &packetEventWriter{
+		deadLetterer: dlq,
+		db:           db,
+		logger:       logger,
+		sub:          sub,
+		batchSize:    getEnvInt("WORKER_FETCH_BATCH_SIZE", 100),
+		fetchWait:    getEnvDuration("WORKER_FETCH_MAX_WAIT", 1*time.Second),
+	}
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		writer.run(ctx)
+	}()
 
-	if packetEvent.Layers != nil {
-		if packetEvent.Layers.IpLayer != nil {
-			srcIP = packetEvent.Layers.IpLayer.SrcIp
-			dstIP = packetEvent.Layers.IpLayer.DstIp
-			ipVersion = packetEvent.Layers.IpLayer.Version
-			if ipVersion == "IPv4" {
-				ipProtocol = getProtocolName(packetEvent.Layers.IpLayer.Protocol)
-				ipTTL = int32(packetEvent.Layers.IpLayer.Ttl)
-			} else {
-				ipHopLimit = int32(packetEvent.Layers.IpLayer.HopLimit)
-			}
+	// flow records are pulled in batches by the flow record writer. Push consumers can't be updated to pull consumers,
+	// so this replaces the push consumers `worker-flows-durable` and `worker-flows` under a new name.
+	for _, replaced := range []string{"worker-flows-durable", "worker-flows"} {
+		err = deleteConsumer(js, "FLOWS", replaced)
+		if err != nil {
+			log.Fatal("Error deleting replaced JetStream consumer:", err)
 		}
+	}
+	err = ensureConsumer(js, "FLOWS", &nats.ConsumerConfig{
+		Durable:       "worker-flows-pull",
+		FilterSubject: "flows.*",
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxDeliver:    maxDeliver,
+		MaxAckPending: getEnvInt("WORKER_MAX_ACK_PENDING", 1000),
+	})
+	if err != nil {
+		log.Fatal("Error creating JetStream consumer:", err)
+	}
 
-		if packetEvent.Layers.TcpLayer != nil {
-			srcPortTCP = int32(packetEvent.Layers.TcpLayer.SrcPort)
-			dstPortTCP = int32(packetEvent.Layers.TcpLayer.DstPort)
-			tcpAck = int64(packetEvent.Layers.TcpLayer.Ack)
-			tcpSeq = int64(packetEvent.Layers.TcpLayer.Seq)
-			tcpWindow = int32(packetEvent.Layers.TcpLayer.Window)
-
-			tcpAckFlag = packetEvent.Layers.TcpLayer.AckFlag
-			tcpFin = packetEvent.Layers.TcpLayer.Fin
-			tcpPsh = packetEvent.Layers.TcpLayer.Psh
-			tcpRst = packetEvent.Layers.TcpLayer.Rst
-			tcpSyn = packetEvent.Layers.TcpLayer.Syn
-			tcpUrg = packetEvent.Layers.TcpLayer.Urg
-		} else if packetEvent.Layers.UdpLayer != nil {
-			srcPortUDP = int32(packetEvent.Layers.UdpLayer.SrcPort)
-			dstPortUDP = int32(packetEvent.Layers.UdpLayer.DstPort)
-			udpLen = int32(packetEvent.Layers.UdpLayer.Length)
-		} else if packetEvent.Layers.TlsLayer != nil {
-			tlsRecordsCount = len(packetEvent.Layers.TlsLayer.Records)
-		}
+	flowsSub, err := js.PullSubscribe("flows.*", "worker-flows-pull", nats.Bind("FLOWS", "worker-flows-pull"))
+	if err != nil {
+		log.Fatal("Error subscribing to JetStream:", err)
 	}
 
-	debugSQL := fmt.Sprintf(
-		`INSERT INTO packet_events (
-            os_unique_identifier, bpf, interface, promiscuous, snap_length,
-            capture_length, original_length, interface_index, truncated, ip_version,
-            ip_src, ip_dst, ip_ttl, ip_hop_limit, ip_protocol,
-            tcp_src_port, tcp_dst_port, tcp_seq, tcp_ack, tcp_fin,
-            tcp_syn, tcp_rst, tcp_psh, tcp_ack_flag, tcp_urg,
-            tcp_window, udp_src_port, udp_dst_port, udp_length, tls_record_count
-        ) VALUES (
-            '%v', '%v', '%v', %v, %v,
-            %v, %v, %v, %v, '%v',
-            '%v', '%v', %v, %v, '%v',
-            %v, %v, %v, %v, %v,
-            %v, %v, %v, %v, %v,
-            %v, %v, %v, %v, %v
-        )`,
-		osUniqueIdentifier, bpf, interfaceName, promiscuous, snapLen,
-		captureLen, originalLen, interfaceIndex, truncated, ipVersion,
-		srcIP, dstIP, ipTTL, ipHopLimit, ipProtocol,
-		srcPortTCP, dstPortTCP, tcpSeq, tcpAck, tcpFin,
-		tcpSyn, tcpRst, tcpPsh, tcpAckFlag, tcpUrg,
-		tcpWindow, srcPortUDP, dstPortUDP, udpLen, int32(tlsRecordsCount),
-	)
-	logger.Info("Debug SQL query", "sql", debugSQL)
+	flowWriter := &flowRecordWriter{
+		deadLetterer: dlq,
+		db:           db,
+		logger:       logger,
+		sub:          flowsSub,
+		batchSize:    getEnvInt("WORKER_FETCH_BATCH_SIZE", 100),
+		fetchWait:    getEnvDuration("WORKER_FETCH_MAX_WAIT", 1*time.Second),
+	}
+	flowWriterDone := make(chan struct{})
+	go func() {
+		defer close(flowWriterDone)
+		flowWriter.run(ctx)
+	}()
 
-	query := `
-	INSERT INTO packet_events (
-		os_unique_identifier, bpf, interface, promiscuous, snap_length,
-		capture_length, original_length, interface_index, truncated, ip_version,
-		ip_src, ip_dst, ip_ttl, ip_hop_limit, ip_protocol,
-		tcp_src_port, tcp_dst_port, tcp_seq, tcp_ack, tcp_fin,
-		tcp_syn, tcp_rst, tcp_psh, tcp_ack_flag, tcp_urg,
-		tcp_window, udp_src_port, udp_dst_port, udp_length, tls_record_count
-	) VALUES (
-		$1, $2, $3, $4, $5,
-		$6, $7, $8, $9, $10,
-		$11, $12, $13, $14, $15,
-		$16, $17, $18, $19, $20,
-		$21, $22, $23, $24, $25,
-		$26, $27, $28, $29, $30
-	)
-	RETURNING id, event_time;
-	`
+	logger.Info("Worker started, listening for packet events and flow records...")
 
-	var id int
-	var eventTime time.Time
+	<-ctx.Done()
 
-	err = db.QueryRowContext(
-		ctx,
-		query,
-		osUniqueIdentifier, bpf, interfaceName, promiscuous, snapLen, // $1 - $5
-		captureLen, originalLen, interfaceIndex, truncated, ipVersion, // $6 - $10
-		srcIP, dstIP, ipTTL, ipHopLimit, ipProtocol, // $11 - $15
-		srcPortTCP, dstPortTCP, tcpSeq, tcpAck, tcpFin, // $16 - $20
-		tcpSyn, tcpRst, tcpPsh, tcpAckFlag, tcpUrg, // $21 - $25
-		tcpWindow, srcPortUDP, dstPortUDP, udpLen, int32(tlsRecordsCount), // $26 - $30
-	).Scan(&id, &eventTime)
+	// graceful drain: the packet event and flow record writers stop fetching and finish their in-flight batches,
+	// then the NATS connection is drained before it closes
+	drainTimeout := getEnvDuration("WORKER_DRAIN_TIMEOUT", 25*time.Second)
+	drainDeadline := time.After(drainTimeout)
+	logger.Info("Draining in-flight packet event and flow record batches...")
+	writersDone := make(chan struct{})
+	go func() {
+		<-writerDone
+		<-flowWriterDone
+		close(writersDone)
+	}()
+	select {
+	case <-writersDone:
+	case <-drainDeadline:
+		logger.Warn("Timed out waiting on in-flight batches, their messages will be redelivered after the ack wait")
+	}
+	err = nc.Drain()
 	if err != nil {
-		log.Printf("insert error: %v", err)
+		logger.Error("Failed to drain NATS connection", "error", err)
+		return
 	}
+	select {
+	case <-natsClosed:
+		logger.Info("Drained, exiting")
+	case <-drainDeadline:
+		logger.Warn("Timed out draining NATS connection, exiting")
+	}
+}
+
+// ensureConsumer creates the durable consumer on the stream, or updates it to the config if it already exists
+func ensureConsumer(js nats.JetStreamContext, stream string, cfg *nats.ConsumerConfig) error {
+	_, err := js.UpdateConsumer(stream, cfg)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		_, err = js.AddConsumer(stream, cfg)
+	}
+	return err
+}
 
-	_ = msg.Ack()
+// deleteConsumer deletes the durable consumer from the stream, if it exists
+func deleteConsumer(js nats.JetStreamContext, stream, durable string) error {
+	err := js.DeleteConsumer(stream, durable)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ensureStream creates the stream, or updates it to the config if it already exists
+func ensureStream(js nats.JetStreamContext, cfg *nats.StreamConfig) error {
+	_, err := js.AddStream(cfg)
+	if errors.Is(err, nats.ErrStreamNameAlreadyInUse) || (err != nil && strings.Contains(err.Error(), "already in use")) {
+		_, err = js.UpdateStream(cfg)
+	}
+	return err
 }
 
 // getEnv reads an environment variable or returns a default
@@ -383,6 +397,24 @@ func getEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
+// getEnvInt reads an integer environment variable or returns a default if it is unset or invalid
+func getEnvInt(key string, defaultVal int) int {
+	val, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil || val <= 0 {
+		return defaultVal
+	}
+	return val
+}
+
+// getEnvDuration reads a duration environment variable, e.g. "5s", or returns a default if it is unset or invalid
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	val, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil || val <= 0 {
+		return defaultVal
+	}
+	return val
+}
+
 func getProtocolName(proto uint32) string {
 	if proto < uint32(len(protocolNames)) && protocolNames[proto] != "" {
 		return protocolNames[proto]
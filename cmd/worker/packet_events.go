@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/danielhoward314/packet-sentry/internal/streams"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// tableColumns are the columns written by COPY to each table the packet event writer writes to,
+// in the order of the values of the table's rows
+var tableColumns = map[string][]string{
+	"packet_events":  packetEventColumns,
+	"dns_events":     dnsEventColumns,
+	"tls_handshakes": tlsHandshakeColumns,
+	"http_events":    httpEventColumns,
+}
+
+// tableRows are the rows to write by table name
+type tableRows map[string][][]any
+
+// addPacketEvent adds the rows of the packet event, one to packet_events and one to each table of its decoded layers.
+// Events without packet data, which carry the HTTP messages an agent's flush completed, get no packet_events row.
+func (r tableRows) addPacketEvent(osUniqueIdentifier string, packetEvent *pbAgent.PacketEvent) {
+	if packetEvent.OriginalLength > 0 {
+		r["packet_events"] = append(r["packet_events"], packetEventRow(osUniqueIdentifier, packetEvent))
+	}
+	if packetEvent.GetLayers().GetDnsLayer() != nil {
+		r["dns_events"] = append(r["dns_events"], dnsEventRow(osUniqueIdentifier, packetEvent))
+	}
+	if tls := packetEvent.GetLayers().GetTlsLayer(); tls.GetClientHello() != nil || tls.GetServerHello() != nil {
+		r["tls_handshakes"] = append(r["tls_handshakes"], tlsHandshakeRows(osUniqueIdentifier, packetEvent)...)
+	}
+	if len(packetEvent.GetLayers().GetHttpLayer().GetMessages()) > 0 {
+		r["http_events"] = append(r["http_events"], httpEventRows(osUniqueIdentifier, packetEvent)...)
+	}
+}
+
+// add adds all rows of other to r
+func (r tableRows) add(other tableRows) {
+	for table, rows := range other {
+		r[table] = append(r[table], rows...)
+	}
+}
+
+// count returns the number of rows across all tables
+func (r tableRows) count() int {
+	count := 0
+	for _, rows := range r {
+		count += len(rows)
+	}
+	return count
+}
+
+// packetEventColumns are the packet_events columns written by COPY, in the order of the values of packetEventRow
+var packetEventColumns = []string{
+	"os_unique_identifier", "bpf", "interface", "promiscuous", "snap_length",
+	"capture_length", "original_length", "interface_index", "truncated", "ip_version",
+	"ip_src", "ip_dst", "ip_ttl", "ip_hop_limit", "ip_protocol",
+	"tcp_src_port", "tcp_dst_port", "tcp_seq", "tcp_ack", "tcp_fin",
+	"tcp_syn", "tcp_rst", "tcp_psh", "tcp_ack_flag", "tcp_urg",
+	"tcp_window", "udp_src_port", "udp_dst_port", "udp_length", "tls_record_count",
+	"eth_src_mac", "eth_dst_mac", "ether_type", "vlan_ids", "arp_operation",
+	"arp_sender_mac", "arp_sender_ip", "arp_target_mac", "arp_target_ip", "icmp_version",
+	"icmp_type", "icmp_code", "icmp_type_code", "icmp_id", "icmp_seq",
+	"tunnel_types", "tunnel_ids", "outer_ip_src", "outer_ip_dst",
+	"event_time",
+}
+
+// packetEventWriter pulls batches of messages from the EVENTS stream and writes all of their packet events
+// in a single COPY transaction. Messages are acked only once the transaction commits,
+// and nak'ed with a delay for redelivery if it fails, so events are neither lost nor written one round-trip at a time.
+// Messages that can't be unmarshaled, or still fail to be written on their last delivery, are dead-lettered to EVENTS_DLQ.
+type packetEventWriter struct {
+	*deadLetterer
+	db        *sql.DB
+	logger    *slog.Logger
+	sub       *nats.Subscription
+	batchSize int
+	fetchWait time.Duration
+}
+
+// run fetches and writes batches until the context is canceled.
+// A batch in flight when the context is canceled is still written, acked or nak'ed before run returns.
+func (w *packetEventWriter) run(ctx context.Context) {
+	logger := w.logger.With("function", "packetEventWriter.run")
+	writeCtx := context.WithoutCancel(ctx)
+
+	for ctx.Err() == nil {
+		msgs, err := w.sub.Fetch(w.batchSize, nats.MaxWait(w.fetchWait))
+		if err != nil {
+			if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+				// no messages within the fetch wait
+				continue
+			}
+			logger.Error("failed to fetch packet event messages", "error", err)
+			time.Sleep(w.fetchWait)
+			continue
+		}
+		w.writeBatch(writeCtx, msgs)
+	}
+}
+
+// pendingMessage is a fetched message with the rows of its packet events
+type pendingMessage struct {
+	msg  *nats.Msg
+	rows tableRows
+}
+
+// writeBatch writes the packet events of the messages, then acks all of them together.
+// If the batch fails, the messages are written one at a time, so a poison message doesn't hold back the rest.
+func (w *packetEventWriter) writeBatch(ctx context.Context, msgs []*nats.Msg) {
+	logger := w.logger.With("function", "packetEventWriter.writeBatch")
+
+	rows := make(tableRows)
+	pending := make([]pendingMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		packetEvents, err := unpackPacketEvents(msg)
+		if err != nil {
+			// redelivering a message that can't be unmarshaled won't fix it
+			logger.Error("failed to unmarshal protobuf message, dead-lettering it", "error", err, "subject", msg.Subject)
+			w.deadLetter(msg, streams.DLQReasonUnmarshal, err)
+			continue
+		}
+
+		osUniqueIdentifier := osUniqueIdentifierFromSubject(msg.Subject)
+		msgRows := make(tableRows)
+		for _, packetEvent := range packetEvents {
+			msgRows.addPacketEvent(osUniqueIdentifier, packetEvent)
+		}
+		rows.add(msgRows)
+		pending = append(pending, pendingMessage{msg: msg, rows: msgRows})
+	}
+
+	if rows.count() > 0 {
+		err := writePacketEvents(ctx, w.db, rows)
+		if err != nil {
+			logger.Error("failed to write packet events, writing messages one at a time", "error", err, "messages", len(pending), "rows", rows.count())
+			w.writeEach(ctx, pending)
+			return
+		}
+		logger.Info("wrote packet events", "messages", len(pending), "rows", rows.count())
+	}
+
+	for _, p := range pending {
+		w.ack(p.msg)
+	}
+}
+
+// writeEach writes the packet events of each message in its own transaction,
+// acking the messages that are written and retrying or dead-lettering the rest
+func (w *packetEventWriter) writeEach(ctx context.Context, pending []pendingMessage) {
+	logger := w.logger.With("function", "packetEventWriter.writeEach")
+
+	for _, p := range pending {
+		if p.rows.count() > 0 {
+			err := writePacketEvents(ctx, w.db, p.rows)
+			if err != nil {
+				logger.Error("failed to write packet events of message", "error", err, "subject", p.msg.Subject, "rows", p.rows.count())
+				w.retryOrDeadLetter(p.msg, err)
+				continue
+			}
+		}
+		w.ack(p.msg)
+	}
+}
+
+func (w *packetEventWriter) ack(msg *nats.Msg) {
+	err := msg.Ack()
+	if err != nil {
+		// the rows are committed, so a redelivery after a failed ack duplicates them rather than losing them
+		w.logger.Error("failed to ack packet event message", "error", err, "subject", msg.Subject)
+	}
+}
+
+// writePacketEvents writes the rows of each table with COPY, all in a single transaction
+func writePacketEvents(ctx context.Context, db *sql.DB, rows tableRows) error {
+	txn, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	for table, columns := range tableColumns {
+		if len(rows[table]) == 0 {
+			continue
+		}
+		err = copyRows(ctx, txn, table, columns, rows[table])
+		if err != nil {
+			return fmt.Errorf("failed to copy rows to %s: %w", table, err)
+		}
+	}
+
+	return txn.Commit()
+}
+
+// copyRows writes the rows to the table with COPY
+func copyRows(ctx context.Context, txn *sql.Tx, table string, columns []string, rows [][]any) error {
+	stmt, err := txn.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		_, err = stmt.ExecContext(ctx, row...)
+		if err != nil {
+			_ = stmt.Close()
+			return err
+		}
+	}
+	// an Exec without arguments flushes the buffered COPY data
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		_ = stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// unpackPacketEvents returns the packet events of a message on `events.*`,
+// which carries either a batch of them or, from agents that predate batching, a single one
+func unpackPacketEvents(msg *nats.Msg) ([]*pbAgent.PacketEvent, error) {
+	if msg.Header.Get(streams.HeaderMessageType) == streams.MessageTypePacketEventBatch {
+		var batch pbAgent.PacketEventBatch
+		err := proto.Unmarshal(msg.Data, &batch)
+		if err != nil {
+			return nil, err
+		}
+		return batch.Events, nil
+	}
+
+	var packetEvent pbAgent.PacketEvent
+	err := proto.Unmarshal(msg.Data, &packetEvent)
+	if err != nil {
+		return nil, err
+	}
+	return []*pbAgent.PacketEvent{&packetEvent}, nil
+}
+
+// osUniqueIdentifierFromSubject returns the id of a subject like "events.id",
+// where the id is the device row's os_unique_identifier
+func osUniqueIdentifierFromSubject(subject string) string {
+	parts := strings.SplitN(subject, ".", 2)
+	if len(parts) == 2 {
+		return parts[1]
+	}
+	return ""
+}
+
+// packetEventRow returns the packet_events column values of the packet event, in the order of packetEventColumns
+func packetEventRow(osUniqueIdentifier string, packetEvent *pbAgent.PacketEvent) []any {
+	// events from agents that predate capture timestamps fall back to the time the worker received them
+	eventTime := time.Now()
+	if packetEvent.CaptureTime != nil {
+		eventTime = packetEvent.CaptureTime.AsTime()
+	}
+
+	// capture config
+	bpf := packetEvent.Bpf
+	interfaceName := packetEvent.DeviceName
+	promiscuous := packetEvent.Promiscuous
+	snapLen := packetEvent.SnapLen
+
+	// capture metadata
+	captureLen := int32(packetEvent.CaptureLength)
+	originalLen := int32(packetEvent.OriginalLength)
+	truncated := packetEvent.Truncated
+	interfaceIndex := packetEvent.InterfaceIndex
+
+	// ipLayer
+	var dstIP, ipVersion, ipProtocol, srcIP string
+	var ipHopLimit, ipTTL int32
+
+	// tcpLayer
+	var tcpAckFlag, tcpFin, tcpPsh, tcpRst, tcpSyn, tcpUrg bool
+	var dstPortTCP, srcPortTCP, tcpWindow int32
+	var tcpAck, tcpSeq int64
+
+	var dstPortUDP, srcPortUDP, udpLen int32
+	var tlsRecordsCount int
+
+	// ethernetLayer
+	var srcMAC, dstMAC, etherType any
+	var vlanIDs []int64
+
+	// arpLayer
+	var arpOperation, arpSenderMAC, arpSenderIP, arpTargetMAC, arpTargetIP any
+
+	// icmpLayer
+	var icmpVersion, icmpType, icmpCode, icmpTypeCode, icmpID, icmpSeq any
+
+	// tunnels
+	var tunnelTypes []string
+	var tunnelIDs []int64
+	var outerSrcIP, outerDstIP any
+
+	if packetEvent.Layers != nil {
+		if packetEvent.Layers.IpLayer != nil {
+			srcIP = packetEvent.Layers.IpLayer.SrcIp
+			dstIP = packetEvent.Layers.IpLayer.DstIp
+			ipVersion = packetEvent.Layers.IpLayer.Version
+			if ipVersion == "IPv4" {
+				ipProtocol = getProtocolName(packetEvent.Layers.IpLayer.Protocol)
+				ipTTL = int32(packetEvent.Layers.IpLayer.Ttl)
+			} else {
+				ipHopLimit = int32(packetEvent.Layers.IpLayer.HopLimit)
+			}
+		}
+
+		if packetEvent.Layers.TcpLayer != nil {
+			srcPortTCP = int32(packetEvent.Layers.TcpLayer.SrcPort)
+			dstPortTCP = int32(packetEvent.Layers.TcpLayer.DstPort)
+			tcpAck = int64(packetEvent.Layers.TcpLayer.Ack)
+			tcpSeq = int64(packetEvent.Layers.TcpLayer.Seq)
+			tcpWindow = int32(packetEvent.Layers.TcpLayer.Window)
+
+			tcpAckFlag = packetEvent.Layers.TcpLayer.AckFlag
+			tcpFin = packetEvent.Layers.TcpLayer.Fin
+			tcpPsh = packetEvent.Layers.TcpLayer.Psh
+			tcpRst = packetEvent.Layers.TcpLayer.Rst
+			tcpSyn = packetEvent.Layers.TcpLayer.Syn
+			tcpUrg = packetEvent.Layers.TcpLayer.Urg
+		} else if packetEvent.Layers.UdpLayer != nil {
+			srcPortUDP = int32(packetEvent.Layers.UdpLayer.SrcPort)
+			dstPortUDP = int32(packetEvent.Layers.UdpLayer.DstPort)
+			udpLen = int32(packetEvent.Layers.UdpLayer.Length)
+		} else if packetEvent.Layers.TlsLayer != nil {
+			tlsRecordsCount = len(packetEvent.Layers.TlsLayer.Records)
+		}
+
+		if ethernet := packetEvent.Layers.EthernetLayer; ethernet != nil {
+			srcMAC = ethernet.SrcMac
+			dstMAC = ethernet.DstMac
+			etherType = ethernet.EtherType
+			for _, vlanTag := range ethernet.VlanTags {
+				vlanIDs = append(vlanIDs, int64(vlanTag.Id))
+			}
+		}
+
+		if arp := packetEvent.Layers.ArpLayer; arp != nil {
+			arpOperation = arp.Operation
+			arpSenderMAC = arp.SenderMac
+			arpSenderIP = arp.SenderIp
+			arpTargetMAC = arp.TargetMac
+			arpTargetIP = arp.TargetIp
+		}
+
+		if icmp := packetEvent.Layers.IcmpLayer; icmp != nil {
+			icmpVersion = icmp.Version
+			icmpType = int32(icmp.Type)
+			icmpCode = int32(icmp.Code)
+			icmpTypeCode = icmp.TypeCode
+			icmpID = int32(icmp.Id)
+			icmpSeq = int32(icmp.Seq)
+		}
+
+		tunnelTypes = packetEvent.Layers.TunnelTypes
+		for _, tunnel := range packetEvent.Layers.Tunnels {
+			tunnelIDs = append(tunnelIDs, int64(tunnel.Id))
+			// the outermost underlay addresses, the ip columns hold those of the innermost packet
+			if outerSrcIP == nil && tunnel.OuterIpLayer != nil {
+				outerSrcIP = tunnel.OuterIpLayer.SrcIp
+				outerDstIP = tunnel.OuterIpLayer.DstIp
+			}
+		}
+	}
+
+	return []any{
+		osUniqueIdentifier, bpf, interfaceName, promiscuous, snapLen,
+		captureLen, originalLen, interfaceIndex, truncated, ipVersion,
+		srcIP, dstIP, ipTTL, ipHopLimit, ipProtocol,
+		srcPortTCP, dstPortTCP, tcpSeq, tcpAck, tcpFin,
+		tcpSyn, tcpRst, tcpPsh, tcpAckFlag, tcpUrg,
+		tcpWindow, srcPortUDP, dstPortUDP, udpLen, int32(tlsRecordsCount),
+		srcMAC, dstMAC, etherType, pq.Array(vlanIDs), arpOperation,
+		arpSenderMAC, arpSenderIP, arpTargetMAC, arpTargetIP, icmpVersion,
+		icmpType, icmpCode, icmpTypeCode, icmpID, icmpSeq,
+		pq.Array(tunnelTypes), pq.Array(tunnelIDs), outerSrcIP, outerDstIP,
+		eventTime,
+	}
+}
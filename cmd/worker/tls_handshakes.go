@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// hello types of the hello_type column of tls_handshakes
+const (
+	helloTypeClient = "client_hello"
+	helloTypeServer = "server_hello"
+)
+
+// tlsHandshakeColumns are the tls_handshakes columns written by COPY, in the order of the values of the tls handshake rows
+var tlsHandshakeColumns = []string{
+	"os_unique_identifier", "bpf", "interface", "ip_src", "ip_dst",
+	"src_port", "dst_port", "hello_type", "tls_version", "sni",
+	"alpn", "cipher_suites", "extensions", "ja3", "ja3_hash",
+	"ja4", "ja3s", "ja3s_hash", "event_time",
+}
+
+// tlsHandshakeRows returns a tls_handshakes row for each hello of a packet event with a TLS layer, in the order of tlsHandshakeColumns
+func tlsHandshakeRows(osUniqueIdentifier string, packetEvent *pbAgent.PacketEvent) [][]any {
+	tls := packetEvent.Layers.TlsLayer
+
+	eventTime := time.Now()
+	if packetEvent.CaptureTime != nil {
+		eventTime = packetEvent.CaptureTime.AsTime()
+	}
+
+	var srcIP, dstIP string
+	if packetEvent.Layers.IpLayer != nil {
+		srcIP = packetEvent.Layers.IpLayer.SrcIp
+		dstIP = packetEvent.Layers.IpLayer.DstIp
+	}
+
+	var srcPort, dstPort int32
+	if packetEvent.Layers.TcpLayer != nil {
+		srcPort = int32(packetEvent.Layers.TcpLayer.SrcPort)
+		dstPort = int32(packetEvent.Layers.TcpLayer.DstPort)
+	}
+
+	rows := make([][]any, 0, 2)
+	if hello := tls.ClientHello; hello != nil {
+		rows = append(rows, []any{
+			osUniqueIdentifier, packetEvent.Bpf, packetEvent.DeviceName, srcIP, dstIP,
+			srcPort, dstPort, helloTypeClient, hello.Version, hello.Sni,
+			pq.Array(hello.Alpn), pq.Array(int64s(hello.CipherSuites)), pq.Array(int64s(hello.Extensions)), hello.Ja3, hello.Ja3Hash,
+			hello.Ja4, nil, nil, eventTime,
+		})
+	}
+	if hello := tls.ServerHello; hello != nil {
+		version := hello.Version
+		if hello.SupportedVersion != "" {
+			version = hello.SupportedVersion
+		}
+		var alpn []string
+		if hello.Alpn != "" {
+			alpn = []string{hello.Alpn}
+		}
+		rows = append(rows, []any{
+			osUniqueIdentifier, packetEvent.Bpf, packetEvent.DeviceName, srcIP, dstIP,
+			srcPort, dstPort, helloTypeServer, version, nil,
+			pq.Array(alpn), pq.Array([]int64{int64(hello.CipherSuite)}), pq.Array(int64s(hello.Extensions)), nil, nil,
+			nil, hello.Ja3S, hello.Ja3SHash, eventTime,
+		})
+	}
+	return rows
+}
+
+func int64s(values []uint32) []int64 {
+	converted := make([]int64, 0, len(values))
+	for _, value := range values {
+		converted = append(converted, int64(value))
+	}
+	return converted
+}
@@ -0,0 +1,24 @@
+package dao
+
+import "time"
+
+// AgentRelease is an agent binary published to the artifact registry for an OS and architecture,
+// with the detached ed25519 signature of its manifest the agent checks before installing it
+type AgentRelease struct {
+	ID          string
+	Version     string
+	OS          string
+	Arch        string
+	SHA256      string
+	SizeBytes   uint64
+	Signature   []byte
+	PublishedAt time.Time
+}
+
+type AgentReleases interface {
+	// Create publishes the binary of a release, which can't be replaced once published
+	Create(release *AgentRelease, data []byte) error
+	Get(version, os, arch string) (*AgentRelease, error)
+	List() ([]*AgentRelease, error)
+	ReadData(id string) ([]byte, error)
+}
@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// command statuses, the command_status ENUM
+const (
+	// CommandStatusPending is a command published to the device's commands subject that the agent hasn't fetched yet
+	CommandStatusPending   = "pending"
+	CommandStatusDelivered = "delivered"
+	CommandStatusSucceeded = "succeeded"
+	CommandStatusFailed    = "failed"
+	// CommandStatusExpired is a command that wasn't run because it was fetched after its expiry,
+	// or whose result the agent didn't report before its expiry
+	CommandStatusExpired = "expired"
+)
+
+// Command is a command issued to a device's agent, tracked from publishing until the agent reports its result
+type Command struct {
+	ID       string
+	DeviceID string
+	Name     string
+	// Args are the command's typed arguments as JSON, nil for commands without arguments
+	Args json.RawMessage
+	// IssuedBy is the id of the administrator who issued the command, or the component that issued it on its own
+	IssuedBy    string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	Status      string
+	Error       string
+	DeliveredAt *time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+}
+
+type Commands interface {
+	Create(command *Command) error
+	Get(id string) (*Command, error)
+	List(deviceID string) ([]*Command, error)
+	// MarkDelivered marks a pending command as fetched by the agent
+	MarkDelivered(id string) error
+	// Complete stores the result of a command the agent reported, or expired for a command fetched after its expiry
+	Complete(id string, status string, commandErr string, startedAt, finishedAt *time.Time) error
+	// ExpirePastDue marks the pending and delivered commands whose expiry passed expired,
+	// returning how many were marked
+	ExpirePastDue() (int64, error)
+}
@@ -2,8 +2,12 @@ package dao
 
 // Datastore exposes services that fulfill the primary datastore interfaces
 type Datastore struct {
-	Administrators Administrators
-	Devices        Devices
-	InstallKeys    InstallKeys
-	Organizations  Organizations
+	Administrators     Administrators
+	AgentReleases      AgentReleases
+	Commands           Commands
+	Devices            Devices
+	DiagnosticsBundles DiagnosticsBundles
+	InstallKeys        InstallKeys
+	Organizations      Organizations
+	PacketSlices       PacketSlices
 }
@@ -1,10 +1,69 @@
 package dao
 
+import "time"
+
 type CaptureConfig struct {
 	Bpf         string `json:"bpf"`
 	DeviceName  string `json:"deviceName"`
 	Promiscuous bool   `json:"promiscuous"`
 	SnapLen     int32  `json:"snapLen"`
+	FlowMode    bool   `json:"flowMode"`
+	HTTPMode    bool   `json:"httpMode"`
+	RingBuffer  bool   `json:"ringBuffer"`
+}
+
+// CaptureStats are the packet counters a device last reported for one of its live captures
+type CaptureStats struct {
+	Bpf                string    `json:"bpf"`
+	DeviceName         string    `json:"deviceName"`
+	Received           uint64    `json:"received"`
+	DroppedByKernel    uint64    `json:"droppedByKernel"`
+	DroppedByInterface uint64    `json:"droppedByInterface"`
+	DroppedByAgent     uint64    `json:"droppedByAgent"`
+	StartedAt          time.Time `json:"startedAt"`
+}
+
+// capture states of the capture results devices report, and the pending state of a desired capture a device hasn't reported
+const (
+	CaptureStateRunning = "running"
+	CaptureStateFailed  = "failed"
+	CaptureStateStopped = "stopped"
+	CaptureStatePending = "pending"
+)
+
+// CaptureResult is the state of one capture of a device, as last reported by the device
+type CaptureResult struct {
+	Bpf        string    `json:"bpf"`
+	DeviceName string    `json:"deviceName"`
+	Error      string    `json:"error"`
+	LinkType   string    `json:"linkType"`
+	AppliedAt  time.Time `json:"appliedAt"`
+	State      string    `json:"state"`
+}
+
+// InterfaceDetails is an interface of a device with its addresses and flags, as last reported by the device
+type InterfaceDetails struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Addresses   []string `json:"addresses"`
+	Flags       []string `json:"flags"`
+}
+
+// Heartbeat is the health of a device's agent, as last reported by the agent
+type Heartbeat struct {
+	AgentVersion    string    `json:"agentVersion"`
+	CommitHash      string    `json:"commitHash"`
+	UptimeSeconds   uint64    `json:"uptimeSeconds"`
+	OS              string    `json:"os"`
+	Arch            string    `json:"arch"`
+	OSVersion       string    `json:"osVersion"`
+	KernelVersion   string    `json:"kernelVersion"`
+	CPUPercent      float64   `json:"cpuPercent"`
+	MemoryBytes     uint64    `json:"memoryBytes"`
+	CapturesRunning uint32    `json:"capturesRunning"`
+	CapturesFailed  uint32    `json:"capturesFailed"`
+	CapturesStopped uint32    `json:"capturesStopped"`
+	ReceivedAt      time.Time `json:"receivedAt"`
 }
 
 type Device struct {
@@ -15,13 +74,32 @@ type Device struct {
 	OrganizationID           string
 	PCapVersion              string
 	Interfaces               []string
+	InterfaceDetails         []InterfaceDetails
 	InterfaceBPFAssociations map[string]map[uint64]CaptureConfig
 	PreviousAssociations     map[string]map[uint64]CaptureConfig
+	CaptureStats             []CaptureStats
+	EventsDropped            uint64
+	CaptureStatsUpdatedAt    *time.Time
+	CaptureResults           []CaptureResult
+	// Online is set by each heartbeat and cleared once the device has been silent for longer than the offline threshold
+	Online       bool
+	LastSeenAt   *time.Time
+	AgentVersion string
+	Heartbeat    *Heartbeat
 }
 
 type Devices interface {
 	Create(device *Device) error
 	GetDeviceByPredicate(predicateName, predicateValue string) (*Device, error)
 	List(organizationID string) ([]*Device, error)
+	// Update stores the device's client cert and BPF associations, the agent's reports are stored by the other updates
 	Update(device *Device) error
+	// UpdateInterfaces stores the interfaces and pcap version the device's agent reported, without touching the rest of the row
+	UpdateInterfaces(id string, interfaces []string, interfaceDetails []InterfaceDetails, pcapVersion string) error
+	UpdateCaptureStats(id string, captureStats []CaptureStats, eventsDropped uint64, collectedAt time.Time) error
+	UpdateCaptureResults(id string, captureResults []CaptureResult) error
+	// UpdateHeartbeat stores the device's heartbeat, marking it online and seen at the heartbeat's receive time
+	UpdateHeartbeat(id string, heartbeat *Heartbeat) error
+	// MarkOffline marks the online devices not seen since the given time offline, returning how many it marked
+	MarkOffline(notSeenSince time.Time) (int64, error)
 }
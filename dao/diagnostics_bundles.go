@@ -0,0 +1,31 @@
+package dao
+
+import "time"
+
+// diagnostics bundle statuses, the diagnostics_bundle_status ENUM
+const (
+	DiagnosticsBundleStatusRequested = "requested"
+	DiagnosticsBundleStatusComplete  = "complete"
+	DiagnosticsBundleStatusFailed    = "failed"
+)
+
+// DiagnosticsBundle is the request for, and once uploaded the zip file of, an agent's logs, config, cert metadata,
+// interfaces and capture stats
+type DiagnosticsBundle struct {
+	ID          string
+	DeviceID    string
+	Status      string
+	Error       string
+	SizeBytes   uint64
+	RequestedAt time.Time
+	CompletedAt *time.Time
+}
+
+type DiagnosticsBundles interface {
+	Create(bundle *DiagnosticsBundle) error
+	Get(id string) (*DiagnosticsBundle, error)
+	List(deviceID string) ([]*DiagnosticsBundle, error)
+	// Complete stores the uploaded zip file of a requested bundle, or the error the agent reported instead
+	Complete(id string, data []byte, bundleErr string) error
+	ReadData(id string) ([]byte, error)
+}
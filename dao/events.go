@@ -11,6 +11,27 @@ type Event struct {
 	IpVersion      string `json:"ip_version,omitempty"`
 }
 
+type DNSAnswer struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	TTL  uint32 `json:"ttl,omitempty"`
+	Data string `json:"data,omitempty"`
+}
+
+type DNSEvent struct {
+	EventTime    string       `json:"event_time,omitempty"`
+	Bpf          string       `json:"bpf,omitempty"`
+	IpSrc        string       `json:"ip_src,omitempty"`
+	IpDst        string       `json:"ip_dst,omitempty"`
+	DNSID        uint32       `json:"dns_id,omitempty"`
+	Response     bool         `json:"response,omitempty"`
+	ResponseCode string       `json:"response_code,omitempty"`
+	QueryName    string       `json:"query_name,omitempty"`
+	QueryType    string       `json:"query_type,omitempty"`
+	Answers      []*DNSAnswer `json:"answers,omitempty"`
+}
+
 type Events interface {
 	Read(deviceID string, start string, end string) ([]*Event, error)
+	ReadDNS(deviceID string, start string, end string) ([]*DNSEvent, error)
 }
@@ -0,0 +1,37 @@
+package dao
+
+import "time"
+
+// packet slice statuses, the packet_slice_status ENUM
+const (
+	PacketSliceStatusRequested = "requested"
+	PacketSliceStatusComplete  = "complete"
+	PacketSliceStatusFailed    = "failed"
+)
+
+// PacketSlice is the request for, and once uploaded the pcap file of, the packets of a capture's ring buffer in a time range
+type PacketSlice struct {
+	ID          string
+	DeviceID    string
+	DeviceName  string
+	Bpf         string
+	Filter      string
+	StartTime   time.Time
+	EndTime     time.Time
+	Status      string
+	Error       string
+	PacketCount uint64
+	SizeBytes   uint64
+	Truncated   bool
+	RequestedAt time.Time
+	CompletedAt *time.Time
+}
+
+type PacketSlices interface {
+	Create(packetSlice *PacketSlice) error
+	Get(id string) (*PacketSlice, error)
+	List(deviceID string) ([]*PacketSlice, error)
+	// Complete stores the uploaded pcap file of a requested slice, or the error the agent reported instead
+	Complete(id string, data []byte, packetCount uint64, truncated bool, sliceErr string) error
+	ReadData(id string) ([]byte, error)
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres/queries"
+)
+
+type agentReleases struct {
+	db *sql.DB
+}
+
+// NewAgentReleases returns an instance implementing the AgentReleases interface
+func NewAgentReleases(db *sql.DB) dao.AgentReleases {
+	return &agentReleases{db: db}
+}
+
+func (ar *agentReleases) Create(release *dao.AgentRelease, data []byte) error {
+	if release == nil {
+		return errors.New("invalid agent release")
+	}
+	if release.Version == "" {
+		return errors.New("invalid version")
+	}
+	if release.OS == "" || release.Arch == "" {
+		return errors.New("invalid os or arch")
+	}
+	if len(data) == 0 {
+		return errors.New("empty agent binary")
+	}
+	if len(release.Signature) == 0 {
+		return errors.New("invalid signature")
+	}
+	release.SizeBytes = uint64(len(data))
+	return ar.db.QueryRow(
+		queries.AgentReleasesInsert,
+		release.Version,
+		release.OS,
+		release.Arch,
+		release.SHA256,
+		release.SizeBytes,
+		release.Signature,
+		data,
+	).Scan(&release.ID, &release.PublishedAt)
+}
+
+func (ar *agentReleases) Get(version, os, arch string) (*dao.AgentRelease, error) {
+	if version == "" {
+		return nil, errors.New("empty version")
+	}
+	return scanAgentRelease(ar.db.QueryRow(queries.AgentReleasesSelectByVersion, version, os, arch))
+}
+
+func (ar *agentReleases) List() ([]*dao.AgentRelease, error) {
+	rows, err := ar.db.Query(queries.AgentReleasesSelect)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	releases := make([]*dao.AgentRelease, 0)
+	for rows.Next() {
+		release, err := scanAgentRelease(rows)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+	return releases, rows.Err()
+}
+
+func (ar *agentReleases) ReadData(id string) ([]byte, error) {
+	if id == "" {
+		return nil, errors.New("empty agent release id")
+	}
+	var data []byte
+	err := ar.db.QueryRow(queries.AgentReleasesSelectData, id).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func scanAgentRelease(row rowScanner) (*dao.AgentRelease, error) {
+	var release dao.AgentRelease
+	err := row.Scan(
+		&release.ID,
+		&release.Version,
+		&release.OS,
+		&release.Arch,
+		&release.SHA256,
+		&release.SizeBytes,
+		&release.Signature,
+		&release.PublishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
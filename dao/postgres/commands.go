@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres/queries"
+)
+
+type commands struct {
+	db *sql.DB
+}
+
+// NewCommands returns an instance implementing the Commands interface
+func NewCommands(db *sql.DB) dao.Commands {
+	return &commands{db: db}
+}
+
+func (c *commands) Create(command *dao.Command) error {
+	if command == nil {
+		return errors.New("invalid command")
+	}
+	if command.DeviceID == "" {
+		return errors.New("invalid device_id")
+	}
+	if command.Name == "" {
+		return errors.New("invalid name")
+	}
+	var args any
+	if len(command.Args) > 0 {
+		args = []byte(command.Args)
+	}
+	return c.db.QueryRow(
+		queries.CommandsInsert,
+		command.DeviceID,
+		command.Name,
+		args,
+		command.IssuedBy,
+		command.ExpiresAt,
+	).Scan(&command.ID, &command.Status, &command.IssuedAt)
+}
+
+func (c *commands) Get(id string) (*dao.Command, error) {
+	if id == "" {
+		return nil, errors.New("empty command id")
+	}
+	return scanCommand(c.db.QueryRow(queries.CommandsSelectById, id))
+}
+
+func (c *commands) List(deviceID string) ([]*dao.Command, error) {
+	if deviceID == "" {
+		return nil, errors.New("empty device id")
+	}
+
+	rows, err := c.db.Query(queries.CommandsSelectByDeviceId, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commands := make([]*dao.Command, 0)
+	for rows.Next() {
+		command, err := scanCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, rows.Err()
+}
+
+func (c *commands) MarkDelivered(id string) error {
+	if id == "" {
+		return errors.New("empty command id")
+	}
+	_, err := c.db.Exec(queries.CommandsMarkDelivered, id)
+	return err
+}
+
+func (c *commands) Complete(id string, status string, commandErr string, startedAt, finishedAt *time.Time) error {
+	if id == "" {
+		return errors.New("empty command id")
+	}
+	switch status {
+	case dao.CommandStatusSucceeded, dao.CommandStatusFailed, dao.CommandStatusExpired:
+	default:
+		return errors.New("invalid command status")
+	}
+	result, err := c.db.Exec(
+		queries.CommandsComplete,
+		status,
+		commandErr,
+		startedAt,
+		finishedAt,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated == 0 {
+		// the command doesn't exist or its result was already stored
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func scanCommand(row rowScanner) (*dao.Command, error) {
+	var command dao.Command
+	var args []byte
+	var deliveredAt, startedAt, finishedAt sql.NullTime
+	err := row.Scan(
+		&command.ID,
+		&command.DeviceID,
+		&command.Name,
+		&args,
+		&command.IssuedBy,
+		&command.IssuedAt,
+		&command.ExpiresAt,
+		&command.Status,
+		&command.Error,
+		&deliveredAt,
+		&startedAt,
+		&finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	command.Args = args
+	if deliveredAt.Valid {
+		command.DeliveredAt = &deliveredAt.Time
+	}
+	if startedAt.Valid {
+		command.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		command.FinishedAt = &finishedAt.Time
+	}
+	return &command, nil
+}
+
+// ExpirePastDue marks the pending and delivered commands whose expiry passed expired
+func (c *commands) ExpirePastDue() (int64, error) {
+	result, err := c.db.Exec(queries.CommandsExpirePastDue)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
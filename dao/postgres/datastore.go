@@ -9,9 +9,13 @@ import (
 // NewDatastore returns a postgres implementation for the primary datastore
 func NewDatastore(db *sql.DB, installKeySecret string) *dao.Datastore {
 	return &dao.Datastore{
-		Administrators: NewAdministrators(db),
-		Devices:        NewDevices(db),
-		InstallKeys:    NewInstallKeys(db, installKeySecret),
-		Organizations:  NewOrganizations(db),
+		Administrators:     NewAdministrators(db),
+		AgentReleases:      NewAgentReleases(db),
+		Commands:           NewCommands(db),
+		Devices:            NewDevices(db),
+		DiagnosticsBundles: NewDiagnosticsBundles(db),
+		InstallKeys:        NewInstallKeys(db, installKeySecret),
+		Organizations:      NewOrganizations(db),
+		PacketSlices:       NewPacketSlices(db),
 	}
 }
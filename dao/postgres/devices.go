@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/danielhoward314/packet-sentry/dao"
 	"github.com/danielhoward314/packet-sentry/dao/postgres/queries"
@@ -68,7 +69,9 @@ func (d *devices) GetDeviceByPredicate(predicateName, predicateValue string) (*d
 
 	var device dao.Device
 	var interfaces []string
-	var interfaceBPFJSON, previousBPFJSON []byte
+	var interfaceBPFJSON, previousBPFJSON, captureStatsJSON, captureResultsJSON, interfaceDetailsJSON []byte
+	var captureStatsUpdatedAt, lastSeenAt sql.NullTime
+	var heartbeatJSON []byte
 
 	err := row.Scan(
 		&device.ID,
@@ -80,6 +83,15 @@ func (d *devices) GetDeviceByPredicate(predicateName, predicateValue string) (*d
 		pq.Array(&interfaces),
 		&interfaceBPFJSON,
 		&previousBPFJSON,
+		&captureStatsJSON,
+		&device.EventsDropped,
+		&captureStatsUpdatedAt,
+		&captureResultsJSON,
+		&interfaceDetailsJSON,
+		&device.Online,
+		&lastSeenAt,
+		&device.AgentVersion,
+		&heartbeatJSON,
 	)
 	if err != nil {
 		return nil, err
@@ -96,6 +108,30 @@ func (d *devices) GetDeviceByPredicate(predicateName, predicateValue string) (*d
 	if err != nil {
 		return nil, fmt.Errorf("parsing previous_associations: %w", err)
 	}
+	err = json.Unmarshal(captureStatsJSON, &device.CaptureStats)
+	if err != nil {
+		return nil, fmt.Errorf("parsing capture_stats: %w", err)
+	}
+	if captureStatsUpdatedAt.Valid {
+		device.CaptureStatsUpdatedAt = &captureStatsUpdatedAt.Time
+	}
+	err = json.Unmarshal(captureResultsJSON, &device.CaptureResults)
+	if err != nil {
+		return nil, fmt.Errorf("parsing capture_results: %w", err)
+	}
+	err = json.Unmarshal(interfaceDetailsJSON, &device.InterfaceDetails)
+	if err != nil {
+		return nil, fmt.Errorf("parsing interface_details: %w", err)
+	}
+	if lastSeenAt.Valid {
+		device.LastSeenAt = &lastSeenAt.Time
+	}
+	if heartbeatJSON != nil {
+		err = json.Unmarshal(heartbeatJSON, &device.Heartbeat)
+		if err != nil {
+			return nil, fmt.Errorf("parsing heartbeat: %w", err)
+		}
+	}
 
 	return &device, nil
 }
@@ -119,11 +155,6 @@ func (d *devices) Update(device *dao.Device) error {
 	if device.OrganizationID == "" {
 		return errors.New("invalid organization_id")
 	}
-	// ensure we will always set `interfaces` column to TEXT[]
-	if device.Interfaces == nil {
-		device.Interfaces = make([]string, 0)
-	}
-
 	var err error
 
 	// if the update has no associations, set it to the empty map
@@ -162,8 +193,6 @@ func (d *devices) Update(device *dao.Device) error {
 		queries.DevicesUpdate,
 		device.ClientCertPEM,
 		device.ClientCertFingerprint,
-		device.PCapVersion,
-		pq.Array(device.Interfaces),
 		interfaceBPFJSON,
 		previousBPFJSON,
 		device.ID,
@@ -171,6 +200,84 @@ func (d *devices) Update(device *dao.Device) error {
 	return err
 }
 
+// UpdateInterfaces stores the interfaces and pcap version the device's agent reported,
+// without touching the rest of the row, e.g. the BPF associations administrators update concurrently
+func (d *devices) UpdateInterfaces(id string, interfaces []string, interfaceDetails []dao.InterfaceDetails, pcapVersion string) error {
+	if id == "" {
+		return errors.New("invalid device ID")
+	}
+	if interfaces == nil {
+		interfaces = make([]string, 0)
+	}
+	if interfaceDetails == nil {
+		interfaceDetails = make([]dao.InterfaceDetails, 0)
+	}
+	interfaceDetailsJSON, err := json.Marshal(interfaceDetails)
+	if err != nil {
+		return fmt.Errorf("marshalling interface_details: %w", err)
+	}
+	_, err = d.db.Exec(queries.DevicesUpdateInterfaces, pq.Array(interfaces), interfaceDetailsJSON, pcapVersion, id)
+	return err
+}
+
+// UpdateCaptureStats replaces the capture stats of the device with the ones it last reported,
+// without touching the rest of the row, which the agent's other RPCs update
+func (d *devices) UpdateCaptureStats(id string, captureStats []dao.CaptureStats, eventsDropped uint64, collectedAt time.Time) error {
+	if id == "" {
+		return errors.New("invalid device ID")
+	}
+	if captureStats == nil {
+		captureStats = make([]dao.CaptureStats, 0)
+	}
+	captureStatsJSON, err := json.Marshal(captureStats)
+	if err != nil {
+		return fmt.Errorf("marshalling capture_stats: %w", err)
+	}
+	_, err = d.db.Exec(queries.DevicesUpdateCaptureStats, captureStatsJSON, int64(eventsDropped), collectedAt, id)
+	return err
+}
+
+// UpdateCaptureResults replaces the capture results of the device, without touching the rest of the row
+func (d *devices) UpdateCaptureResults(id string, captureResults []dao.CaptureResult) error {
+	if id == "" {
+		return errors.New("invalid device ID")
+	}
+	if captureResults == nil {
+		captureResults = make([]dao.CaptureResult, 0)
+	}
+	captureResultsJSON, err := json.Marshal(captureResults)
+	if err != nil {
+		return fmt.Errorf("marshalling capture_results: %w", err)
+	}
+	_, err = d.db.Exec(queries.DevicesUpdateCaptureResults, captureResultsJSON, id)
+	return err
+}
+
+// UpdateHeartbeat stores the device's heartbeat, marking it online, without touching the rest of the row
+func (d *devices) UpdateHeartbeat(id string, heartbeat *dao.Heartbeat) error {
+	if id == "" {
+		return errors.New("empty device id")
+	}
+	if heartbeat == nil {
+		return errors.New("invalid heartbeat")
+	}
+	heartbeatJSON, err := json.Marshal(heartbeat)
+	if err != nil {
+		return fmt.Errorf("marshalling heartbeat: %w", err)
+	}
+	_, err = d.db.Exec(queries.DevicesUpdateHeartbeat, heartbeatJSON, heartbeat.AgentVersion, heartbeat.ReceivedAt, id)
+	return err
+}
+
+// MarkOffline marks the online devices not seen since the given time offline
+func (d *devices) MarkOffline(notSeenSince time.Time) (int64, error) {
+	result, err := d.db.Exec(queries.DevicesMarkOffline, notSeenSince)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (d *devices) List(organizationID string) ([]*dao.Device, error) {
 	if organizationID == "" {
 		return nil, fmt.Errorf("empty organization id")
@@ -185,7 +292,9 @@ func (d *devices) List(organizationID string) ([]*dao.Device, error) {
 	for rows.Next() {
 		var device dao.Device
 		var interfaces []string
-		var interfaceBPFJSON, previousBPFJSON []byte
+		var interfaceBPFJSON, previousBPFJSON, captureStatsJSON, captureResultsJSON, interfaceDetailsJSON []byte
+		var captureStatsUpdatedAt, lastSeenAt sql.NullTime
+		var heartbeatJSON []byte
 
 		rowErr := rows.Scan(
 			&device.ID,
@@ -197,6 +306,15 @@ func (d *devices) List(organizationID string) ([]*dao.Device, error) {
 			pq.Array(&interfaces),
 			&interfaceBPFJSON,
 			&previousBPFJSON,
+			&captureStatsJSON,
+			&device.EventsDropped,
+			&captureStatsUpdatedAt,
+			&captureResultsJSON,
+			&interfaceDetailsJSON,
+			&device.Online,
+			&lastSeenAt,
+			&device.AgentVersion,
+			&heartbeatJSON,
 		)
 		if rowErr != nil {
 			return nil, rowErr
@@ -213,6 +331,30 @@ func (d *devices) List(organizationID string) ([]*dao.Device, error) {
 		if rowErr != nil {
 			return nil, fmt.Errorf("parsing previous_associations: %w", rowErr)
 		}
+		rowErr = json.Unmarshal(captureStatsJSON, &device.CaptureStats)
+		if rowErr != nil {
+			return nil, fmt.Errorf("parsing capture_stats: %w", rowErr)
+		}
+		if captureStatsUpdatedAt.Valid {
+			device.CaptureStatsUpdatedAt = &captureStatsUpdatedAt.Time
+		}
+		rowErr = json.Unmarshal(captureResultsJSON, &device.CaptureResults)
+		if rowErr != nil {
+			return nil, fmt.Errorf("parsing capture_results: %w", rowErr)
+		}
+		rowErr = json.Unmarshal(interfaceDetailsJSON, &device.InterfaceDetails)
+		if rowErr != nil {
+			return nil, fmt.Errorf("parsing interface_details: %w", rowErr)
+		}
+		if lastSeenAt.Valid {
+			device.LastSeenAt = &lastSeenAt.Time
+		}
+		if heartbeatJSON != nil {
+			rowErr = json.Unmarshal(heartbeatJSON, &device.Heartbeat)
+			if rowErr != nil {
+				return nil, fmt.Errorf("parsing heartbeat: %w", rowErr)
+			}
+		}
 
 		devices = append(devices, &device)
 	}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres/queries"
+)
+
+type diagnosticsBundles struct {
+	db *sql.DB
+}
+
+// NewDiagnosticsBundles returns an instance implementing the DiagnosticsBundles interface
+func NewDiagnosticsBundles(db *sql.DB) dao.DiagnosticsBundles {
+	return &diagnosticsBundles{db: db}
+}
+
+func (dbs *diagnosticsBundles) Create(bundle *dao.DiagnosticsBundle) error {
+	if bundle == nil {
+		return errors.New("invalid diagnostics bundle")
+	}
+	if bundle.DeviceID == "" {
+		return errors.New("invalid device_id")
+	}
+	return dbs.db.QueryRow(
+		queries.DiagnosticsBundlesInsert,
+		bundle.DeviceID,
+	).Scan(&bundle.ID, &bundle.Status, &bundle.RequestedAt)
+}
+
+func (dbs *diagnosticsBundles) Get(id string) (*dao.DiagnosticsBundle, error) {
+	if id == "" {
+		return nil, errors.New("empty diagnostics bundle id")
+	}
+	return scanDiagnosticsBundle(dbs.db.QueryRow(queries.DiagnosticsBundlesSelectById, id))
+}
+
+func (dbs *diagnosticsBundles) List(deviceID string) ([]*dao.DiagnosticsBundle, error) {
+	if deviceID == "" {
+		return nil, errors.New("empty device id")
+	}
+
+	rows, err := dbs.db.Query(queries.DiagnosticsBundlesSelectByDeviceId, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bundles := make([]*dao.DiagnosticsBundle, 0)
+	for rows.Next() {
+		bundle, err := scanDiagnosticsBundle(rows)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, rows.Err()
+}
+
+func (dbs *diagnosticsBundles) Complete(id string, data []byte, bundleErr string) error {
+	if id == "" {
+		return errors.New("empty diagnostics bundle id")
+	}
+	status := dao.DiagnosticsBundleStatusComplete
+	if bundleErr != "" {
+		status = dao.DiagnosticsBundleStatusFailed
+		data = nil
+	}
+	result, err := dbs.db.Exec(
+		queries.DiagnosticsBundlesComplete,
+		status,
+		bundleErr,
+		data,
+		len(data),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated == 0 {
+		// the bundle doesn't exist or was already uploaded
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (dbs *diagnosticsBundles) ReadData(id string) ([]byte, error) {
+	if id == "" {
+		return nil, errors.New("empty diagnostics bundle id")
+	}
+	var data []byte
+	err := dbs.db.QueryRow(queries.DiagnosticsBundlesSelectData, id).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func scanDiagnosticsBundle(row rowScanner) (*dao.DiagnosticsBundle, error) {
+	var bundle dao.DiagnosticsBundle
+	var completedAt sql.NullTime
+	err := row.Scan(
+		&bundle.ID,
+		&bundle.DeviceID,
+		&bundle.Status,
+		&bundle.Error,
+		&bundle.SizeBytes,
+		&bundle.RequestedAt,
+		&completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		bundle.CompletedAt = &completedAt.Time
+	}
+	return &bundle, nil
+}
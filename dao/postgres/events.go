@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/danielhoward314/packet-sentry/dao"
@@ -62,3 +63,59 @@ func (e *events) Read(deviceID string, start string, end string) ([]*dao.Event,
 
 	return events, nil
 }
+
+func (e *events) ReadDNS(deviceID string, start string, end string) ([]*dao.DNSEvent, error) {
+	if deviceID == "" {
+		return nil, fmt.Errorf("empty device id")
+	}
+	if start == "" {
+		return nil, fmt.Errorf("empty start")
+	}
+	if end == "" {
+		return nil, fmt.Errorf("empty end")
+	}
+
+	dnsEvents := make([]*dao.DNSEvent, 0)
+	rows, rowsErr := e.db.Query(
+		queries.DNSEventsSelectByDeviceIdDatetime,
+		deviceID,
+		start,
+		end,
+	)
+	if rowsErr != nil {
+		return nil, rowsErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dnsEvent dao.DNSEvent
+		var dnsID int32
+		var answers []byte
+
+		rowErr := rows.Scan(
+			&dnsEvent.EventTime,
+			&dnsEvent.Bpf,
+			&dnsEvent.IpSrc,
+			&dnsEvent.IpDst,
+			&dnsID,
+			&dnsEvent.Response,
+			&dnsEvent.ResponseCode,
+			&dnsEvent.QueryName,
+			&dnsEvent.QueryType,
+			&answers,
+		)
+		if rowErr != nil {
+			return nil, rowErr
+		}
+		dnsEvent.DNSID = uint32(dnsID)
+
+		err := json.Unmarshal(answers, &dnsEvent.Answers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dns answers: %w", err)
+		}
+
+		dnsEvents = append(dnsEvents, &dnsEvent)
+	}
+
+	return dnsEvents, rows.Err()
+}
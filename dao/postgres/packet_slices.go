@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres/queries"
+)
+
+type packetSlices struct {
+	db *sql.DB
+}
+
+// NewPacketSlices returns an instance implementing the PacketSlices interface
+func NewPacketSlices(db *sql.DB) dao.PacketSlices {
+	return &packetSlices{db: db}
+}
+
+func (ps *packetSlices) Create(packetSlice *dao.PacketSlice) error {
+	if packetSlice == nil {
+		return errors.New("invalid packet slice")
+	}
+	if packetSlice.DeviceID == "" {
+		return errors.New("invalid device_id")
+	}
+	if packetSlice.DeviceName == "" {
+		return errors.New("invalid device_name")
+	}
+	return ps.db.QueryRow(
+		queries.PacketSlicesInsert,
+		packetSlice.DeviceID,
+		packetSlice.DeviceName,
+		packetSlice.Bpf,
+		packetSlice.Filter,
+		packetSlice.StartTime,
+		packetSlice.EndTime,
+	).Scan(&packetSlice.ID, &packetSlice.Status, &packetSlice.RequestedAt)
+}
+
+func (ps *packetSlices) Get(id string) (*dao.PacketSlice, error) {
+	if id == "" {
+		return nil, errors.New("empty packet slice id")
+	}
+	return scanPacketSlice(ps.db.QueryRow(queries.PacketSlicesSelectById, id))
+}
+
+func (ps *packetSlices) List(deviceID string) ([]*dao.PacketSlice, error) {
+	if deviceID == "" {
+		return nil, errors.New("empty device id")
+	}
+
+	rows, err := ps.db.Query(queries.PacketSlicesSelectByDeviceId, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	packetSlices := make([]*dao.PacketSlice, 0)
+	for rows.Next() {
+		packetSlice, err := scanPacketSlice(rows)
+		if err != nil {
+			return nil, err
+		}
+		packetSlices = append(packetSlices, packetSlice)
+	}
+	return packetSlices, rows.Err()
+}
+
+func (ps *packetSlices) Complete(id string, data []byte, packetCount uint64, truncated bool, sliceErr string) error {
+	if id == "" {
+		return errors.New("empty packet slice id")
+	}
+	status := dao.PacketSliceStatusComplete
+	if sliceErr != "" {
+		status = dao.PacketSliceStatusFailed
+		data = nil
+	}
+	result, err := ps.db.Exec(
+		queries.PacketSlicesComplete,
+		status,
+		sliceErr,
+		data,
+		packetCount,
+		len(data),
+		truncated,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated == 0 {
+		// the slice doesn't exist or was already uploaded
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (ps *packetSlices) ReadData(id string) ([]byte, error) {
+	if id == "" {
+		return nil, errors.New("empty packet slice id")
+	}
+	var data []byte
+	err := ps.db.QueryRow(queries.PacketSlicesSelectData, id).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// rowScanner is the Scan method shared by sql.Row and sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPacketSlice(row rowScanner) (*dao.PacketSlice, error) {
+	var packetSlice dao.PacketSlice
+	var completedAt sql.NullTime
+	err := row.Scan(
+		&packetSlice.ID,
+		&packetSlice.DeviceID,
+		&packetSlice.DeviceName,
+		&packetSlice.Bpf,
+		&packetSlice.Filter,
+		&packetSlice.StartTime,
+		&packetSlice.EndTime,
+		&packetSlice.Status,
+		&packetSlice.Error,
+		&packetSlice.PacketCount,
+		&packetSlice.SizeBytes,
+		&packetSlice.Truncated,
+		&packetSlice.RequestedAt,
+		&completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		packetSlice.CompletedAt = &completedAt.Time
+	}
+	return &packetSlice, nil
+}
@@ -0,0 +1,25 @@
+package queries
+
+const AgentReleasesInsert = `
+INSERT INTO agent_releases (version, os, arch, sha256, size_bytes, signature, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, published_at
+`
+
+const AgentReleasesSelectByVersion = `
+SELECT id, version, os, arch, sha256, size_bytes, signature, published_at
+FROM agent_releases
+WHERE version = $1 AND os = $2 AND arch = $3
+`
+
+const AgentReleasesSelect = `
+SELECT id, version, os, arch, sha256, size_bytes, signature, published_at
+FROM agent_releases
+ORDER BY published_at DESC, os, arch
+`
+
+const AgentReleasesSelectData = `
+SELECT data
+FROM agent_releases
+WHERE id = $1
+`
@@ -0,0 +1,46 @@
+package queries
+
+const CommandsInsert = `
+INSERT INTO commands (device_id, name, args, issued_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, status, issued_at
+`
+
+const CommandsSelectById = `
+SELECT id, device_id, name, args, issued_by, issued_at, expires_at, status, error,
+       delivered_at, started_at, finished_at
+FROM commands
+WHERE id = $1
+`
+
+const CommandsSelectByDeviceId = `
+SELECT id, device_id, name, args, issued_by, issued_at, expires_at, status, error,
+       delivered_at, started_at, finished_at
+FROM commands
+WHERE device_id = $1
+ORDER BY issued_at DESC
+`
+
+const CommandsMarkDelivered = `
+UPDATE commands
+SET status = 'delivered',
+	delivered_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+`
+
+const CommandsComplete = `
+UPDATE commands
+SET status = $1,
+	error = $2,
+	started_at = $3,
+	finished_at = COALESCE($4, CURRENT_TIMESTAMP)
+WHERE id = $5 AND status IN ('pending', 'delivered')
+`
+
+const CommandsExpirePastDue = `
+UPDATE commands
+SET status = 'expired',
+	error = CASE WHEN status = 'delivered' THEN 'no result was reported before the command expired' ELSE error END,
+	finished_at = CURRENT_TIMESTAMP
+WHERE status IN ('pending', 'delivered') AND expires_at < CURRENT_TIMESTAMP
+`
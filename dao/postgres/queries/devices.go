@@ -9,33 +9,76 @@ RETURNING id
 
 const DevicesSelectById = `
 SELECT id, os_unique_identifier, client_cert_pem, client_cert_fingerprint, organization_id,
-       pcap_version, interfaces, interface_bpf_associations, previous_associations
+       pcap_version, interfaces, interface_bpf_associations, previous_associations,
+       capture_stats, events_dropped, capture_stats_updated_at, capture_results, interface_details,
+       online, last_seen_at, agent_version, heartbeat
 FROM devices
 WHERE id = $1
 `
 
 const DevicesSelectByOSUniqueIdentifier = `
 SELECT id, os_unique_identifier, client_cert_pem, client_cert_fingerprint, organization_id,
-       pcap_version, interfaces, interface_bpf_associations, previous_associations
+       pcap_version, interfaces, interface_bpf_associations, previous_associations,
+       capture_stats, events_dropped, capture_stats_updated_at, capture_results, interface_details,
+       online, last_seen_at, agent_version, heartbeat
 FROM devices
 WHERE os_unique_identifier = $1
 `
 
 const DevicesSelectByOrganizationID = `
 SELECT id, os_unique_identifier, client_cert_pem, client_cert_fingerprint, organization_id,
-       pcap_version, interfaces, interface_bpf_associations, previous_associations
+       pcap_version, interfaces, interface_bpf_associations, previous_associations,
+       capture_stats, events_dropped, capture_stats_updated_at, capture_results, interface_details,
+       online, last_seen_at, agent_version, heartbeat
 FROM devices
 WHERE organization_id = $1
 `
 
+// DevicesUpdate leaves the interfaces, interface details and pcap version to DevicesUpdateInterfaces,
+// so an administrator's update never overwrites what the agent concurrently reports
 const DevicesUpdate = `
 UPDATE devices
 SET client_cert_pem = $1,
 	client_cert_fingerprint = $2,
-	pcap_version = $3,
-	interfaces = $4,
-	interface_bpf_associations = $5,
-	previous_associations = $6
-WHERE id = $7
+	interface_bpf_associations = $3,
+	previous_associations = $4
+WHERE id = $5
 RETURNING id
 `
+
+const DevicesUpdateInterfaces = `
+UPDATE devices
+SET interfaces = $1,
+	interface_details = $2,
+	pcap_version = $3
+WHERE id = $4
+`
+
+const DevicesUpdateCaptureStats = `
+UPDATE devices
+SET capture_stats = $1,
+	events_dropped = $2,
+	capture_stats_updated_at = $3
+WHERE id = $4
+`
+
+const DevicesUpdateCaptureResults = `
+UPDATE devices
+SET capture_results = $1
+WHERE id = $2
+`
+
+const DevicesUpdateHeartbeat = `
+UPDATE devices
+SET heartbeat = $1,
+	agent_version = $2,
+	last_seen_at = $3,
+	online = TRUE
+WHERE id = $4
+`
+
+const DevicesMarkOffline = `
+UPDATE devices
+SET online = FALSE
+WHERE online AND (last_seen_at IS NULL OR last_seen_at < $1)
+`
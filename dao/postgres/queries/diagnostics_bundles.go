@@ -0,0 +1,36 @@
+package queries
+
+const DiagnosticsBundlesInsert = `
+INSERT INTO diagnostics_bundles (device_id)
+VALUES ($1)
+RETURNING id, status, requested_at
+`
+
+const DiagnosticsBundlesSelectById = `
+SELECT id, device_id, status, error, size_bytes, requested_at, completed_at
+FROM diagnostics_bundles
+WHERE id = $1
+`
+
+const DiagnosticsBundlesSelectByDeviceId = `
+SELECT id, device_id, status, error, size_bytes, requested_at, completed_at
+FROM diagnostics_bundles
+WHERE device_id = $1
+ORDER BY requested_at DESC
+`
+
+const DiagnosticsBundlesComplete = `
+UPDATE diagnostics_bundles
+SET status = $1,
+	error = $2,
+	data = $3,
+	size_bytes = $4,
+	completed_at = CURRENT_TIMESTAMP
+WHERE id = $5 AND status = 'requested'
+`
+
+const DiagnosticsBundlesSelectData = `
+SELECT data
+FROM diagnostics_bundles
+WHERE id = $1 AND status = 'complete'
+`
@@ -8,3 +8,14 @@ FROM packet_events
 WHERE os_unique_identifier = $1
 AND event_time BETWEEN $2 AND $3
 `
+
+const DNSEventsSelectByDeviceIdDatetime = `
+SELECT
+	event_time, bpf, COALESCE(ip_src, ''), COALESCE(ip_dst, ''),
+	dns_id, response, COALESCE(response_code, ''), COALESCE(query_name, ''),
+	COALESCE(query_type, ''), answers
+FROM dns_events
+WHERE os_unique_identifier = $1
+AND event_time BETWEEN $2 AND $3
+ORDER BY event_time
+`
@@ -0,0 +1,40 @@
+package queries
+
+const PacketSlicesInsert = `
+INSERT INTO packet_slices (device_id, device_name, bpf, filter, start_time, end_time)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, status, requested_at
+`
+
+const PacketSlicesSelectById = `
+SELECT id, device_id, device_name, bpf, filter, start_time, end_time, status, error,
+       packet_count, size_bytes, truncated, requested_at, completed_at
+FROM packet_slices
+WHERE id = $1
+`
+
+const PacketSlicesSelectByDeviceId = `
+SELECT id, device_id, device_name, bpf, filter, start_time, end_time, status, error,
+       packet_count, size_bytes, truncated, requested_at, completed_at
+FROM packet_slices
+WHERE device_id = $1
+ORDER BY requested_at DESC
+`
+
+const PacketSlicesComplete = `
+UPDATE packet_slices
+SET status = $1,
+	error = $2,
+	data = $3,
+	packet_count = $4,
+	size_bytes = $5,
+	truncated = $6,
+	completed_at = CURRENT_TIMESTAMP
+WHERE id = $7 AND status = 'requested'
+`
+
+const PacketSlicesSelectData = `
+SELECT data
+FROM packet_slices
+WHERE id = $1 AND status = 'complete'
+`
@@ -0,0 +1,24 @@
+// Package bpf validates BPF filters with libpcap's compiler, so an invalid filter is rejected before it is sent to an agent.
+package bpf
+
+import (
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// DefaultLinkType is the link type filters are compiled for when the interface's one isn't known yet
+const DefaultLinkType = layers.LinkTypeEthernet
+
+// Validate compiles the filter for the link type with the given name, e.g. EN10MB, returning the compiler's error for an invalid filter.
+// An empty or unknown link type name compiles for the default link type.
+func Validate(filter, linkTypeName string, snapLen int) error {
+	linkType := DefaultLinkType
+	if linkTypeName != "" {
+		dlt := pcap.DatalinkNameToVal(linkTypeName)
+		if dlt >= 0 {
+			linkType = layers.LinkType(dlt)
+		}
+	}
+	_, err := pcap.CompileBPFFilter(linkType, snapLen, filter)
+	return err
+}
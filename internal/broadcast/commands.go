@@ -2,6 +2,7 @@ package broadcast
 
 import (
 	"sync"
+	"time"
 )
 
 const (
@@ -9,11 +10,95 @@ const (
 	CommandGetBPFConfig = "get_bpf_config"
 	// CommandSendInterfaces tells the pcap manager to send all interfaces available for capture
 	CommandSendInterfaces = "send_interfaces"
+	// CommandUploadPacketSlice tells the pcap manager to upload the packets of a capture's ring buffer in a time range
+	CommandUploadPacketSlice = "upload_packet_slice"
+	// CommandSetLogLevel tells the pcap manager to change the agent's log level until a timed revert
+	CommandSetLogLevel = "set_log_level"
+	// CommandCollectDiagnostics tells the pcap manager to upload a zip of the agent's logs, config, cert metadata, interfaces and capture stats
+	CommandCollectDiagnostics = "collect_diagnostics"
+	// CommandUpdateAgent tells the update manager to install a signed release of the agent and restart into it
+	CommandUpdateAgent = "update_agent"
 )
 
-// Command is the command sent over channels to subscribers
+// statuses of the command results the agent reports to the server
+const (
+	CommandStatusSucceeded = "succeeded"
+	CommandStatusFailed    = "failed"
+	// CommandStatusExpired is the result of a command received after its expiry, which isn't run
+	CommandStatusExpired = "expired"
+)
+
+// commandsBufferSize is how many commands a subscriber can fall behind by before commands are dropped for it
+const commandsBufferSize = 16
+
+// Command is the command sent over channels to subscribers, in the envelope the server issued it in.
+// Commands issued before the server tracked them, and the ones the agent issues itself, have no id and no expiry.
 type Command struct {
+	ID   string `json:"id,omitempty"`
 	Name string `json:"name"`
+	// IssuedBy is the id of the administrator who issued the command, or the component that issued it on its own
+	IssuedBy  string    `json:"issuedBy,omitempty"`
+	IssuedAt  time.Time `json:"issuedAt,omitzero"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	// the typed arguments of the commands that take any, at most one of which is set
+	UploadPacketSlice  *UploadPacketSliceArgs  `json:"uploadPacketSlice,omitempty"`
+	SetLogLevel        *SetLogLevelArgs        `json:"setLogLevel,omitempty"`
+	CollectDiagnostics *CollectDiagnosticsArgs `json:"collectDiagnostics,omitempty"`
+	UpdateAgent        *UpdateAgentArgs        `json:"updateAgent,omitempty"`
+}
+
+// UploadPacketSliceArgs are the arguments of the `upload_packet_slice` command
+type UploadPacketSliceArgs struct {
+	SliceID    string `json:"sliceId"`
+	DeviceName string `json:"deviceName"`
+	BPF        string `json:"bpf"`
+	// Filter is an optional BPF further filtering the packets of the slice
+	Filter string    `json:"filter,omitempty"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// SetLogLevelArgs are the arguments of the `set_log_level` command
+type SetLogLevelArgs struct {
+	// Level is one of debug, info, warn or error
+	Level string `json:"level"`
+	// RevertAfter is how long until the agent reverts to its default level
+	RevertAfter time.Duration `json:"revertAfter"`
+}
+
+// CollectDiagnosticsArgs are the arguments of the `collect_diagnostics` command
+type CollectDiagnosticsArgs struct {
+	BundleID string `json:"bundleId"`
+}
+
+// UpdateAgentArgs are the arguments of the `update_agent` command
+type UpdateAgentArgs struct {
+	Version string `json:"version"`
+	// SHA256 is the hex SHA-256 of the agent binary of the release for the device's OS and architecture
+	SHA256    string `json:"sha256"`
+	SizeBytes uint64 `json:"sizeBytes"`
+	// Signature is the detached ed25519 signature of the release manifest of the version, OS, architecture, SHA-256 and size
+	Signature []byte `json:"signature"`
+}
+
+// Args returns the command's typed arguments, nil for commands without arguments
+func (c *Command) Args() any {
+	switch {
+	case c.UploadPacketSlice != nil:
+		return c.UploadPacketSlice
+	case c.SetLogLevel != nil:
+		return c.SetLogLevel
+	case c.CollectDiagnostics != nil:
+		return c.CollectDiagnostics
+	case c.UpdateAgent != nil:
+		return c.UpdateAgent
+	}
+	return nil
+}
+
+// Expired reports whether the command's expiry has passed, never for commands without an expiry
+func (c *Command) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt)
 }
 
 // CommandsBroadcaster is the pub-sub mechanism for broadcasting commands
@@ -32,12 +117,12 @@ func NewCommandsBroadcaster() *CommandsBroadcaster {
 
 // Subscribe is the method subscribers call to receive the latest command
 func (cb *CommandsBroadcaster) Subscribe() <-chan Command {
-	ch := make(chan Command, 1)
+	ch := make(chan Command, commandsBufferSize)
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 	cb.subs = append(cb.subs, ch)
 	if cb.last != nil {
-		ch <- Command{Name: cb.last.Name}
+		ch <- *cb.last
 	}
 	return ch
 }
@@ -49,7 +134,7 @@ func (cb *CommandsBroadcaster) Publish(command *Command) {
 	cb.last = command
 	for _, sub := range cb.subs {
 		select {
-		case sub <- Command{Name: command.Name}:
+		case sub <- *command:
 		default:
 		}
 	}
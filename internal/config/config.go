@@ -78,6 +78,71 @@ func GetPollInterval() time.Duration {
 	return 1 * time.Minute
 }
 
+// GetCommandStreamIdleTimeout returns how long the command stream may go without a message, commands or the server's noops,
+// before it is considered dead and reopened
+func GetCommandStreamIdleTimeout() time.Duration {
+	return 90 * time.Second
+}
+
+// GetCommandStreamReconnectBaseDelay returns the backoff delay before the first attempt to reopen a broken command stream
+func GetCommandStreamReconnectBaseDelay() time.Duration {
+	return 1 * time.Second
+}
+
+// GetCommandStreamReconnectMaxDelay returns the max backoff delay between attempts to reopen a broken command stream
+func GetCommandStreamReconnectMaxDelay() time.Duration {
+	return 1 * time.Minute
+}
+
+// GetHeartbeatInterval returns the interval at which the agent tells the server it is alive
+func GetHeartbeatInterval() time.Duration {
+	return 1 * time.Minute
+}
+
+// GetLogLevelRevertAfter returns how long a log level set by a `set_log_level` command lasts when the command doesn't say
+func GetLogLevelRevertAfter() time.Duration {
+	return 1 * time.Hour
+}
+
+// GetDiagnosticsLogMaxBytes returns the max size of the logs included in the bundle of a `collect_diagnostics` command, older logs are left out
+func GetDiagnosticsLogMaxBytes() int64 {
+	return 16 * 1024 * 1024
+}
+
+// GetAgentBinaryPath returns the path of the agent's executable, which an `update_agent` command replaces
+func GetAgentBinaryPath() string {
+	if runtime.GOOS == "windows" {
+		installDir := GetInstallDir()
+		return filepath.Join(installDir, "packet_sentry.exe")
+	}
+	return "/opt/packet-sentry/bin/packet-sentry-agent"
+}
+
+// GetUpdateStateFilePath returns the path of the JSON file tracking an update from the swap of the binary until the new version heartbeats
+func GetUpdateStateFilePath() string {
+	if runtime.GOOS == "windows" {
+		installDir := GetInstallDir()
+		return filepath.Join(installDir, "update.json")
+	}
+	return "/opt/packet-sentry/update.json"
+}
+
+// GetUpdateGracePeriod returns how long after the binary is swapped the new version has to heartbeat before it is rolled back
+func GetUpdateGracePeriod() time.Duration {
+	return 5 * time.Minute
+}
+
+// GetUpdateMaxStartAttempts returns how many times the new version may start within the grace period before it is rolled back,
+// so a version that crashes on start doesn't wait out the grace period
+func GetUpdateMaxStartAttempts() int {
+	return 3
+}
+
+// GetUpdateMaxBytes returns the max size of an agent binary downloaded for an `update_agent` command
+func GetUpdateMaxBytes() uint64 {
+	return 256 * 1024 * 1024
+}
+
 // GetBPFConfigFilePath returns the path of cached on-disk BPF config
 func GetBPFConfigFilePath() string {
 	if runtime.GOOS == "windows" {
@@ -86,3 +151,163 @@ func GetBPFConfigFilePath() string {
 	}
 	return "/opt/packet-sentry/bpfConfig.json"
 }
+
+// GetFlowActiveTimeout returns the max duration a flow is aggregated before its record is emitted, even if it is still active
+func GetFlowActiveTimeout() time.Duration {
+	return 1 * time.Minute
+}
+
+// GetFlowIdleTimeout returns the duration without packets after which a flow's record is emitted
+func GetFlowIdleTimeout() time.Duration {
+	return 15 * time.Second
+}
+
+// GetFlowExpiryInterval returns the interval at which the flow table is checked for expired flows
+func GetFlowExpiryInterval() time.Duration {
+	return 5 * time.Second
+}
+
+// GetFlowTableMaxFlows returns the max number of flows tracked at once in the flow table
+func GetFlowTableMaxFlows() int {
+	return 65536
+}
+
+// GetHTTPFlowByteBudget returns the max number of reassembled TCP bytes inspected per connection of an http mode capture
+func GetHTTPFlowByteBudget() int {
+	return 64 * 1024
+}
+
+// GetHTTPMaxStreams returns the max number of TCP connections reassembled at once per http mode capture
+func GetHTTPMaxStreams() int {
+	return 4096
+}
+
+// GetHTTPMaxBufferedPagesPerStream returns the max number of out-of-order pages buffered per TCP connection before skipping the missing bytes
+func GetHTTPMaxBufferedPagesPerStream() int {
+	return 16
+}
+
+// GetHTTPIdleTimeout returns the duration without packets after which a TCP connection is no longer reassembled
+func GetHTTPIdleTimeout() time.Duration {
+	return 2 * time.Minute
+}
+
+// GetHTTPFlushInterval returns the interval at which missing bytes that are still awaited are skipped and idle connections are closed
+func GetHTTPFlushInterval() time.Duration {
+	return 5 * time.Second
+}
+
+// GetSpoolDir returns the directory of the on-disk spool of packet events that couldn't be sent to the server
+func GetSpoolDir() string {
+	if runtime.GOOS == "windows" {
+		installDir := GetInstallDir()
+		return filepath.Join(installDir, "spool")
+	}
+	return "/opt/packet-sentry/spool"
+}
+
+// GetSpoolMaxBytes returns the max total size of the packet event spool, beyond which the oldest events are dropped
+func GetSpoolMaxBytes() int64 {
+	return 256 * 1024 * 1024
+}
+
+// GetSpoolSegmentMaxBytes returns the max size of each file of the packet event spool
+func GetSpoolSegmentMaxBytes() int64 {
+	return 4 * 1024 * 1024
+}
+
+// GetSpoolReplayInterval returns the interval at which spooled packet events are replayed over a live stream
+func GetSpoolReplayInterval() time.Duration {
+	return 1 * time.Second
+}
+
+// GetRingBufferDir returns the directory of the rolling pcapng files of the captures with a ring buffer, one subdirectory per capture
+func GetRingBufferDir() string {
+	if runtime.GOOS == "windows" {
+		installDir := GetInstallDir()
+		return filepath.Join(installDir, "ring")
+	}
+	return "/opt/packet-sentry/ring"
+}
+
+// GetRingBufferMaxBytes returns the max total size of the ring buffer of each capture, beyond which the oldest packets are deleted
+func GetRingBufferMaxBytes() int64 {
+	return 512 * 1024 * 1024
+}
+
+// GetRingBufferMaxAge returns the max age of the packets of a ring buffer, older ones are deleted
+func GetRingBufferMaxAge() time.Duration {
+	return 24 * time.Hour
+}
+
+// GetRingBufferSegmentMaxBytes returns the size at which a ring buffer starts a new pcapng file
+func GetRingBufferSegmentMaxBytes() int64 {
+	return 16 * 1024 * 1024
+}
+
+// GetRingBufferSegmentMaxAge returns the age at which a ring buffer starts a new pcapng file,
+// bounding how much of the buffer outlives the max age
+func GetRingBufferSegmentMaxAge() time.Duration {
+	return 5 * time.Minute
+}
+
+// GetRingBufferPruneInterval returns the interval at which ring buffers delete the packets beyond their max age
+func GetRingBufferPruneInterval() time.Duration {
+	return 1 * time.Minute
+}
+
+// GetPacketSliceMaxBytes returns the max size of the pcap file uploaded for an `upload_packet_slice` command, later packets are left out
+func GetPacketSliceMaxBytes() int64 {
+	return 32 * 1024 * 1024
+}
+
+// GetCaptureStatsInterval returns the interval at which live captures read their packet counters from libpcap
+func GetCaptureStatsInterval() time.Duration {
+	return 10 * time.Second
+}
+
+// GetCaptureStatsReportInterval returns the interval at which the packet counters of the live captures are reported to the server
+func GetCaptureStatsReportInterval() time.Duration {
+	return 5 * time.Minute
+}
+
+// GetPacketLossReportInterval returns the interval at which counts of dropped packets are reported
+func GetPacketLossReportInterval() time.Duration {
+	return 1 * time.Minute
+}
+
+// GetStreamReconnectBaseDelay returns the backoff delay before the first attempt to reopen a broken packet stream
+func GetStreamReconnectBaseDelay() time.Duration {
+	return 1 * time.Second
+}
+
+// GetStreamReconnectMaxDelay returns the max backoff delay between attempts to reopen a broken packet stream
+func GetStreamReconnectMaxDelay() time.Duration {
+	return 2 * time.Minute
+}
+
+// GetPacketBatchInterval returns the max duration packet events are buffered before they are sent as a batch
+func GetPacketBatchInterval() time.Duration {
+	return 1 * time.Second
+}
+
+// GetPacketBatchMaxEvents returns the number of buffered packet events at which a batch is sent without waiting on the interval
+func GetPacketBatchMaxEvents() int {
+	return 500
+}
+
+// GetPacketBatchMaxBytes returns the size a batch of packet events is kept under, sending it without waiting on the interval once the next event wouldn't fit.
+// The agent-api publishes each batch as one NATS message, so it is well below the default NATS max_payload of 1 MiB.
+func GetPacketBatchMaxBytes() int {
+	return 512 * 1024
+}
+
+// GetStreamCompressor returns the name of the gRPC compressor for packet event batches, either "gzip" or "zstd"
+func GetStreamCompressor() string {
+	return "zstd"
+}
+
+// GetInterfaceScanInterval returns the interval at which the interfaces are listed to detect added, removed or changed ones
+func GetInterfaceScanInterval() time.Duration {
+	return 30 * time.Second
+}
@@ -0,0 +1,81 @@
+// Package grpczstd registers a zstd compressor with gRPC, the way google.golang.org/grpc/encoding/gzip does for gzip.
+// Importing it for its side effect lets clients pass grpc.UseCompressor(grpczstd.Name) and servers decompress zstd messages.
+package grpczstd
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the name the zstd compressor is registered under
+const Name = "zstd"
+
+func init() {
+	encoding.RegisterCompressor(&compressor{})
+}
+
+type compressor struct {
+	encoderPool sync.Pool
+	decoderPool sync.Pool
+}
+
+type writer struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+// Close flushes the compressed frame and returns the encoder to the pool
+func (w *writer) Close() error {
+	defer w.pool.Put(w)
+	return w.Encoder.Close()
+}
+
+type reader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+// Read returns the decoder to the pool once the compressed message is fully read
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.Decoder.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r)
+	}
+	return n, err
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if pooled, ok := c.encoderPool.Get().(*writer); ok {
+		pooled.Encoder.Reset(w)
+		return pooled, nil
+	}
+	encoder, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &writer{Encoder: encoder, pool: &c.encoderPool}, nil
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	if pooled, ok := c.decoderPool.Get().(*reader); ok {
+		err := pooled.Decoder.Reset(r)
+		if err != nil {
+			c.decoderPool.Put(pooled)
+			return nil, err
+		}
+		return pooled, nil
+	}
+	// no concurrency so Reset doesn't leave goroutines behind, and no goroutines are leaked by pooled decoders
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &reader{Decoder: decoder, pool: &c.decoderPool}, nil
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
@@ -4,10 +4,14 @@ package log
 const (
 	// KeyArch is the key name constant "arch" for use in the structured logger
 	KeyArch = "arch"
+	// KeyBackoff is the key name constant "backoff" for use in the structured logger
+	KeyBackoff = "backoff"
 	// KeyBPF is the key name constant "bpf" for use in the structured logger
 	KeyBPF = "bpf"
 	// KeyBPFHash is the key name constant "bpfHash" for use in the structured logger
 	KeyBPFHash = "bpfHash"
+	// KeyBundleID is the key name constant "bundleId" for use in the structured logger
+	KeyBundleID = "bundleId"
 	// KeyCaptureConfig is the key name constant "captureConfig" for use in the structured logger
 	KeyCaptureConfig = "captureConfig"
 	// KeyCertFingerprint is the key name constant "cert_fingerprint" for use in the structured logger
@@ -16,6 +20,8 @@ const (
 	KeyCertificateSigningRequest = "certificateSigningRequest"
 	// KeyCommand is the key name constant "command" for use in the structured logger
 	KeyCommand = "command"
+	// KeyCommandID is the key name constant "commandId" for use in the structured logger
+	KeyCommandID = "commandId"
 	// KeyDeviceName is the key name constant "deviceName" for use in the structured logger
 	KeyDeviceName = "deviceName"
 	// KeyDroppedPacket is the key name constant "droppedPacket" for use in the structured logger
@@ -24,22 +30,64 @@ const (
 	KeyError = "error"
 	// KeyExistingCertFingerprint is the key name constant "existing_cert_fingerprint" for use in the structured logger
 	KeyExistingCertFingerprint = "existing_cert_fingerprint"
+	// KeyFlowMode is the key name constant "flowMode" for use in the structured logger
+	KeyFlowMode = "flowMode"
+	// KeyFlows is the key name constant "flows" for use in the structured logger
+	KeyFlows = "flows"
+	// KeyFlowsDropped is the key name constant "flowsDropped" for use in the structured logger
+	KeyFlowsDropped = "flowsDropped"
 	// KeyFunction is the key name constant "function" for use in the structured logger
 	KeyFunction = "function"
+	// KeyGRPCCode is the key name constant "grpcCode" for use in the structured logger
+	KeyGRPCCode = "grpcCode"
+	// KeyHTTPMode is the key name constant "httpMode" for use in the structured logger
+	KeyHTTPMode = "httpMode"
+	// KeyHTTPStreamsDropped is the key name constant "httpStreamsDropped" for use in the structured logger
+	KeyHTTPStreamsDropped = "httpStreamsDropped"
+	// KeyLogLevel is the key name constant "logLevel" for use in the structured logger
+	KeyLogLevel = "logLevel"
 	// KeyOS is the key name constant "os" for use in the structured logger
 	KeyOS = "os"
+	// KeyOSUniqueIdentifier is the key name constant "osUniqueIdentifier" for use in the structured logger
+	KeyOSUniqueIdentifier = "osUniqueIdentifier"
+	// KeyPacketCount is the key name constant "packetCount" for use in the structured logger
+	KeyPacketCount = "packetCount"
+	// KeyPacketsDropped is the key name constant "packetsDropped" for use in the structured logger
+	KeyPacketsDropped = "packetsDropped"
+	// KeyPacketsReplayed is the key name constant "packetsReplayed" for use in the structured logger
+	KeyPacketsReplayed = "packetsReplayed"
+	// KeyPath is the key name constant "path" for use in the structured logger
+	KeyPath = "path"
 	// KeyPCapVersion is the key name constant "pcapVersion" for use in the structured logger
 	KeyPCapVersion = "pcapVersion"
 	// KeyPromiscuous is the key name constant "promiscuous" for use in the structured logger
 	KeyPromiscuous = "promiscuous"
+	// KeyReconnectAttempt is the key name constant "reconnectAttempt" for use in the structured logger
+	KeyReconnectAttempt = "reconnectAttempt"
+	// KeyRevertAt is the key name constant "revertAt" for use in the structured logger
+	KeyRevertAt = "revertAt"
+	// KeyRingBuffer is the key name constant "ringBuffer" for use in the structured logger
+	KeyRingBuffer = "ringBuffer"
 	// KeyServiceName is the key name constant "serviceName" for use in the structured logger
 	KeyServiceName = "serviceName"
+	// KeySince is the key name constant "since" for use in the structured logger
+	KeySince = "since"
+	// KeySliceID is the key name constant "sliceId" for use in the structured logger
+	KeySliceID = "sliceId"
 	// KeySnapLen is the key name constant "snapLen" for use in the structured logger
 	KeySnapLen = "snapLen"
+	// KeyStartAttempts is the key name constant "startAttempts" for use in the structured logger
+	KeyStartAttempts = "startAttempts"
 	// KeyStatus is the key name constant "status" for use in the structured logger
 	KeyStatus = "status"
+	// KeyStreamState is the key name constant "streamState" for use in the structured logger
+	KeyStreamState = "streamState"
+	// KeyStreamHealth is the key name constant "streamHealth" for use in the structured logger
+	KeyStreamHealth = "streamHealth"
 	// KeyTimeout is the key name constant "timeout" for use in the structured logger
 	KeyTimeout = "timeout"
 	// KeyURI is the key name constant "uri" for use in the structured logger
 	KeyURI = "uri"
+	// KeyVersion is the key name constant "version" for use in the structured logger
+	KeyVersion = "version"
 )
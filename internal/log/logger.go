@@ -9,6 +9,26 @@ import (
 	"github.com/danielhoward314/packet-sentry/internal/config"
 )
 
+// DefaultLevel is the level the agent logs at unless a `set_log_level` command changed it
+const DefaultLevel = slog.LevelInfo
+
+// level is shared by every logger derived from the base logger, so changing it takes effect everywhere at once
+var level = func() *slog.LevelVar {
+	lv := &slog.LevelVar{}
+	lv.Set(DefaultLevel)
+	return lv
+}()
+
+// SetLevel changes the level of the base logger and every logger derived from it
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level returns the current level of the base logger
+func Level() slog.Level {
+	return level.Level()
+}
+
 // GetBaseLogger returns the base instance of the structured logger
 func GetBaseLogger() *slog.Logger {
 	logFilePath := config.GetLogFilePath()
@@ -22,7 +42,7 @@ func GetBaseLogger() *slog.Logger {
 	}
 
 	handler := slog.NewJSONHandler(rotatingLog, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: level,
 	})
 
 	logger := slog.New(handler)
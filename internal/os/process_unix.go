@@ -0,0 +1,18 @@
+//go:build linux || darwin
+
+package os
+
+import (
+	"syscall"
+	"time"
+)
+
+// ProcessCPUTime returns the user and system CPU time the agent's process has used since it started
+func ProcessCPUTime() (time.Duration, error) {
+	var rusage syscall.Rusage
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &rusage)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(rusage.Utime.Nano() + rusage.Stime.Nano()), nil
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package os
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// ProcessCPUTime returns the user and kernel CPU time the agent's process has used since it started
+func ProcessCPUTime() (time.Duration, error) {
+	var creationTime, exitTime, kernelTime, userTime windows.Filetime
+	err := windows.GetProcessTimes(windows.CurrentProcess(), &creationTime, &exitTime, &kernelTime, &userTime)
+	if err != nil {
+		return 0, err
+	}
+	return filetimeDuration(kernelTime) + filetimeDuration(userTime), nil
+}
+
+// filetimeDuration converts a FILETIME holding a duration, in 100-nanosecond intervals
+func filetimeDuration(ft windows.Filetime) time.Duration {
+	return time.Duration(uint64(ft.HighDateTime)<<32|uint64(ft.LowDateTime)) * 100
+}
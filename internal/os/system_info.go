@@ -11,9 +11,18 @@ const (
 	logAttrValSvcName = "systemInfo"
 )
 
+// OSDetails are the name and version of the operating system and its kernel
+type OSDetails struct {
+	// OSVersion is the OS's name and version, e.g. "Ubuntu 24.04.2 LTS", "macOS 15.5" or "Windows 11 Pro 24H2"
+	OSVersion string
+	// KernelVersion is the kernel's release, e.g. "6.8.0-60-generic", "24.5.0" or "10.0.26100"
+	KernelVersion string
+}
+
 // SystemInfo is the interface for platform-specific operations for getting info about the system
 type SystemInfo interface {
 	GetUniqueSystemIdentifier() (string, error)
+	GetOSDetails() (*OSDetails, error)
 }
 
 // NewSystemInfo returns a platform-specific implementation of the SystemInfo interface
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os/exec"
 	"strings"
+	"syscall"
 
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
 )
@@ -47,3 +48,21 @@ func (dsi *darwinSystemInfo) GetUniqueSystemIdentifier() (string, error) {
 	}
 	return serialNumber, nil
 }
+
+// GetOSDetails is the darwin implementation for getting the OS and kernel versions, from sw_vers and the kern.osrelease sysctl
+func (dsi *darwinSystemInfo) GetOSDetails() (*OSDetails, error) {
+	kernelVersion, err := syscall.Sysctl("kern.osrelease")
+	if err != nil {
+		return nil, err
+	}
+	details := &OSDetails{
+		OSVersion:     "macOS",
+		KernelVersion: kernelVersion,
+	}
+	out, err := exec.Command("/usr/bin/sw_vers", "-productVersion").Output()
+	if err != nil {
+		return nil, err
+	}
+	details.OSVersion = "macOS " + strings.TrimSpace(string(out))
+	return details, nil
+}
@@ -43,3 +43,30 @@ func (lsi *linuxSystemInfo) GetUniqueSystemIdentifier() (string, error) {
 	}
 	return "", errors.New("machine-id not found")
 }
+
+// GetOSDetails is the linux implementation for getting the OS and kernel versions,
+// from os-release and the kernel's osrelease
+func (lsi *linuxSystemInfo) GetOSDetails() (*OSDetails, error) {
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return nil, err
+	}
+	details := &OSDetails{
+		OSVersion:     "Linux",
+		KernelVersion: strings.TrimSpace(string(data)),
+	}
+	for _, path := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		data, err = os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			prettyName, ok := strings.CutPrefix(line, "PRETTY_NAME=")
+			if ok {
+				details.OSVersion = strings.Trim(strings.TrimSpace(prettyName), `"`)
+				return details, nil
+			}
+		}
+	}
+	return details, nil
+}
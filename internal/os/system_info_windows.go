@@ -9,6 +9,8 @@ import (
 	"os/exec"
 	"strings"
 
+	"golang.org/x/sys/windows/registry"
+
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
 )
 
@@ -46,3 +48,38 @@ func (wsi *windowsSystemInfo) GetUniqueSystemIdentifier() (string, error) {
 
 	return uuid, nil
 }
+
+// GetOSDetails is the windows implementation for getting the OS and kernel versions, from the registry's CurrentVersion key
+func (wsi *windowsSystemInfo) GetOSDetails() (*OSDetails, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	productName, _, err := key.GetStringValue("ProductName")
+	if err != nil {
+		return nil, err
+	}
+	osVersion := productName
+	displayVersion, _, err := key.GetStringValue("DisplayVersion")
+	if err == nil {
+		osVersion += " " + displayVersion
+	}
+	major, _, err := key.GetIntegerValue("CurrentMajorVersionNumber")
+	if err != nil {
+		return nil, err
+	}
+	minor, _, err := key.GetIntegerValue("CurrentMinorVersionNumber")
+	if err != nil {
+		return nil, err
+	}
+	build, _, err := key.GetStringValue("CurrentBuild")
+	if err != nil {
+		return nil, err
+	}
+	return &OSDetails{
+		OSVersion:     osVersion,
+		KernelVersion: fmt.Sprintf("%d.%d.%s", major, minor, build),
+	}, nil
+}
@@ -0,0 +1,159 @@
+package pcap
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// restoreCachedConfig starts the packet captures of the BPF config cached on disk by the last successful enforcement
+func (m *pcapManager) restoreCachedConfig() error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.restoreCachedConfig")
+
+	content, err := os.ReadFile(config.GetBPFConfigFilePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Info("no cached BPF config on disk, waiting on server for BPF config")
+			return nil
+		}
+		return err
+	}
+
+	var cachedConfig CachedBPFConfig
+	err = json.Unmarshal(content, &cachedConfig)
+	if err != nil {
+		return err
+	}
+
+	bpfConfig := &pbAgent.BPFConfig{
+		Create: make(map[string]*pbAgent.InterfaceCaptureMap),
+	}
+	for ifaceName, captures := range cachedConfig.InterfacesToBPFAssociations {
+		bpfConfig.Create[ifaceName] = &pbAgent.InterfaceCaptureMap{
+			Captures: make(map[uint64]*pbAgent.CaptureConfig),
+		}
+		for filterHash, captureConfig := range captures {
+			bpfConfig.Create[ifaceName].Captures[filterHash] = captureConfigToPB(captureConfig)
+		}
+	}
+
+	logger.Info("restoring packet captures from cached BPF config")
+	return m.enforceConfig(bpfConfig)
+}
+
+// saveCachedConfig writes the config of all live packet captures to disk so they can be restored on the next startup
+func (m *pcapManager) saveCachedConfig() error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.saveCachedConfig")
+
+	cachedConfig := CachedBPFConfig{
+		InterfacesToBPFAssociations: m.runningConfig(),
+	}
+	content, err := json.Marshal(cachedConfig)
+	if err != nil {
+		return err
+	}
+
+	// write then rename, so a crash mid-write can't leave a truncated cache behind
+	cachedConfigFilePath := config.GetBPFConfigFilePath()
+	tmpFilePath := cachedConfigFilePath + ".tmp"
+	err = os.WriteFile(tmpFilePath, content, 0o600)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("caching BPF config to disk")
+	return os.Rename(tmpFilePath, cachedConfigFilePath)
+}
+
+// reconcileConfig builds the create, update and delete maps that take the live packet captures to the server's desired state
+func (m *pcapManager) reconcileConfig(desired map[string]*pbAgent.InterfaceCaptureMap) *pbAgent.BPFConfig {
+	running := m.runningConfig()
+	bpfConfig := &pbAgent.BPFConfig{
+		Create: make(map[string]*pbAgent.InterfaceCaptureMap),
+		Update: make(map[string]*pbAgent.InterfaceCaptureMap),
+		Delete: make(map[string]*pbAgent.InterfaceCaptureMap),
+	}
+
+	addCapture := func(captureMaps map[string]*pbAgent.InterfaceCaptureMap, ifaceName string, filterHash uint64, captureConfig *pbAgent.CaptureConfig) {
+		if captureMaps[ifaceName] == nil {
+			captureMaps[ifaceName] = &pbAgent.InterfaceCaptureMap{Captures: make(map[uint64]*pbAgent.CaptureConfig)}
+		}
+		captureMaps[ifaceName].Captures[filterHash] = captureConfig
+	}
+
+	for ifaceName, desiredCaptures := range desired {
+		for filterHash, desiredConfig := range desiredCaptures.GetCaptures() {
+			runningConfig, isRunning := running[ifaceName][filterHash]
+			if !isRunning {
+				addCapture(bpfConfig.Create, ifaceName, filterHash, desiredConfig)
+				continue
+			}
+			if runningConfig.BPF != desiredConfig.Bpf ||
+				runningConfig.Promiscuous != desiredConfig.Promiscuous ||
+				runningConfig.SnapLen != desiredConfig.SnapLen ||
+				runningConfig.FlowMode != desiredConfig.FlowMode ||
+				runningConfig.HTTPMode != desiredConfig.HttpMode ||
+				runningConfig.RingBuffer != desiredConfig.RingBuffer {
+				addCapture(bpfConfig.Update, ifaceName, filterHash, desiredConfig)
+			}
+		}
+	}
+
+	for ifaceName, runningCaptures := range running {
+		for filterHash, runningConfig := range runningCaptures {
+			_, isDesired := desired[ifaceName].GetCaptures()[filterHash]
+			if !isDesired {
+				addCapture(bpfConfig.Delete, ifaceName, filterHash, captureConfigToPB(runningConfig))
+			}
+		}
+	}
+
+	// the failures of captures that are no longer desired have nothing left to retry
+	desiredKeys := make(map[captureKey]bool)
+	for ifaceName, desiredCaptures := range desired {
+		for _, desiredConfig := range desiredCaptures.GetCaptures() {
+			desiredKeys[captureKey{deviceName: ifaceName, bpf: desiredConfig.Bpf}] = true
+		}
+	}
+	for key := range m.captureFailures {
+		if !desiredKeys[key] {
+			delete(m.captureFailures, key)
+		}
+	}
+
+	return bpfConfig
+}
+
+// runningConfig returns a snapshot of the config of all live packet captures
+func (m *pcapManager) runningConfig() map[string]map[uint64]*CaptureConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	running := make(map[string]map[uint64]*CaptureConfig)
+	for ifaceName, captures := range m.ifaceNameToFiltersAssociations {
+		if len(captures) == 0 {
+			continue
+		}
+		running[ifaceName] = make(map[uint64]*CaptureConfig)
+		for filterHash, capture := range captures {
+			running[ifaceName][filterHash] = capture.config
+		}
+	}
+	return running
+}
+
+func captureConfigToPB(captureConfig *CaptureConfig) *pbAgent.CaptureConfig {
+	return &pbAgent.CaptureConfig{
+		Bpf:         captureConfig.BPF,
+		DeviceName:  captureConfig.DeviceName,
+		Promiscuous: captureConfig.Promiscuous,
+		SnapLen:     captureConfig.SnapLen,
+		FlowMode:    captureConfig.FlowMode,
+		HttpMode:    captureConfig.HTTPMode,
+		RingBuffer:  captureConfig.RingBuffer,
+	}
+}
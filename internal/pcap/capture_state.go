@@ -0,0 +1,104 @@
+package pcap
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// capture states of the capture results reported to the server
+const (
+	captureStateRunning = "running"
+	captureStateFailed  = "failed"
+	captureStateStopped = "stopped"
+)
+
+// captureKey identifies a capture by its interface and BPF
+type captureKey struct {
+	deviceName string
+	bpf        string
+}
+
+// captureFailure is why a capture of the BPF config failed to start, with its config to retry it with.
+// The pcap manager keeps it until the capture starts or the config deletes it.
+type captureFailure struct {
+	filterHash    uint64
+	captureConfig *pbAgent.CaptureConfig
+	err           error
+	linkType      string
+	at            time.Time
+}
+
+// recordCaptureResult keeps the failure of a capture the config created or updated, or forgets an earlier one once it started
+func (m *pcapManager) recordCaptureResult(ifaceName string, filterHash uint64, captureConfig *pbAgent.CaptureConfig, linkType string, err error) {
+	key := captureKey{deviceName: ifaceName, bpf: captureConfig.Bpf}
+	if err == nil {
+		delete(m.captureFailures, key)
+		return
+	}
+	m.captureFailures[key] = &captureFailure{
+		filterHash:    filterHash,
+		captureConfig: captureConfig,
+		err:           err,
+		linkType:      linkType,
+		at:            time.Now(),
+	}
+}
+
+// captureResults returns the state of every capture of the agent: the live ones, running or stopped, and the ones that failed to start.
+// It is called from the StartAll goroutine, which owns the interfaces and capture failures.
+func (m *pcapManager) captureResults() []*pbAgent.CaptureResult {
+	var results []*pbAgent.CaptureResult
+	m.mu.Lock()
+	for ifaceName, captures := range m.ifaceNameToFiltersAssociations {
+		for _, capture := range captures {
+			state := captureStateRunning
+			// a capture whose interface went away may not notice, since libpcap keeps retrying the read
+			if capture.ended.Load() || (len(m.interfaces) > 0 && m.interfaces[ifaceName] == nil) {
+				state = captureStateStopped
+			}
+			results = append(results, &pbAgent.CaptureResult{
+				Bpf:        capture.config.BPF,
+				DeviceName: ifaceName,
+				LinkType:   capture.linkType,
+				AppliedAt:  timestamppb.New(capture.startedAt),
+				State:      state,
+			})
+		}
+	}
+	m.mu.Unlock()
+
+	for key, failure := range m.captureFailures {
+		results = append(results, &pbAgent.CaptureResult{
+			Bpf:        key.bpf,
+			DeviceName: key.deviceName,
+			Error:      failure.err.Error(),
+			LinkType:   failure.linkType,
+			AppliedAt:  timestamppb.New(failure.at),
+			State:      captureStateFailed,
+		})
+	}
+
+	slices.SortFunc(results, func(a, b *pbAgent.CaptureResult) int {
+		return cmp.Or(cmp.Compare(a.DeviceName, b.DeviceName), cmp.Compare(a.Bpf, b.Bpf))
+	})
+	return results
+}
+
+// reportCaptureResults sends the server the state of every capture of the agent
+func (m *pcapManager) reportCaptureResults() error {
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		return fmt.Errorf("no agent gRPC client available, cannot report capture results")
+	}
+
+	_, err := client.ReportCaptureResults(m.ctx, &pbAgent.ReportCaptureResultsRequest{Results: m.captureResults()})
+	return err
+}
@@ -0,0 +1,45 @@
+package pcap
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// reportCommandResult reports the outcome of a command to the server.
+// Commands without an id weren't tracked by the server, e.g. the `get_bpf_config` of the first poll, so nothing is reported for them.
+func (m *pcapManager) reportCommandResult(command broadcast.Command, startedAt time.Time, err error) {
+	if command.ID == "" {
+		return
+	}
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.reportCommandResult", psLog.KeyCommand, command.Name, psLog.KeyCommandID, command.ID)
+
+	result := &pbAgent.CommandResult{
+		CommandId:  command.ID,
+		Name:       command.Name,
+		Status:     broadcast.CommandStatusSucceeded,
+		StartedAt:  timestamppb.New(startedAt),
+		FinishedAt: timestamppb.Now(),
+	}
+	if err != nil {
+		result.Status = broadcast.CommandStatusFailed
+		result.Error = err.Error()
+	}
+
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		logger.Error("no agent gRPC client available, cannot report command result")
+		return
+	}
+
+	_, err = client.ReportCommandResult(m.ctx, result)
+	if err != nil {
+		logger.Error("failed to report command result", psLog.KeyError, err)
+	}
+}
@@ -0,0 +1,360 @@
+package pcap
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	"github.com/danielhoward314/packet-sentry/internal/version"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// diagnosticsChunkBytes is the size of the chunks a diagnostics bundle is uploaded in
+const diagnosticsChunkBytes = 1024 * 1024
+
+// diagnosticsSnapshot is the state of the pcap manager included in a diagnostics bundle,
+// taken on the StartAll goroutine so the bundle can be written and uploaded without holding it up
+type diagnosticsSnapshot struct {
+	agent          diagnosticsAgent
+	interfaces     *pbAgent.ReportInterfacesRequest
+	captureStats   *pbAgent.ReportCaptureStatsRequest
+	captureResults *pbAgent.ReportCaptureResultsRequest
+}
+
+// diagnosticsAgent is the agent.json file of a diagnostics bundle
+type diagnosticsAgent struct {
+	Version      string       `json:"version"`
+	CommitHash   string       `json:"commitHash,omitempty"`
+	BuildTime    string       `json:"buildTime,omitempty"`
+	OS           string       `json:"os"`
+	Arch         string       `json:"arch"`
+	StartedAt    time.Time    `json:"startedAt"`
+	CollectedAt  time.Time    `json:"collectedAt"`
+	LogLevel     string       `json:"logLevel"`
+	StreamHealth StreamHealth `json:"streamHealth"`
+}
+
+// diagnosticsCert is the metadata of a certificate in the certs.json file of a diagnostics bundle
+type diagnosticsCert struct {
+	Path         string    `json:"path"`
+	Error        string    `json:"error,omitempty"`
+	Subject      string    `json:"subject,omitempty"`
+	Issuer       string    `json:"issuer,omitempty"`
+	SerialNumber string    `json:"serialNumber,omitempty"`
+	NotBefore    time.Time `json:"notBefore,omitzero"`
+	NotAfter     time.Time `json:"notAfter,omitzero"`
+	Fingerprint  string    `json:"fingerprint,omitempty"`
+}
+
+// diagnosticsSnapshot returns the state of the pcap manager for a diagnostics bundle.
+// It is called from the StartAll goroutine, which owns the interfaces and capture failures.
+func (m *pcapManager) diagnosticsSnapshot(now time.Time) *diagnosticsSnapshot {
+	return &diagnosticsSnapshot{
+		agent: diagnosticsAgent{
+			Version:      version.Version,
+			CommitHash:   version.CommitHash,
+			BuildTime:    version.BuildTime,
+			OS:           runtime.GOOS,
+			Arch:         runtime.GOARCH,
+			StartedAt:    m.startedAt,
+			CollectedAt:  now,
+			LogLevel:     psLog.Level().String(),
+			StreamHealth: m.StreamHealth(),
+		},
+		interfaces: &pbAgent.ReportInterfacesRequest{
+			Interfaces:  m.reportedInterfaces,
+			PcapVersion: m.pcapVersion,
+		},
+		captureStats:   m.captureStats(),
+		captureResults: &pbAgent.ReportCaptureResultsRequest{Results: m.captureResults()},
+	}
+}
+
+// diagnosticsWriter uploads the zip file written to it in chunks over a diagnostics stream
+type diagnosticsWriter struct {
+	stream   pbAgent.AgentService_UploadDiagnosticsClient
+	bundleID string
+	buf      []byte
+}
+
+func (w *diagnosticsWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= diagnosticsChunkBytes {
+		err := w.stream.Send(&pbAgent.DiagnosticsChunk{
+			BundleId: w.bundleID,
+			Data:     w.buf[:diagnosticsChunkBytes],
+		})
+		if err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[diagnosticsChunkBytes:]
+	}
+	return len(p), nil
+}
+
+// close sends the rest of the file with the error of the bundle, if any, as the last chunk, and closes the stream
+func (w *diagnosticsWriter) close(bundleErr error) error {
+	chunk := &pbAgent.DiagnosticsChunk{
+		BundleId: w.bundleID,
+		Data:     w.buf,
+	}
+	if bundleErr != nil {
+		chunk.Error = bundleErr.Error()
+	}
+	err := w.stream.Send(chunk)
+	if err != nil {
+		return err
+	}
+	_, err = w.stream.CloseAndRecv()
+	return err
+}
+
+// uploadDiagnostics uploads the diagnostics bundle requested by a `collect_diagnostics` command as a zip file.
+// Bundles that can't be written are still uploaded, with the error, so the server doesn't wait on them.
+// It returns why the bundle couldn't be written or uploaded, for the command's result.
+func (m *pcapManager) uploadDiagnostics(args *broadcast.CollectDiagnosticsArgs, snapshot *diagnosticsSnapshot) error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.uploadDiagnostics")
+
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		logger.Error("no mTLS client available, cannot upload diagnostics")
+		return errors.New("no mTLS client available, cannot upload diagnostics")
+	}
+
+	if args == nil || args.BundleID == "" {
+		logger.Error("invalid collect_diagnostics command, missing bundle id")
+		return errors.New("missing bundle id")
+	}
+	logger = logger.With(psLog.KeyBundleID, args.BundleID)
+
+	// the zip file is already compressed, so it isn't compressed again on the wire
+	stream, err := client.UploadDiagnostics(m.ctx)
+	if err != nil {
+		logger.Error("failed to open diagnostics stream", psLog.KeyError, err)
+		return fmt.Errorf("failed to open diagnostics stream: %w", err)
+	}
+	writer := &diagnosticsWriter{stream: stream, bundleID: args.BundleID}
+
+	bundleErr := writeDiagnostics(writer, snapshot)
+	if bundleErr != nil {
+		logger.Error("failed to write diagnostics bundle", psLog.KeyError, bundleErr)
+	}
+
+	err = writer.close(bundleErr)
+	if err != nil {
+		logger.Error("failed to upload diagnostics", psLog.KeyError, err)
+		return fmt.Errorf("failed to upload diagnostics: %w", err)
+	}
+	if bundleErr != nil {
+		return bundleErr
+	}
+	logger.Info("uploaded diagnostics")
+	return nil
+}
+
+// writeDiagnostics writes the diagnostics bundle to w as a zip file of:
+// the agent's build and state, its interfaces, the counters and state of its captures, the metadata of its certs,
+// its config files and its most recent logs. Files that can't be read are left out, with why in errors.txt.
+func writeDiagnostics(w io.Writer, snapshot *diagnosticsSnapshot) error {
+	zw := zip.NewWriter(w)
+	var fileErrs []string
+
+	err := writeZipJSON(zw, "agent.json", snapshot.agent)
+	if err != nil {
+		return err
+	}
+	for _, file := range []struct {
+		name    string
+		message proto.Message
+	}{
+		{"interfaces.json", snapshot.interfaces},
+		{"capture_stats.json", snapshot.captureStats},
+		{"capture_results.json", snapshot.captureResults},
+	} {
+		content, err := protojson.MarshalOptions{Multiline: true}.Marshal(file.message)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", file.name, err)
+		}
+		err = writeZipFile(zw, file.name, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	// never the private key, only the metadata of the certs
+	certs := []diagnosticsCert{
+		certMetadata(config.GetClientCertFilePath()),
+		certMetadata(config.GetCACertFilePath()),
+	}
+	err = writeZipJSON(zw, "certs.json", certs)
+	if err != nil {
+		return err
+	}
+
+	// not the bootstrap file, since it holds the install key
+	for _, path := range []string{config.GetConfigFilePath(), config.GetBPFConfigFilePath()} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				fileErrs = append(fileErrs, fmt.Sprintf("%s: %s", path, err))
+			}
+			continue
+		}
+		err = writeZipFile(zw, "config/"+filepath.Base(path), content)
+		if err != nil {
+			return err
+		}
+	}
+
+	logErrs, err := writeDiagnosticsLogs(zw, config.GetLogFilePath(), config.GetDiagnosticsLogMaxBytes())
+	if err != nil {
+		return err
+	}
+	fileErrs = append(fileErrs, logErrs...)
+
+	if len(fileErrs) > 0 {
+		err = writeZipFile(zw, "errors.txt", []byte(strings.Join(fileErrs, "\n")+"\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeDiagnosticsLogs writes the log file and its most recent rotated backups to the zip file, newest first, up to maxBytes.
+// The log file itself is cut to its last maxBytes, and a backup is left out with all the older ones once it doesn't fit.
+// It returns the errors of the log files that couldn't be read, and the error of the zip file.
+func writeDiagnosticsLogs(zw *zip.Writer, logFilePath string, maxBytes int64) ([]string, error) {
+	var fileErrs []string
+
+	remaining := maxBytes
+	file, err := os.Open(logFilePath)
+	if err != nil {
+		fileErrs = append(fileErrs, fmt.Sprintf("%s: %s", logFilePath, err))
+	} else {
+		defer file.Close()
+		written, err := writeZipTail(zw, "logs/"+filepath.Base(logFilePath), file, remaining)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= written
+	}
+
+	// lumberjack names the backups <name>-<timestamp><ext>, gzipped once compressed
+	ext := filepath.Ext(logFilePath)
+	prefix := strings.TrimSuffix(filepath.Base(logFilePath), ext) + "-"
+	entries, err := os.ReadDir(filepath.Dir(logFilePath))
+	if err != nil {
+		return append(fileErrs, fmt.Sprintf("%s: %s", filepath.Dir(logFilePath), err)), nil
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, prefix) && (strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+".gz")) {
+			backups = append(backups, name)
+		}
+	}
+	// the timestamps sort in the order of the backups
+	slices.Sort(backups)
+	slices.Reverse(backups)
+	for _, name := range backups {
+		path := filepath.Join(filepath.Dir(logFilePath), name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fileErrs = append(fileErrs, fmt.Sprintf("%s: %s", path, err))
+			continue
+		}
+		if int64(len(content)) > remaining {
+			break
+		}
+		err = writeZipFile(zw, "logs/"+name, content)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= int64(len(content))
+	}
+	return fileErrs, nil
+}
+
+// writeZipTail writes the last maxBytes of the file to the zip file, returning how many bytes it wrote
+func writeZipTail(zw *zip.Writer, name string, file *os.File, maxBytes int64) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() > maxBytes {
+		_, err = file.Seek(info.Size()-maxBytes, io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+	}
+	fw, err := zw.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	return io.Copy(fw, io.LimitReader(file, maxBytes))
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return writeZipFile(zw, name, content)
+}
+
+func writeZipFile(zw *zip.Writer, name string, content []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(content)
+	return err
+}
+
+// certMetadata returns the metadata of the PEM certificate at the path, with why it couldn't be read if it can't
+func certMetadata(path string) diagnosticsCert {
+	metadata := diagnosticsCert{Path: path}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		metadata.Error = err.Error()
+		return metadata
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		metadata.Error = "no PEM block found"
+		return metadata
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		metadata.Error = err.Error()
+		return metadata
+	}
+	fingerprint := sha256.Sum256(cert.Raw)
+	metadata.Subject = cert.Subject.String()
+	metadata.Issuer = cert.Issuer.String()
+	metadata.SerialNumber = cert.SerialNumber.String()
+	metadata.NotBefore = cert.NotBefore
+	metadata.NotAfter = cert.NotAfter
+	metadata.Fingerprint = fmt.Sprintf("%X", fingerprint[:])
+	return metadata
+}
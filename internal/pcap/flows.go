@@ -0,0 +1,333 @@
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+const (
+	flowEndReasonActiveTimeout = "active_timeout"
+	flowEndReasonIdleTimeout   = "idle_timeout"
+	flowEndReasonTCPClose      = "tcp_close"
+	// flowEndReasonCaptureStop ends the flows of a capture that was stopped, or of all captures when the agent stops
+	flowEndReasonCaptureStop = "capture_stop"
+)
+
+// flowRecordsPendingFile is the file in the spool directory that keeps the flow records not sent before the agent stopped
+const flowRecordsPendingFile = "flows.pending"
+
+// flowRecordMaxBytes bounds the length prefix of a saved flow record, a flow record being a few hundred bytes at most
+const flowRecordMaxBytes = 64 * 1024
+
+// TCP flag bits as they appear in the TCP header, used to build the union of flags seen over a flow
+const (
+	tcpFlagFIN uint32 = 1 << iota
+	tcpFlagSYN
+	tcpFlagRST
+	tcpFlagPSH
+	tcpFlagACK
+	tcpFlagURG
+	tcpFlagECE
+	tcpFlagCWR
+)
+
+// flowKey identifies a unidirectional flow by its 5-tuple plus the interface and BPF of the capture that saw it
+type flowKey struct {
+	deviceName string
+	bpf        string
+	srcIP      string
+	dstIP      string
+	srcPort    uint16
+	dstPort    uint16
+	protocol   uint8
+}
+
+// flowEntry holds the aggregated counters of a flow while it is live in the flow table
+type flowEntry struct {
+	ipVersion string
+	packets   uint64
+	bytes     uint64
+	tcpFlags  uint32
+	firstSeen time.Time
+	lastSeen  time.Time
+	closed    bool
+}
+
+// flowTable aggregates packets into flows, emitting a record for each flow on active/idle timeouts or TCP close.
+// It is not safe for concurrent use; the pcap manager only touches it from its StartAll goroutine.
+type flowTable struct {
+	activeTimeout time.Duration
+	idleTimeout   time.Duration
+	maxFlows      int
+	flows         map[flowKey]*flowEntry
+	dropped       uint64
+}
+
+func newFlowTable(activeTimeout, idleTimeout time.Duration, maxFlows int) *flowTable {
+	return &flowTable{
+		activeTimeout: activeTimeout,
+		idleTimeout:   idleTimeout,
+		maxFlows:      maxFlows,
+		flows:         make(map[flowKey]*flowEntry),
+	}
+}
+
+// add accounts the packet to its flow, creating the flow if needed.
+// It returns false when the packet has no IP layer to key a flow by, so the caller can fall back to a packet event.
+func (ft *flowTable) add(wrappedPkt WrappedPacket) bool {
+	pkt := wrappedPkt.PacketEventData
+	key := flowKey{
+		deviceName: wrappedPkt.DeviceName,
+		bpf:        wrappedPkt.Bpf,
+	}
+	var ipVersion string
+
+	// tunneled packets are keyed by the innermost packet, so overlay traffic is attributed to its endpoints
+	switch ip := innermostLayer(pkt, layers.LayerTypeIPv4, layers.LayerTypeIPv6).(type) {
+	case *layers.IPv4:
+		ipVersion = "IPv4"
+		key.srcIP = ip.SrcIP.String()
+		key.dstIP = ip.DstIP.String()
+		key.protocol = uint8(ip.Protocol)
+	case *layers.IPv6:
+		ipVersion = "IPv6"
+		key.srcIP = ip.SrcIP.String()
+		key.dstIP = ip.DstIP.String()
+		key.protocol = uint8(ip.NextHeader)
+	default:
+		return false
+	}
+
+	var tcpFlags uint32
+	var closed bool
+	if tcpLayer := innermostTransportLayer(pkt, layers.LayerTypeTCP); tcpLayer != nil {
+		tcp := tcpLayer.(*layers.TCP)
+		key.srcPort = uint16(tcp.SrcPort)
+		key.dstPort = uint16(tcp.DstPort)
+		tcpFlags = tcpFlagsToBits(tcp)
+		closed = tcp.FIN || tcp.RST
+	} else if udpLayer := innermostTransportLayer(pkt, layers.LayerTypeUDP); udpLayer != nil {
+		udp := udpLayer.(*layers.UDP)
+		key.srcPort = uint16(udp.SrcPort)
+		key.dstPort = uint16(udp.DstPort)
+	}
+
+	metadata := pkt.Metadata()
+	seen := metadata.Timestamp
+	if seen.IsZero() {
+		seen = time.Now()
+	}
+
+	entry, exists := ft.flows[key]
+	if !exists {
+		if len(ft.flows) >= ft.maxFlows {
+			ft.dropped++
+			return true
+		}
+		entry = &flowEntry{
+			ipVersion: ipVersion,
+			firstSeen: seen,
+		}
+		ft.flows[key] = entry
+	}
+
+	entry.packets++
+	entry.bytes += uint64(metadata.Length)
+	entry.tcpFlags |= tcpFlags
+	entry.lastSeen = seen
+	entry.closed = entry.closed || closed
+	return true
+}
+
+// expire removes and returns records for all flows that were closed or hit their active or idle timeout as of now
+func (ft *flowTable) expire(now time.Time) []*pbAgent.FlowRecord {
+	records := make([]*pbAgent.FlowRecord, 0)
+	for key, entry := range ft.flows {
+		var endReason string
+		switch {
+		case entry.closed:
+			endReason = flowEndReasonTCPClose
+		case now.Sub(entry.lastSeen) >= ft.idleTimeout:
+			endReason = flowEndReasonIdleTimeout
+		case now.Sub(entry.firstSeen) >= ft.activeTimeout:
+			endReason = flowEndReasonActiveTimeout
+		default:
+			continue
+		}
+		records = append(records, newFlowRecord(key, entry, endReason))
+		delete(ft.flows, key)
+	}
+	return records
+}
+
+// flushCapture removes and returns records for all flows of the capture with the given interface and BPF
+func (ft *flowTable) flushCapture(deviceName, bpf string) []*pbAgent.FlowRecord {
+	records := make([]*pbAgent.FlowRecord, 0)
+	for key, entry := range ft.flows {
+		if key.deviceName != deviceName || key.bpf != bpf {
+			continue
+		}
+		records = append(records, newFlowRecord(key, entry, flowEndReasonCaptureStop))
+		delete(ft.flows, key)
+	}
+	return records
+}
+
+// flushAll removes and returns records for all flows
+func (ft *flowTable) flushAll() []*pbAgent.FlowRecord {
+	records := make([]*pbAgent.FlowRecord, 0, len(ft.flows))
+	for key, entry := range ft.flows {
+		records = append(records, newFlowRecord(key, entry, flowEndReasonCaptureStop))
+		delete(ft.flows, key)
+	}
+	return records
+}
+
+// takeDropped returns the number of flows not tracked because the table was full since the last call, and resets it
+func (ft *flowTable) takeDropped() uint64 {
+	dropped := ft.dropped
+	ft.dropped = 0
+	return dropped
+}
+
+func newFlowRecord(key flowKey, entry *flowEntry, endReason string) *pbAgent.FlowRecord {
+	return &pbAgent.FlowRecord{
+		Bpf:        key.bpf,
+		DeviceName: key.deviceName,
+		IpVersion:  entry.ipVersion,
+		SrcIp:      key.srcIP,
+		DstIp:      key.dstIP,
+		SrcPort:    uint32(key.srcPort),
+		DstPort:    uint32(key.dstPort),
+		Protocol:   uint32(key.protocol),
+		Packets:    entry.packets,
+		Bytes:      entry.bytes,
+		TcpFlags:   entry.tcpFlags,
+		FirstSeen:  timestamppb.New(entry.firstSeen),
+		LastSeen:   timestamppb.New(entry.lastSeen),
+		EndReason:  endReason,
+	}
+}
+
+func tcpFlagsToBits(tcp *layers.TCP) uint32 {
+	var bits uint32
+	if tcp.FIN {
+		bits |= tcpFlagFIN
+	}
+	if tcp.SYN {
+		bits |= tcpFlagSYN
+	}
+	if tcp.RST {
+		bits |= tcpFlagRST
+	}
+	if tcp.PSH {
+		bits |= tcpFlagPSH
+	}
+	if tcp.ACK {
+		bits |= tcpFlagACK
+	}
+	if tcp.URG {
+		bits |= tcpFlagURG
+	}
+	if tcp.ECE {
+		bits |= tcpFlagECE
+	}
+	if tcp.CWR {
+		bits |= tcpFlagCWR
+	}
+	return bits
+}
+
+// saveFlowRecords writes the flow records to the file as length-prefixed records like the spool's,
+// replacing the file in one rename so a crash never leaves a partial one
+func saveFlowRecords(path string, flowRecords []*pbAgent.FlowRecord) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(f)
+	for _, flowRecord := range flowRecords {
+		content, err := proto.Marshal(flowRecord)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		header := make([]byte, spoolRecordHeaderLen)
+		binary.BigEndian.PutUint32(header, uint32(len(content)))
+		_, err = writer.Write(append(header, content...))
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	err = writer.Flush()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// loadFlowRecords reads and deletes the flow records saved by a previous run.
+// A missing file has no records, and the records after a corrupt one are skipped.
+func loadFlowRecords(path string) ([]*pbAgent.FlowRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer os.Remove(path)
+	defer f.Close()
+
+	flowRecords := make([]*pbAgent.FlowRecord, 0)
+	reader := bufio.NewReader(f)
+	header := make([]byte, spoolRecordHeaderLen)
+	for {
+		_, err = io.ReadFull(reader, header)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return flowRecords, nil
+			}
+			return flowRecords, err
+		}
+		recordLen := binary.BigEndian.Uint32(header)
+		if recordLen > flowRecordMaxBytes {
+			return flowRecords, fmt.Errorf("corrupt flow record length %d", recordLen)
+		}
+		content := make([]byte, recordLen)
+		_, err = io.ReadFull(reader, content)
+		if err != nil {
+			return flowRecords, err
+		}
+		flowRecord := &pbAgent.FlowRecord{}
+		err = proto.Unmarshal(content, flowRecord)
+		if err != nil {
+			return flowRecords, err
+		}
+		flowRecords = append(flowRecords, flowRecord)
+	}
+}
@@ -0,0 +1,99 @@
+package pcap
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"time"
+
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	psOS "github.com/danielhoward314/packet-sentry/internal/os"
+	"github.com/danielhoward314/packet-sentry/internal/version"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// memoryMetric is the runtime metric of all the memory the Go runtime has mapped from the OS
+const memoryMetric = "/memory/classes/total:bytes"
+
+// heartbeat returns the agent's build, uptime, OS, resource use since the previous heartbeat and the number of captures in each state.
+// It is called from the StartAll goroutine, which owns the CPU time sample and the capture state.
+func (m *pcapManager) heartbeat(now time.Time) *pbAgent.HeartbeatRequest {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.heartbeat")
+
+	heartbeat := &pbAgent.HeartbeatRequest{
+		AgentVersion:  version.Version,
+		CommitHash:    version.CommitHash,
+		UptimeSeconds: uint64(now.Sub(m.startedAt).Seconds()),
+		Os:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+	}
+
+	// the OS is only looked up until it is known, since it doesn't change without a restart
+	if m.osDetails == nil {
+		osDetails, err := m.systemInfo.GetOSDetails()
+		if err != nil {
+			logger.Error("failed to get OS details", psLog.KeyError, err)
+		} else {
+			m.osDetails = osDetails
+		}
+	}
+	if m.osDetails != nil {
+		heartbeat.OsVersion = m.osDetails.OSVersion
+		heartbeat.KernelVersion = m.osDetails.KernelVersion
+	}
+
+	cpuTime, err := psOS.ProcessCPUTime()
+	if err != nil {
+		logger.Error("failed to get process CPU time", psLog.KeyError, err)
+	} else {
+		// the first heartbeat covers the whole uptime
+		elapsed := now.Sub(m.startedAt)
+		used := cpuTime
+		if !m.cpuSampledAt.IsZero() {
+			elapsed = now.Sub(m.cpuSampledAt)
+			used = cpuTime - m.cpuTime
+		}
+		if elapsed > 0 {
+			heartbeat.CpuPercent = 100 * used.Seconds() / elapsed.Seconds()
+		}
+		m.cpuTime = cpuTime
+		m.cpuSampledAt = now
+	}
+
+	sample := []metrics.Sample{{Name: memoryMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() == metrics.KindUint64 {
+		heartbeat.MemoryBytes = sample[0].Value.Uint64()
+	}
+
+	for _, result := range m.captureResults() {
+		switch result.State {
+		case captureStateRunning:
+			heartbeat.CapturesRunning++
+		case captureStateFailed:
+			heartbeat.CapturesFailed++
+		case captureStateStopped:
+			heartbeat.CapturesStopped++
+		}
+	}
+	return heartbeat
+}
+
+// sendHeartbeat tells the server the agent is alive, with its health, and the heartbeat listener that the server accepted it
+func (m *pcapManager) sendHeartbeat() error {
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		return fmt.Errorf("no agent gRPC client available, cannot send heartbeat")
+	}
+
+	_, err := client.Heartbeat(m.ctx, m.heartbeat(time.Now()))
+	if err != nil {
+		return err
+	}
+	if m.heartbeatListener != nil {
+		m.heartbeatListener.HeartbeatAccepted()
+	}
+	return nil
+}
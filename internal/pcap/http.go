@@ -0,0 +1,393 @@
+package pcap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/reassembly"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+const (
+	// httpMaxHeaderBytes is the max size of the headers of a message, larger ones are skipped
+	httpMaxHeaderBytes = 16 * 1024
+	// httpMaxPendingRequests is the max number of request methods kept per connection to match responses to
+	httpMaxPendingRequests = 16
+)
+
+var (
+	httpHeaderEnd       = []byte("\r\n\r\n")
+	httpResponsePrefix  = []byte("HTTP/1.")
+	httpRequestPrefixes = [][]byte{
+		[]byte("GET "), []byte("POST "), []byte("PUT "), []byte("DELETE "), []byte("HEAD "),
+		[]byte("OPTIONS "), []byte("PATCH "), []byte("CONNECT "), []byte("TRACE "),
+	}
+)
+
+// httpDecoderKey identifies the capture an http decoder reassembles the TCP connections of.
+// Each capture gets its own decoder, since two captures can see the same packets.
+type httpDecoderKey struct {
+	deviceName string
+	bpf        string
+}
+
+// httpDecoder reassembles the TCP connections of one http mode capture and parses the HTTP/1.x message headers in them.
+// It is not safe for concurrent use; the pcap manager only touches it from its StartAll goroutine.
+type httpDecoder struct {
+	assembler *reassembly.Assembler
+	factory   *httpStreamFactory
+	lastSeen  time.Time
+}
+
+func newHTTPDecoder(byteBudget, maxStreams, maxBufferedPagesPerStream int) *httpDecoder {
+	factory := &httpStreamFactory{
+		byteBudget: byteBudget,
+		maxStreams: maxStreams,
+	}
+	assembler := reassembly.NewAssembler(reassembly.NewStreamPool(factory))
+	assembler.MaxBufferedPagesPerConnection = maxBufferedPagesPerStream
+	assembler.MaxBufferedPagesTotal = maxBufferedPagesPerStream * maxStreams
+	return &httpDecoder{
+		assembler: assembler,
+		factory:   factory,
+	}
+}
+
+// httpMessage is a parsed HTTP message with the endpoints of the direction it was sent in
+// and the capture time of the data that completed its headers
+type httpMessage struct {
+	message    *pbAgent.HTTPMessage
+	netFlow    gopacket.Flow
+	tcpFlow    gopacket.Flow
+	capturedAt time.Time
+}
+
+// packetEvent returns a packet event of the capture without packet data, carrying the message and its endpoints,
+// for messages that aren't completed by a packet of the capture
+func (m httpMessage) packetEvent(key httpDecoderKey) *pbAgent.PacketEvent {
+	ipVersion := "IPv4"
+	if m.netFlow.EndpointType() == layers.EndpointIPv6 {
+		ipVersion = "IPv6"
+	}
+	var srcPort, dstPort uint32
+	if src, dst := m.tcpFlow.Endpoints(); len(src.Raw()) == 2 && len(dst.Raw()) == 2 {
+		srcPort = uint32(binary.BigEndian.Uint16(src.Raw()))
+		dstPort = uint32(binary.BigEndian.Uint16(dst.Raw()))
+	}
+	capturedAt := m.capturedAt
+	if capturedAt.IsZero() {
+		capturedAt = time.Now()
+	}
+	return &pbAgent.PacketEvent{
+		Bpf:        key.bpf,
+		DeviceName: key.deviceName,
+		Layers: &pbAgent.Layers{
+			IpLayer: &pbAgent.IPLayer{
+				Version:  ipVersion,
+				SrcIp:    m.netFlow.Src().String(),
+				DstIp:    m.netFlow.Dst().String(),
+				Protocol: uint32(layers.IPProtocolTCP),
+			},
+			TcpLayer: &pbAgent.TCPLayer{
+				SrcPort: srcPort,
+				DstPort: dstPort,
+			},
+			HttpLayer: &pbAgent.HTTPLayer{Messages: []*pbAgent.HTTPMessage{m.message}},
+		},
+		CaptureTime: timestamppb.New(capturedAt),
+	}
+}
+
+// httpAssemblerContext passes the capture info of the packet being assembled to the streams
+type httpAssemblerContext gopacket.CaptureInfo
+
+func (c *httpAssemblerContext) GetCaptureInfo() gopacket.CaptureInfo {
+	return gopacket.CaptureInfo(*c)
+}
+
+// decode assembles the TCP segment of the packet, returning the HTTP messages whose headers it completes
+func (d *httpDecoder) decode(packet gopacket.Packet) []*pbAgent.HTTPMessage {
+	tcp, ok := innermostTransportLayer(packet, layers.LayerTypeTCP).(*layers.TCP)
+	if !ok {
+		return nil
+	}
+	networkLayer, ok := innermostLayer(packet, layers.LayerTypeIPv4, layers.LayerTypeIPv6).(gopacket.NetworkLayer)
+	if !ok {
+		return nil
+	}
+
+	captureInfo := httpAssemblerContext(packet.Metadata().CaptureInfo)
+	d.lastSeen = captureInfo.Timestamp
+	d.assembler.AssembleWithContext(networkLayer.NetworkFlow(), tcp, &captureInfo)
+	httpMessages := d.factory.takeMessages()
+	if len(httpMessages) == 0 {
+		return nil
+	}
+	messages := make([]*pbAgent.HTTPMessage, 0, len(httpMessages))
+	for _, m := range httpMessages {
+		messages = append(messages, m.message)
+	}
+	return messages
+}
+
+// flush skips the missing bytes that out-of-order data has waited on since before flushOlderThan,
+// and stops reassembling connections without packets since before closeOlderThan.
+// It returns the messages completed by the flush, which don't belong to a packet.
+func (d *httpDecoder) flush(flushOlderThan, closeOlderThan time.Time) []httpMessage {
+	d.assembler.FlushWithOptions(reassembly.FlushOptions{T: flushOlderThan, TC: closeOlderThan})
+	return d.factory.takeMessages()
+}
+
+// idle reports whether the decoder has no connections and saw no packets since before the given time
+func (d *httpDecoder) idle(since time.Time) bool {
+	return d.factory.streams == 0 && d.lastSeen.Before(since)
+}
+
+// takeDropped returns the number of connections not reassembled because of the max streams since the last call, and resets it
+func (d *httpDecoder) takeDropped() uint64 {
+	dropped := d.factory.dropped
+	d.factory.dropped = 0
+	return dropped
+}
+
+// httpStreamFactory creates the streams of the TCP connections of an http decoder, up to its max streams
+type httpStreamFactory struct {
+	byteBudget int
+	maxStreams int
+	streams    int
+	dropped    uint64
+	messages   []httpMessage
+}
+
+func (f *httpStreamFactory) New(netFlow, tcpFlow gopacket.Flow, tcp *layers.TCP, ac reassembly.AssemblerContext) reassembly.Stream {
+	if f.streams >= f.maxStreams {
+		f.dropped++
+		return &httpStream{factory: f, done: true}
+	}
+	f.streams++
+	return &httpStream{
+		factory: f,
+		counted: true,
+		budget:  f.byteBudget,
+		// the flows are of the connection's first packet, which is the client to server direction
+		halves: [2]httpHalfStream{
+			{netFlow: netFlow, tcpFlow: tcpFlow},
+			{netFlow: netFlow.Reverse(), tcpFlow: tcpFlow.Reverse()},
+		},
+	}
+}
+
+func (f *httpStreamFactory) takeMessages() []httpMessage {
+	messages := f.messages
+	f.messages = nil
+	return messages
+}
+
+// httpStream parses the HTTP/1.x message headers of both directions of a TCP connection.
+// Only the first budget bytes of the connection are inspected, bodies are skipped by their content length.
+type httpStream struct {
+	factory *httpStreamFactory
+	counted bool
+	done    bool
+	budget  int
+	halves  [2]httpHalfStream
+	// requestMethods are the methods of the requests not answered yet, in order,
+	// since the response to a HEAD request has a content length but no body
+	requestMethods []string
+}
+
+// httpHalfStream is the parse state of one direction of a TCP connection
+type httpHalfStream struct {
+	// netFlow and tcpFlow are the endpoints of the direction
+	netFlow gopacket.Flow
+	tcpFlow gopacket.Flow
+	// synced is set while the parser is at a known position in the messages of the direction
+	synced        bool
+	header        []byte
+	bodyRemaining int64
+}
+
+func (s *httpStream) Accept(tcp *layers.TCP, ci gopacket.CaptureInfo, dir reassembly.TCPFlowDirection, nextSeq reassembly.Sequence, start *bool, ac reassembly.AssemblerContext) bool {
+	if s.done {
+		return false
+	}
+	// start reassembling connections opened before the capture started, e.g. keep-alive connections,
+	// the parser resyncs on the first segment that starts a message
+	*start = true
+	return true
+}
+
+func (s *httpStream) ReassembledSG(sg reassembly.ScatterGather, ac reassembly.AssemblerContext) {
+	if s.done {
+		return
+	}
+	dir, _, _, skip := sg.Info()
+	half := &s.halves[0]
+	if dir == reassembly.TCPDirServerToClient {
+		half = &s.halves[1]
+	}
+
+	// A skip is bytes the capture never saw, e.g. a packet cut off by the capture's snap length or a lost packet,
+	// -1 being an unknown number of bytes at the start of a connection opened before the capture.
+	if skip != 0 {
+		if skip > 0 && half.bodyRemaining >= int64(skip) {
+			half.bodyRemaining -= int64(skip)
+		} else {
+			half.lostSync()
+		}
+	}
+
+	length, _ := sg.Lengths()
+	if length > s.budget {
+		length = s.budget
+	}
+	data := sg.Fetch(length)
+	s.budget -= length
+	if s.budget == 0 {
+		s.done = true
+	}
+
+	s.parse(half, data, sg.CaptureInfo(0).Timestamp)
+}
+
+func (s *httpStream) ReassemblyComplete(ac reassembly.AssemblerContext) bool {
+	if s.counted {
+		s.factory.streams--
+		s.counted = false
+	}
+	return true
+}
+
+// parse parses the messages of data, which continues the reassembled bytes of the given direction
+// and was captured at capturedAt
+func (s *httpStream) parse(half *httpHalfStream, data []byte, capturedAt time.Time) {
+	for len(data) > 0 {
+		if half.bodyRemaining > 0 {
+			n := min(int64(len(data)), half.bodyRemaining)
+			half.bodyRemaining -= n
+			data = data[n:]
+			continue
+		}
+
+		if !half.synced {
+			// after lost bytes, only a segment that starts with a message can be parsed
+			if !startsHTTPMessage(data) {
+				return
+			}
+			half.synced = true
+		}
+
+		half.header = append(half.header, data...)
+		end := bytes.Index(half.header, httpHeaderEnd)
+		if end < 0 {
+			if len(half.header) > httpMaxHeaderBytes {
+				half.lostSync()
+			}
+			return
+		}
+		header := half.header[:end+len(httpHeaderEnd)]
+		data = half.header[end+len(httpHeaderEnd):]
+		half.header = nil
+
+		message, bodyLength, err := s.parseHeader(header)
+		if err != nil {
+			half.lostSync()
+			return
+		}
+		s.factory.messages = append(s.factory.messages, httpMessage{
+			message:    message,
+			netFlow:    half.netFlow,
+			tcpFlow:    half.tcpFlow,
+			capturedAt: capturedAt,
+		})
+		if bodyLength < 0 {
+			// the body ends with the connection or is chunked, either way the next message can't be found by length
+			half.lostSync()
+			return
+		}
+		half.bodyRemaining = bodyLength
+	}
+}
+
+// parseHeader parses the headers of a request or response, returning the message and the length of its body, -1 if unknown
+func (s *httpStream) parseHeader(header []byte) (*pbAgent.HTTPMessage, int64, error) {
+	reader := bufio.NewReader(bytes.NewReader(header))
+
+	if bytes.HasPrefix(header, httpResponsePrefix) {
+		request := &http.Request{Method: http.MethodGet}
+		if len(s.requestMethods) > 0 {
+			request.Method = s.requestMethods[0]
+			s.requestMethods = s.requestMethods[1:]
+		}
+		response, err := http.ReadResponse(reader, request)
+		if err != nil {
+			return nil, 0, err
+		}
+		if response.StatusCode < 200 && len(s.requestMethods) < httpMaxPendingRequests {
+			// an informational response, e.g. 100 Continue, is followed by the final response to the same request
+			s.requestMethods = append([]string{request.Method}, s.requestMethods...)
+		}
+		message := &pbAgent.HTTPMessage{
+			Response:      true,
+			Version:       response.Proto,
+			StatusCode:    int32(response.StatusCode),
+			ContentLength: response.ContentLength,
+		}
+		return message, bodyLength(response.ContentLength, response.TransferEncoding, request.Method == http.MethodHead || response.StatusCode < 200), nil
+	}
+
+	request, err := http.ReadRequest(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(s.requestMethods) < httpMaxPendingRequests {
+		s.requestMethods = append(s.requestMethods, request.Method)
+	}
+	message := &pbAgent.HTTPMessage{
+		Version:       request.Proto,
+		Method:        request.Method,
+		Host:          request.Host,
+		Uri:           request.RequestURI,
+		UserAgent:     request.UserAgent(),
+		ContentLength: request.ContentLength,
+	}
+	return message, bodyLength(request.ContentLength, request.TransferEncoding, false), nil
+}
+
+// bodyLength returns the number of body bytes that follow the headers, -1 if unknown
+func bodyLength(contentLength int64, transferEncoding []string, noBody bool) int64 {
+	if noBody {
+		return 0
+	}
+	if len(transferEncoding) > 0 && !strings.EqualFold(transferEncoding[0], "identity") {
+		return -1
+	}
+	return contentLength
+}
+
+// lostSync drops the partial message of the direction, the parser resyncs on the next segment that starts a message
+func (h *httpHalfStream) lostSync() {
+	h.synced = false
+	h.header = nil
+	h.bodyRemaining = 0
+}
+
+func startsHTTPMessage(data []byte) bool {
+	if bytes.HasPrefix(data, httpResponsePrefix) {
+		return true
+	}
+	for _, prefix := range httpRequestPrefixes {
+		if bytes.HasPrefix(data, prefix) {
+			return true
+		}
+	}
+	return false
+}
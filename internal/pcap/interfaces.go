@@ -0,0 +1,226 @@
+package pcap
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+
+	"github.com/google/gopacket/pcap"
+	"google.golang.org/protobuf/proto"
+
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// libpcap's PCAP_IF_* interface flags, which gopacket doesn't define
+const (
+	pcapIfLoopback                     = 0x00000001
+	pcapIfUp                           = 0x00000002
+	pcapIfRunning                      = 0x00000004
+	pcapIfWireless                     = 0x00000008
+	pcapIfConnectionStatus             = 0x00000030
+	pcapIfConnectionStatusConnected    = 0x00000010
+	pcapIfConnectionStatusDisconnected = 0x00000020
+)
+
+// findInterfaces lists the interfaces, remembering them as the ones present
+func (m *pcapManager) findInterfaces() ([]pcap.Interface, error) {
+	interfaces, err := pcap.FindAllDevs()
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]*pcap.Interface, len(interfaces))
+	m.mu.Lock()
+	for i := range interfaces {
+		// keep the associations of interfaces that already have live captures, e.g. restored from the cache
+		if m.ifaceNameToFiltersAssociations[interfaces[i].Name] == nil {
+			m.ifaceNameToFiltersAssociations[interfaces[i].Name] = make(map[uint64]*packetCapture)
+		}
+		present[interfaces[i].Name] = &interfaces[i]
+	}
+	m.mu.Unlock()
+	m.interfaces = present
+	return interfaces, nil
+}
+
+// reportInterfaces sends the server the interfaces with their addresses and flags
+func (m *pcapManager) reportInterfaces(interfaces []pcap.Interface) error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.reportInterfaces")
+
+	m.pcapVersion = pcap.Version()
+	logger.Info("collected pcap version", slog.String(psLog.KeyPCapVersion, m.pcapVersion))
+
+	reportRequest := &pbAgent.ReportInterfacesRequest{
+		Interfaces:  interfaceDetails(interfaces),
+		PcapVersion: m.pcapVersion,
+	}
+
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+
+	if client == nil {
+		logger.Warn("no agent gRPC client available")
+		return fmt.Errorf("no agent gRPC client available")
+	}
+
+	_, err := client.ReportInterfaces(m.ctx, reportRequest)
+	if err != nil {
+		return err
+	}
+	m.reportedInterfaces = reportRequest.Interfaces
+	return nil
+}
+
+// scanInterfaces lists the interfaces, reports them to the server if any were added, removed or changed since the last report,
+// and restarts the captures of interfaces that came back
+func (m *pcapManager) scanInterfaces() error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.scanInterfaces")
+
+	previous := m.interfaces
+	interfaces, err := m.findInterfaces()
+	if err != nil {
+		return fmt.Errorf("failed to find all devices with pcap: %w", err)
+	}
+
+	// before the first listing, e.g. while captures restored from the cache run, no interface can have come back
+	cameBack := make(map[string]bool)
+	for ifaceName := range m.interfaces {
+		if len(previous) > 0 && previous[ifaceName] == nil {
+			logger.Info("interface added", slog.String(psLog.KeyDeviceName, ifaceName))
+			cameBack[ifaceName] = true
+		}
+	}
+	for ifaceName := range previous {
+		if m.interfaces[ifaceName] == nil {
+			logger.Warn("interface removed, its packet captures are stopped until it comes back", slog.String(psLog.KeyDeviceName, ifaceName))
+		}
+	}
+
+	m.restartCaptures(cameBack)
+
+	if interfacesEqual(m.reportedInterfaces, interfaceDetails(interfaces)) {
+		return nil
+	}
+	logger.Info("interfaces changed, reporting them to the server")
+	return m.reportInterfaces(interfaces)
+}
+
+// restartCaptures restarts the live captures that stopped on their own on interfaces that are present,
+// along with all the captures of the interfaces that came back, whose handles went away with the interface,
+// and retries the captures that failed to start on the interfaces that came back
+func (m *pcapManager) restartCaptures(cameBack map[string]bool) {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.restartCaptures")
+
+	bpfConfig := &pbAgent.BPFConfig{
+		Create: make(map[string]*pbAgent.InterfaceCaptureMap),
+		Update: make(map[string]*pbAgent.InterfaceCaptureMap),
+	}
+	addCapture := func(captureMaps map[string]*pbAgent.InterfaceCaptureMap, ifaceName string, filterHash uint64, captureConfig *pbAgent.CaptureConfig) {
+		if captureMaps[ifaceName] == nil {
+			captureMaps[ifaceName] = &pbAgent.InterfaceCaptureMap{Captures: make(map[uint64]*pbAgent.CaptureConfig)}
+		}
+		captureMaps[ifaceName].Captures[filterHash] = captureConfig
+	}
+
+	m.mu.Lock()
+	for ifaceName, captures := range m.ifaceNameToFiltersAssociations {
+		if m.interfaces[ifaceName] == nil {
+			continue
+		}
+		for filterHash, capture := range captures {
+			if cameBack[ifaceName] || capture.ended.Load() {
+				addCapture(bpfConfig.Update, ifaceName, filterHash, captureConfigToPB(capture.config))
+			}
+		}
+	}
+	m.mu.Unlock()
+	for key, failure := range m.captureFailures {
+		if cameBack[key.deviceName] {
+			addCapture(bpfConfig.Create, key.deviceName, failure.filterHash, failure.captureConfig)
+		}
+	}
+	if len(bpfConfig.Create) == 0 && len(bpfConfig.Update) == 0 {
+		return
+	}
+
+	logger.Info("restarting packet captures of interfaces that came back or stopped delivering packets")
+	err := m.enforceConfig(bpfConfig)
+	if err != nil {
+		logger.Error("failed to restart packet captures", psLog.KeyError, err)
+	}
+	err = m.reportCaptureResults()
+	if err != nil {
+		logger.Error("failed to report capture results", psLog.KeyError, err)
+	}
+	err = m.saveCachedConfig()
+	if err != nil {
+		logger.Error("failed to cache BPF config", psLog.KeyError, err)
+	}
+}
+
+// interfaceDetails converts the interfaces for the server, sorted by name
+func interfaceDetails(interfaces []pcap.Interface) []*pbAgent.InterfaceDetails {
+	details := make([]*pbAgent.InterfaceDetails, 0, len(interfaces))
+	for _, iface := range interfaces {
+		ifaceDetails := &pbAgent.InterfaceDetails{
+			Name:        iface.Name,
+			Description: iface.Description,
+			Flags:       interfaceFlags(iface.Flags),
+		}
+		for _, address := range iface.Addresses {
+			if address.IP == nil {
+				continue
+			}
+			ifaceDetails.Addresses = append(ifaceDetails.Addresses, interfaceAddress(address))
+		}
+		details = append(details, ifaceDetails)
+	}
+	slices.SortFunc(details, func(a, b *pbAgent.InterfaceDetails) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return details
+}
+
+// interfaceAddress returns the address in CIDR notation, or the bare IP when the netmask is unknown
+func interfaceAddress(address pcap.InterfaceAddress) string {
+	if address.Netmask != nil {
+		ones, bits := address.Netmask.Size()
+		if bits > 0 {
+			return (&net.IPNet{IP: address.IP, Mask: net.CIDRMask(ones, bits)}).String()
+		}
+	}
+	return address.IP.String()
+}
+
+func interfaceFlags(flags uint32) []string {
+	var names []string
+	if flags&pcapIfLoopback != 0 {
+		names = append(names, "LOOPBACK")
+	}
+	if flags&pcapIfUp != 0 {
+		names = append(names, "UP")
+	}
+	if flags&pcapIfRunning != 0 {
+		names = append(names, "RUNNING")
+	}
+	if flags&pcapIfWireless != 0 {
+		names = append(names, "WIRELESS")
+	}
+	switch flags & pcapIfConnectionStatus {
+	case pcapIfConnectionStatusConnected:
+		names = append(names, "CONNECTED")
+	case pcapIfConnectionStatusDisconnected:
+		names = append(names, "DISCONNECTED")
+	}
+	return names
+}
+
+func interfacesEqual(a, b []*pbAgent.InterfaceDetails) bool {
+	return slices.EqualFunc(a, b, func(x, y *pbAgent.InterfaceDetails) bool {
+		return proto.Equal(x, y)
+	})
+}
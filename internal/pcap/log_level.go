@@ -0,0 +1,57 @@
+package pcap
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+)
+
+// setLogLevel changes the level of every logger of the agent as a `set_log_level` command asks, until the revert timer fires.
+// A later command replaces both the level and the revert timer of an earlier one.
+// It is called from the StartAll goroutine, which owns the revert timer.
+func (m *pcapManager) setLogLevel(args *broadcast.SetLogLevelArgs) error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.setLogLevel")
+
+	if args == nil || args.Level == "" {
+		return errors.New("missing log level")
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(args.Level))
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", args.Level, err)
+	}
+	revertAfter := args.RevertAfter
+	if revertAfter <= 0 {
+		revertAfter = config.GetLogLevelRevertAfter()
+	}
+
+	if m.logLevelRevert != nil {
+		m.logLevelRevert.Stop()
+	}
+	m.logLevelRevert = time.NewTimer(revertAfter)
+	m.logLevelRevertC = m.logLevelRevert.C
+
+	// logged before the change, so it isn't filtered out when the level is raised
+	logger.Info(
+		"changing log level",
+		slog.String(psLog.KeyLogLevel, level.String()),
+		slog.Time(psLog.KeyRevertAt, time.Now().Add(revertAfter)),
+	)
+	psLog.SetLevel(level)
+	return nil
+}
+
+// revertLogLevel restores the default log level once the level set by a `set_log_level` command is due to revert
+func (m *pcapManager) revertLogLevel() {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.revertLogLevel")
+
+	m.logLevelRevert = nil
+	m.logLevelRevertC = nil
+	psLog.SetLevel(psLog.DefaultLevel)
+	logger.Info("reverted log level", slog.String(psLog.KeyLogLevel, psLog.DefaultLevel.String()))
+}
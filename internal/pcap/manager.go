@@ -5,12 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	"github.com/danielhoward314/packet-sentry/internal/config"
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	psOS "github.com/danielhoward314/packet-sentry/internal/os"
 	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
 )
 
@@ -18,11 +26,17 @@ const (
 	logAttrValSvcName = "pcapManager"
 )
 
+// HeartbeatListener is told each time the server accepted a heartbeat of the agent
+type HeartbeatListener interface {
+	HeartbeatAccepted()
+}
+
 // PCapManager is the interface for managing packet capture for all interfaces and associated filters.
 type PCapManager interface {
 	StartAll()
 	StopAll()
 	StopOne(ifaceName string, filterHash uint64, filter string) error
+	StreamHealth() StreamHealth
 }
 
 type pcapManager struct {
@@ -30,19 +44,44 @@ type pcapManager struct {
 	agentMTLSClientBroadcaster     *broadcast.AgentMTLSClientBroadcaster
 	agentMTLSClientMu              sync.RWMutex
 	cancelFunc                     context.CancelFunc
+	cpuSampledAt                   time.Time
+	cpuTime                        time.Duration
+	captureFailures                map[captureKey]*captureFailure
 	commandsBroadcaster            *broadcast.CommandsBroadcaster
 	commandMu                      sync.RWMutex
 	currentStreamCancel            context.CancelFunc
 	ctx                            context.Context
+	droppedPackets                 atomic.Uint64
+	eventsDropped                  atomic.Uint64
+	flowStreamClient               pbAgent.AgentService_SendFlowRecordClient
+	flowTable                      *flowTable
+	heartbeatListener              HeartbeatListener
+	httpDecoders                   map[httpDecoderKey]*httpDecoder
 	ifaceNameToFiltersAssociations map[string]map[uint64]*packetCapture
 	interfaces                     map[string]*pcap.Interface
 	logger                         *slog.Logger
+	logLevelRevert                 *time.Timer
+	logLevelRevertC                <-chan time.Time
 	mu                             sync.Mutex
+	osDetails                      *psOS.OSDetails
+	packetBatch                    []*pbAgent.PacketEvent
+	packetBatchBytes               int
 	packetChan                     chan WrappedPacket
-	packetStreamClient             pbAgent.AgentService_SendPacketEventClient
+	packetStreamClient             pbAgent.AgentService_SendPacketEventBatchClient
+	pendingFlowRecords             []*pbAgent.FlowRecord
 	pcapVersion                    string
+	pendingReconcile               bool
+	reconnectAttempt               int
+	reportedInterfaces             []*pbAgent.InterfaceDetails
+	reconnectC                     <-chan time.Time
+	spool                          *spool
+	startedAt                      time.Time
 	stopOnce                       sync.Once
+	streamHealth                   StreamHealth
+	streamHealthMu                 sync.RWMutex
 	streamMu                       sync.Mutex
+	streamOpenedAt                 time.Time
+	systemInfo                     psOS.SystemInfo
 	wg                             sync.WaitGroup
 }
 
@@ -50,8 +89,10 @@ type pcapManager struct {
 func NewPCapManager(
 	ctx context.Context,
 	baseLogger *slog.Logger,
+	systemInfo psOS.SystemInfo,
 	commandsBroadcaster *broadcast.CommandsBroadcaster,
 	agentMTLSClientBroadcaster *broadcast.AgentMTLSClientBroadcaster,
+	heartbeatListener HeartbeatListener,
 ) PCapManager {
 	childCtx, cancelFunc := context.WithCancel(ctx)
 	childLogger := baseLogger.With(slog.String(psLog.KeyServiceName, logAttrValSvcName))
@@ -59,20 +100,45 @@ func NewPCapManager(
 	return &pcapManager{
 		agentMTLSClientBroadcaster:     agentMTLSClientBroadcaster,
 		cancelFunc:                     cancelFunc,
+		captureFailures:                make(map[captureKey]*captureFailure),
 		commandsBroadcaster:            commandsBroadcaster,
 		ctx:                            childCtx,
+		flowTable:                      newFlowTable(config.GetFlowActiveTimeout(), config.GetFlowIdleTimeout(), config.GetFlowTableMaxFlows()),
+		heartbeatListener:              heartbeatListener,
+		httpDecoders:                   make(map[httpDecoderKey]*httpDecoder),
 		ifaceNameToFiltersAssociations: make(map[string]map[uint64]*packetCapture),
 		interfaces:                     make(map[string]*pcap.Interface),
 		logger:                         childLogger,
 		packetChan:                     make(chan WrappedPacket, 500),
+		pendingReconcile:               true,
+		startedAt:                      time.Now(),
+		streamHealth:                   StreamHealth{State: StreamStateDisconnected, Since: time.Now()},
+		systemInfo:                     systemInfo,
 	}
 }
 
 // StartAll coordinates packet capture with several key functions:
-// (1) subscribes to commands to trigger fetching config
-// (2) upon receiving `get_bpf_config` command, fetches config from the server
-// (3) enforces config by starting all packet captures for all interfaces and associated filters
-// (4) subscribes to mTLS client updates
+// (1) restores the packet captures of the BPF config cached on disk, so capture resumes without the server
+// (2) subscribes to commands to trigger fetching config
+// (3) upon receiving `get_bpf_config` command, fetches config from the server,
+// reconciling the running captures against the server's desired state on the first fetch
+// (4) enforces config by starting all packet captures for all interfaces and associated filters,
+// reporting the state of every capture to the server
+// (5) subscribes to mTLS client updates, opening new streams with each client
+// and reopening broken streams with backoff when they fail with a retryable error
+// (6) aggregates packets of flow mode captures into flows, emitting flow records as flows expire
+// (7) reassembles the TCP connections of http mode captures, adding the HTTP/1.x messages they complete to packet events
+// (8) batches packet events by size or time window before sending them over the stream
+// (9) spools packet events to disk while the stream is down, replaying them in order once it is back up
+// (10) periodically reports packets lost to a full packet channel or a full spool, and the stream health
+// (11) upon receiving `upload_packet_slice` command, uploads the packets of a capture's ring buffer in a time range,
+// and periodically deletes the packets of ring buffers beyond their max age
+// (12) periodically reports the packet counters and the state of the captures to the server
+// (13) periodically lists the interfaces, reporting them to the server when they change,
+// and restarts the captures of interfaces that come back
+// (14) sends the server a heartbeat with each new mTLS client and then every minute, with the agent's health and capture counts
+// (15) upon receiving `set_log_level` command, changes the agent's log level until it reverts to the default on a timer
+// (16) upon receiving `collect_diagnostics` command, uploads a zip of the agent's logs, config, cert metadata, interfaces and capture stats
 func (m *pcapManager) StartAll() {
 	logger := m.logger.With(psLog.KeyFunction, "PCapManager.StartAll")
 
@@ -85,8 +151,47 @@ func (m *pcapManager) StartAll() {
 		return
 	}
 
+	err := m.restoreCachedConfig()
+	if err != nil {
+		logger.Error("failed to restore cached BPF config", psLog.KeyError, err)
+	}
+
+	m.spool, err = openSpool(config.GetSpoolDir(), config.GetSpoolMaxBytes(), config.GetSpoolSegmentMaxBytes())
+	if err != nil {
+		logger.Error("failed to open packet event spool, packets will be dropped while the stream is down", psLog.KeyError, err)
+	} else {
+		defer m.spool.close()
+		if !m.spool.empty() {
+			logger.Info("found packet events spooled by a previous run, replaying once the stream is up")
+		}
+	}
+	m.streamMu.Lock()
+	m.pendingFlowRecords, err = loadFlowRecords(filepath.Join(config.GetSpoolDir(), flowRecordsPendingFile))
+	m.streamMu.Unlock()
+	if err != nil {
+		logger.Error("failed to load flow records saved by a previous run", psLog.KeyError, err)
+	}
+
 	clientSubscription := m.agentMTLSClientBroadcaster.Subscribe()
 	commandsSubscription := m.commandsBroadcaster.Subscribe()
+	flowExpiryTicker := time.NewTicker(config.GetFlowExpiryInterval())
+	defer flowExpiryTicker.Stop()
+	httpFlushTicker := time.NewTicker(config.GetHTTPFlushInterval())
+	defer httpFlushTicker.Stop()
+	packetBatchTicker := time.NewTicker(config.GetPacketBatchInterval())
+	defer packetBatchTicker.Stop()
+	spoolReplayTicker := time.NewTicker(config.GetSpoolReplayInterval())
+	defer spoolReplayTicker.Stop()
+	packetLossReportTicker := time.NewTicker(config.GetPacketLossReportInterval())
+	defer packetLossReportTicker.Stop()
+	ringBufferPruneTicker := time.NewTicker(config.GetRingBufferPruneInterval())
+	defer ringBufferPruneTicker.Stop()
+	captureStatsReportTicker := time.NewTicker(config.GetCaptureStatsReportInterval())
+	defer captureStatsReportTicker.Stop()
+	interfaceScanTicker := time.NewTicker(config.GetInterfaceScanInterval())
+	defer interfaceScanTicker.Stop()
+	heartbeatTicker := time.NewTicker(config.GetHeartbeatInterval())
+	defer heartbeatTicker.Stop()
 
 	for {
 		select {
@@ -96,65 +201,154 @@ func (m *pcapManager) StartAll() {
 			m.agentMTLSClient = client
 			m.agentMTLSClientMu.Unlock()
 
-			// If there's an existing stream, cancel it (which should cause CloseSend)
 			m.streamMu.Lock()
-			if m.currentStreamCancel != nil {
-				m.currentStreamCancel()
-			}
-			// Create a new context derived from m.ctx for this stream
-			ctx, cancel := context.WithCancel(m.ctx)
-			// The stream needs its own context and cancel func so that it is distinct each time
-			// and can be canceled any time a client update is received on this channel
-			stream, err := client.SendPacketEvent(ctx)
+			// a new client supersedes any scheduled reconnect
+			m.reconnectC = nil
+			err := m.openStreams(client)
 			if err != nil {
 				logger.Error("failed to open packet stream", psLog.KeyError, err)
-				m.currentStreamCancel = cancel
-				m.streamMu.Unlock()
-				continue
+				m.handleStreamError(err)
 			}
-			m.packetStreamClient = stream
-			m.currentStreamCancel = cancel
 			m.streamMu.Unlock()
+			err = m.sendHeartbeat()
+			if err != nil {
+				logger.Error("failed to send heartbeat", psLog.KeyError, err)
+			}
+		case <-m.reconnectDue():
+			m.reconnectStreams()
 		case command := <-commandsSubscription:
 			m.commandMu.Lock()
 			commandName := command.Name
 			m.commandMu.Unlock()
+			startedAt := time.Now()
 			switch commandName {
 			case broadcast.CommandSendInterfaces:
 				logger.Info("processing command", psLog.KeyCommand, broadcast.CommandSendInterfaces)
 				err := m.sendInterfaces()
 				if err != nil {
 					logger.Error("failed to send interfaces to server", psLog.KeyError, err)
-					continue
 				}
+				m.reportCommandResult(command, startedAt, err)
 			case broadcast.CommandGetBPFConfig:
 				logger.Info("processing command", psLog.KeyCommand, broadcast.CommandGetBPFConfig)
-				bpfConfig, err := m.fetchBPFConfig()
+				err := m.applyBPFConfig()
+				m.reportCommandResult(command, startedAt, err)
+			case broadcast.CommandUploadPacketSlice:
+				logger.Info("processing command", psLog.KeyCommand, broadcast.CommandUploadPacketSlice, psLog.KeyCommandID, command.ID)
+				// reading and uploading a slice takes a while, so it doesn't hold up the packets of the live captures
+				go func() {
+					err := m.uploadPacketSlice(command.UploadPacketSlice)
+					m.reportCommandResult(command, startedAt, err)
+				}()
+			case broadcast.CommandSetLogLevel:
+				logger.Info("processing command", psLog.KeyCommand, broadcast.CommandSetLogLevel, psLog.KeyCommandID, command.ID)
+				err := m.setLogLevel(command.SetLogLevel)
 				if err != nil {
-					logger.Error("failed to fetch BPF config", psLog.KeyError, err)
-					continue
-				}
-				err = m.enforceConfig(bpfConfig)
-				if err != nil {
-					logger.Error("failed to enforce BPF config", psLog.KeyError, err)
-					continue
+					logger.Error("failed to set log level", psLog.KeyError, err)
 				}
+				m.reportCommandResult(command, startedAt, err)
+			case broadcast.CommandCollectDiagnostics:
+				logger.Info("processing command", psLog.KeyCommand, broadcast.CommandCollectDiagnostics, psLog.KeyCommandID, command.ID)
+				snapshot := m.diagnosticsSnapshot(startedAt)
+				// reading the logs and uploading the bundle takes a while, so it doesn't hold up the packets of the live captures
+				go func() {
+					err := m.uploadDiagnostics(command.CollectDiagnostics, snapshot)
+					m.reportCommandResult(command, startedAt, err)
+				}()
 			default:
 				// do nothing, command not for this manager
 			}
 		case pkt := <-m.packetChan:
-			err := m.sendPacketEvent(pkt)
+			var httpMessages []*pbAgent.HTTPMessage
+			if pkt.HTTPMode {
+				httpMessages = m.decodeHTTP(pkt)
+			}
+			// a packet completing HTTP messages is sent as a packet event even in flow mode, so the messages aren't lost to the flow
+			if pkt.FlowMode && len(httpMessages) == 0 && m.flowTable.add(pkt) {
+				continue
+			}
+			err := m.sendPacketEvent(pkt, httpMessages)
 			if err != nil {
 				logger.Error("failed to send packet event", psLog.KeyError, err)
 				continue
 			}
+		case <-flowExpiryTicker.C:
+			if dropped := m.flowTable.takeDropped(); dropped > 0 {
+				logger.Warn("flow table full, packets for new flows were not aggregated", psLog.KeyFlowsDropped, dropped)
+			}
+			err := m.sendFlowRecords(m.flowTable.expire(time.Now()))
+			if err != nil {
+				logger.Error("failed to send flow records", psLog.KeyError, err)
+				continue
+			}
+		case <-httpFlushTicker.C:
+			m.flushHTTPDecoders(time.Now())
+		case <-packetBatchTicker.C:
+			m.streamMu.Lock()
+			err := m.flushPacketBatch()
+			m.streamMu.Unlock()
+			if err != nil {
+				logger.Error("failed to flush packet event batch", psLog.KeyError, err)
+				continue
+			}
+		case <-spoolReplayTicker.C:
+			err := m.replaySpool()
+			if err != nil {
+				logger.Error("failed to replay spooled packet events", psLog.KeyError, err)
+				continue
+			}
+		case <-packetLossReportTicker.C:
+			m.reportPacketLoss()
+		case <-ringBufferPruneTicker.C:
+			m.pruneRingBuffers(time.Now())
+		case <-interfaceScanTicker.C:
+			err := m.scanInterfaces()
+			if err != nil {
+				logger.Error("failed to scan interfaces", psLog.KeyError, err)
+				continue
+			}
+		case <-m.logLevelRevertC:
+			m.revertLogLevel()
+		case <-heartbeatTicker.C:
+			err := m.sendHeartbeat()
+			if err != nil {
+				logger.Error("failed to send heartbeat", psLog.KeyError, err)
+				continue
+			}
+		case <-captureStatsReportTicker.C:
+			err := m.reportCaptureStats()
+			if err != nil {
+				logger.Error("failed to report capture stats", psLog.KeyError, err)
+			}
+			// the state too, since a capture stops on its own when its interface stops delivering packets
+			err = m.reportCaptureResults()
+			if err != nil {
+				logger.Error("failed to report capture results", psLog.KeyError, err)
+				continue
+			}
 		case <-m.ctx.Done():
 			logger.Error("pcap manager context canceled")
+			// keep the events of the pending batch, and the flows of the stopped captures, for the next run
+			m.streamMu.Lock()
+			m.spoolPacketEvents(m.packetBatch)
+			flowRecords := append(m.pendingFlowRecords, m.flowTable.flushAll()...)
+			err := saveFlowRecords(filepath.Join(config.GetSpoolDir(), flowRecordsPendingFile), flowRecords)
+			if err != nil {
+				logger.Error("failed to save unsent flow records", psLog.KeyFlowsDropped, len(flowRecords), psLog.KeyError, err)
+			}
+			m.streamMu.Unlock()
 			return
 		}
 	}
 }
 
+// reconnectDue returns the channel that fires when a scheduled stream reconnect is due, nil if none is scheduled
+func (m *pcapManager) reconnectDue() <-chan time.Time {
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+	return m.reconnectC
+}
+
 // StopOne stops and removes a capture for the given interface name for the given filter hash
 func (m *pcapManager) StopOne(ifaceName string, filterHash uint64, filter string) error {
 	logger := m.logger.With(psLog.KeyFunction, "PCapManager.StopOne")
@@ -189,6 +383,12 @@ func (m *pcapManager) StopOne(ifaceName string, filterHash uint64, filter string
 		}
 	}
 	capture.Stop()
+	m.droppedPackets.Add(capture.takeDropped())
+	// the capture's flows won't see any more packets, so their records are sent now rather than on their idle timeout
+	err := m.sendFlowRecords(m.flowTable.flushCapture(ifaceName, capture.config.BPF))
+	if err != nil {
+		logger.Error("failed to send flow records of stopped capture", psLog.KeyError, err)
+	}
 	logger.Info(
 		"deleting bpf hash entry from interface name's map",
 		slog.String(psLog.KeyDeviceName, ifaceName),
@@ -199,6 +399,15 @@ func (m *pcapManager) StopOne(ifaceName string, filterHash uint64, filter string
 	return nil
 }
 
+// hasCapture reports whether there is a live capture for the given interface name and filter hash
+func (m *pcapManager) hasCapture(ifaceName string, filterHash uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, exists := m.ifaceNameToFiltersAssociations[ifaceName][filterHash]
+	return exists
+}
+
 // StopAll stops all packet captures for all interfaces and associated filters.
 func (m *pcapManager) StopAll() {
 	logger := m.logger.With(psLog.KeyFunction, "PCapManager.StopAll")
@@ -230,38 +439,52 @@ func (m *pcapManager) StopAll() {
 func (m *pcapManager) sendInterfaces() error {
 	logger := m.logger.With(psLog.KeyFunction, "PCapManager.sendInterfaces")
 
-	m.pcapVersion = pcap.Version()
-	logger.Info("collected pcap version", slog.String(psLog.KeyPCapVersion, m.pcapVersion))
-	interfaces, err := pcap.FindAllDevs()
+	interfaces, err := m.findInterfaces()
 	if err != nil {
 		logger.Error("failed to find all devices with pcap", psLog.KeyError, err)
 		return err
 	}
-
-	reportRequest := &pbAgent.ReportInterfacesRequest{
-		Interfaces:  make([]*pbAgent.InterfaceDetails, 0),
-		PcapVersion: m.pcapVersion,
-	}
-
 	for _, iface := range interfaces {
 		logger.Info("found device", slog.String(psLog.KeyDeviceName, iface.Name))
-		m.ifaceNameToFiltersAssociations[iface.Name] = make(map[uint64]*packetCapture)
-		m.interfaces[iface.Name] = &iface
-		reportRequest.Interfaces = append(reportRequest.Interfaces, &pbAgent.InterfaceDetails{Name: iface.Name})
 	}
 
 	logger.Info("sending interfaces")
-	m.agentMTLSClientMu.RLock()
-	client := m.agentMTLSClient
-	m.agentMTLSClientMu.RUnlock()
+	return m.reportInterfaces(interfaces)
+}
 
-	if client == nil {
-		logger.Warn("no agent gRPC client available")
-		return fmt.Errorf("no agent gRPC client available")
-	}
+// applyBPFConfig fetches the BPF config from the server and enforces it, reporting the state of every capture
+func (m *pcapManager) applyBPFConfig() error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.applyBPFConfig")
 
-	_, err = client.ReportInterfaces(m.ctx, reportRequest)
-	return err
+	bpfConfig, err := m.fetchBPFConfig()
+	if err != nil {
+		logger.Error("failed to fetch BPF config", psLog.KeyError, err)
+		return err
+	}
+	if m.pendingReconcile {
+		// the server's create/update/delete maps are a delta against the config it last sent,
+		// which says nothing about what this agent restored from cache or lost on restart
+		logger.Info("reconciling running packet captures with server's desired BPF config")
+		bpfConfig = m.reconcileConfig(bpfConfig.Desired)
+	}
+	err = m.enforceConfig(bpfConfig)
+	reportErr := m.reportCaptureResults()
+	if reportErr != nil {
+		logger.Error("failed to report capture results", psLog.KeyError, reportErr)
+	}
+	if err != nil {
+		logger.Error("failed to enforce BPF config", psLog.KeyError, err)
+		// a partially enforced config leaves captures out of sync with the delta the server tracks
+		m.pendingReconcile = true
+		return err
+	}
+	m.pendingReconcile = false
+	err = m.saveCachedConfig()
+	if err != nil {
+		// the captures are running, they just won't be restored before the first fetch after a restart
+		logger.Error("failed to cache BPF config", psLog.KeyError, err)
+	}
+	return nil
 }
 
 func (m *pcapManager) fetchBPFConfig() (*pbAgent.BPFConfig, error) {
@@ -285,13 +508,23 @@ func (m *pcapManager) fetchBPFConfig() (*pbAgent.BPFConfig, error) {
 }
 
 func (m *pcapManager) enforceConfig(bpfConfig *pbAgent.BPFConfig) error {
-	logger := m.logger.With(psLog.KeyFunction, "PCapManager.fetchBPFConfig")
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.enforceConfig")
 
 	var errs []error
+	// only the captures created or updated by this config get started,
+	// the rest of the associations are already live
+	capturesToStart := make(map[string]map[uint64]*packetCapture)
+	addCaptureToStart := func(ifaceName string, filterHash uint64, capture *packetCapture) {
+		if capturesToStart[ifaceName] == nil {
+			capturesToStart[ifaceName] = make(map[uint64]*packetCapture)
+		}
+		capturesToStart[ifaceName][filterHash] = capture
+	}
 
 	if len(bpfConfig.Delete) > 0 {
 		for ifaceName, bpfAssociationsToDelete := range bpfConfig.Delete {
 			for filterHash, captureCfg := range bpfAssociationsToDelete.Captures {
+				delete(m.captureFailures, captureKey{deviceName: ifaceName, bpf: captureCfg.Bpf})
 				deleteErr := m.StopOne(ifaceName, filterHash, captureCfg.Bpf)
 				if deleteErr != nil {
 					switch deleteErr.(type) {
@@ -343,6 +576,7 @@ func (m *pcapManager) enforceConfig(bpfConfig *pbAgent.BPFConfig) error {
 							updateErr.Error(),
 						)
 						errs = append(errs, updateErr)
+						m.recordCaptureResult(ifaceName, filterHash, captureCfg, "", updateErr)
 						continue
 					}
 				}
@@ -356,6 +590,9 @@ func (m *pcapManager) enforceConfig(bpfConfig *pbAgent.BPFConfig) error {
 						Promiscuous: captureCfg.Promiscuous,
 						SnapLen:     captureCfg.SnapLen,
 						Timeout:     pcap.BlockForever,
+						FlowMode:    captureCfg.FlowMode,
+						HTTPMode:    captureCfg.HttpMode,
+						RingBuffer:  captureCfg.RingBuffer,
 					},
 					&m.wg,
 					m.packetChan,
@@ -370,12 +607,10 @@ func (m *pcapManager) enforceConfig(bpfConfig *pbAgent.BPFConfig) error {
 						updateErr.Error(),
 					)
 					errs = append(errs, updateErr)
+					m.recordCaptureResult(ifaceName, filterHash, captureCfg, "", updateErr)
 					continue
 				}
-				if m.ifaceNameToFiltersAssociations[ifaceName] == nil {
-					m.ifaceNameToFiltersAssociations[ifaceName] = make(map[uint64]*packetCapture)
-				}
-				m.ifaceNameToFiltersAssociations[ifaceName][filterHash] = updatedPacketCapture
+				addCaptureToStart(ifaceName, filterHash, updatedPacketCapture)
 			}
 		}
 	}
@@ -392,6 +627,9 @@ func (m *pcapManager) enforceConfig(bpfConfig *pbAgent.BPFConfig) error {
 						Promiscuous: captureCfg.Promiscuous,
 						SnapLen:     captureCfg.SnapLen,
 						Timeout:     pcap.BlockForever,
+						FlowMode:    captureCfg.FlowMode,
+						HTTPMode:    captureCfg.HttpMode,
+						RingBuffer:  captureCfg.RingBuffer,
 					},
 					&m.wg,
 					m.packetChan,
@@ -406,22 +644,44 @@ func (m *pcapManager) enforceConfig(bpfConfig *pbAgent.BPFConfig) error {
 						createErr.Error(),
 					)
 					errs = append(errs, createErr)
+					m.recordCaptureResult(ifaceName, filterHash, captureCfg, "", createErr)
 					continue
 				}
-				if m.ifaceNameToFiltersAssociations[ifaceName] == nil {
-					m.ifaceNameToFiltersAssociations[ifaceName] = make(map[uint64]*packetCapture)
-				}
-				m.ifaceNameToFiltersAssociations[ifaceName][filterHash] = createdPacketCapture
+				addCaptureToStart(ifaceName, filterHash, createdPacketCapture)
 			}
 		}
 	}
 
-	for _, bpfAssociations := range m.ifaceNameToFiltersAssociations {
-		for _, packetCaptureToStart := range bpfAssociations {
+	for ifaceName, bpfAssociations := range capturesToStart {
+		for filterHash, packetCaptureToStart := range bpfAssociations {
+			// a create for an association that is already live, e.g. one restored from the cache, replaces it
+			if m.hasCapture(ifaceName, filterHash) {
+				logger.Info(
+					"replacing live packet capture for BPF association",
+					slog.String(psLog.KeyDeviceName, ifaceName),
+					slog.String(psLog.KeyBPF, packetCaptureToStart.config.BPF),
+					slog.Uint64(psLog.KeyBPFHash, filterHash),
+				)
+				stopErr := m.StopOne(ifaceName, filterHash, packetCaptureToStart.config.BPF)
+				if stopErr != nil {
+					errs = append(errs, stopErr)
+					m.recordCaptureResult(ifaceName, filterHash, captureConfigToPB(packetCaptureToStart.config), "", stopErr)
+					continue
+				}
+			}
 			pcapStartErr := packetCaptureToStart.Start()
 			if pcapStartErr != nil {
 				errs = append(errs, pcapStartErr)
+				m.recordCaptureResult(ifaceName, filterHash, captureConfigToPB(packetCaptureToStart.config), packetCaptureToStart.linkType, pcapStartErr)
+				continue
+			}
+			m.recordCaptureResult(ifaceName, filterHash, captureConfigToPB(packetCaptureToStart.config), packetCaptureToStart.linkType, nil)
+			m.mu.Lock()
+			if m.ifaceNameToFiltersAssociations[ifaceName] == nil {
+				m.ifaceNameToFiltersAssociations[ifaceName] = make(map[uint64]*packetCapture)
 			}
+			m.ifaceNameToFiltersAssociations[ifaceName][filterHash] = packetCaptureToStart
+			m.mu.Unlock()
 		}
 	}
 
@@ -432,7 +692,7 @@ func (m *pcapManager) enforceConfig(bpfConfig *pbAgent.BPFConfig) error {
 	return nil
 }
 
-func (m *pcapManager) sendPacketEvent(wrappedPkt WrappedPacket) error {
+func (m *pcapManager) sendPacketEvent(wrappedPkt WrappedPacket, httpMessages []*pbAgent.HTTPMessage) error {
 	logger := m.logger.With(psLog.KeyFunction, "PCapManager.sendPacketEvent")
 
 	pkt := wrappedPkt.PacketEventData
@@ -441,29 +701,265 @@ func (m *pcapManager) sendPacketEvent(wrappedPkt WrappedPacket) error {
 	}
 
 	if err := pkt.ErrorLayer(); err != nil {
-		logger.Error("failed to decode packet", psLog.KeyError, err)
+		if pkt.Layer(layers.LayerTypeTCP) != nil {
+			// TCP payloads are decoded as datagrams, so segments that don't start at a message boundary routinely fail to decode
+			logger.Debug("failed to decode TCP payload", psLog.KeyError, err)
+		} else {
+			logger.Error("failed to decode packet", psLog.KeyError, err)
+		}
 	}
 
 	packetEvent := ConvertPacketToEvent(wrappedPkt)
+	if len(httpMessages) > 0 {
+		packetEvent.Layers.HttpLayer = &pbAgent.HTTPLayer{Messages: httpMessages}
+	}
+	return m.batchPacketEvent(packetEvent)
+}
 
+// batchPacketEvent adds the packet event to the pending batch, sending the batch once it reaches its max events or size
+func (m *pcapManager) batchPacketEvent(packetEvent *pbAgent.PacketEvent) error {
 	m.streamMu.Lock()
 	defer m.streamMu.Unlock()
 
-	if m.packetStreamClient == nil {
-		logger.Warn("no stream available, dropping packet", psLog.KeyDroppedPacket, pkt.String())
+	// send the batch first if the event would push it over its max size
+	var errs []error
+	eventBytes := proto.Size(packetEvent)
+	if len(m.packetBatch) > 0 && m.packetBatchBytes+eventBytes > config.GetPacketBatchMaxBytes() {
+		errs = append(errs, m.flushPacketBatch())
+	}
+
+	m.packetBatch = append(m.packetBatch, packetEvent)
+	m.packetBatchBytes += eventBytes
+	if len(m.packetBatch) < config.GetPacketBatchMaxEvents() && m.packetBatchBytes < config.GetPacketBatchMaxBytes() {
+		return errors.Join(errs...)
+	}
+	errs = append(errs, m.flushPacketBatch())
+	return errors.Join(errs...)
+}
+
+// decodeHTTP reassembles the packet with the other TCP segments its capture saw, returning the HTTP messages it completes
+func (m *pcapManager) decodeHTTP(wrappedPkt WrappedPacket) []*pbAgent.HTTPMessage {
+	if wrappedPkt.PacketEventData == nil {
 		return nil
 	}
+	key := httpDecoderKey{deviceName: wrappedPkt.DeviceName, bpf: wrappedPkt.Bpf}
+	decoder, exists := m.httpDecoders[key]
+	if !exists {
+		decoder = newHTTPDecoder(config.GetHTTPFlowByteBudget(), config.GetHTTPMaxStreams(), config.GetHTTPMaxBufferedPagesPerStream())
+		m.httpDecoders[key] = decoder
+	}
+	return decoder.decode(wrappedPkt.PacketEventData)
+}
 
-	err := m.packetStreamClient.Send(packetEvent)
+// flushHTTPDecoders skips missing TCP segments that were awaited for a flush interval, closes idle connections,
+// and removes the decoders of captures that no longer see packets.
+// The messages the flush completes are sent as packet events of their capture without packet data.
+func (m *pcapManager) flushHTTPDecoders(now time.Time) {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.flushHTTPDecoders")
+
+	idleSince := now.Add(-config.GetHTTPIdleTimeout())
+	for key, decoder := range m.httpDecoders {
+		for _, message := range decoder.flush(now.Add(-config.GetHTTPFlushInterval()), idleSince) {
+			err := m.batchPacketEvent(message.packetEvent(key))
+			if err != nil {
+				logger.Error("failed to send http message completed by flush", psLog.KeyError, err)
+			}
+		}
+		if dropped := decoder.takeDropped(); dropped > 0 {
+			logger.Warn(
+				"too many TCP connections to reassemble for http mode capture, new connections were not decoded",
+				slog.String(psLog.KeyDeviceName, key.deviceName),
+				slog.String(psLog.KeyBPF, key.bpf),
+				psLog.KeyHTTPStreamsDropped, dropped,
+			)
+		}
+		if decoder.idle(idleSince) {
+			delete(m.httpDecoders, key)
+		}
+	}
+}
+
+// flushPacketBatch sends the pending batch of packet events over the stream, spooling it if the stream is down.
+// The caller must hold streamMu.
+func (m *pcapManager) flushPacketBatch() error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.flushPacketBatch")
+
+	if len(m.packetBatch) == 0 {
+		return nil
+	}
+	packetEvents := m.packetBatch
+	m.packetBatch = nil
+	m.packetBatchBytes = 0
+
+	// while there are spooled events, new ones are spooled behind them to keep the order
+	if m.packetStreamClient == nil || (m.spool != nil && !m.spool.empty()) {
+		return m.spoolPacketEvents(packetEvents)
+	}
+
+	err := m.sendBatchOnPacketStream(packetEvents)
 	if err != nil {
-		// TODO: switch on codes for retry-able versus non-retry-able errors
-		// create reconnection logic for non-retryable ones
-		// send on a reconnectCh to get a new stream client on demand,
-		// as opposed to the existing subscriber mechanism
-		// for receiving a new client connection when the client cert changes
-		logger.Error("failed to send packet over stream", psLog.KeyError, err)
-		return err
+		logger.Error("failed to send packet event batch over stream, spooling batch", psLog.KeyError, err)
+		return m.spoolPacketEvents(packetEvents)
+	}
+
+	return nil
+}
+
+// spoolPacketEvents appends the packet events to the on-disk spool, counting those that can't be spooled as dropped.
+// The caller must hold streamMu.
+func (m *pcapManager) spoolPacketEvents(packetEvents []*pbAgent.PacketEvent) error {
+	if len(packetEvents) == 0 {
+		return nil
+	}
+	if m.spool == nil {
+		m.droppedPackets.Add(uint64(len(packetEvents)))
+		m.eventsDropped.Add(uint64(len(packetEvents)))
+		return fmt.Errorf("no stream or spool available, dropping %d packets", len(packetEvents))
+	}
+
+	var errs []error
+	for _, packetEvent := range packetEvents {
+		err := m.spool.append(packetEvent)
+		if err != nil {
+			m.droppedPackets.Add(1)
+			m.eventsDropped.Add(1)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to spool %d packet events: %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
+
+// replaySpool sends spooled packet events over the live stream, oldest first,
+// for at most one replay interval so new packets aren't held up behind a large spool
+func (m *pcapManager) replaySpool() error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.replaySpool")
+
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+
+	if m.spool == nil || m.packetStreamClient == nil {
+		return nil
+	}
+
+	replayed := 0
+	deadline := time.Now().Add(config.GetSpoolReplayInterval())
+	for !m.spool.empty() && time.Now().Before(deadline) {
+		sent, err := m.spool.replay(config.GetPacketBatchMaxEvents(), config.GetPacketBatchMaxBytes(), m.sendBatchOnPacketStream)
+		replayed += sent
+		if err != nil {
+			if replayed > 0 {
+				logger.Info("replayed spooled packet events", psLog.KeyPacketsReplayed, replayed)
+			}
+			return err
+		}
+	}
+
+	if replayed > 0 {
+		logger.Info("replayed spooled packet events", psLog.KeyPacketsReplayed, replayed)
+	}
+	return nil
+}
+
+// reportPacketLoss logs the number of packets lost since the last report, and the stream health while it is not connected
+func (m *pcapManager) reportPacketLoss() {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.reportPacketLoss")
+
+	streamHealth := m.StreamHealth()
+	if streamHealth.State != StreamStateConnected {
+		logger.Warn("packet stream is not connected, spooling packet events", psLog.KeyStreamHealth, streamHealth)
+	}
+
+	dropped := m.droppedPackets.Swap(0)
+	m.mu.Lock()
+	for _, captures := range m.ifaceNameToFiltersAssociations {
+		for _, capture := range captures {
+			dropped += capture.takeDropped()
+		}
+	}
+	m.mu.Unlock()
+	if dropped > 0 {
+		logger.Warn("packets dropped since last report, packet channel was full or packets could not be spooled", psLog.KeyPacketsDropped, dropped)
+	}
+
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+	if m.spool == nil {
+		return
+	}
+	spoolDropped := m.spool.takeDropped()
+	m.eventsDropped.Add(spoolDropped)
+	if spoolDropped > 0 {
+		logger.Warn("spooled packet events were dropped, the spool was full or their records were corrupt", psLog.KeyPacketsDropped, spoolDropped)
+	}
+}
+
+// reportCaptureStats sends the server the packet counters of the live captures, so it knows when their data is incomplete
+func (m *pcapManager) reportCaptureStats() error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.reportCaptureStats")
+
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		logger.Warn("no agent gRPC client available, skipping capture stats report")
+		return nil
+	}
+
+	_, err := client.ReportCaptureStats(m.ctx, m.captureStats())
+	return err
+}
+
+// captureStats returns the packet counters of the live captures
+func (m *pcapManager) captureStats() *pbAgent.ReportCaptureStatsRequest {
+	request := &pbAgent.ReportCaptureStatsRequest{
+		EventsDropped: m.eventsDropped.Load(),
+		CollectedAt:   timestamppb.Now(),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, captures := range m.ifaceNameToFiltersAssociations {
+		for _, capture := range captures {
+			request.Captures = append(request.Captures, capture.captureStats())
+		}
+	}
+	return request
+}
+
+func (m *pcapManager) sendFlowRecords(flowRecords []*pbAgent.FlowRecord) error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.sendFlowRecords")
+
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+
+	// records that couldn't be sent are kept for the next send, up to the max flows of the flow table
+	m.pendingFlowRecords = append(m.pendingFlowRecords, flowRecords...)
+	if excess := len(m.pendingFlowRecords) - config.GetFlowTableMaxFlows(); excess > 0 {
+		logger.Warn("too many unsent flow records, dropping the oldest", psLog.KeyFlowsDropped, excess)
+		m.pendingFlowRecords = m.pendingFlowRecords[excess:]
+	}
+	if len(m.pendingFlowRecords) == 0 {
+		return nil
+	}
+
+	if m.flowStreamClient == nil {
+		logger.Debug("no flow stream available, keeping flow records for the next send", psLog.KeyFlows, len(m.pendingFlowRecords))
+		return nil
+	}
+
+	for i, flowRecord := range m.pendingFlowRecords {
+		err := m.flowStreamClient.Send(flowRecord)
+		if err != nil {
+			logger.Error("failed to send flow record over stream", psLog.KeyError, err)
+			m.pendingFlowRecords = m.pendingFlowRecords[i:]
+			// the flow stream shares its connection with the packet stream, reopen both
+			m.handleStreamError(err)
+			return err
+		}
 	}
+	m.pendingFlowRecords = nil
 
 	return nil
 }
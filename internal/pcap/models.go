@@ -15,6 +15,9 @@ type CaptureConfig struct {
 	Promiscuous bool          `json:"promiscuous"`
 	SnapLen     int32         `json:"snapLen"`
 	Timeout     time.Duration `json:"timeout"`
+	FlowMode    bool          `json:"flowMode"`
+	HTTPMode    bool          `json:"httpMode"`
+	RingBuffer  bool          `json:"ringBuffer"`
 }
 
 // LogValue implements the slog.LogValuer interface for the CaptureConfig struct
@@ -25,6 +28,27 @@ func (cc *CaptureConfig) LogValue() slog.Value {
 		slog.Bool(psLog.KeyPromiscuous, cc.Promiscuous),
 		slog.Int64(psLog.KeySnapLen, int64(cc.SnapLen)),
 		slog.String(psLog.KeyTimeout, cc.Timeout.String()),
+		slog.Bool(psLog.KeyFlowMode, cc.FlowMode),
+		slog.Bool(psLog.KeyHTTPMode, cc.HTTPMode),
+		slog.Bool(psLog.KeyRingBuffer, cc.RingBuffer),
+	)
+}
+
+// StreamHealth holds the state of the packet event stream for logs and health reporting
+type StreamHealth struct {
+	State             string    `json:"state"`
+	ReconnectAttempts int       `json:"reconnectAttempts"`
+	LastError         string    `json:"lastError,omitempty"`
+	Since             time.Time `json:"since"`
+}
+
+// LogValue implements the slog.LogValuer interface for the StreamHealth struct
+func (sh StreamHealth) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String(psLog.KeyStreamState, sh.State),
+		slog.Int(psLog.KeyReconnectAttempt, sh.ReconnectAttempts),
+		slog.String(psLog.KeyError, sh.LastError),
+		slog.Time(psLog.KeySince, sh.Since),
 	)
 }
 
@@ -1,7 +1,14 @@
 package pcap
 
 import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/google/gopacket/layers"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
 )
@@ -9,6 +16,10 @@ import (
 func ConvertPacketToEvent(wrappedPkt WrappedPacket) *pbAgent.PacketEvent {
 	pkt := wrappedPkt.PacketEventData
 	metadata := pkt.Metadata()
+	captureTime := metadata.Timestamp
+	if captureTime.IsZero() {
+		captureTime = time.Now()
+	}
 	event := &pbAgent.PacketEvent{
 		Bpf:            wrappedPkt.Bpf,
 		DeviceName:     wrappedPkt.DeviceName,
@@ -19,31 +30,50 @@ func ConvertPacketToEvent(wrappedPkt WrappedPacket) *pbAgent.PacketEvent {
 		InterfaceIndex: int32(metadata.InterfaceIndex),
 		Truncated:      metadata.Truncated,
 		Layers:         &pbAgent.Layers{},
+		CaptureTime:    timestamppb.New(captureTime),
+	}
+
+	// Ethernet layer of the link, absent for captures on e.g. loopback or tunnel interfaces
+	pktLayers := pkt.Layers()
+	for i, layer := range pktLayers {
+		if layer.LayerType() == layers.LayerTypeEthernet {
+			event.Layers.EthernetLayer = ethernetLayerInfo(pktLayers, i)
+			break
+		}
 	}
 
-	// IP layer
-	if ipv4Layer := pkt.Layer(layers.LayerTypeIPv4); ipv4Layer != nil {
-		ipv4 := ipv4Layer.(*layers.IPv4)
-		event.Layers.IpLayer = &pbAgent.IPLayer{
-			Version:  "IPv4",
-			SrcIp:    ipv4.SrcIP.String(),
-			DstIp:    ipv4.DstIP.String(),
-			Ttl:      uint32(ipv4.TTL),
-			Protocol: uint32(ipv4.Protocol),
+	// ARP layer
+	if arpLayer := pkt.Layer(layers.LayerTypeARP); arpLayer != nil {
+		arp := arpLayer.(*layers.ARP)
+		arpInfo := &pbAgent.ARPLayer{
+			Operation: strconv.Itoa(int(arp.Operation)),
+			SenderMac: net.HardwareAddr(arp.SourceHwAddress).String(),
+			SenderIp:  net.IP(arp.SourceProtAddress).String(),
+			TargetMac: net.HardwareAddr(arp.DstHwAddress).String(),
+			TargetIp:  net.IP(arp.DstProtAddress).String(),
 		}
-	} else if ipv6Layer := pkt.Layer(layers.LayerTypeIPv6); ipv6Layer != nil {
-		ipv6 := ipv6Layer.(*layers.IPv6)
-		event.Layers.IpLayer = &pbAgent.IPLayer{
-			Version:  "IPv6",
-			SrcIp:    ipv6.SrcIP.String(),
-			DstIp:    ipv6.DstIP.String(),
-			HopLimit: uint32(ipv6.HopLimit),
-			Protocol: uint32(ipv6.NextHeader),
+		switch arp.Operation {
+		case layers.ARPRequest:
+			arpInfo.Operation = "request"
+		case layers.ARPReply:
+			arpInfo.Operation = "reply"
 		}
+		event.Layers.ArpLayer = arpInfo
+	}
+
+	// Tunnels, reporting the underlay headers of each encapsulation
+	event.Layers.Tunnels = decodeTunnels(pkt)
+	for _, tunnel := range event.Layers.Tunnels {
+		event.Layers.TunnelTypes = append(event.Layers.TunnelTypes, tunnel.Type)
+	}
+
+	// IP layer, of the innermost packet for tunneled traffic so it is attributed to the endpoints rather than the underlay
+	if ipLayer := innermostLayer(pkt, layers.LayerTypeIPv4, layers.LayerTypeIPv6); ipLayer != nil {
+		event.Layers.IpLayer = ipLayerInfo(ipLayer)
 	}
 
 	// TCP layer
-	if tcpLayer := pkt.Layer(layers.LayerTypeTCP); tcpLayer != nil {
+	if tcpLayer := innermostTransportLayer(pkt, layers.LayerTypeTCP); tcpLayer != nil {
 		tcp := tcpLayer.(*layers.TCP)
 		event.Layers.TcpLayer = &pbAgent.TCPLayer{
 			SrcPort: uint32(tcp.SrcPort),
@@ -61,51 +91,152 @@ func ConvertPacketToEvent(wrappedPkt WrappedPacket) *pbAgent.PacketEvent {
 	}
 
 	// UDP layer
-	if udpLayer := pkt.Layer(layers.LayerTypeUDP); udpLayer != nil {
-		udp := udpLayer.(*layers.UDP)
-		event.Layers.UdpLayer = &pbAgent.UDPLayer{
-			SrcPort: uint32(udp.SrcPort),
-			DstPort: uint32(udp.DstPort),
-			Length:  uint32(udp.Length),
+	if udpLayer := innermostTransportLayer(pkt, layers.LayerTypeUDP); udpLayer != nil {
+		event.Layers.UdpLayer = udpLayerInfo(udpLayer.(*layers.UDP))
+	}
+
+	// ICMP layer
+	if icmpv4Layer := pkt.Layer(layers.LayerTypeICMPv4); icmpv4Layer != nil {
+		icmpv4 := icmpv4Layer.(*layers.ICMPv4)
+		event.Layers.IcmpLayer = &pbAgent.ICMPLayer{
+			Version:  "ICMPv4",
+			Type:     uint32(icmpv4.TypeCode.Type()),
+			Code:     uint32(icmpv4.TypeCode.Code()),
+			TypeCode: icmpv4.TypeCode.String(),
+			Id:       uint32(icmpv4.Id),
+			Seq:      uint32(icmpv4.Seq),
+		}
+	} else if icmpv6Layer := pkt.Layer(layers.LayerTypeICMPv6); icmpv6Layer != nil {
+		icmpv6 := icmpv6Layer.(*layers.ICMPv6)
+		event.Layers.IcmpLayer = &pbAgent.ICMPLayer{
+			Version:  "ICMPv6",
+			Type:     uint32(icmpv6.TypeCode.Type()),
+			Code:     uint32(icmpv6.TypeCode.Code()),
+			TypeCode: icmpv6.TypeCode.String(),
+		}
+		// unlike ICMPv4, gopacket decodes the ICMPv6 echo id and seq as a layer of their own
+		if echoLayer := pkt.Layer(layers.LayerTypeICMPv6Echo); echoLayer != nil {
+			echo := echoLayer.(*layers.ICMPv6Echo)
+			event.Layers.IcmpLayer.Id = uint32(echo.Identifier)
+			event.Layers.IcmpLayer.Seq = uint32(echo.SeqNumber)
 		}
 	}
 
-	// TLS layer
-	if tlsLayer := pkt.Layer(layers.LayerTypeTLS); tlsLayer != nil {
-		tls := tlsLayer.(*layers.TLS)
-		tlsInfo := &pbAgent.TLSLayer{}
+	// TLS layer. The hellos are parsed from the TCP payload rather than from gopacket's TLS layer,
+	// which isn't decoded when the segment ends in a partial record.
+	var clientHello *pbAgent.TLSClientHello
+	var serverHello *pbAgent.TLSServerHello
+	if tcpLayer := innermostTransportLayer(pkt, layers.LayerTypeTCP); tcpLayer != nil {
+		tcp := tcpLayer.(*layers.TCP)
+		if tcp.SrcPort == tlsPort || tcp.DstPort == tlsPort {
+			clientHello, serverHello = parseTLSHellos(tcp.LayerPayload())
+		}
+	}
+	tlsLayer := pkt.Layer(layers.LayerTypeTLS)
+	if tlsLayer != nil || clientHello != nil || serverHello != nil {
+		tlsInfo := &pbAgent.TLSLayer{
+			ClientHello: clientHello,
+			ServerHello: serverHello,
+		}
 
-		for _, hs := range tls.Handshake {
-			tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
-				Type:    "Handshake",
-				Version: hs.Version.String(),
-				Length:  uint32(hs.Length),
-			})
+		if tlsLayer != nil {
+			tls := tlsLayer.(*layers.TLS)
+			for _, hs := range tls.Handshake {
+				tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
+					Type:    "Handshake",
+					Version: hs.Version.String(),
+					Length:  uint32(hs.Length),
+				})
+			}
+			for _, app := range tls.AppData {
+				tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
+					Type:    "AppData",
+					Version: app.Version.String(),
+					Length:  uint32(app.Length),
+				})
+			}
+			for _, alert := range tls.Alert {
+				tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
+					Type:    "Alert",
+					Version: alert.Version.String(),
+					Length:  uint32(alert.Length),
+				})
+			}
+			for _, cc := range tls.ChangeCipherSpec {
+				tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
+					Type:    "ChangeCipherSpec",
+					Version: cc.Version.String(),
+					Length:  uint32(cc.Length),
+				})
+			}
 		}
-		for _, app := range tls.AppData {
-			tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
-				Type:    "AppData",
-				Version: app.Version.String(),
-				Length:  uint32(app.Length),
-			})
+
+		event.Layers.TlsLayer = tlsInfo
+	}
+
+	// DNS layer
+	if dnsLayer := pkt.Layer(layers.LayerTypeDNS); dnsLayer != nil {
+		dns := dnsLayer.(*layers.DNS)
+		dnsInfo := &pbAgent.DNSLayer{
+			Id:            uint32(dns.ID),
+			Response:      dns.QR,
+			Opcode:        dns.OpCode.String(),
+			ResponseCode:  dns.ResponseCode.String(),
+			Authoritative: dns.AA,
+			Truncated:     dns.TC,
 		}
-		for _, alert := range tls.Alert {
-			tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
-				Type:    "Alert",
-				Version: alert.Version.String(),
-				Length:  uint32(alert.Length),
+
+		for _, question := range dns.Questions {
+			dnsInfo.Questions = append(dnsInfo.Questions, &pbAgent.DNSQuestion{
+				Name:  string(question.Name),
+				Type:  question.Type.String(),
+				Class: question.Class.String(),
 			})
 		}
-		for _, cc := range tls.ChangeCipherSpec {
-			tlsInfo.Records = append(tlsInfo.Records, &pbAgent.TLSRecord{
-				Type:    "ChangeCipherSpec",
-				Version: cc.Version.String(),
-				Length:  uint32(cc.Length),
+		for _, answer := range dns.Answers {
+			dnsInfo.Answers = append(dnsInfo.Answers, &pbAgent.DNSResourceRecord{
+				Name:  string(answer.Name),
+				Type:  answer.Type.String(),
+				Class: answer.Class.String(),
+				Ttl:   answer.TTL,
+				Data:  dnsRecordData(answer),
 			})
 		}
 
-		event.Layers.TlsLayer = tlsInfo
+		event.Layers.DnsLayer = dnsInfo
 	}
 
 	return event
 }
+
+// dnsRecordData returns the data of a DNS resource record in presentation format
+func dnsRecordData(record layers.DNSResourceRecord) string {
+	switch record.Type {
+	case layers.DNSTypeA, layers.DNSTypeAAAA:
+		if record.IP != nil {
+			return record.IP.String()
+		}
+	case layers.DNSTypeCNAME:
+		return string(record.CNAME)
+	case layers.DNSTypeNS:
+		return string(record.NS)
+	case layers.DNSTypePTR:
+		return string(record.PTR)
+	case layers.DNSTypeMX:
+		return fmt.Sprintf("%d %s", record.MX.Preference, record.MX.Name)
+	case layers.DNSTypeSRV:
+		return fmt.Sprintf("%d %d %d %s", record.SRV.Priority, record.SRV.Weight, record.SRV.Port, record.SRV.Name)
+	case layers.DNSTypeSOA:
+		return fmt.Sprintf(
+			"%s %s %d %d %d %d %d",
+			record.SOA.MName, record.SOA.RName, record.SOA.Serial, record.SOA.Refresh, record.SOA.Retry, record.SOA.Expire, record.SOA.Minimum,
+		)
+	case layers.DNSTypeTXT:
+		txts := make([]string, 0, len(record.TXTs))
+		for _, txt := range record.TXTs {
+			txts = append(txts, strconv.Quote(string(txt)))
+		}
+		return strings.Join(txts, " ")
+	}
+	return ""
+}
@@ -0,0 +1,224 @@
+package pcap
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// packetSliceChunkBytes is the size of the chunks a packet slice is uploaded in
+const packetSliceChunkBytes = 1024 * 1024
+
+// packetSliceRequest holds the arguments of an `upload_packet_slice` command
+type packetSliceRequest struct {
+	sliceID    string
+	deviceName string
+	bpf        string
+	filter     string
+	start      time.Time
+	end        time.Time
+}
+
+// parsePacketSliceRequest validates the command's arguments.
+// The request is nil only without a slice id, since the error of an invalid request is uploaded under its slice id.
+func parsePacketSliceRequest(args *broadcast.UploadPacketSliceArgs) (*packetSliceRequest, error) {
+	if args == nil || args.SliceID == "" {
+		return nil, errors.New("missing slice id")
+	}
+	request := &packetSliceRequest{
+		sliceID:    args.SliceID,
+		deviceName: args.DeviceName,
+		bpf:        args.BPF,
+		filter:     args.Filter,
+		start:      args.Start,
+		end:        args.End,
+	}
+	if request.deviceName == "" {
+		return request, errors.New("missing interface name")
+	}
+	if !request.end.After(request.start) {
+		return request, errors.New("slice end is not after its start")
+	}
+	return request, nil
+}
+
+// packetSliceWriter uploads the pcap file written to it in chunks over a packet slice stream
+type packetSliceWriter struct {
+	stream  pbAgent.AgentService_UploadPacketSliceClient
+	sliceID string
+	buf     []byte
+}
+
+func (w *packetSliceWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= packetSliceChunkBytes {
+		err := w.stream.Send(&pbAgent.PacketSliceChunk{
+			SliceId: w.sliceID,
+			Data:    w.buf[:packetSliceChunkBytes],
+		})
+		if err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[packetSliceChunkBytes:]
+	}
+	return len(p), nil
+}
+
+// close sends the rest of the file with the result of the slice as the last chunk, and closes the stream
+func (w *packetSliceWriter) close(packetCount uint64, truncated bool, sliceErr error) error {
+	chunk := &pbAgent.PacketSliceChunk{
+		SliceId:     w.sliceID,
+		Data:        w.buf,
+		PacketCount: packetCount,
+		Truncated:   truncated,
+	}
+	if sliceErr != nil {
+		chunk.Error = sliceErr.Error()
+	}
+	err := w.stream.Send(chunk)
+	if err != nil {
+		return err
+	}
+	_, err = w.stream.CloseAndRecv()
+	return err
+}
+
+// uploadPacketSlice uploads the packets of a capture's ring buffer requested by an `upload_packet_slice` command.
+// The ring buffer of a capture that is no longer live is read from disk until it is pruned.
+// Slices that can't be read are still uploaded, with the error, so the server doesn't wait on them.
+// It returns why the slice couldn't be read or uploaded, for the command's result.
+func (m *pcapManager) uploadPacketSlice(args *broadcast.UploadPacketSliceArgs) error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.uploadPacketSlice")
+
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		logger.Error("no mTLS client available, cannot upload packet slice")
+		return errors.New("no mTLS client available, cannot upload packet slice")
+	}
+
+	request, sliceErr := parsePacketSliceRequest(args)
+	if request == nil {
+		logger.Error("invalid upload_packet_slice command", psLog.KeyError, sliceErr)
+		return sliceErr
+	}
+	logger = logger.With(psLog.KeySliceID, request.sliceID)
+
+	stream, err := client.UploadPacketSlice(m.ctx, grpc.UseCompressor(config.GetStreamCompressor()))
+	if err != nil {
+		logger.Error("failed to open packet slice stream", psLog.KeyError, err)
+		return fmt.Errorf("failed to open packet slice stream: %w", err)
+	}
+	writer := &packetSliceWriter{stream: stream, sliceID: request.sliceID}
+
+	var packetCount uint64
+	var truncated bool
+	if sliceErr == nil {
+		packetCount, truncated, sliceErr = m.writePacketSlice(writer, request)
+	}
+	if sliceErr != nil {
+		logger.Error(
+			"failed to read packet slice from ring buffer",
+			slog.String(psLog.KeyDeviceName, request.deviceName),
+			slog.String(psLog.KeyBPF, request.bpf),
+			psLog.KeyError, sliceErr,
+		)
+	}
+
+	err = writer.close(packetCount, truncated, sliceErr)
+	if err != nil {
+		logger.Error("failed to upload packet slice", psLog.KeyError, err)
+		return fmt.Errorf("failed to upload packet slice: %w", err)
+	}
+	if sliceErr != nil {
+		return sliceErr
+	}
+	logger.Info("uploaded packet slice", psLog.KeyPacketCount, packetCount)
+	return nil
+}
+
+// writePacketSlice writes the requested packets of the ring buffer of the capture to w as a pcap file
+func (m *pcapManager) writePacketSlice(w *packetSliceWriter, request *packetSliceRequest) (uint64, bool, error) {
+	var segments []*ringSegment
+	var err error
+	ring := m.ringBuffer(request.deviceName, request.bpf)
+	if ring != nil {
+		segments, err = ring.snapshot(request.start, request.end)
+	} else {
+		segments, err = readRingSegments(ringBufferDir(config.GetRingBufferDir(), request.deviceName, request.bpf))
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, false, fmt.Errorf("no ring buffer for BPF %q on interface %s", request.bpf, request.deviceName)
+		}
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return writePacketSlice(w, segments, request.start, request.end, request.filter, config.GetPacketSliceMaxBytes())
+}
+
+// ringBuffer returns the ring buffer of the live capture of the interface and BPF, nil if there is none
+func (m *pcapManager) ringBuffer(deviceName, bpf string) *ringBuffer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, capture := range m.ifaceNameToFiltersAssociations[deviceName] {
+		if capture.config.BPF == bpf {
+			return capture.ring
+		}
+	}
+	return nil
+}
+
+// pruneRingBuffers deletes the packets of ring buffers that are older than the max age,
+// including those of ring buffers whose capture was deleted or no longer has a ring buffer
+func (m *pcapManager) pruneRingBuffers(now time.Time) {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.pruneRingBuffers")
+
+	liveDirs := make(map[string]*ringBuffer)
+	m.mu.Lock()
+	for _, captures := range m.ifaceNameToFiltersAssociations {
+		for _, capture := range captures {
+			if capture.ring != nil {
+				liveDirs[capture.ring.dir] = capture.ring
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for _, ring := range liveDirs {
+		err := ring.prune(now)
+		if err != nil {
+			logger.Error("failed to prune ring buffer", psLog.KeyError, err)
+		}
+	}
+
+	baseDir := config.GetRingBufferDir()
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Error("failed to read ring buffer directory", psLog.KeyError, err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(baseDir, entry.Name())
+		if !entry.IsDir() || liveDirs[dir] != nil {
+			continue
+		}
+		err := pruneRingBufferDir(dir, config.GetRingBufferMaxAge(), now)
+		if err != nil {
+			logger.Error("failed to prune ring buffer of stopped capture", psLog.KeyError, err)
+		}
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/pcap"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/danielhoward314/packet-sentry/internal/config"
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
 )
 
 // packetCapture holds the config used to create a capture and the handle to the live capture
@@ -16,10 +21,20 @@ type packetCapture struct {
 	cancelFunc context.CancelFunc
 	config     *CaptureConfig
 	ctx        context.Context
+	dropped    atomic.Uint64
 	handle     *pcap.Handle
 	logger     *slog.Logger
 	packetOut  chan<- WrappedPacket
-	wg         *sync.WaitGroup
+	ring       *ringBuffer
+	// linkType is the libpcap name of the link type of the capture's interface, set once Start opened it
+	linkType  string
+	startedAt time.Time
+	// ended is set when the capture stopped on its own, because the interface stopped delivering packets
+	ended atomic.Bool
+	stats atomic.Pointer[pcap.Stats]
+	// totalDropped counts the same packets as dropped, without being reset by takeDropped
+	totalDropped atomic.Uint64
+	wg           *sync.WaitGroup
 }
 
 type WrappedPacket struct {
@@ -28,6 +43,8 @@ type WrappedPacket struct {
 	OSUniqueIdentifer string
 	Promiscuous       bool
 	SnapLen           int32
+	FlowMode          bool
+	HTTPMode          bool
 	PacketEventData   gopacket.Packet
 }
 
@@ -63,6 +80,7 @@ func (pc *packetCapture) Start() error {
 		pc.cleanup()
 		return err
 	}
+	pc.linkType = pcap.DatalinkValToName(int(pc.handle.LinkType()))
 
 	logger.Info("setting BPF")
 	err = pc.handle.SetBPFFilter(pc.config.BPF)
@@ -72,11 +90,35 @@ func (pc *packetCapture) Start() error {
 		return err
 	}
 
+	if pc.config.RingBuffer {
+		pc.ring, err = openRingBuffer(
+			ringBufferDir(config.GetRingBufferDir(), pc.config.DeviceName, pc.config.BPF),
+			pc.config,
+			config.GetRingBufferMaxBytes(),
+			config.GetRingBufferSegmentMaxBytes(),
+			config.GetRingBufferMaxAge(),
+			config.GetRingBufferSegmentMaxAge(),
+		)
+		if err != nil {
+			// the packet events of the capture don't depend on the ring buffer
+			logger.Error("error opening ring buffer, capturing without it", psLog.KeyError, err)
+			pc.ring = nil
+		}
+	}
+
+	pc.startedAt = time.Now()
 	go func() {
 		// will be called when context is canceled and we exit this goroutine
 		defer pc.cleanup()
+		statsTicker := time.NewTicker(config.GetCaptureStatsInterval())
+		defer statsTicker.Stop()
 		packetSource := gopacket.NewPacketSource(pc.handle, pc.handle.LinkType())
+		// decode TCP payloads by port, e.g. as TLS on 443, which gopacket otherwise leaves as a raw payload
+		packetSource.DecodeOptions.DecodeStreamsAsDatagrams = true
 		packetChan := packetSource.Packets()
+		linkType := pc.handle.LinkType()
+		// ringFailing is set while writes to the ring buffer fail, so a full disk is logged once rather than per packet
+		ringFailing := false
 		logger.Info("packet source created, entering capture loop")
 
 		for {
@@ -86,14 +128,25 @@ func (pc *packetCapture) Start() error {
 			case packet, ok := <-packetChan:
 				if !ok {
 					logger.Info("packet channel closed")
+					pc.ended.Store(true)
 					return
 				}
 
+				if pc.ring != nil {
+					err := pc.ring.write(packet.Metadata().CaptureInfo, packet.Data(), linkType)
+					if err != nil && !ringFailing {
+						logger.Error("error writing packet to ring buffer", psLog.KeyError, err)
+					}
+					ringFailing = err != nil
+				}
+
 				wrapped := WrappedPacket{
 					Bpf:             pc.config.BPF,
 					DeviceName:      pc.config.DeviceName,
 					Promiscuous:     pc.config.Promiscuous,
 					SnapLen:         pc.config.SnapLen,
+					FlowMode:        pc.config.FlowMode,
+					HTTPMode:        pc.config.HTTPMode,
 					PacketEventData: packet,
 				}
 
@@ -104,9 +157,18 @@ func (pc *packetCapture) Start() error {
 				select {
 				case pc.packetOut <- wrapped:
 				default:
-					// TODO: replace with a dropped packet log that gets sent, then wiped, on a 24-hour interval
-					logger.Warn("packet channel full, dropping packet", psLog.KeyDroppedPacket, packet.String())
+					// counted rather than logged per packet, the pcap manager reports the total periodically
+					pc.dropped.Add(1)
+					pc.totalDropped.Add(1)
+				}
+
+			case <-statsTicker.C:
+				stats, err := pc.handle.Stats()
+				if err != nil {
+					logger.Error("error reading capture stats", psLog.KeyError, err)
+					continue
 				}
+				pc.stats.Store(stats)
 
 			case <-pc.ctx.Done():
 				logger.Info("context canceled, stopping packet capture")
@@ -119,6 +181,27 @@ func (pc *packetCapture) Start() error {
 	return nil
 }
 
+// takeDropped returns the number of packets dropped because the packet channel was full since the last call, and resets it
+func (pc *packetCapture) takeDropped() uint64 {
+	return pc.dropped.Swap(0)
+}
+
+// captureStats returns the packet counters of the capture since it started, as of its last stats read
+func (pc *packetCapture) captureStats() *pbAgent.CaptureStats {
+	captureStats := &pbAgent.CaptureStats{
+		Bpf:            pc.config.BPF,
+		DeviceName:     pc.config.DeviceName,
+		DroppedByAgent: pc.totalDropped.Load(),
+		StartedAt:      timestamppb.New(pc.startedAt),
+	}
+	if stats := pc.stats.Load(); stats != nil {
+		captureStats.Received = uint64(stats.PacketsReceived)
+		captureStats.DroppedByKernel = uint64(stats.PacketsDropped)
+		captureStats.DroppedByInterface = uint64(stats.PacketsIfDropped)
+	}
+	return captureStats
+}
+
 // Stop terminates the packet capture process
 func (pc *packetCapture) Stop() {
 	logger := pc.logger.With(psLog.KeyFunction, "packetCapture.Stop")
@@ -128,6 +211,12 @@ func (pc *packetCapture) Stop() {
 
 func (pc *packetCapture) cleanup() {
 	logger := pc.logger.With(psLog.KeyFunction, "packetCapture.cleanup")
+	if pc.ring != nil {
+		err := pc.ring.close()
+		if err != nil {
+			logger.Error("error closing ring buffer", psLog.KeyError, err)
+		}
+	}
 	if pc.handle != nil {
 		logger.Info("closing packet capture handle")
 		pc.handle.Close()
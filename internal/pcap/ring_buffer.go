@@ -0,0 +1,467 @@
+package pcap
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+	"github.com/google/gopacket/pcapgo"
+)
+
+const (
+	ringSegmentExt = ".pcapng"
+	// ringPacketBlockHeaderLen is the length of the header and trailer of a pcapng enhanced packet block without options
+	ringPacketBlockHeaderLen = 32
+	// slicePacketHeaderLen is the length of the record header of each packet of a classic pcap file
+	slicePacketHeaderLen = 16
+	// sliceFileHeaderLen is the length of the file header of a classic pcap file
+	sliceFileHeaderLen = 24
+	// sliceDefaultSnapLen is the snap length of a slice whose ring buffer didn't record one
+	sliceDefaultSnapLen = 262144
+)
+
+// ringSegment is one pcapng file of a ring buffer, named after the unix nanoseconds of its first packet
+type ringSegment struct {
+	path string
+	// start and end are the timestamps of the first and last packets of the segment
+	start time.Time
+	end   time.Time
+	size  int64
+}
+
+// ringBuffer keeps the raw packets of one capture as rotating pcapng files,
+// deleting the oldest files beyond a max total size or once all of their packets are older than a max age.
+// The capture's goroutine writes packets while the pcap manager reads slices and prunes, so it is safe for concurrent use.
+type ringBuffer struct {
+	mu              sync.Mutex
+	dir             string
+	deviceName      string
+	bpf             string
+	snapLen         int32
+	maxBytes        int64
+	maxAge          time.Duration
+	segmentMaxBytes int64
+	segmentMaxAge   time.Duration
+	segments        []*ringSegment
+	totalBytes      int64
+	file            *os.File
+	fileWriter      *countingWriter
+	writer          *pcapgo.NgWriter
+	linkType        layers.LinkType
+}
+
+// ringBufferDir returns the directory of the ring buffer of the capture of the given interface and BPF.
+// The name is a hash since interface names, e.g. on Windows, and BPFs aren't valid file names.
+func ringBufferDir(baseDir, deviceName, bpf string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(deviceName))
+	hash.Write([]byte{0})
+	hash.Write([]byte(bpf))
+	return filepath.Join(baseDir, fmt.Sprintf("%016x", hash.Sum64()))
+}
+
+// openRingBuffer opens the ring buffer in the given directory, keeping the segments left on disk by a previous capture
+func openRingBuffer(dir string, captureConfig *CaptureConfig, maxBytes, segmentMaxBytes int64, maxAge, segmentMaxAge time.Duration) (*ringBuffer, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := readRingSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rb := &ringBuffer{
+		dir:             dir,
+		deviceName:      captureConfig.DeviceName,
+		bpf:             captureConfig.BPF,
+		snapLen:         captureConfig.SnapLen,
+		maxBytes:        maxBytes,
+		maxAge:          maxAge,
+		segmentMaxBytes: segmentMaxBytes,
+		segmentMaxAge:   segmentMaxAge,
+		segments:        segments,
+	}
+	for _, segment := range segments {
+		rb.totalBytes += segment.size
+	}
+	return rb, nil
+}
+
+// readRingSegments returns the segments of the ring buffer in the given directory, oldest first.
+// The end of each segment is the modification time of its file, which is when its last packet was written.
+func readRingSegments(dir string) ([]*ringSegment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]*ringSegment, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ringSegmentExt) {
+			continue
+		}
+		startNanos, err := strconv.ParseInt(strings.TrimSuffix(name, ringSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		segments = append(segments, &ringSegment{
+			path:  filepath.Join(dir, name),
+			start: time.Unix(0, startNanos),
+			end:   info.ModTime(),
+			size:  info.Size(),
+		})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].start.Before(segments[j].start)
+	})
+	return segments, nil
+}
+
+// write appends the packet to the newest segment, starting a new one when it is full or old,
+// and deletes the oldest segments beyond the max size
+func (rb *ringBuffer) write(ci gopacket.CaptureInfo, data []byte, linkType layers.LinkType) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	tail := rb.tail()
+	if rb.writer == nil || linkType != rb.linkType || tail.size >= rb.segmentMaxBytes || ci.Timestamp.Sub(tail.start) >= rb.segmentMaxAge {
+		err := rb.rotate(ci.Timestamp, linkType)
+		if err != nil {
+			return err
+		}
+		tail = rb.tail()
+	}
+
+	// the segment's only interface is index 0, whatever the index of the captured interface
+	ci.InterfaceIndex = 0
+	err := rb.writer.WritePacket(ci, data)
+	if err != nil {
+		return err
+	}
+	blockLen := ringPacketBlockLen(len(data))
+	tail.size += blockLen
+	rb.totalBytes += blockLen
+	if ci.Timestamp.After(tail.end) {
+		tail.end = ci.Timestamp
+	}
+
+	for len(rb.segments) > 1 && rb.totalBytes > rb.maxBytes {
+		err = rb.removeOldest()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ringPacketBlockLen returns the size of the pcapng block of a packet of the given length, padded to 32 bits
+func ringPacketBlockLen(dataLen int) int64 {
+	return int64(ringPacketBlockHeaderLen + dataLen + (4-dataLen%4)%4)
+}
+
+// rotate closes the newest segment and starts a new one with the given first packet timestamp.
+// The caller must hold mu.
+func (rb *ringBuffer) rotate(start time.Time, linkType layers.LinkType) error {
+	err := rb.closeWriter()
+	if err != nil {
+		return err
+	}
+
+	// keep the file names in order even if the clock went back
+	if tail := rb.tail(); tail != nil && !start.After(tail.start) {
+		start = tail.start.Add(time.Nanosecond)
+	}
+	path := filepath.Join(rb.dir, strconv.FormatInt(start.UnixNano(), 10)+ringSegmentExt)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	fileWriter := &countingWriter{w: f}
+	writer, err := pcapgo.NewNgWriterInterface(
+		fileWriter,
+		pcapgo.NgInterface{
+			Name:                rb.deviceName,
+			Filter:              rb.bpf,
+			OS:                  runtime.GOOS,
+			LinkType:            linkType,
+			SnapLength:          uint32(rb.snapLen),
+			TimestampResolution: 9,
+		},
+		pcapgo.NgWriterOptions{
+			SectionInfo: pcapgo.NgSectionInfo{
+				Hardware:    runtime.GOARCH,
+				OS:          runtime.GOOS,
+				Application: "packet-sentry",
+			},
+		},
+	)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	rb.file = f
+	rb.fileWriter = fileWriter
+	rb.writer = writer
+	rb.linkType = linkType
+	rb.segments = append(rb.segments, &ringSegment{path: path, start: start, end: start, size: fileWriter.n})
+	rb.totalBytes += fileWriter.n
+	return nil
+}
+
+// prune deletes the segments whose packets are all older than the max age
+func (rb *ringBuffer) prune(now time.Time) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	cutoff := now.Add(-rb.maxAge)
+	for len(rb.segments) > 0 && rb.segments[0].end.Before(cutoff) {
+		if len(rb.segments) == 1 {
+			// the next packet starts a new segment
+			err := rb.closeWriter()
+			if err != nil {
+				return err
+			}
+		}
+		err := rb.removeOldest()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot flushes the newest segment and returns copies of the segments with packets in the given time range,
+// sized to what is on disk so they can be read while packets are still being written
+func (rb *ringBuffer) snapshot(start, end time.Time) ([]*ringSegment, error) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if rb.writer != nil {
+		err := rb.writer.Flush()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	segments := make([]*ringSegment, 0, len(rb.segments))
+	for i, segment := range rb.segments {
+		if segment.start.After(end) || segment.end.Before(start) {
+			continue
+		}
+		segmentCopy := *segment
+		if i == len(rb.segments)-1 && rb.writer != nil {
+			segmentCopy.size = rb.fileWriter.n
+		}
+		segments = append(segments, &segmentCopy)
+	}
+	return segments, nil
+}
+
+// close closes the newest segment, leaving all segments on disk for slices and the next capture of the same interface and BPF
+func (rb *ringBuffer) close() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.closeWriter()
+}
+
+// tail returns the newest segment, nil if there are none.
+// The caller must hold mu.
+func (rb *ringBuffer) tail() *ringSegment {
+	if len(rb.segments) == 0 {
+		return nil
+	}
+	return rb.segments[len(rb.segments)-1]
+}
+
+// closeWriter flushes and closes the file of the newest segment.
+// The caller must hold mu.
+func (rb *ringBuffer) closeWriter() error {
+	if rb.writer == nil {
+		return nil
+	}
+	flushErr := rb.writer.Flush()
+	closeErr := rb.file.Close()
+	rb.file = nil
+	rb.fileWriter = nil
+	rb.writer = nil
+	return errors.Join(flushErr, closeErr)
+}
+
+// removeOldest deletes the oldest segment.
+// The caller must hold mu.
+func (rb *ringBuffer) removeOldest() error {
+	oldest := rb.segments[0]
+	rb.segments = rb.segments[1:]
+	rb.totalBytes -= oldest.size
+	err := os.Remove(oldest.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// pruneRingBufferDir deletes the segments of a ring buffer without a live capture once they are older than the max age,
+// and the directory itself once it is empty
+func pruneRingBufferDir(dir string, maxAge time.Duration, now time.Time) error {
+	segments, err := readRingSegments(dir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	cutoff := now.Add(-maxAge)
+	remaining := len(segments)
+	for _, segment := range segments {
+		if !segment.end.Before(cutoff) {
+			continue
+		}
+		err := os.Remove(segment.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		remaining--
+	}
+	if remaining == 0 {
+		// fails if anything but segments is left in the directory, which is then kept
+		_ = os.Remove(dir)
+	}
+	return errors.Join(errs...)
+}
+
+// writePacketSlice writes the packets of the segments captured in the given time range, and matching the filter if any,
+// to w as a classic pcap file, the format most tools read.
+// Packets that would take the file past maxBytes are left out and the slice is reported as truncated.
+// It returns the number of packets written.
+func writePacketSlice(w io.Writer, segments []*ringSegment, start, end time.Time, filter string, maxBytes int64) (uint64, bool, error) {
+	var sliceWriter *pcapgo.Writer
+	var linkType layers.LinkType
+	var bpf *pcap.BPF
+	var packets uint64
+	written := int64(0)
+
+	for _, segment := range segments {
+		f, err := os.Open(segment.path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// pruned since the slice started
+				continue
+			}
+			return packets, false, err
+		}
+
+		truncated, err := func() (bool, error) {
+			defer f.Close()
+
+			reader, err := pcapgo.NewNgReader(io.LimitReader(f, segment.size), pcapgo.DefaultNgReaderOptions)
+			if err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+					// a segment cut off before its first packet, e.g. by a crash
+					return false, nil
+				}
+				return false, fmt.Errorf("failed to read ring buffer segment %s: %w", segment.path, err)
+			}
+
+			if sliceWriter == nil {
+				linkType = reader.LinkType()
+				snapLen := sliceDefaultSnapLen
+				if intf, err := reader.Interface(0); err == nil && intf.SnapLength > 0 {
+					snapLen = int(intf.SnapLength)
+				}
+				if filter != "" {
+					bpf, err = pcap.NewBPF(linkType, snapLen, filter)
+					if err != nil {
+						return false, fmt.Errorf("invalid slice filter: %w", err)
+					}
+				}
+				sliceWriter = pcapgo.NewWriterNanos(w)
+				err = sliceWriter.WriteFileHeader(uint32(snapLen), linkType)
+				if err != nil {
+					return false, err
+				}
+				written += sliceFileHeaderLen
+			} else if reader.LinkType() != linkType {
+				// a pcap file has a single link type, so segments of another one are left out
+				return false, nil
+			}
+
+			for {
+				data, ci, err := reader.ReadPacketData()
+				if err != nil {
+					if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+						return false, nil
+					}
+					return false, fmt.Errorf("failed to read ring buffer segment %s: %w", segment.path, err)
+				}
+				if ci.Timestamp.Before(start) || ci.Timestamp.After(end) {
+					continue
+				}
+				if bpf != nil && !bpf.Matches(ci, data) {
+					continue
+				}
+				recordLen := int64(slicePacketHeaderLen + len(data))
+				if written+recordLen > maxBytes {
+					return true, nil
+				}
+				err = sliceWriter.WritePacket(ci, data)
+				if err != nil {
+					return false, err
+				}
+				written += recordLen
+				packets++
+			}
+		}()
+		if err != nil || truncated {
+			return packets, truncated, err
+		}
+	}
+
+	if sliceWriter == nil {
+		// no packets in the range, still a valid pcap file
+		err := pcapgo.NewWriterNanos(w).WriteFileHeader(sliceDefaultSnapLen, layers.LinkTypeEthernet)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	return packets, false, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
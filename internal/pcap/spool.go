@@ -0,0 +1,367 @@
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// errCorruptSpoolRecord marks a record whose length prefix is intact but whose content can't be unmarshaled
+var errCorruptSpoolRecord = errors.New("corrupt spooled packet event")
+
+const (
+	spoolSegmentExt = ".spool"
+	// spoolRecordHeaderLen is the length of the big-endian uint32 size prefix of each spooled record
+	spoolRecordHeaderLen = 4
+)
+
+// spoolSegment is one append-only file of the spool
+type spoolSegment struct {
+	seq     uint64
+	path    string
+	size    int64
+	records uint64
+}
+
+// spool is a bounded, on-disk write-ahead log of packet events that couldn't be sent to the server.
+// Events are appended to the newest segment and replayed in order, in batches, from the oldest one.
+// When appending would exceed the max size, the oldest segments are deleted and their events counted as lost.
+// The replay position is only kept in memory, so events of a partially replayed segment are sent again after a restart.
+// It is not safe for concurrent use; the pcap manager serializes its use with streamMu.
+type spool struct {
+	dir             string
+	maxBytes        int64
+	segmentMaxBytes int64
+	segments        []*spoolSegment
+	totalBytes      int64
+	writer          *os.File
+	headOffset      int64
+	headReplayed    uint64
+	// dropped counts the spooled events lost to spool overflow or corrupt records since the last takeDropped
+	dropped uint64
+}
+
+// openSpool opens the spool in the given directory, recovering the segments left on disk by a previous run
+func openSpool(dir string, maxBytes, segmentMaxBytes int64) (*spool, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spool{
+		dir:             dir,
+		maxBytes:        maxBytes,
+		segmentMaxBytes: segmentMaxBytes,
+		segments:        make([]*spoolSegment, 0),
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segment, err := recoverSpoolSegment(filepath.Join(dir, name), seq)
+		if err != nil {
+			return nil, err
+		}
+		if segment.records == 0 {
+			_ = os.Remove(segment.path)
+			continue
+		}
+		s.segments = append(s.segments, segment)
+		s.totalBytes += segment.size
+	}
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].seq < s.segments[j].seq
+	})
+
+	return s, nil
+}
+
+// recoverSpoolSegment counts the complete records of a segment file,
+// truncating a partially written record left behind by a crash mid-append
+func recoverSpoolSegment(path string, seq uint64) (*spoolSegment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	segment := &spoolSegment{seq: seq, path: path}
+	reader := bufio.NewReader(f)
+	for {
+		recordLen, err := readSpoolRecord(reader, nil)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, err
+		}
+		segment.size += recordLen
+		segment.records++
+	}
+
+	err = f.Truncate(segment.size)
+	if err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// readSpoolRecord reads the next record into packetEvent and returns its length on disk.
+// A nil packetEvent skips over the record without unmarshalling it.
+// If the content can't be unmarshaled, the length is still returned, with an error wrapping errCorruptSpoolRecord.
+func readSpoolRecord(reader *bufio.Reader, packetEvent *pbAgent.PacketEvent) (int64, error) {
+	header := make([]byte, spoolRecordHeaderLen)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return 0, err
+	}
+	content := make([]byte, binary.BigEndian.Uint32(header))
+	_, err = io.ReadFull(reader, content)
+	if err != nil {
+		return 0, err
+	}
+	if packetEvent != nil {
+		err = proto.Unmarshal(content, packetEvent)
+		if err != nil {
+			return int64(spoolRecordHeaderLen + len(content)), fmt.Errorf("%w: %w", errCorruptSpoolRecord, err)
+		}
+	}
+	return int64(spoolRecordHeaderLen + len(content)), nil
+}
+
+// empty reports whether there are no spooled events left to replay
+func (s *spool) empty() bool {
+	return len(s.segments) == 0
+}
+
+// append writes the packet event to the newest segment, making room by dropping the oldest segments if the spool is full
+func (s *spool) append(packetEvent *pbAgent.PacketEvent) error {
+	content, err := proto.Marshal(packetEvent)
+	if err != nil {
+		return err
+	}
+	recordLen := int64(spoolRecordHeaderLen + len(content))
+	if recordLen > s.segmentMaxBytes {
+		// the caller counts the events it fails to append, dropped only counts spooled events lost to overflow
+		return fmt.Errorf("packet event of %d bytes exceeds max spool segment size", recordLen)
+	}
+
+	for len(s.segments) > 0 && s.totalBytes+recordLen > s.maxBytes {
+		err = s.dropOldestSegment()
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.writer == nil || s.tail().size+recordLen > s.segmentMaxBytes {
+		err = s.rotate()
+		if err != nil {
+			return err
+		}
+	}
+
+	record := make([]byte, spoolRecordHeaderLen, recordLen)
+	binary.BigEndian.PutUint32(record, uint32(len(content)))
+	record = append(record, content...)
+	_, err = s.writer.Write(record)
+	if err != nil {
+		return err
+	}
+
+	tail := s.tail()
+	tail.size += recordLen
+	tail.records++
+	s.totalBytes += recordLen
+	return nil
+}
+
+// replay sends up to maxEvents spooled events of the oldest segment as one batch, in order, keeping the batch under maxBytes
+// unless its first event alone is larger, and deletes the segment once all of its events are sent.
+// On a send error, the events of the batch are kept for the next replay.
+// Records that can't be unmarshaled are skipped, and if a record's length prefix is corrupt or the segment ends early,
+// the rest of the segment is dropped, so a bad record never blocks the events behind it. Either way the lost events count as dropped.
+// It returns the number of events sent.
+func (s *spool) replay(maxEvents int, maxBytes int, send func([]*pbAgent.PacketEvent) error) (int, error) {
+	if s.empty() {
+		return 0, nil
+	}
+
+	head := s.segments[0]
+	if len(s.segments) == 1 && s.writer != nil {
+		// stop appending to the segment being replayed, new events go to the next one
+		err := s.closeWriter()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	f, err := os.Open(head.path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	_, err = f.Seek(s.headOffset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	reader := bufio.NewReader(f)
+	packetEvents := make([]*pbAgent.PacketEvent, 0, maxEvents)
+	var batchLen int64
+	var batchBytes int
+	var skipped uint64
+	truncated := false
+	for s.headReplayed+uint64(len(packetEvents))+skipped < head.records && len(packetEvents) < maxEvents {
+		header, err := reader.Peek(spoolRecordHeaderLen)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				truncated = true
+				break
+			}
+			return 0, err
+		}
+		eventBytes := int(binary.BigEndian.Uint32(header))
+		if s.headOffset+batchLen+int64(spoolRecordHeaderLen+eventBytes) > head.size {
+			// the length prefix points past the end of the segment, so the records behind it can't be found
+			truncated = true
+			break
+		}
+		if len(packetEvents) > 0 && batchBytes+eventBytes > maxBytes {
+			break
+		}
+
+		packetEvent := &pbAgent.PacketEvent{}
+		recordLen, err := readSpoolRecord(reader, packetEvent)
+		if err != nil {
+			if errors.Is(err, errCorruptSpoolRecord) {
+				batchLen += recordLen
+				skipped++
+				continue
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				truncated = true
+				break
+			}
+			return 0, err
+		}
+		batchBytes += eventBytes
+		packetEvents = append(packetEvents, packetEvent)
+		batchLen += recordLen
+	}
+
+	if len(packetEvents) > 0 {
+		err = send(packetEvents)
+		if err != nil {
+			return 0, err
+		}
+	}
+	s.headOffset += batchLen
+	s.headReplayed += uint64(len(packetEvents)) + skipped
+	s.dropped += skipped
+	if truncated {
+		s.dropped += head.records - s.headReplayed
+		s.headReplayed = head.records
+	}
+
+	if s.headReplayed == head.records {
+		err = s.removeHead()
+		if err != nil {
+			return len(packetEvents), err
+		}
+	}
+	return len(packetEvents), nil
+}
+
+// takeDropped returns the number of events lost to spool overflow since the last call, and resets it
+func (s *spool) takeDropped() uint64 {
+	dropped := s.dropped
+	s.dropped = 0
+	return dropped
+}
+
+// close closes the segment being appended to, leaving all segments on disk for the next run
+func (s *spool) close() error {
+	return s.closeWriter()
+}
+
+func (s *spool) tail() *spoolSegment {
+	return s.segments[len(s.segments)-1]
+}
+
+// rotate starts a new segment for appends
+func (s *spool) rotate() error {
+	err := s.closeWriter()
+	if err != nil {
+		return err
+	}
+
+	var seq uint64
+	if len(s.segments) > 0 {
+		seq = s.tail().seq + 1
+	}
+	path := filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolSegmentExt))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	s.writer = f
+	s.segments = append(s.segments, &spoolSegment{seq: seq, path: path})
+	return nil
+}
+
+func (s *spool) closeWriter() error {
+	if s.writer == nil {
+		return nil
+	}
+	err := s.writer.Close()
+	s.writer = nil
+	return err
+}
+
+// dropOldestSegment deletes the oldest segment, counting its events that were not replayed yet as lost
+func (s *spool) dropOldestSegment() error {
+	head := s.segments[0]
+	s.dropped += head.records - s.headReplayed
+	return s.removeHead()
+}
+
+func (s *spool) removeHead() error {
+	head := s.segments[0]
+	if len(s.segments) == 1 {
+		err := s.closeWriter()
+		if err != nil {
+			return err
+		}
+	}
+	s.segments = s.segments[1:]
+	s.totalBytes -= head.size
+	s.headOffset = 0
+	s.headReplayed = 0
+	err := os.Remove(head.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package pcap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	_ "github.com/danielhoward314/packet-sentry/internal/grpczstd"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// stream states exposed in the agent logs and health reporting
+const (
+	// StreamStateDisconnected means no mTLS client has been published yet, so no stream was opened
+	StreamStateDisconnected = "disconnected"
+	// StreamStateConnected means the packet event stream is open
+	StreamStateConnected = "connected"
+	// StreamStateReconnecting means the stream broke with a retryable error and a reconnect is scheduled
+	StreamStateReconnecting = "reconnecting"
+	// StreamStateFailed means the stream broke with a non-retryable error and waits on a new mTLS client
+	StreamStateFailed = "failed"
+)
+
+// isRetryableStreamCode classifies the gRPC status codes a stream can break with.
+// Retryable codes are transient server or network conditions, e.g. a server restart.
+// The rest won't change by retrying with the same client, e.g. a rejected client certificate.
+func isRetryableStreamCode(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable,
+		codes.ResourceExhausted,
+		codes.Aborted,
+		codes.DeadlineExceeded,
+		codes.Internal,
+		codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// reconnectBackoff returns the jittered exponential delay before the given reconnect attempt
+func reconnectBackoff(attempt int) time.Duration {
+	delay := config.GetStreamReconnectMaxDelay()
+	if attempt < 32 {
+		exponential := config.GetStreamReconnectBaseDelay() << attempt
+		if exponential > 0 && exponential < delay {
+			delay = exponential
+		}
+	}
+	// jitter between half and all of the delay, so agents don't reconnect in lockstep after a server restart
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// streamSendError resolves the error of a failed Send on the packet stream.
+// Send returns io.EOF when the server ended the stream, the status it ended it with comes from CloseAndRecv.
+func streamSendError(stream pbAgent.AgentService_SendPacketEventBatchClient, err error) error {
+	if !errors.Is(err, io.EOF) {
+		return err
+	}
+	_, err = stream.CloseAndRecv()
+	if err == nil {
+		return status.Error(codes.Unavailable, "stream closed by server")
+	}
+	return err
+}
+
+// StreamHealth returns the current state of the packet event stream
+func (m *pcapManager) StreamHealth() StreamHealth {
+	m.streamHealthMu.RLock()
+	defer m.streamHealthMu.RUnlock()
+	return m.streamHealth
+}
+
+func (m *pcapManager) setStreamHealth(state string, err error) {
+	m.streamHealthMu.Lock()
+	defer m.streamHealthMu.Unlock()
+
+	if m.streamHealth.State != state {
+		m.streamHealth.Since = time.Now()
+	}
+	m.streamHealth.State = state
+	m.streamHealth.ReconnectAttempts = m.reconnectAttempt
+	m.streamHealth.LastError = ""
+	if err != nil {
+		m.streamHealth.LastError = err.Error()
+	}
+}
+
+// openStreams cancels any current streams and opens new packet event batch and flow record streams with the client.
+// Packet event batches are compressed on the wire with the configured gRPC compressor.
+// The caller must hold streamMu.
+func (m *pcapManager) openStreams(client pbAgent.AgentServiceClient) error {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.openStreams")
+
+	// If there's an existing stream, cancel it (which should cause CloseSend)
+	if m.currentStreamCancel != nil {
+		m.currentStreamCancel()
+	}
+	// The stream needs its own context and cancel func so that it is distinct each time
+	// and can be canceled any time a client update is received or the stream is reopened
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.currentStreamCancel = cancel
+
+	stream, err := client.SendPacketEventBatch(ctx, grpc.UseCompressor(config.GetStreamCompressor()))
+	if err != nil {
+		m.packetStreamClient = nil
+		m.flowStreamClient = nil
+		return err
+	}
+	m.packetStreamClient = stream
+	// the flow stream shares the packet stream's context, so both are canceled together
+	flowStream, err := client.SendFlowRecord(ctx)
+	if err != nil {
+		logger.Error("failed to open flow record stream", psLog.KeyError, err)
+		m.flowStreamClient = nil
+	} else {
+		m.flowStreamClient = flowStream
+	}
+
+	// the backoff isn't reset until the stream has stayed up for a while, see handleStreamError
+	m.streamOpenedAt = time.Now()
+	m.setStreamHealth(StreamStateConnected, nil)
+	return nil
+}
+
+// reconnectStreams reopens the streams with the current mTLS client once a scheduled reconnect is due
+func (m *pcapManager) reconnectStreams() {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.reconnectStreams")
+
+	m.agentMTLSClientMu.RLock()
+	client := m.agentMTLSClient
+	m.agentMTLSClientMu.RUnlock()
+
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+
+	m.reconnectC = nil
+	if client == nil {
+		m.setStreamHealth(StreamStateDisconnected, nil)
+		return
+	}
+
+	logger.Info("reopening packet stream", psLog.KeyReconnectAttempt, m.reconnectAttempt)
+	err := m.openStreams(client)
+	if err != nil {
+		m.handleStreamError(err)
+		return
+	}
+	logger.Info("packet stream reconnected", psLog.KeyStreamState, StreamStateConnected)
+}
+
+// handleStreamError tears down the broken streams, scheduling a reconnect with backoff if the error is retryable.
+// The caller must hold streamMu.
+func (m *pcapManager) handleStreamError(err error) {
+	logger := m.logger.With(psLog.KeyFunction, "PCapManager.handleStreamError")
+
+	if m.ctx.Err() != nil {
+		// shutting down, the stream broke because its context was canceled
+		return
+	}
+
+	// a stream that stayed up for a while broke for a new reason, so the backoff starts over,
+	// while one that breaks right after opening keeps backing off
+	if m.packetStreamClient != nil && time.Since(m.streamOpenedAt) > config.GetStreamReconnectMaxDelay() {
+		m.reconnectAttempt = 0
+	}
+	m.packetStreamClient = nil
+	m.flowStreamClient = nil
+	if m.currentStreamCancel != nil {
+		m.currentStreamCancel()
+		m.currentStreamCancel = nil
+	}
+
+	code := status.Code(err)
+	if !isRetryableStreamCode(code) {
+		m.reconnectC = nil
+		m.setStreamHealth(StreamStateFailed, err)
+		logger.Error(
+			"packet stream failed with non-retryable error, waiting on a new mTLS client",
+			psLog.KeyStreamState, StreamStateFailed,
+			psLog.KeyGRPCCode, code.String(),
+			psLog.KeyError, err,
+		)
+		return
+	}
+
+	if m.reconnectC != nil {
+		// a reconnect is already scheduled
+		return
+	}
+	delay := reconnectBackoff(m.reconnectAttempt)
+	m.reconnectAttempt++
+	m.reconnectC = time.After(delay)
+	m.setStreamHealth(StreamStateReconnecting, err)
+	logger.Warn(
+		"packet stream broken, scheduling reconnect",
+		psLog.KeyStreamState, StreamStateReconnecting,
+		psLog.KeyReconnectAttempt, m.reconnectAttempt,
+		psLog.KeyBackoff, delay.String(),
+		psLog.KeyGRPCCode, code.String(),
+		psLog.KeyError, err,
+	)
+}
+
+// sendBatchOnPacketStream sends the packet events as one batch over the live stream, handing a failed send to handleStreamError.
+// The caller must hold streamMu.
+func (m *pcapManager) sendBatchOnPacketStream(packetEvents []*pbAgent.PacketEvent) error {
+	stream := m.packetStreamClient
+	if stream == nil {
+		return fmt.Errorf("no packet stream available")
+	}
+	err := stream.Send(&pbAgent.PacketEventBatch{Events: packetEvents})
+	if err != nil {
+		err = streamSendError(stream, err)
+		m.handleStreamError(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package pcap
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket/layers"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+const (
+	tlsRecordHeaderLen        = 5
+	tlsHandshakeHeaderLen     = 4
+	tlsHandshakeClientHello   = 1
+	tlsHandshakeServerHello   = 2
+	tlsExtServerName          = 0
+	tlsExtSupportedGroups     = 10
+	tlsExtECPointFormats      = 11
+	tlsExtSignatureAlgorithms = 13
+	tlsExtALPN                = 16
+	tlsExtSupportedVersions   = 43
+	tlsServerNameTypeHostName = 0
+	// ja4EmptyHash is the JA4 hash part used when there is nothing to hash
+	ja4EmptyHash = "000000000000"
+)
+
+// tlsPort is the TCP port whose payloads are parsed for TLS hellos, the one gopacket decodes as TLS
+const tlsPort = layers.TCPPort(443)
+
+var errTLSTruncated = errors.New("truncated tls handshake")
+
+// tlsReader reads the big-endian, length-prefixed fields of TLS handshake messages
+type tlsReader struct {
+	data []byte
+	err  error
+}
+
+func (r *tlsReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.err = errTLSTruncated
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *tlsReader) uint8() uint8 {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *tlsReader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+// vector reads a vector with a length prefix of prefixLen bytes
+func (r *tlsReader) vector(prefixLen int) *tlsReader {
+	var n int
+	switch prefixLen {
+	case 1:
+		n = int(r.uint8())
+	case 2:
+		n = int(r.uint16())
+	case 3:
+		b := r.bytes(3)
+		if b != nil {
+			n = int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		}
+	}
+	return &tlsReader{data: r.bytes(n), err: r.err}
+}
+
+// uint16s reads the rest of the data as a list of uint16 values
+func (r *tlsReader) uint16s() []uint32 {
+	values := make([]uint32, 0, len(r.data)/2)
+	for len(r.data) >= 2 && r.err == nil {
+		values = append(values, uint32(r.uint16()))
+	}
+	return values
+}
+
+// isGREASE reports whether the value is one of the reserved GREASE values of RFC 8701,
+// which clients send at random to keep servers tolerant of unknown values and so are left out of fingerprints
+func isGREASE(value uint32) bool {
+	return value&0x0f0f == 0x0a0a && value>>8 == value&0xff
+}
+
+func withoutGREASE(values []uint32) []uint32 {
+	filtered := make([]uint32, 0, len(values))
+	for _, value := range values {
+		if !isGREASE(value) {
+			filtered = append(filtered, value)
+		}
+	}
+	return filtered
+}
+
+// parseTLSHellos parses the ClientHello and ServerHello handshake messages of the plaintext handshake records of a TCP payload.
+// Every complete record of the payload is read, rather than gopacket's TLS layer, which only keeps the last record in its contents
+// and isn't decoded at all when the segment ends in a partial record, e.g. a ServerHello followed by the start of a long Certificate.
+// A hello split across packets isn't reassembled, so it is only parsed if it fits in this packet.
+func parseTLSHellos(payload []byte) (*pbAgent.TLSClientHello, *pbAgent.TLSServerHello) {
+	// concatenate the handshake records, since a handshake message may be fragmented across records
+	var handshake []byte
+	records := payload
+	for len(records) >= tlsRecordHeaderLen {
+		recordLen := int(binary.BigEndian.Uint16(records[3:5]))
+		if len(records) < tlsRecordHeaderLen+recordLen {
+			break
+		}
+		if layers.TLSType(records[0]) == layers.TLSHandshake {
+			handshake = append(handshake, records[tlsRecordHeaderLen:tlsRecordHeaderLen+recordLen]...)
+		}
+		records = records[tlsRecordHeaderLen+recordLen:]
+	}
+
+	var clientHello *pbAgent.TLSClientHello
+	var serverHello *pbAgent.TLSServerHello
+	r := &tlsReader{data: handshake}
+	for len(r.data) >= tlsHandshakeHeaderLen && r.err == nil {
+		msgType := r.uint8()
+		msg := r.vector(3)
+		if msg.err != nil {
+			break
+		}
+		switch msgType {
+		case tlsHandshakeClientHello:
+			hello, err := parseTLSClientHello(msg)
+			if err == nil {
+				clientHello = hello
+			}
+		case tlsHandshakeServerHello:
+			hello, err := parseTLSServerHello(msg)
+			if err == nil {
+				serverHello = hello
+			}
+		default:
+			// the rest of a plaintext handshake is either encrypted in TLS 1.3 or not of interest
+			return clientHello, serverHello
+		}
+	}
+	return clientHello, serverHello
+}
+
+func parseTLSClientHello(r *tlsReader) (*pbAgent.TLSClientHello, error) {
+	hello := &pbAgent.TLSClientHello{}
+	legacyVersion := uint32(r.uint16())
+	hello.Version = layers.TLSVersion(legacyVersion).String()
+	r.bytes(32) // random
+	r.vector(1) // legacy session id
+	hello.CipherSuites = r.vector(2).uint16s()
+	r.vector(1) // legacy compression methods
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// extensions are optional in a ClientHello before TLS 1.3
+	if len(r.data) > 0 {
+		extensions := r.vector(2)
+		for len(extensions.data) > 0 && extensions.err == nil {
+			extType := uint32(extensions.uint16())
+			ext := extensions.vector(2)
+			hello.Extensions = append(hello.Extensions, extType)
+			switch extType {
+			case tlsExtServerName:
+				names := ext.vector(2)
+				for len(names.data) > 0 && names.err == nil {
+					nameType := names.uint8()
+					name := names.vector(2)
+					if nameType == tlsServerNameTypeHostName && name.err == nil {
+						hello.Sni = string(name.data)
+					}
+				}
+			case tlsExtALPN:
+				protocols := ext.vector(2)
+				for len(protocols.data) > 0 && protocols.err == nil {
+					protocol := protocols.vector(1)
+					if protocol.err == nil {
+						hello.Alpn = append(hello.Alpn, string(protocol.data))
+					}
+				}
+			case tlsExtSupportedGroups:
+				hello.SupportedGroups = ext.vector(2).uint16s()
+			case tlsExtECPointFormats:
+				for _, format := range ext.vector(1).data {
+					hello.EcPointFormats = append(hello.EcPointFormats, uint32(format))
+				}
+			case tlsExtSignatureAlgorithms:
+				hello.SignatureAlgorithms = ext.vector(2).uint16s()
+			case tlsExtSupportedVersions:
+				hello.SupportedVersions = ext.vector(1).uint16s()
+			}
+		}
+		if extensions.err != nil {
+			return nil, extensions.err
+		}
+	}
+
+	hello.Ja3 = ja3(legacyVersion, hello)
+	hello.Ja3Hash = md5Hex(hello.Ja3)
+	hello.Ja4 = ja4(legacyVersion, hello)
+	return hello, nil
+}
+
+func parseTLSServerHello(r *tlsReader) (*pbAgent.TLSServerHello, error) {
+	hello := &pbAgent.TLSServerHello{}
+	legacyVersion := uint32(r.uint16())
+	hello.Version = layers.TLSVersion(legacyVersion).String()
+	r.bytes(32) // random
+	r.vector(1) // legacy session id
+	hello.CipherSuite = uint32(r.uint16())
+	r.uint8() // legacy compression method
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if len(r.data) > 0 {
+		extensions := r.vector(2)
+		for len(extensions.data) > 0 && extensions.err == nil {
+			extType := uint32(extensions.uint16())
+			ext := extensions.vector(2)
+			hello.Extensions = append(hello.Extensions, extType)
+			switch extType {
+			case tlsExtALPN:
+				protocol := ext.vector(2).vector(1)
+				if protocol.err == nil {
+					hello.Alpn = string(protocol.data)
+				}
+			case tlsExtSupportedVersions:
+				hello.SupportedVersion = layers.TLSVersion(ext.uint16()).String()
+			}
+		}
+		if extensions.err != nil {
+			return nil, extensions.err
+		}
+	}
+
+	hello.Ja3S = fmt.Sprintf("%d,%d,%s", legacyVersion, hello.CipherSuite, joinUint32s(hello.Extensions, "-"))
+	hello.Ja3SHash = md5Hex(hello.Ja3S)
+	return hello, nil
+}
+
+// ja3 returns the JA3 string of the ClientHello: its version, ciphers, extensions, groups and point formats in decimal, without GREASE values
+func ja3(legacyVersion uint32, hello *pbAgent.TLSClientHello) string {
+	return strings.Join([]string{
+		strconv.FormatUint(uint64(legacyVersion), 10),
+		joinUint32s(withoutGREASE(hello.CipherSuites), "-"),
+		joinUint32s(withoutGREASE(hello.Extensions), "-"),
+		joinUint32s(withoutGREASE(hello.SupportedGroups), "-"),
+		joinUint32s(hello.EcPointFormats, "-"),
+	}, ",")
+}
+
+// ja4 returns the JA4 fingerprint of the ClientHello, e.g. t13d1516h2_8daaf6152771_e5627efa2ab1.
+// The first part describes the hello, the second is a truncated hash of the sorted ciphers
+// and the third is a truncated hash of the sorted extensions followed by the signature algorithms.
+func ja4(legacyVersion uint32, hello *pbAgent.TLSClientHello) string {
+	version := legacyVersion
+	supportedVersions := withoutGREASE(hello.SupportedVersions)
+	for _, supportedVersion := range supportedVersions {
+		if supportedVersion > version {
+			version = supportedVersion
+		}
+	}
+
+	destination := "i"
+	if hello.Sni != "" {
+		destination = "d"
+	}
+
+	alpn := "00"
+	if len(hello.Alpn) > 0 && hello.Alpn[0] != "" {
+		first, last := hello.Alpn[0][0], hello.Alpn[0][len(hello.Alpn[0])-1]
+		if isAlphanumeric(first) && isAlphanumeric(last) {
+			alpn = string([]byte{first, last})
+		} else {
+			alpnHex := hex.EncodeToString([]byte(hello.Alpn[0]))
+			alpn = string([]byte{alpnHex[0], alpnHex[len(alpnHex)-1]})
+		}
+	}
+
+	ciphers := withoutGREASE(hello.CipherSuites)
+	extensions := withoutGREASE(hello.Extensions)
+	description := fmt.Sprintf(
+		"t%s%s%02d%02d%s",
+		ja4Version(version), destination, min(len(ciphers), 99), min(len(extensions), 99), alpn,
+	)
+
+	cipherHash := ja4EmptyHash
+	if len(ciphers) > 0 {
+		cipherHash = ja4Hash(sortedHex(ciphers))
+	}
+
+	// SNI and ALPN are already part of the description
+	hashedExtensions := make([]uint32, 0, len(extensions))
+	for _, extension := range extensions {
+		if extension != tlsExtServerName && extension != tlsExtALPN {
+			hashedExtensions = append(hashedExtensions, extension)
+		}
+	}
+	extensionHash := ja4EmptyHash
+	if len(hashedExtensions) > 0 {
+		extensionInput := sortedHex(hashedExtensions)
+		if len(hello.SignatureAlgorithms) > 0 {
+			extensionInput += "_" + hexUint32s(withoutGREASE(hello.SignatureAlgorithms))
+		}
+		extensionHash = ja4Hash(extensionInput)
+	}
+
+	return description + "_" + cipherHash + "_" + extensionHash
+}
+
+func ja4Version(version uint32) string {
+	switch version {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	case 0x0002:
+		return "s2"
+	default:
+		return "00"
+	}
+}
+
+func isAlphanumeric(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')
+}
+
+func ja4Hash(input string) string {
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func md5Hex(input string) string {
+	sum := md5.Sum([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+func joinUint32s(values []uint32, sep string) string {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, strconv.FormatUint(uint64(value), 10))
+	}
+	return strings.Join(strs, sep)
+}
+
+// hexUint32s returns the values as comma separated, 4 digit lowercase hex
+func hexUint32s(values []uint32) string {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, fmt.Sprintf("%04x", value))
+	}
+	return strings.Join(strs, ",")
+}
+
+func sortedHex(values []uint32) string {
+	sorted := append([]uint32(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return hexUint32s(sorted)
+}
@@ -0,0 +1,146 @@
+package pcap
+
+import (
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// tunnel types of the tunnel_types field of packet events
+const (
+	tunnelTypeVLAN   = "VLAN"
+	tunnelTypeGRE    = "GRE"
+	tunnelTypeVXLAN  = "VXLAN"
+	tunnelTypeGENEVE = "GENEVE"
+	tunnelTypeIPIP   = "IPIP"
+)
+
+// innermostLayer returns the last layer of any of the given types.
+// For a tunneled packet, that is the layer of the innermost packet rather than of the underlay.
+func innermostLayer(pkt gopacket.Packet, layerTypes ...gopacket.LayerType) gopacket.Layer {
+	pktLayers := pkt.Layers()
+	for i := len(pktLayers) - 1; i >= 0; i-- {
+		for _, layerType := range layerTypes {
+			if pktLayers[i].LayerType() == layerType {
+				return pktLayers[i]
+			}
+		}
+	}
+	return nil
+}
+
+// innermostTransportLayer returns the layer of the given type that follows the innermost IP header, nil if the innermost packet has none.
+// This keeps the UDP header of a VXLAN or GENEVE underlay from being taken for the transport of an inner TCP or ICMP packet.
+func innermostTransportLayer(pkt gopacket.Packet, layerType gopacket.LayerType) gopacket.Layer {
+	pktLayers := pkt.Layers()
+	for i := len(pktLayers) - 1; i >= 0; i-- {
+		switch pktLayers[i].LayerType() {
+		case layerType:
+			return pktLayers[i]
+		case layers.LayerTypeIPv4, layers.LayerTypeIPv6:
+			return nil
+		}
+	}
+	return nil
+}
+
+// decodeTunnels returns the encapsulations of the packet, outermost first, each with the headers that carry it
+func decodeTunnels(pkt gopacket.Packet) []*pbAgent.Tunnel {
+	var tunnels []*pbAgent.Tunnel
+	var outerIP *pbAgent.IPLayer
+	var outerUDP *pbAgent.UDPLayer
+	// tunneled is set once a tunnel header follows the last IP header, so an IP header right after another one is IP in IP
+	tunneled := false
+
+	pktLayers := pkt.Layers()
+	for i, layer := range pktLayers {
+		switch l := layer.(type) {
+		case *layers.Dot1Q:
+			tunnels = append(tunnels, &pbAgent.Tunnel{Type: tunnelTypeVLAN, Id: uint32(l.VLANIdentifier)})
+		case *layers.Ethernet:
+			// an Ethernet header after a tunnel header is the encapsulated frame
+			if i > 0 && len(tunnels) > 0 {
+				last := tunnels[len(tunnels)-1]
+				if last.Type != tunnelTypeVLAN && last.InnerEthernetLayer == nil {
+					last.InnerEthernetLayer = ethernetLayerInfo(pktLayers, i)
+				}
+			}
+		case *layers.IPv4, *layers.IPv6:
+			ipInfo := ipLayerInfo(layer)
+			if outerIP != nil && !tunneled {
+				tunnels = append(tunnels, &pbAgent.Tunnel{Type: tunnelTypeIPIP, OuterIpLayer: outerIP})
+			}
+			outerIP = ipInfo
+			outerUDP = nil
+			tunneled = false
+		case *layers.UDP:
+			outerUDP = udpLayerInfo(l)
+		case *layers.GRE:
+			tunnels = append(tunnels, &pbAgent.Tunnel{Type: tunnelTypeGRE, Id: l.Key, OuterIpLayer: outerIP})
+			tunneled = true
+		case *layers.VXLAN:
+			tunnels = append(tunnels, &pbAgent.Tunnel{Type: tunnelTypeVXLAN, Id: l.VNI, OuterIpLayer: outerIP, OuterUdpLayer: outerUDP})
+			tunneled = true
+		case *layers.Geneve:
+			tunnels = append(tunnels, &pbAgent.Tunnel{Type: tunnelTypeGENEVE, Id: l.VNI, OuterIpLayer: outerIP, OuterUdpLayer: outerUDP})
+			tunneled = true
+		}
+	}
+	return tunnels
+}
+
+// ethernetLayerInfo returns the Ethernet header at index i of the packet's layers, with the VLAN tags that follow it
+func ethernetLayerInfo(pktLayers []gopacket.Layer, i int) *pbAgent.EthernetLayer {
+	ethernet := pktLayers[i].(*layers.Ethernet)
+	ethernetInfo := &pbAgent.EthernetLayer{
+		SrcMac: ethernet.SrcMAC.String(),
+		DstMac: ethernet.DstMAC.String(),
+	}
+	etherType := ethernet.EthernetType
+	for _, layer := range pktLayers[i+1:] {
+		dot1q, ok := layer.(*layers.Dot1Q)
+		if !ok {
+			break
+		}
+		ethernetInfo.VlanTags = append(ethernetInfo.VlanTags, &pbAgent.VLANTag{
+			Id:           uint32(dot1q.VLANIdentifier),
+			Priority:     uint32(dot1q.Priority),
+			DropEligible: dot1q.DropEligible,
+		})
+		etherType = dot1q.Type
+	}
+	ethernetInfo.EtherType = etherType.String()
+	return ethernetInfo
+}
+
+// ipLayerInfo returns the IP header of an IPv4 or IPv6 layer
+func ipLayerInfo(layer gopacket.Layer) *pbAgent.IPLayer {
+	switch ip := layer.(type) {
+	case *layers.IPv4:
+		return &pbAgent.IPLayer{
+			Version:  "IPv4",
+			SrcIp:    ip.SrcIP.String(),
+			DstIp:    ip.DstIP.String(),
+			Ttl:      uint32(ip.TTL),
+			Protocol: uint32(ip.Protocol),
+		}
+	case *layers.IPv6:
+		return &pbAgent.IPLayer{
+			Version:  "IPv6",
+			SrcIp:    ip.SrcIP.String(),
+			DstIp:    ip.DstIP.String(),
+			HopLimit: uint32(ip.HopLimit),
+			Protocol: uint32(ip.NextHeader),
+		}
+	}
+	return nil
+}
+
+func udpLayerInfo(udp *layers.UDP) *pbAgent.UDPLayer {
+	return &pbAgent.UDPLayer{
+		SrcPort: uint32(udp.SrcPort),
+		DstPort: uint32(udp.DstPort),
+		Length:  uint32(udp.Length),
+	}
+}
@@ -2,10 +2,17 @@ package poll
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	"github.com/danielhoward314/packet-sentry/internal/broadcast"
 	"github.com/danielhoward314/packet-sentry/internal/config"
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
@@ -29,10 +36,15 @@ type pollManager struct {
 	cancelFunc                 context.CancelFunc
 	commandsBroadcaster        *broadcast.CommandsBroadcaster
 	ctx                        context.Context
+	hasPolled                  bool
 	logger                     *slog.Logger
 	pollInterval               time.Duration
 	shutdownChannel            chan struct{}
 	stopOnce                   sync.Once
+	// streamCancel closes the command stream of the current client
+	streamCancel context.CancelFunc
+	// streaming is set while the command stream is open, the polls are skipped then
+	streaming atomic.Bool
 }
 
 // NewPollManager returns an implementation of the PollManager interface
@@ -58,8 +70,8 @@ func NewPollManager(
 	return pm
 }
 
-// Start runs an infinite loop in a goroutine, polling the server for commands on a configured interval
-// and subscribing to mTLS client updates
+// Start runs an infinite loop in a goroutine, subscribing to mTLS client updates and opening a command stream with each client,
+// and polling the server for commands on a configured interval while the stream is down
 func (pm *pollManager) Start() {
 	logger := pm.logger.With(psLog.KeyFunction, "PollManager.Start")
 	logger.Info("starting poll manager")
@@ -69,10 +81,23 @@ func (pm *pollManager) Start() {
 	for {
 		select {
 		case clientUpdate := <-sub:
+			client := pbAgent.NewAgentServiceClient(clientUpdate.ClientConn)
 			pm.agentMTLSClientMu.Lock()
-			pm.agentMTLSClient = pbAgent.NewAgentServiceClient(clientUpdate.ClientConn)
+			pm.agentMTLSClient = client
 			pm.agentMTLSClientMu.Unlock()
+
+			// a new client supersedes the stream of the previous one
+			if pm.streamCancel != nil {
+				pm.streamCancel()
+			}
+			var streamCtx context.Context
+			streamCtx, pm.streamCancel = context.WithCancel(pm.ctx)
+			go pm.streamCommands(streamCtx, client)
 		case <-time.After(pm.pollInterval):
+			// the first poll always happens, since it fetches BPF config
+			if pm.hasPolled && pm.streaming.Load() {
+				continue
+			}
 			logger.Info("sending poll request")
 			pm.agentMTLSClientMu.RLock()
 			client := pm.agentMTLSClient
@@ -86,15 +111,18 @@ func (pm *pollManager) Start() {
 			pbCmds, err := client.PollCommand(pm.ctx, &pbAgent.Empty{})
 			if err != nil {
 				logger.Error("failed to get command on poll", psLog.KeyError, err)
+				continue
+			}
+
+			if !pm.hasPolled {
+				// the first poll after startup always fetches BPF config,
+				// so the captures restored from the on-disk cache get reconciled with the server's
+				pm.hasPolled = true
+				pbCmds.Commands = withGetBPFConfig(pbCmds.Commands)
 			}
 
 			for _, pbCmd := range pbCmds.Commands {
-				if pbCmd.Name != "noop" {
-					logger.Info("received command", psLog.KeyCommand, pbCmd.Name)
-					pm.commandsBroadcaster.Publish(&broadcast.Command{Name: pbCmd.Name})
-				} else {
-					logger.Info("received noop command, skipping publish")
-				}
+				pm.publishCommand(client, pbCmd)
 			}
 
 		case <-pm.ctx.Done():
@@ -104,6 +132,99 @@ func (pm *pollManager) Start() {
 	}
 }
 
+// streamCommands keeps a command stream open with the client until the context is canceled,
+// reopening it with backoff when it breaks, while the polls take over
+func (pm *pollManager) streamCommands(ctx context.Context, client pbAgent.AgentServiceClient) {
+	logger := pm.logger.With(psLog.KeyFunction, "PollManager.streamCommands")
+
+	attempt := 0
+	for {
+		openedAt := time.Now()
+		err := pm.runCommandStream(ctx, client)
+		pm.streaming.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if status.Code(err) == codes.Unimplemented {
+			logger.Warn("server doesn't support command streams, polling for commands", psLog.KeyError, err)
+			return
+		}
+		// a stream that stayed up for a while broke for a new reason, so the backoff starts over
+		if time.Since(openedAt) > config.GetCommandStreamReconnectMaxDelay() {
+			attempt = 0
+		}
+		delay := reconnectBackoff(attempt)
+		attempt++
+		logger.Warn("command stream broke, polling for commands until it is reopened", psLog.KeyError, err, psLog.KeyBackoff, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runCommandStream opens a command stream and publishes the commands pushed on it, acknowledging the ones with an id,
+// until the stream breaks or goes quiet for longer than the idle timeout
+func (pm *pollManager) runCommandStream(ctx context.Context, client pbAgent.AgentServiceClient) error {
+	logger := pm.logger.With(psLog.KeyFunction, "PollManager.runCommandStream")
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the server sends a noop whenever it has had no commands for a while, so silence means the connection is dead
+	idleTimeout := config.GetCommandStreamIdleTimeout()
+	idle := time.AfterFunc(idleTimeout, cancel)
+	defer idle.Stop()
+
+	stream, err := client.CommandStream(streamCtx)
+	if err != nil {
+		return err
+	}
+	err = stream.Send(&pbAgent.CommandStreamRequest{})
+	if err != nil {
+		return err
+	}
+	pm.streaming.Store(true)
+	logger.Info("command stream opened")
+
+	for {
+		pbCmd, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() == nil && streamCtx.Err() != nil {
+				return fmt.Errorf("no message on command stream for %s", idleTimeout)
+			}
+			return err
+		}
+		idle.Reset(idleTimeout)
+		if pbCmd.Id != "" {
+			err = stream.Send(&pbAgent.CommandStreamRequest{AckCommandId: pbCmd.Id})
+			if err != nil {
+				return err
+			}
+		}
+		pm.publishCommand(client, pbCmd)
+	}
+}
+
+// publishCommand publishes a command received from the server to the commands broadcaster,
+// skipping noops and reporting commands whose expiry passed as expired
+func (pm *pollManager) publishCommand(client pbAgent.AgentServiceClient, pbCmd *pbAgent.Command) {
+	logger := pm.logger.With(psLog.KeyFunction, "PollManager.publishCommand")
+
+	if pbCmd.Name == "noop" {
+		logger.Debug("received noop command, skipping publish")
+		return
+	}
+	logger.Info("received command", psLog.KeyCommand, pbCmd.Name, psLog.KeyCommandID, pbCmd.Id)
+	command := commandFromPB(pbCmd)
+	if command.Expired(time.Now()) {
+		logger.Warn("received expired command, skipping publish", psLog.KeyCommand, command.Name, psLog.KeyCommandID, command.ID)
+		pm.reportExpired(client, command)
+		return
+	}
+	pm.commandsBroadcaster.Publish(command)
+}
+
 func (pm *pollManager) Stop() {
 	logger := pm.logger.With(psLog.KeyFunction, "PollManager.Stop")
 
@@ -112,3 +233,89 @@ func (pm *pollManager) Stop() {
 		pm.cancelFunc()
 	})
 }
+
+// withGetBPFConfig returns the commands with a `get_bpf_config` command appended, unless one is already present
+func withGetBPFConfig(pbCmds []*pbAgent.Command) []*pbAgent.Command {
+	for _, pbCmd := range pbCmds {
+		if pbCmd.Name == broadcast.CommandGetBPFConfig {
+			return pbCmds
+		}
+	}
+	return append(pbCmds, &pbAgent.Command{Name: broadcast.CommandGetBPFConfig})
+}
+
+// reportExpired reports the command as expired, since it isn't run
+func (pm *pollManager) reportExpired(client pbAgent.AgentServiceClient, command *broadcast.Command) {
+	logger := pm.logger.With(psLog.KeyFunction, "PollManager.reportExpired")
+
+	now := timestamppb.Now()
+	_, err := client.ReportCommandResult(pm.ctx, &pbAgent.CommandResult{
+		CommandId:  command.ID,
+		Name:       command.Name,
+		Status:     broadcast.CommandStatusExpired,
+		Error:      "command expired at " + command.ExpiresAt.Format(time.RFC3339),
+		StartedAt:  now,
+		FinishedAt: now,
+	})
+	if err != nil {
+		logger.Error("failed to report expired command", psLog.KeyCommandID, command.ID, psLog.KeyError, err)
+	}
+}
+
+// commandFromPB converts the command envelope the server sent for the commands broadcaster
+func commandFromPB(pbCmd *pbAgent.Command) *broadcast.Command {
+	command := &broadcast.Command{
+		ID:       pbCmd.Id,
+		Name:     pbCmd.Name,
+		IssuedBy: pbCmd.IssuedBy,
+	}
+	if pbCmd.IssuedAt != nil {
+		command.IssuedAt = pbCmd.IssuedAt.AsTime()
+	}
+	if pbCmd.ExpiresAt != nil {
+		command.ExpiresAt = pbCmd.ExpiresAt.AsTime()
+	}
+	if args := pbCmd.GetUploadPacketSlice(); args != nil {
+		command.UploadPacketSlice = &broadcast.UploadPacketSliceArgs{
+			SliceID:    args.SliceId,
+			DeviceName: args.DeviceName,
+			BPF:        args.Bpf,
+			Filter:     args.Filter,
+			Start:      args.Start.AsTime(),
+			End:        args.End.AsTime(),
+		}
+	}
+	if args := pbCmd.GetSetLogLevel(); args != nil {
+		command.SetLogLevel = &broadcast.SetLogLevelArgs{
+			Level:       args.Level,
+			RevertAfter: time.Duration(args.RevertAfterSeconds) * time.Second,
+		}
+	}
+	if args := pbCmd.GetCollectDiagnostics(); args != nil {
+		command.CollectDiagnostics = &broadcast.CollectDiagnosticsArgs{
+			BundleID: args.BundleId,
+		}
+	}
+	if args := pbCmd.GetUpdateAgent(); args != nil {
+		command.UpdateAgent = &broadcast.UpdateAgentArgs{
+			Version:   args.Version,
+			SHA256:    args.Sha256,
+			SizeBytes: args.SizeBytes,
+			Signature: args.Signature,
+		}
+	}
+	return command
+}
+
+// reconnectBackoff returns the jittered exponential delay before the given attempt to reopen the command stream
+func reconnectBackoff(attempt int) time.Duration {
+	delay := config.GetCommandStreamReconnectMaxDelay()
+	if attempt < 32 {
+		exponential := config.GetCommandStreamReconnectBaseDelay() << attempt
+		if exponential > 0 && exponential < delay {
+			delay = exponential
+		}
+	}
+	// jitter between half and all of the delay, so agents don't reconnect in lockstep after a server restart
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,47 @@
+// Package streams holds the names shared by the publishers and consumers of the NATS JetStream streams
+package streams
+
+const (
+	// HeaderMessageType is the NATS header naming the protobuf message type of a message's data,
+	// for subjects that carry more than one type
+	HeaderMessageType = "Packet-Sentry-Message-Type"
+	// MessageTypePacketEventBatch is the HeaderMessageType value of messages carrying a PacketEventBatch.
+	// Messages on `events.*` without the header carry a single PacketEvent.
+	MessageTypePacketEventBatch = "agent.PacketEventBatch"
+)
+
+// MaxMessageBytes is the size the data of a published message is kept under,
+// well below the default NATS max_payload of 1 MiB so headers and protobuf framing never push a message over it
+const MaxMessageBytes = 512 * 1024
+
+const (
+	// StreamEventsDLQ is the name of the dead-letter stream for messages of the EVENTS and FLOWS streams the worker failed to process
+	StreamEventsDLQ = "EVENTS_DLQ"
+	// SubjectPrefixEventsDLQ prefixes the original subject of a dead-lettered message, e.g. "dlq.events.<id>" or "dlq.flows.<id>"
+	SubjectPrefixEventsDLQ = "dlq."
+)
+
+// headers the worker sets on dead-lettered messages, next to the headers of the original message
+const (
+	// HeaderDLQPrefix prefixes all of the dead-letter headers, which are dropped when a message is replayed
+	HeaderDLQPrefix = "Packet-Sentry-DLQ-"
+	// HeaderDLQReason is why the message was dead-lettered, one of the DLQReason values
+	HeaderDLQReason = "Packet-Sentry-DLQ-Reason"
+	// HeaderDLQError is the error of the last attempt to process the message
+	HeaderDLQError = "Packet-Sentry-DLQ-Error"
+	// HeaderDLQOriginalSubject is the subject the message was originally published on, which it is replayed to
+	HeaderDLQOriginalSubject = "Packet-Sentry-DLQ-Original-Subject"
+	// HeaderDLQOriginalSequence is the sequence of the message in its original stream
+	HeaderDLQOriginalSequence = "Packet-Sentry-DLQ-Original-Sequence"
+	// HeaderDLQNumDelivered is how many times the message was delivered before it was dead-lettered
+	HeaderDLQNumDelivered = "Packet-Sentry-DLQ-Num-Delivered"
+	// HeaderDLQFailedAt is the RFC 3339 time the message was dead-lettered
+	HeaderDLQFailedAt = "Packet-Sentry-DLQ-Failed-At"
+)
+
+const (
+	// DLQReasonUnmarshal marks messages whose data couldn't be unmarshaled
+	DLQReasonUnmarshal = "unmarshal"
+	// DLQReasonWrite marks messages whose packet events or flow record couldn't be written before the max deliveries were reached
+	DLQReasonWrite = "write"
+)
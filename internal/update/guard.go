@@ -0,0 +1,53 @@
+package update
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+)
+
+// GuardEnv is set by the agent guard in the environment of the agent binary it starts.
+// The agent only installs updates when it was started by the guard, since nothing else rolls back a release that fails to start.
+const GuardEnv = "PACKET_SENTRY_AGENT_GUARD"
+
+// CheckStart is run by the agent guard each time the service manager starts the agent, before the agent binary is started.
+// It counts the starts of the version being confirmed and restores the previous version's binary once they exceed the max
+// or the grace period passed. Since it doesn't depend on the new binary running at all, a release that fails before its update manager runs,
+// e.g. one that can't be loaded or panics while initializing, is rolled back all the same.
+func CheckStart(logger *slog.Logger) error {
+	logger = logger.With(psLog.KeyFunction, "update.CheckStart")
+
+	state, err := readState()
+	if err != nil {
+		return err
+	}
+	if state == nil || state.Error != "" {
+		// no update to confirm, or one whose failure is left for the version running to report
+		return nil
+	}
+
+	state.StartAttempts++
+	var reason error
+	switch {
+	case state.StartAttempts > config.GetUpdateMaxStartAttempts():
+		reason = fmt.Errorf("version %s started %d times without a heartbeat", state.ToVersion, state.StartAttempts-1)
+	case time.Now().After(state.Deadline):
+		reason = fmt.Errorf("version %s sent no heartbeat within %s", state.ToVersion, config.GetUpdateGracePeriod())
+	default:
+		logger.Info("starting new version", psLog.KeyVersion, state.ToVersion, psLog.KeyStartAttempts, state.StartAttempts)
+		return writeState(state)
+	}
+
+	logger.Error("rolling back update", psLog.KeyVersion, state.ToVersion, psLog.KeyError, reason)
+	_, err = restorePreviousBinary(state, reason)
+	return err
+}
+
+// startedByGuard reports whether the agent was started by the agent guard
+func startedByGuard() bool {
+	return os.Getenv(GuardEnv) != ""
+}
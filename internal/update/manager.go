@@ -0,0 +1,226 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	"github.com/danielhoward314/packet-sentry/internal/version"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+const (
+	logAttrValSvcName = "updateManager"
+)
+
+// UpdateManager installs the signed releases `update_agent` commands ask for, restarting the agent into them,
+// and rolls a release back when it doesn't heartbeat within the grace period
+type UpdateManager interface {
+	Start()
+	Stop()
+	// HeartbeatAccepted is called each time the server accepted a heartbeat of the agent
+	HeartbeatAccepted()
+}
+
+type updateManager struct {
+	agentMTLSClient            pbAgent.AgentServiceClient
+	agentMTLSClientBroadcaster *broadcast.AgentMTLSClientBroadcaster
+	agentMTLSClientMu          sync.RWMutex
+	cancelFunc                 context.CancelFunc
+	commandsBroadcaster        *broadcast.CommandsBroadcaster
+	ctx                        context.Context
+	// graceC fires when the update being confirmed is due to be rolled back, nil if there is none
+	graceC      <-chan time.Time
+	heartbeatC  chan struct{}
+	heartbeated bool
+	logger      *slog.Logger
+	// restart stops the agent so its service manager starts it again from the binary on disk
+	restart  func()
+	state    *updateState
+	stopOnce sync.Once
+}
+
+// NewUpdateManager returns an implementation of the UpdateManager interface
+func NewUpdateManager(
+	ctx context.Context,
+	baseLogger *slog.Logger,
+	commandsBroadcaster *broadcast.CommandsBroadcaster,
+	agentMTLSClientBroadcaster *broadcast.AgentMTLSClientBroadcaster,
+	restart func(),
+) UpdateManager {
+	childCtx, cancelFunc := context.WithCancel(ctx)
+	childLogger := baseLogger.With(slog.String(psLog.KeyServiceName, logAttrValSvcName))
+
+	return &updateManager{
+		agentMTLSClientBroadcaster: agentMTLSClientBroadcaster,
+		cancelFunc:                 cancelFunc,
+		commandsBroadcaster:        commandsBroadcaster,
+		ctx:                        childCtx,
+		heartbeatC:                 make(chan struct{}, 1),
+		logger:                     childLogger,
+		restart:                    restart,
+	}
+}
+
+// Start runs an infinite loop in a goroutine that:
+// (1) picks up the update the previous run swapped in, rolling it back if it started too often or its grace period passed
+// (2) upon receiving `update_agent` command, downloads the release, checks its signature and swaps it in, then restarts the agent
+// (3) confirms the update once the new version's heartbeat is accepted, reporting the command's result
+// (4) rolls the update back and restarts the agent if no heartbeat is accepted within the grace period
+func (um *updateManager) Start() {
+	logger := um.logger.With(psLog.KeyFunction, "UpdateManager.Start")
+	logger.Info("starting update manager")
+
+	um.resumeUpdate()
+
+	clientSubscription := um.agentMTLSClientBroadcaster.Subscribe()
+	commandsSubscription := um.commandsBroadcaster.Subscribe()
+
+	for {
+		select {
+		case clientUpdate := <-clientSubscription:
+			um.agentMTLSClientMu.Lock()
+			um.agentMTLSClient = pbAgent.NewAgentServiceClient(clientUpdate.ClientConn)
+			um.agentMTLSClientMu.Unlock()
+			um.completeUpdate()
+		case command := <-commandsSubscription:
+			if command.Name != broadcast.CommandUpdateAgent {
+				// do nothing, command not for this manager
+				continue
+			}
+			logger.Info("processing command", psLog.KeyCommand, broadcast.CommandUpdateAgent, psLog.KeyCommandID, command.ID)
+			startedAt := time.Now()
+			err := um.update(command, startedAt)
+			if err != nil {
+				logger.Error("failed to update agent", psLog.KeyError, err)
+				um.reportCommandResult(command.ID, startedAt, err)
+				continue
+			}
+			// the new version reports the command's result once its heartbeat is accepted
+			logger.Info("restarting into new version", psLog.KeyVersion, command.UpdateAgent.Version)
+			um.restart()
+		case <-um.heartbeatC:
+			um.heartbeated = true
+			um.completeUpdate()
+		case <-um.graceC:
+			um.rollback(fmt.Errorf("version %s sent no heartbeat within %s", version.Version, config.GetUpdateGracePeriod()))
+		case <-um.ctx.Done():
+			logger.Error("update manager context canceled")
+			return
+		}
+	}
+}
+
+// Stop cancels the update manager's context
+func (um *updateManager) Stop() {
+	um.stopOnce.Do(func() {
+		um.cancelFunc()
+	})
+}
+
+func (um *updateManager) HeartbeatAccepted() {
+	select {
+	case um.heartbeatC <- struct{}{}:
+	default:
+	}
+}
+
+// update installs the release an `update_agent` command asks for, leaving it to take effect on restart
+func (um *updateManager) update(command broadcast.Command, startedAt time.Time) error {
+	args := command.UpdateAgent
+	if !selfUpdateSupported {
+		return fmt.Errorf("self-update is not supported on %s, upgrade with the installer", runtime.GOOS)
+	}
+	if !startedByGuard() {
+		return errors.New("agent wasn't started by packet-sentry-agent-guard, which rolls back a release that fails to start, reinstall the agent to enable self-update")
+	}
+	if args == nil || args.Version == "" {
+		return errors.New("missing version")
+	}
+	if args.Version == version.Version {
+		return fmt.Errorf("already running version %s", version.Version)
+	}
+	if um.state != nil {
+		return fmt.Errorf("the update to version %s is still in progress", um.state.ToVersion)
+	}
+	if args.SizeBytes == 0 || args.SizeBytes > config.GetUpdateMaxBytes() {
+		return fmt.Errorf("invalid release size of %d bytes", args.SizeBytes)
+	}
+	publicKey, err := releasePublicKey()
+	if err != nil {
+		return err
+	}
+
+	um.agentMTLSClientMu.RLock()
+	client := um.agentMTLSClient
+	um.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		return errors.New("no mTLS client available, cannot download release")
+	}
+
+	data, err := downloadRelease(um.ctx, client, args.Version, args.SizeBytes)
+	if err != nil {
+		return fmt.Errorf("failed to download release %s: %w", args.Version, err)
+	}
+	err = verifyRelease(publicKey, data, args)
+	if err != nil {
+		return fmt.Errorf("release %s failed verification: %w", args.Version, err)
+	}
+
+	state := &updateState{
+		CommandID:   command.ID,
+		StartedAt:   startedAt,
+		FromVersion: version.Version,
+		ToVersion:   args.Version,
+		Deadline:    time.Now().Add(config.GetUpdateGracePeriod()),
+	}
+	err = installRelease(data, state)
+	if err != nil {
+		return fmt.Errorf("failed to install release %s: %w", args.Version, err)
+	}
+	um.state = state
+	return nil
+}
+
+// reportCommandResult reports the outcome of an `update_agent` command to the server.
+// Commands without an id weren't tracked by the server, so nothing is reported for them.
+func (um *updateManager) reportCommandResult(commandID string, startedAt time.Time, err error) {
+	if commandID == "" {
+		return
+	}
+	logger := um.logger.With(psLog.KeyFunction, "UpdateManager.reportCommandResult", psLog.KeyCommandID, commandID)
+
+	result := &pbAgent.CommandResult{
+		CommandId:  commandID,
+		Name:       broadcast.CommandUpdateAgent,
+		Status:     broadcast.CommandStatusSucceeded,
+		StartedAt:  timestamppb.New(startedAt),
+		FinishedAt: timestamppb.Now(),
+	}
+	if err != nil {
+		result.Status = broadcast.CommandStatusFailed
+		result.Error = err.Error()
+	}
+
+	um.agentMTLSClientMu.RLock()
+	client := um.agentMTLSClient
+	um.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		logger.Error("no agent gRPC client available, cannot report command result")
+		return
+	}
+
+	_, err = client.ReportCommandResult(um.ctx, result)
+	if err != nil {
+		logger.Error("failed to report command result", psLog.KeyError, err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build linux || darwin
+
+package update
+
+// selfUpdateSupported is set where the service manager starts the agent again once it exits to restart,
+// systemd with Restart=always and launchd with KeepAlive on a non-zero exit
+const selfUpdateSupported = true
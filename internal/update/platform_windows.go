@@ -0,0 +1,7 @@
+//go:build windows
+
+package update
+
+// selfUpdateSupported is unset on Windows, where the service isn't configured to restart after it exits
+// and the msi installer owns the binary
+const selfUpdateSupported = false
@@ -0,0 +1,175 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+)
+
+// PublicKey is the base64 ed25519 public key the releases of the artifact registry are signed with, set at build time with -ldflags -X.
+// An agent built without one refuses to update.
+var PublicKey = ""
+
+// releasePublicKey returns the release signing key built into the agent
+func releasePublicKey() (ed25519.PublicKey, error) {
+	if PublicKey == "" {
+		return nil, errors.New("agent was built without a release signing key")
+	}
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release signing key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key of %d bytes", len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// ReleaseManifest returns the manifest the detached signature of a release covers.
+// Signing the version, OS and architecture along with the binary's hash and size keeps a signed binary
+// from being installed as any other release, e.g. an old version labeled as a new one.
+func ReleaseManifest(version, goos, goarch, sha256Hex string, sizeBytes uint64) []byte {
+	return []byte(fmt.Sprintf(
+		"packet-sentry-agent-release\nversion=%s\nos=%s\narch=%s\nsha256=%s\nsize=%d\n",
+		version,
+		goos,
+		goarch,
+		strings.ToLower(sha256Hex),
+		sizeBytes,
+	))
+}
+
+// previousBinaryPath returns the path the binary of the version an update replaced is kept at, to roll back to
+func previousBinaryPath() string {
+	return config.GetAgentBinaryPath() + ".previous"
+}
+
+// downloadRelease downloads the agent binary of the release for the agent's OS and architecture, up to its expected size
+func downloadRelease(ctx context.Context, client pbAgent.AgentServiceClient, version string, sizeBytes uint64) ([]byte, error) {
+	stream, err := client.DownloadAgentRelease(ctx, &pbAgent.DownloadAgentReleaseRequest{
+		Version: version,
+		Os:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, sizeBytes)
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(data)+len(chunk.Data)) > sizeBytes {
+			return nil, fmt.Errorf("release is larger than its size of %d bytes", sizeBytes)
+		}
+		data = append(data, chunk.Data...)
+	}
+}
+
+// verifyRelease checks the downloaded binary against the size and SHA-256 of the command,
+// and the detached signature against the release signing key and the manifest of the command's version,
+// the agent's own OS and architecture and the downloaded binary's hash and size
+func verifyRelease(publicKey ed25519.PublicKey, data []byte, args *broadcast.UpdateAgentArgs) error {
+	if uint64(len(data)) != args.SizeBytes {
+		return fmt.Errorf("downloaded %d bytes instead of %d", len(data), args.SizeBytes)
+	}
+	sum := sha256.Sum256(data)
+	sha256Hex := hex.EncodeToString(sum[:])
+	if sha256Hex != strings.ToLower(args.SHA256) {
+		return errors.New("SHA-256 mismatch")
+	}
+	manifest := ReleaseManifest(args.Version, runtime.GOOS, runtime.GOARCH, sha256Hex, uint64(len(data)))
+	if !ed25519.Verify(publicKey, manifest, args.Signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// installRelease keeps a copy of the running binary to roll back to, records the update and swaps the new binary in.
+// The new binary is written next to the running one, so the rename that swaps it in is atomic,
+// and the service manager either starts the old binary or the new one, never a partial one.
+func installRelease(data []byte, state *updateState) error {
+	binaryPath := config.GetAgentBinaryPath()
+
+	current, err := os.ReadFile(binaryPath)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(previousBinaryPath(), current, 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := writeTemp(binaryPath, data, 0o755)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	err = writeState(state)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, binaryPath)
+	if err != nil {
+		_ = removeState()
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with the content by renaming a temp file over it
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := writeTemp(path, content, perm)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, path)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeTemp writes the content to a synced temp file in the directory of path, returning the temp file's path
+func writeTemp(path string, content []byte, perm os.FileMode) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
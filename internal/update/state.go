@@ -0,0 +1,160 @@
+package update
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielhoward314/packet-sentry/internal/config"
+	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	"github.com/danielhoward314/packet-sentry/internal/version"
+)
+
+// updateState tracks an update on disk from the swap of the binary until the new version heartbeats or is rolled back,
+// so the version running after each restart knows where the update is at
+type updateState struct {
+	CommandID   string    `json:"commandId,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	FromVersion string    `json:"fromVersion"`
+	ToVersion   string    `json:"toVersion"`
+	// Deadline is when the new version is rolled back unless its heartbeat was accepted
+	Deadline      time.Time `json:"deadline"`
+	StartAttempts int       `json:"startAttempts"`
+	// Error is why the update failed, for the version running after it to report
+	Error string `json:"error,omitempty"`
+}
+
+// resumeUpdate picks up the update tracked on disk when the agent starts.
+// The new version waits on its heartbeat until the deadline, its starts having been counted by the guard,
+// while a version running after an update failed only has the failure left to report.
+// Any other version was started in place of the one swapped in, and is rolled back like one that missed its heartbeat.
+func (um *updateManager) resumeUpdate() {
+	logger := um.logger.With(psLog.KeyFunction, "UpdateManager.resumeUpdate")
+
+	state, err := readState()
+	if err != nil {
+		logger.Error("failed to read update state", psLog.KeyError, err)
+		return
+	}
+	if state == nil {
+		return
+	}
+	um.state = state
+
+	switch {
+	case state.Error != "":
+		// reported once there is a client
+	case version.Version != state.ToVersion:
+		um.rollback(fmt.Errorf("agent started version %s instead of %s", version.Version, state.ToVersion))
+	default:
+		remaining := time.Until(state.Deadline)
+		if remaining <= 0 {
+			um.rollback(fmt.Errorf("version %s sent no heartbeat within %s", version.Version, config.GetUpdateGracePeriod()))
+			return
+		}
+		logger.Info("waiting on heartbeat of new version", psLog.KeyVersion, version.Version, psLog.KeyTimeout, remaining)
+		um.graceC = time.After(remaining)
+	}
+}
+
+// completeUpdate reports the result of the update tracked on disk once there is a client to report it with:
+// a failure right away, a success once the new version's heartbeat was accepted
+func (um *updateManager) completeUpdate() {
+	logger := um.logger.With(psLog.KeyFunction, "UpdateManager.completeUpdate")
+
+	if um.state == nil {
+		return
+	}
+	um.agentMTLSClientMu.RLock()
+	client := um.agentMTLSClient
+	um.agentMTLSClientMu.RUnlock()
+	if client == nil {
+		return
+	}
+
+	var updateErr error
+	if um.state.Error != "" {
+		updateErr = errors.New(um.state.Error)
+	} else if !um.heartbeated || version.Version != um.state.ToVersion {
+		return
+	}
+	um.reportCommandResult(um.state.CommandID, um.state.StartedAt, updateErr)
+	if updateErr == nil {
+		logger.Info("update confirmed by heartbeat", psLog.KeyVersion, version.Version)
+	}
+
+	err := removeState()
+	if err != nil {
+		logger.Error("failed to remove update state", psLog.KeyError, err)
+	}
+	um.state = nil
+	um.graceC = nil
+}
+
+// rollback swaps the previous version's binary back in and restarts the agent into it,
+// leaving the reason in the update state for the previous version to report
+func (um *updateManager) rollback(reason error) {
+	logger := um.logger.With(psLog.KeyFunction, "UpdateManager.rollback")
+	logger.Error("rolling back update", psLog.KeyVersion, version.Version, psLog.KeyError, reason)
+
+	um.graceC = nil
+	restored, err := restorePreviousBinary(um.state, reason)
+	if err != nil {
+		logger.Error("failed to roll back update", psLog.KeyError, err)
+	}
+	if !restored {
+		// with nothing to roll back to, this version keeps running and reports the update as failed
+		um.completeUpdate()
+		return
+	}
+	um.restart()
+}
+
+// restorePreviousBinary renames the previous version's binary back over the agent binary,
+// recording the reason in the update state for the version running next to report.
+// It reports whether the previous binary was restored, along with any error restoring it or writing the state.
+func restorePreviousBinary(state *updateState, reason error) (bool, error) {
+	renameErr := os.Rename(previousBinaryPath(), config.GetAgentBinaryPath())
+	if renameErr != nil {
+		state.Error = fmt.Sprintf("%s, and failed to roll back to version %s: %s", reason, state.FromVersion, renameErr)
+	} else {
+		state.Error = fmt.Sprintf("%s, rolled back to version %s", reason, state.FromVersion)
+	}
+	writeErr := writeState(state)
+	return renameErr == nil, errors.Join(renameErr, writeErr)
+}
+
+// readState returns the update state on disk, nil if no update is in progress
+func readState() (*updateState, error) {
+	content, err := os.ReadFile(config.GetUpdateStateFilePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state updateState
+	err = json.Unmarshal(content, &state)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func writeState(state *updateState) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(config.GetUpdateStateFilePath(), content, 0o600)
+}
+
+func removeState() error {
+	err := os.Remove(config.GetUpdateStateFilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
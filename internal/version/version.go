@@ -0,0 +1,11 @@
+// Package version holds the build information of the agent, set at build time with -ldflags -X.
+package version
+
+var (
+	// Version is the agent's release, e.g. v1.2.0, or "dev" for local builds
+	Version = "dev"
+	// CommitHash is the short hash of the commit the agent was built from
+	CommitHash = ""
+	// BuildTime is when the agent was built, in RFC 3339
+	BuildTime = ""
+)
@@ -25,6 +25,7 @@ const (
 	commonBootstrapFile  = commonInstallDir + "/agentBootstrap.json"
 	commonBinDir         = commonInstallDir + "/bin"
 	commonBinFile        = commonBinDir + "/packet-sentry-agent"
+	commonGuardBinFile   = commonBinDir + "/packet-sentry-agent-guard"
 	commonSystemdDir     = "/etc/systemd/system"
 	commonSystemdSvcFile = commonSystemdDir + "/packet-sentry-agent.service"
 )
@@ -62,7 +63,8 @@ const (
 	rpmBuildDir              = commonBuildDir + "/rpm"
 	rpmSourcesDir            = rpmBuildDir + "/SOURCES"
 	rpmSpecsDir              = rpmBuildDir + "/SPECS"
-	rpmSourcesBinaryTemplate = rpmSourcesDir + "/packet_sentry_linux_" // expects <amd64|arm64> appended
+	rpmSourcesBinaryTemplate = rpmSourcesDir + "/packet_sentry_linux_"       // expects <amd64|arm64> appended
+	rpmSourcesGuardTemplate  = rpmSourcesDir + "/packet_sentry_guard_linux_" // expects <amd64|arm64> appended
 	rpmSourcesSetupFile      = rpmSourcesDir + "/setup.sh"
 	rpmSourcesServiceFile    = rpmSourcesDir + "/packet-sentry-agent.service"
 	rpmSpecsMainSpec         = rpmSpecsDir + "/packet-sentry-agent.spec"
@@ -80,6 +82,7 @@ type CommonPackageInfo struct {
 type DebPackageInfo struct {
 	CommonPackageInfo
 	BinFile         string
+	GuardBinFile    string
 	InstallDir      string
 	MaintainerEmail string
 }
@@ -89,6 +92,7 @@ type RPMSpecData struct {
 	BinaryDestDir    string
 	BinarySourceName string
 	GoArch           string
+	GuardSourceName  string
 	ServiceDestDir   string
 	SetupScriptDest  string
 	SetupScriptName  string
@@ -180,7 +184,7 @@ func writeToFile(filePath, content string) error {
 	return nil
 }
 
-func buildDebPackage(goBuildBinary, version, arch string) {
+func buildDebPackage(goBuildBinary, goBuildGuardBinary, version, arch string) {
 	fmt.Println("Setting up the .deb build directories")
 	requiredDebDirs := []FileWithMode{
 		{File: debBuildDir, Mode: 0755},
@@ -205,6 +209,14 @@ func buildDebPackage(goBuildBinary, version, arch string) {
 	if err := os.Chmod(filepath.Join(debBuildDir, commonBinFile), 0755); err != nil {
 		log.Fatalf("Error setting permissions 0755 on file %s due to: %s", filepath.Join(debBuildDir, commonBinFile), err)
 	}
+	fmt.Printf("Copying file %s to %s\n", goBuildGuardBinary, filepath.Join(debBuildDir, commonGuardBinFile))
+	_, err = copy(goBuildGuardBinary, filepath.Join(debBuildDir, commonGuardBinFile))
+	if err != nil {
+		log.Fatalf("copy from src %s to dest %s failed due to %s\n", goBuildGuardBinary, filepath.Join(debBuildDir, commonGuardBinFile), err)
+	}
+	if err := os.Chmod(filepath.Join(debBuildDir, commonGuardBinFile), 0755); err != nil {
+		log.Fatalf("Error setting permissions 0755 on file %s due to: %s", filepath.Join(debBuildDir, commonGuardBinFile), err)
+	}
 
 	fmt.Println("Parsing and executing templates; copying to expected deb build directories")
 	debPackageInfo := DebPackageInfo{
@@ -216,6 +228,7 @@ func buildDebPackage(goBuildBinary, version, arch string) {
 			Version:                version,
 		},
 		BinFile:         commonBinFile,
+		GuardBinFile:    commonGuardBinFile,
 		InstallDir:      commonInstallDir,
 		MaintainerEmail: "maintainer@example.com",
 	}
@@ -253,7 +266,7 @@ func buildDebPackage(goBuildBinary, version, arch string) {
 	fmt.Println("DEB package built:", debOutput)
 }
 
-func buildRPMPackage(goBuildBinary, version, arch string) {
+func buildRPMPackage(goBuildBinary, goBuildGuardBinary, version, arch string) {
 	fmt.Println("Setting up the RPM build directories")
 	requiredRPMDirs := []FileWithMode{
 		{File: rpmBuildDir, Mode: 0755},
@@ -268,9 +281,11 @@ func buildRPMPackage(goBuildBinary, version, arch string) {
 
 	fmt.Println("Copying executable source files into expected rpm build directory (i.e. SOURCES)")
 	sourcesBinaryDest := rpmSourcesBinaryTemplate + arch
+	sourcesGuardDest := rpmSourcesGuardTemplate + arch
 
 	rpmSources := map[string]FileWithMode{
 		sourcesBinaryDest:     {File: goBuildBinary, Mode: 0755},
+		sourcesGuardDest:      {File: goBuildGuardBinary, Mode: 0755},
 		rpmSourcesServiceFile: {File: commonSystemdServiceTemplate, Mode: 0755},
 		rpmSourcesSetupFile:   {File: rpmSetupFile, Mode: 0755},
 	}
@@ -308,6 +323,7 @@ func buildRPMPackage(goBuildBinary, version, arch string) {
 		BinaryDestDir:    commonBinDir,
 		BinarySourceName: fmt.Sprintf("packet_sentry_linux_%s", arch),
 		GoArch:           arch,
+		GuardSourceName:  fmt.Sprintf("packet_sentry_guard_linux_%s", arch),
 		ServiceDestDir:   commonSystemdDir,
 		SetupScriptDest:  commonInstallDir,
 		SetupScriptName:  rpmSetupFileName,
@@ -416,11 +432,17 @@ func main() {
 		log.Fatalf("Error: Binary %s not found. Run `./scripts/build_agent linux %s` to build it.\n", goBuildBinary, arch)
 	}
 
+	goBuildGuardBinary := fmt.Sprintf("./build/packet_sentry_guard_linux_%s", arch)
+	fmt.Printf("Checking for existing go build binary %s\n", goBuildGuardBinary)
+	if _, err := os.Stat(goBuildGuardBinary); os.IsNotExist(err) {
+		log.Fatalf("Error: Binary %s not found. Run `./scripts/build_agent linux %s` to build it.\n", goBuildGuardBinary, arch)
+	}
+
 	switch format {
 	case "deb":
-		buildDebPackage(goBuildBinary, version, arch)
+		buildDebPackage(goBuildBinary, goBuildGuardBinary, version, arch)
 	case "rpm":
-		buildRPMPackage(goBuildBinary, version, arch)
+		buildRPMPackage(goBuildBinary, goBuildGuardBinary, version, arch)
 	default:
 		log.Fatalf("Unsupported installer format %s\n", format)
 	}
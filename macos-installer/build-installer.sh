@@ -29,8 +29,10 @@ mkdir -p "$ROOT_DIR/macos-installer/build/opt/packet-sentry/bin"
 # Copy the darwin Go build for the ARCH to where we need for `pkgbuild` and `productbuild`.
 if [[ "$ARCH" == "amd64" ]]; then
     cp -f "$ROOT_DIR/build/packet_sentry_darwin_amd64" "$ROOT_DIR/macos-installer/build/opt/packet-sentry/bin/packet-sentry-agent"
+    cp -f "$ROOT_DIR/build/packet_sentry_guard_darwin_amd64" "$ROOT_DIR/macos-installer/build/opt/packet-sentry/bin/packet-sentry-agent-guard"
 elif [[ "$ARCH" == "arm64" ]]; then
     cp -f "$ROOT_DIR/build/packet_sentry_darwin_arm64" "$ROOT_DIR/macos-installer/build/opt/packet-sentry/bin/packet-sentry-agent"
+    cp -f "$ROOT_DIR/build/packet_sentry_guard_darwin_arm64" "$ROOT_DIR/macos-installer/build/opt/packet-sentry/bin/packet-sentry-agent-guard"
 else
     echo "Unsupported architecture: $ARCH"
     usage
@@ -40,6 +42,7 @@ fi
 cp -f "$ROOT_DIR/macos-installer/package/com.danielhoward314.packet-sentry-agent.plist" "$ROOT_DIR/macos-installer/build/opt/packet-sentry/com.danielhoward314.packet-sentry-agent.plist"
 
 chmod +x "$ROOT_DIR/macos-installer/build/opt/packet-sentry/bin/packet-sentry-agent"
+chmod +x "$ROOT_DIR/macos-installer/build/opt/packet-sentry/bin/packet-sentry-agent-guard"
 
 pushd "$ROOT_DIR/macos-installer/package"
 
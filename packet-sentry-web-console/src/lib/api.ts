@@ -1,8 +1,15 @@
 import {
   ActivateAdministratorRequest,
+  AgentRelease,
   CreateAdministratorRequest,
   CreateInstallKeyRequest,
+  DeviceCommand,
+  DiagnosticsBundle,
+  PacketSlice,
+  RequestPacketSliceRequest,
+  SetLogLevelRequest,
   UpdateAdministratorRequest,
+  UpdateAgentRequest,
   UpdateDeviceRequest,
   UpdateOrganizationRequest,
 } from "@/types/api";
@@ -76,9 +83,103 @@ export async function updateDevice(
   return baseClient.put(`/devices/${id}`, request);
 }
 
+export async function requestPacketSlice(
+  deviceId: string,
+  request: RequestPacketSliceRequest,
+): Promise<PacketSlice> {
+  const res = await baseClient.post(`/devices/${deviceId}/packet-slices`, request);
+  return res.data;
+}
+
+export async function listPacketSlices(deviceId: string): Promise<any> {
+  const res = await baseClient.get(`/devices/${deviceId}/packet-slices`);
+  return res.data;
+}
+
+export async function downloadPacketSlice(
+  deviceId: string,
+  sliceId: string,
+): Promise<Blob> {
+  const res = await baseClient.get(
+    `/devices/${deviceId}/packet-slices/${sliceId}/pcap`,
+    { responseType: "blob" },
+  );
+  return res.data;
+}
+
+export async function listDeviceCommands(
+  deviceId: string,
+): Promise<{ commands: DeviceCommand[] }> {
+  const res = await baseClient.get(`/devices/${deviceId}/commands`);
+  return res.data;
+}
+
+export async function getDeviceCommand(
+  deviceId: string,
+  commandId: string,
+): Promise<DeviceCommand> {
+  const res = await baseClient.get(`/devices/${deviceId}/commands/${commandId}`);
+  return res.data;
+}
+
+export async function setDeviceLogLevel(
+  deviceId: string,
+  request: SetLogLevelRequest,
+): Promise<DeviceCommand> {
+  const res = await baseClient.post(`/devices/${deviceId}/log-level`, request);
+  return res.data;
+}
+
+export async function requestDiagnostics(
+  deviceId: string,
+): Promise<DiagnosticsBundle> {
+  const res = await baseClient.post(`/devices/${deviceId}/diagnostics`, {});
+  return res.data;
+}
+
+export async function listDiagnostics(
+  deviceId: string,
+): Promise<{ bundles: DiagnosticsBundle[] }> {
+  const res = await baseClient.get(`/devices/${deviceId}/diagnostics`);
+  return res.data;
+}
+
+export async function downloadDiagnostics(
+  deviceId: string,
+  bundleId: string,
+): Promise<Blob> {
+  const res = await baseClient.get(
+    `/devices/${deviceId}/diagnostics/${bundleId}/zip`,
+    { responseType: "blob" },
+  );
+  return res.data;
+}
+
+export async function updateAgent(
+  deviceId: string,
+  request: UpdateAgentRequest,
+): Promise<DeviceCommand> {
+  const res = await baseClient.post(`/devices/${deviceId}/update`, request);
+  return res.data;
+}
+
+export async function listAgentReleases(): Promise<{
+  releases: AgentRelease[];
+}> {
+  const res = await baseClient.get(`/agent-releases`);
+  return res.data;
+}
+
 export async function getEvents(deviceId: string, start: string, end: string): Promise<any> {
   const res = await baseClient.get(
     `/events/${deviceId}?start=${start}&end=${end}`,
   );
   return res.data;
 }
+
+export async function getDNSEvents(deviceId: string, start: string, end: string): Promise<any> {
+  const res = await baseClient.get(
+    `/events/${deviceId}/dns?start=${start}&end=${end}`,
+  );
+  return res.data;
+}
@@ -149,6 +149,69 @@ export interface GetDeviceResponse {
   previousAssociations?: Record<string, InterfaceCaptureMap>;
   pcapVersion: string;
   interfaces: string[];
+  captureStats?: CaptureStats[];
+  eventsDropped?: string; // uint64 is a string in JSON
+  captureStatsUpdatedAt?: string;
+  captureResults?: CaptureResult[];
+  captures?: CaptureStatus[];
+  interfaceDetails?: InterfaceDetails[];
+  online?: boolean;
+  lastSeenAt?: string;
+  agentVersion?: string;
+  heartbeat?: Heartbeat;
+}
+
+export interface Heartbeat {
+  agentVersion: string;
+  commitHash?: string;
+  uptimeSeconds: string; // uint64 is a string in JSON
+  os: string;
+  arch: string;
+  osVersion?: string;
+  kernelVersion?: string;
+  cpuPercent?: number; // percent of one core since the previous heartbeat
+  memoryBytes?: string;
+  capturesRunning?: number;
+  capturesFailed?: number;
+  capturesStopped?: number;
+  receivedAt: string;
+}
+
+export interface InterfaceDetails {
+  name: string;
+  description?: string;
+  addresses?: string[]; // CIDR notation, or a bare IP when the netmask is unknown
+  flags?: string[];
+}
+
+export type CaptureState = "running" | "failed" | "stopped";
+
+export interface CaptureResult {
+  bpf: string;
+  deviceName: string;
+  error?: string;
+  linkType?: string;
+  appliedAt: string;
+  state: CaptureState;
+}
+
+export interface CaptureStatus {
+  deviceName: string;
+  bpf: string;
+  desired?: boolean;
+  actualState: CaptureState | "pending";
+  error?: string;
+  appliedAt?: string;
+}
+
+export interface CaptureStats {
+  bpf: string;
+  deviceName: string;
+  received?: string; // uint64 is a string in JSON
+  droppedByKernel?: string;
+  droppedByInterface?: string;
+  droppedByAgent?: string;
+  startedAt: string;
 }
 
 export interface InterfaceCaptureMap {
@@ -161,6 +224,9 @@ export interface CaptureConfig {
   promiscuous?: boolean;
   snapLen?: number;
   timeout?: number;
+  flowMode?: boolean;
+  httpMode?: boolean;
+  ringBuffer?: boolean;
 }
 
 export interface UpdateDeviceRequest {
@@ -171,6 +237,81 @@ export interface UpdateDeviceRequest {
   interfaceBpfAssociations?: Record<string, InterfaceCaptureMap>;
 }
 
+export interface RequestPacketSliceRequest {
+  deviceName: string;
+  bpf: string;
+  filter?: string;
+  startTime: string; // RFC 3339
+  endTime: string; // RFC 3339
+}
+
+export interface PacketSlice {
+  id: string;
+  deviceId: string;
+  deviceName: string;
+  bpf: string;
+  filter: string;
+  startTime: string;
+  endTime: string;
+  status: "requested" | "complete" | "failed";
+  error: string;
+  packetCount: string; // uint64 is a string in JSON
+  sizeBytes: string;
+  truncated: boolean;
+  requestedAt: string;
+  completedAt?: string;
+  // only set in the response to the request
+  commandId?: string;
+}
+
+export interface DeviceCommand {
+  id: string;
+  deviceId: string;
+  name: string;
+  args: string; // the command's typed arguments as JSON
+  issuedBy: string;
+  issuedAt: string;
+  expiresAt: string;
+  status: "pending" | "delivered" | "succeeded" | "failed" | "expired";
+  error: string;
+  deliveredAt?: string;
+  startedAt?: string;
+  finishedAt?: string;
+}
+
+export interface SetLogLevelRequest {
+  level: "debug" | "info" | "warn" | "error";
+  // defaults to an hour, at most a day
+  revertAfterSeconds?: number;
+}
+
+export interface DiagnosticsBundle {
+  id: string;
+  deviceId: string;
+  status: "requested" | "complete" | "failed";
+  error: string;
+  sizeBytes: string; // uint64 is a string in JSON
+  requestedAt: string;
+  completedAt?: string;
+  // only set in the response to the request
+  commandId?: string;
+}
+
+export interface UpdateAgentRequest {
+  version: string;
+}
+
+export interface AgentRelease {
+  id: string;
+  version: string;
+  os: string;
+  arch: string;
+  sha256: string;
+  sizeBytes: string; // uint64 is a string in JSON
+  signature: string; // base64 detached ed25519 signature of the release manifest
+  publishedAt: string;
+}
+
 export interface GetPacketEventResponse {
   event_time: string;
   bpf: string;
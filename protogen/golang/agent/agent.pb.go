@@ -9,6 +9,7 @@ package agent
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -58,8 +59,13 @@ func (*Empty) Descriptor() ([]byte, []int) {
 }
 
 type InterfaceDetails struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// the interface's addresses in CIDR notation, or as a bare IP when the netmask is unknown
+	Addresses []string `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	// the interface's libpcap flags, e.g. UP, RUNNING, LOOPBACK
+	Flags         []string `protobuf:"bytes,4,rep,name=flags,proto3" json:"flags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -101,6 +107,28 @@ func (x *InterfaceDetails) GetName() string {
 	return ""
 }
 
+func (x *InterfaceDetails) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *InterfaceDetails) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *InterfaceDetails) GetFlags() []string {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+// ReportInterfacesRequest lists every interface of the device, sent at bootstrap and whenever the interfaces change
 type ReportInterfacesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Interfaces    []*InterfaceDetails    `protobuf:"bytes,1,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
@@ -153,9 +181,26 @@ func (x *ReportInterfacesRequest) GetPcapVersion() string {
 	return ""
 }
 
+// Command is the envelope of a command issued to the agent.
+// Commands issued before the server tracked them have no id, and no result is reported for them.
 type Command struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Id    string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	// the id of the administrator who issued the command, or the component that issued it on its own
+	IssuedBy string                 `protobuf:"bytes,4,opt,name=issued_by,json=issuedBy,proto3" json:"issued_by,omitempty"`
+	IssuedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	// the agent doesn't run a command received after its expiry, reporting it as expired instead
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// the typed arguments of the commands that take any
+	//
+	// Types that are valid to be assigned to Args:
+	//
+	//	*Command_UploadPacketSlice
+	//	*Command_SetLogLevel
+	//	*Command_CollectDiagnostics
+	//	*Command_UpdateAgent
+	Args          isCommand_Args `protobuf_oneof:"args"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -197,76 +242,135 @@ func (x *Command) GetName() string {
 	return ""
 }
 
-type CommandsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Commands      []*Command             `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Command) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
 }
 
-func (x *CommandsResponse) Reset() {
-	*x = CommandsResponse{}
-	mi := &file_agent_agent_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Command) GetIssuedBy() string {
+	if x != nil {
+		return x.IssuedBy
+	}
+	return ""
 }
 
-func (x *CommandsResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Command) GetIssuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
 }
 
-func (*CommandsResponse) ProtoMessage() {}
+func (x *Command) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
 
-func (x *CommandsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[4]
+func (x *Command) GetArgs() isCommand_Args {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Command) GetUploadPacketSlice() *UploadPacketSliceArgs {
+	if x != nil {
+		if x, ok := x.Args.(*Command_UploadPacketSlice); ok {
+			return x.UploadPacketSlice
 		}
-		return ms
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use CommandsResponse.ProtoReflect.Descriptor instead.
-func (*CommandsResponse) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{4}
+func (x *Command) GetSetLogLevel() *SetLogLevelArgs {
+	if x != nil {
+		if x, ok := x.Args.(*Command_SetLogLevel); ok {
+			return x.SetLogLevel
+		}
+	}
+	return nil
 }
 
-func (x *CommandsResponse) GetCommands() []*Command {
+func (x *Command) GetCollectDiagnostics() *CollectDiagnosticsArgs {
 	if x != nil {
-		return x.Commands
+		if x, ok := x.Args.(*Command_CollectDiagnostics); ok {
+			return x.CollectDiagnostics
+		}
 	}
 	return nil
 }
 
-type CaptureConfig struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Bpf           string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
-	DeviceName    string                 `protobuf:"bytes,2,opt,name=deviceName,proto3" json:"deviceName,omitempty"`
-	Promiscuous   bool                   `protobuf:"varint,3,opt,name=promiscuous,proto3" json:"promiscuous,omitempty"`
-	SnapLen       int32                  `protobuf:"varint,4,opt,name=snapLen,proto3" json:"snapLen,omitempty"`
-	Timeout       int64                  `protobuf:"varint,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+func (x *Command) GetUpdateAgent() *UpdateAgentArgs {
+	if x != nil {
+		if x, ok := x.Args.(*Command_UpdateAgent); ok {
+			return x.UpdateAgent
+		}
+	}
+	return nil
+}
+
+type isCommand_Args interface {
+	isCommand_Args()
+}
+
+type Command_UploadPacketSlice struct {
+	UploadPacketSlice *UploadPacketSliceArgs `protobuf:"bytes,7,opt,name=upload_packet_slice,json=uploadPacketSlice,proto3,oneof"`
+}
+
+type Command_SetLogLevel struct {
+	SetLogLevel *SetLogLevelArgs `protobuf:"bytes,8,opt,name=set_log_level,json=setLogLevel,proto3,oneof"`
+}
+
+type Command_CollectDiagnostics struct {
+	CollectDiagnostics *CollectDiagnosticsArgs `protobuf:"bytes,9,opt,name=collect_diagnostics,json=collectDiagnostics,proto3,oneof"`
+}
+
+type Command_UpdateAgent struct {
+	UpdateAgent *UpdateAgentArgs `protobuf:"bytes,10,opt,name=update_agent,json=updateAgent,proto3,oneof"`
+}
+
+func (*Command_UploadPacketSlice) isCommand_Args() {}
+
+func (*Command_SetLogLevel) isCommand_Args() {}
+
+func (*Command_CollectDiagnostics) isCommand_Args() {}
+
+func (*Command_UpdateAgent) isCommand_Args() {}
+
+// UploadPacketSliceArgs are the arguments of the `upload_packet_slice` command
+type UploadPacketSliceArgs struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	SliceId string                 `protobuf:"bytes,1,opt,name=slice_id,json=sliceId,proto3" json:"slice_id,omitempty"`
+	// the interface and BPF of the ring buffer capture to read the packets of
+	DeviceName string `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	Bpf        string `protobuf:"bytes,3,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	// an optional BPF further filtering the packets of the slice
+	Filter        string                 `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end,proto3" json:"end,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CaptureConfig) Reset() {
-	*x = CaptureConfig{}
-	mi := &file_agent_agent_proto_msgTypes[5]
+func (x *UploadPacketSliceArgs) Reset() {
+	*x = UploadPacketSliceArgs{}
+	mi := &file_agent_agent_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CaptureConfig) String() string {
+func (x *UploadPacketSliceArgs) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CaptureConfig) ProtoMessage() {}
+func (*UploadPacketSliceArgs) ProtoMessage() {}
 
-func (x *CaptureConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[5]
+func (x *UploadPacketSliceArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -277,70 +381,79 @@ func (x *CaptureConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CaptureConfig.ProtoReflect.Descriptor instead.
-func (*CaptureConfig) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use UploadPacketSliceArgs.ProtoReflect.Descriptor instead.
+func (*UploadPacketSliceArgs) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *CaptureConfig) GetBpf() string {
+func (x *UploadPacketSliceArgs) GetSliceId() string {
 	if x != nil {
-		return x.Bpf
+		return x.SliceId
 	}
 	return ""
 }
 
-func (x *CaptureConfig) GetDeviceName() string {
+func (x *UploadPacketSliceArgs) GetDeviceName() string {
 	if x != nil {
 		return x.DeviceName
 	}
 	return ""
 }
 
-func (x *CaptureConfig) GetPromiscuous() bool {
+func (x *UploadPacketSliceArgs) GetBpf() string {
 	if x != nil {
-		return x.Promiscuous
+		return x.Bpf
 	}
-	return false
+	return ""
 }
 
-func (x *CaptureConfig) GetSnapLen() int32 {
+func (x *UploadPacketSliceArgs) GetFilter() string {
 	if x != nil {
-		return x.SnapLen
+		return x.Filter
 	}
-	return 0
+	return ""
 }
 
-func (x *CaptureConfig) GetTimeout() int64 {
+func (x *UploadPacketSliceArgs) GetStart() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Timeout
+		return x.Start
 	}
-	return 0
+	return nil
 }
 
-type BPFConfig struct {
-	state         protoimpl.MessageState          `protogen:"open.v1"`
-	Create        map[string]*InterfaceCaptureMap `protobuf:"bytes,1,rep,name=create,proto3" json:"create,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Update        map[string]*InterfaceCaptureMap `protobuf:"bytes,2,rep,name=update,proto3" json:"update,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Delete        map[string]*InterfaceCaptureMap `protobuf:"bytes,3,rep,name=delete,proto3" json:"delete,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *UploadPacketSliceArgs) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
 }
 
-func (x *BPFConfig) Reset() {
-	*x = BPFConfig{}
-	mi := &file_agent_agent_proto_msgTypes[6]
+// SetLogLevelArgs are the arguments of the `set_log_level` command
+type SetLogLevelArgs struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// debug, info, warn or error
+	Level string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	// the agent reverts to its default level after this many seconds
+	RevertAfterSeconds int64 `protobuf:"varint,2,opt,name=revert_after_seconds,json=revertAfterSeconds,proto3" json:"revert_after_seconds,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SetLogLevelArgs) Reset() {
+	*x = SetLogLevelArgs{}
+	mi := &file_agent_agent_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BPFConfig) String() string {
+func (x *SetLogLevelArgs) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BPFConfig) ProtoMessage() {}
+func (*SetLogLevelArgs) ProtoMessage() {}
 
-func (x *BPFConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[6]
+func (x *SetLogLevelArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -351,53 +464,98 @@ func (x *BPFConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BPFConfig.ProtoReflect.Descriptor instead.
-func (*BPFConfig) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use SetLogLevelArgs.ProtoReflect.Descriptor instead.
+func (*SetLogLevelArgs) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *BPFConfig) GetCreate() map[string]*InterfaceCaptureMap {
+func (x *SetLogLevelArgs) GetLevel() string {
 	if x != nil {
-		return x.Create
+		return x.Level
 	}
-	return nil
+	return ""
 }
 
-func (x *BPFConfig) GetUpdate() map[string]*InterfaceCaptureMap {
+func (x *SetLogLevelArgs) GetRevertAfterSeconds() int64 {
 	if x != nil {
-		return x.Update
+		return x.RevertAfterSeconds
 	}
-	return nil
+	return 0
 }
 
-func (x *BPFConfig) GetDelete() map[string]*InterfaceCaptureMap {
+// CollectDiagnosticsArgs are the arguments of the `collect_diagnostics` command
+type CollectDiagnosticsArgs struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BundleId      string                 `protobuf:"bytes,1,opt,name=bundle_id,json=bundleId,proto3" json:"bundle_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectDiagnosticsArgs) Reset() {
+	*x = CollectDiagnosticsArgs{}
+	mi := &file_agent_agent_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectDiagnosticsArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectDiagnosticsArgs) ProtoMessage() {}
+
+func (x *CollectDiagnosticsArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[6]
 	if x != nil {
-		return x.Delete
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type InterfaceCaptureMap struct {
-	state         protoimpl.MessageState    `protogen:"open.v1"`
-	Captures      map[uint64]*CaptureConfig `protobuf:"bytes,1,rep,name=captures,proto3" json:"captures,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+// Deprecated: Use CollectDiagnosticsArgs.ProtoReflect.Descriptor instead.
+func (*CollectDiagnosticsArgs) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CollectDiagnosticsArgs) GetBundleId() string {
+	if x != nil {
+		return x.BundleId
+	}
+	return ""
+}
+
+// UpdateAgentArgs are the arguments of the `update_agent` command, the release of the artifact registry to update to
+type UpdateAgentArgs struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Version string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// the hex SHA-256 and the size of the agent binary of the release for the device's OS and architecture
+	Sha256    string `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	SizeBytes uint64 `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// the detached ed25519 signature of the manifest of the release's version, os, arch, sha256 and size,
+	// checked against the release signing key built into the agent
+	Signature     []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InterfaceCaptureMap) Reset() {
-	*x = InterfaceCaptureMap{}
+func (x *UpdateAgentArgs) Reset() {
+	*x = UpdateAgentArgs{}
 	mi := &file_agent_agent_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InterfaceCaptureMap) String() string {
+func (x *UpdateAgentArgs) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InterfaceCaptureMap) ProtoMessage() {}
+func (*UpdateAgentArgs) ProtoMessage() {}
 
-func (x *InterfaceCaptureMap) ProtoReflect() protoreflect.Message {
+func (x *UpdateAgentArgs) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_agent_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -409,47 +567,67 @@ func (x *InterfaceCaptureMap) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InterfaceCaptureMap.ProtoReflect.Descriptor instead.
-func (*InterfaceCaptureMap) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateAgentArgs.ProtoReflect.Descriptor instead.
+func (*UpdateAgentArgs) Descriptor() ([]byte, []int) {
 	return file_agent_agent_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *InterfaceCaptureMap) GetCaptures() map[uint64]*CaptureConfig {
+func (x *UpdateAgentArgs) GetVersion() string {
 	if x != nil {
-		return x.Captures
+		return x.Version
+	}
+	return ""
+}
+
+func (x *UpdateAgentArgs) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *UpdateAgentArgs) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *UpdateAgentArgs) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
 	}
 	return nil
 }
 
-type PacketEvent struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Bpf            string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
-	DeviceName     string                 `protobuf:"bytes,2,opt,name=deviceName,proto3" json:"deviceName,omitempty"`
-	Promiscuous    bool                   `protobuf:"varint,3,opt,name=promiscuous,proto3" json:"promiscuous,omitempty"`
-	SnapLen        int32                  `protobuf:"varint,4,opt,name=snapLen,proto3" json:"snapLen,omitempty"`
-	CaptureLength  uint32                 `protobuf:"varint,5,opt,name=capture_length,json=captureLength,proto3" json:"capture_length,omitempty"`
-	OriginalLength uint32                 `protobuf:"varint,6,opt,name=original_length,json=originalLength,proto3" json:"original_length,omitempty"`
-	InterfaceIndex int32                  `protobuf:"varint,7,opt,name=interface_index,json=interfaceIndex,proto3" json:"interface_index,omitempty"`
-	Truncated      bool                   `protobuf:"varint,8,opt,name=truncated,proto3" json:"truncated,omitempty"`
-	Layers         *Layers                `protobuf:"bytes,9,opt,name=layers,proto3" json:"layers,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// CommandResult is the outcome of a command the agent received
+type CommandResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	CommandId string                 `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// "succeeded", "failed" or "expired"
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PacketEvent) Reset() {
-	*x = PacketEvent{}
+func (x *CommandResult) Reset() {
+	*x = CommandResult{}
 	mi := &file_agent_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PacketEvent) String() string {
+func (x *CommandResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PacketEvent) ProtoMessage() {}
+func (*CommandResult) ProtoMessage() {}
 
-func (x *PacketEvent) ProtoReflect() protoreflect.Message {
+func (x *CommandResult) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -461,99 +639,2196 @@ func (x *PacketEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PacketEvent.ProtoReflect.Descriptor instead.
-func (*PacketEvent) Descriptor() ([]byte, []int) {
+// Deprecated: Use CommandResult.ProtoReflect.Descriptor instead.
+func (*CommandResult) Descriptor() ([]byte, []int) {
 	return file_agent_agent_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *PacketEvent) GetBpf() string {
+func (x *CommandResult) GetCommandId() string {
 	if x != nil {
-		return x.Bpf
+		return x.CommandId
 	}
 	return ""
 }
 
-func (x *PacketEvent) GetDeviceName() string {
+func (x *CommandResult) GetName() string {
 	if x != nil {
-		return x.DeviceName
+		return x.Name
 	}
 	return ""
 }
 
-func (x *PacketEvent) GetPromiscuous() bool {
+func (x *CommandResult) GetStatus() string {
 	if x != nil {
-		return x.Promiscuous
+		return x.Status
 	}
-	return false
+	return ""
 }
 
-func (x *PacketEvent) GetSnapLen() int32 {
+func (x *CommandResult) GetError() string {
 	if x != nil {
-		return x.SnapLen
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-func (x *PacketEvent) GetCaptureLength() uint32 {
+func (x *CommandResult) GetStartedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.CaptureLength
+		return x.StartedAt
 	}
-	return 0
+	return nil
 }
 
-func (x *PacketEvent) GetOriginalLength() uint32 {
+func (x *CommandResult) GetFinishedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.OriginalLength
+		return x.FinishedAt
 	}
-	return 0
+	return nil
+}
+
+// CommandStreamRequest is sent by the agent when it opens the command stream, then for every command it receives with an id
+type CommandStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the id of the command received, empty for the message opening the stream.
+	// A command is only acked off the device's commands subject once the agent acknowledges it,
+	// so a command sent on a stream that breaks is delivered again.
+	AckCommandId  string `protobuf:"bytes,1,opt,name=ack_command_id,json=ackCommandId,proto3" json:"ack_command_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandStreamRequest) Reset() {
+	*x = CommandStreamRequest{}
+	mi := &file_agent_agent_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandStreamRequest) ProtoMessage() {}
+
+func (x *CommandStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandStreamRequest.ProtoReflect.Descriptor instead.
+func (*CommandStreamRequest) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CommandStreamRequest) GetAckCommandId() string {
+	if x != nil {
+		return x.AckCommandId
+	}
+	return ""
+}
+
+type CommandsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*Command             `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandsResponse) Reset() {
+	*x = CommandsResponse{}
+	mi := &file_agent_agent_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandsResponse) ProtoMessage() {}
+
+func (x *CommandsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandsResponse.ProtoReflect.Descriptor instead.
+func (*CommandsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CommandsResponse) GetCommands() []*Command {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+type CaptureConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bpf           string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,2,opt,name=deviceName,proto3" json:"deviceName,omitempty"`
+	Promiscuous   bool                   `protobuf:"varint,3,opt,name=promiscuous,proto3" json:"promiscuous,omitempty"`
+	SnapLen       int32                  `protobuf:"varint,4,opt,name=snapLen,proto3" json:"snapLen,omitempty"`
+	Timeout       int64                  `protobuf:"varint,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	FlowMode      bool                   `protobuf:"varint,6,opt,name=flowMode,proto3" json:"flowMode,omitempty"`
+	HttpMode      bool                   `protobuf:"varint,7,opt,name=httpMode,proto3" json:"httpMode,omitempty"`
+	RingBuffer    bool                   `protobuf:"varint,8,opt,name=ringBuffer,proto3" json:"ringBuffer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureConfig) Reset() {
+	*x = CaptureConfig{}
+	mi := &file_agent_agent_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureConfig) ProtoMessage() {}
+
+func (x *CaptureConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureConfig.ProtoReflect.Descriptor instead.
+func (*CaptureConfig) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CaptureConfig) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *CaptureConfig) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *CaptureConfig) GetPromiscuous() bool {
+	if x != nil {
+		return x.Promiscuous
+	}
+	return false
+}
+
+func (x *CaptureConfig) GetSnapLen() int32 {
+	if x != nil {
+		return x.SnapLen
+	}
+	return 0
+}
+
+func (x *CaptureConfig) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *CaptureConfig) GetFlowMode() bool {
+	if x != nil {
+		return x.FlowMode
+	}
+	return false
+}
+
+func (x *CaptureConfig) GetHttpMode() bool {
+	if x != nil {
+		return x.HttpMode
+	}
+	return false
+}
+
+func (x *CaptureConfig) GetRingBuffer() bool {
+	if x != nil {
+		return x.RingBuffer
+	}
+	return false
+}
+
+// CaptureStats are the packet counters of a live capture, cumulative since the capture started
+type CaptureStats struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Bpf        string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	DeviceName string                 `protobuf:"bytes,2,opt,name=deviceName,proto3" json:"deviceName,omitempty"`
+	// packets the capture received from the kernel, after the BPF
+	Received uint64 `protobuf:"varint,3,opt,name=received,proto3" json:"received,omitempty"`
+	// packets the kernel dropped because the capture's buffer was full
+	DroppedByKernel uint64 `protobuf:"varint,4,opt,name=dropped_by_kernel,json=droppedByKernel,proto3" json:"dropped_by_kernel,omitempty"`
+	// packets the interface or its driver dropped, not reported on every platform
+	DroppedByInterface uint64 `protobuf:"varint,5,opt,name=dropped_by_interface,json=droppedByInterface,proto3" json:"dropped_by_interface,omitempty"`
+	// packets the agent dropped because it couldn't keep up with the capture
+	DroppedByAgent uint64                 `protobuf:"varint,6,opt,name=dropped_by_agent,json=droppedByAgent,proto3" json:"dropped_by_agent,omitempty"`
+	StartedAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CaptureStats) Reset() {
+	*x = CaptureStats{}
+	mi := &file_agent_agent_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureStats) ProtoMessage() {}
+
+func (x *CaptureStats) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureStats.ProtoReflect.Descriptor instead.
+func (*CaptureStats) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CaptureStats) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *CaptureStats) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *CaptureStats) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetDroppedByKernel() uint64 {
+	if x != nil {
+		return x.DroppedByKernel
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetDroppedByInterface() uint64 {
+	if x != nil {
+		return x.DroppedByInterface
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetDroppedByAgent() uint64 {
+	if x != nil {
+		return x.DroppedByAgent
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+type ReportCaptureStatsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Captures []*CaptureStats        `protobuf:"bytes,1,rep,name=captures,proto3" json:"captures,omitempty"`
+	// packet events lost after capture, with no stream or spool to take them or to spool overflow, cumulative since the agent started
+	EventsDropped uint64                 `protobuf:"varint,2,opt,name=events_dropped,json=eventsDropped,proto3" json:"events_dropped,omitempty"`
+	CollectedAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportCaptureStatsRequest) Reset() {
+	*x = ReportCaptureStatsRequest{}
+	mi := &file_agent_agent_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportCaptureStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportCaptureStatsRequest) ProtoMessage() {}
+
+func (x *ReportCaptureStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportCaptureStatsRequest.ProtoReflect.Descriptor instead.
+func (*ReportCaptureStatsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReportCaptureStatsRequest) GetCaptures() []*CaptureStats {
+	if x != nil {
+		return x.Captures
+	}
+	return nil
+}
+
+func (x *ReportCaptureStatsRequest) GetEventsDropped() uint64 {
+	if x != nil {
+		return x.EventsDropped
+	}
+	return 0
+}
+
+func (x *ReportCaptureStatsRequest) GetCollectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return nil
+}
+
+// CaptureResult is the outcome of applying one capture of a BPF config from the server
+type CaptureResult struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Bpf        string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	DeviceName string                 `protobuf:"bytes,2,opt,name=deviceName,proto3" json:"deviceName,omitempty"`
+	// why the capture failed to start, empty if it started
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// the libpcap name of the interface's link type, e.g. EN10MB, set once the capture opened the interface
+	LinkType string `protobuf:"bytes,4,opt,name=link_type,json=linkType,proto3" json:"link_type,omitempty"`
+	// when the capture started, or failed to
+	AppliedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	// running, failed to start, or stopped when the interface stopped delivering packets, e.g. because it went away
+	State         string `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureResult) Reset() {
+	*x = CaptureResult{}
+	mi := &file_agent_agent_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureResult) ProtoMessage() {}
+
+func (x *CaptureResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureResult.ProtoReflect.Descriptor instead.
+func (*CaptureResult) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CaptureResult) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetLinkType() string {
+	if x != nil {
+		return x.LinkType
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetAppliedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return nil
+}
+
+func (x *CaptureResult) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type ReportCaptureResultsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the state of every capture of the agent, replacing the ones it last reported
+	Results       []*CaptureResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportCaptureResultsRequest) Reset() {
+	*x = ReportCaptureResultsRequest{}
+	mi := &file_agent_agent_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportCaptureResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportCaptureResultsRequest) ProtoMessage() {}
+
+func (x *ReportCaptureResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportCaptureResultsRequest.ProtoReflect.Descriptor instead.
+func (*ReportCaptureResultsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReportCaptureResultsRequest) GetResults() []*CaptureResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// DiagnosticsChunk is a piece of the zip file of a diagnostics bundle requested by a `collect_diagnostics` command.
+// The agent sends the file in order over one stream, then closes it.
+type DiagnosticsChunk struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	BundleId string                 `protobuf:"bytes,1,opt,name=bundle_id,json=bundleId,proto3" json:"bundle_id,omitempty"`
+	Data     []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// set on the last chunk when the agent could not collect the bundle
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnosticsChunk) Reset() {
+	*x = DiagnosticsChunk{}
+	mi := &file_agent_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnosticsChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnosticsChunk) ProtoMessage() {}
+
+func (x *DiagnosticsChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnosticsChunk.ProtoReflect.Descriptor instead.
+func (*DiagnosticsChunk) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DiagnosticsChunk) GetBundleId() string {
+	if x != nil {
+		return x.BundleId
+	}
+	return ""
+}
+
+func (x *DiagnosticsChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DiagnosticsChunk) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// PacketSliceChunk is a piece of the classic pcap file of the packets of a capture's ring buffer requested by an `upload_packet_slice` command.
+// The agent sends the file in order over one stream, then closes it.
+type PacketSliceChunk struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	SliceId string                 `protobuf:"bytes,1,opt,name=slice_id,json=sliceId,proto3" json:"slice_id,omitempty"`
+	Data    []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// set on the last chunk when the agent could not read the rest of the slice
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// the number of packets in the slice and whether it stopped at the max slice size, set on the last chunk
+	PacketCount   uint64 `protobuf:"varint,4,opt,name=packet_count,json=packetCount,proto3" json:"packet_count,omitempty"`
+	Truncated     bool   `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PacketSliceChunk) Reset() {
+	*x = PacketSliceChunk{}
+	mi := &file_agent_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PacketSliceChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PacketSliceChunk) ProtoMessage() {}
+
+func (x *PacketSliceChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PacketSliceChunk.ProtoReflect.Descriptor instead.
+func (*PacketSliceChunk) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PacketSliceChunk) GetSliceId() string {
+	if x != nil {
+		return x.SliceId
+	}
+	return ""
+}
+
+func (x *PacketSliceChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *PacketSliceChunk) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PacketSliceChunk) GetPacketCount() uint64 {
+	if x != nil {
+		return x.PacketCount
+	}
+	return 0
+}
+
+func (x *PacketSliceChunk) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type BPFConfig struct {
+	state  protoimpl.MessageState          `protogen:"open.v1"`
+	Create map[string]*InterfaceCaptureMap `protobuf:"bytes,1,rep,name=create,proto3" json:"create,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Update map[string]*InterfaceCaptureMap `protobuf:"bytes,2,rep,name=update,proto3" json:"update,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Delete map[string]*InterfaceCaptureMap `protobuf:"bytes,3,rep,name=delete,proto3" json:"delete,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// the full set of associations the device should be capturing, for agents reconciling their live captures
+	Desired       map[string]*InterfaceCaptureMap `protobuf:"bytes,4,rep,name=desired,proto3" json:"desired,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BPFConfig) Reset() {
+	*x = BPFConfig{}
+	mi := &file_agent_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BPFConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BPFConfig) ProtoMessage() {}
+
+func (x *BPFConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BPFConfig.ProtoReflect.Descriptor instead.
+func (*BPFConfig) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BPFConfig) GetCreate() map[string]*InterfaceCaptureMap {
+	if x != nil {
+		return x.Create
+	}
+	return nil
+}
+
+func (x *BPFConfig) GetUpdate() map[string]*InterfaceCaptureMap {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
+
+func (x *BPFConfig) GetDelete() map[string]*InterfaceCaptureMap {
+	if x != nil {
+		return x.Delete
+	}
+	return nil
+}
+
+func (x *BPFConfig) GetDesired() map[string]*InterfaceCaptureMap {
+	if x != nil {
+		return x.Desired
+	}
+	return nil
+}
+
+type InterfaceCaptureMap struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Captures      map[uint64]*CaptureConfig `protobuf:"bytes,1,rep,name=captures,proto3" json:"captures,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InterfaceCaptureMap) Reset() {
+	*x = InterfaceCaptureMap{}
+	mi := &file_agent_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InterfaceCaptureMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterfaceCaptureMap) ProtoMessage() {}
+
+func (x *InterfaceCaptureMap) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterfaceCaptureMap.ProtoReflect.Descriptor instead.
+func (*InterfaceCaptureMap) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *InterfaceCaptureMap) GetCaptures() map[uint64]*CaptureConfig {
+	if x != nil {
+		return x.Captures
+	}
+	return nil
+}
+
+type PacketEvent struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Bpf            string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	DeviceName     string                 `protobuf:"bytes,2,opt,name=deviceName,proto3" json:"deviceName,omitempty"`
+	Promiscuous    bool                   `protobuf:"varint,3,opt,name=promiscuous,proto3" json:"promiscuous,omitempty"`
+	SnapLen        int32                  `protobuf:"varint,4,opt,name=snapLen,proto3" json:"snapLen,omitempty"`
+	CaptureLength  uint32                 `protobuf:"varint,5,opt,name=capture_length,json=captureLength,proto3" json:"capture_length,omitempty"`
+	OriginalLength uint32                 `protobuf:"varint,6,opt,name=original_length,json=originalLength,proto3" json:"original_length,omitempty"`
+	InterfaceIndex int32                  `protobuf:"varint,7,opt,name=interface_index,json=interfaceIndex,proto3" json:"interface_index,omitempty"`
+	Truncated      bool                   `protobuf:"varint,8,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	Layers         *Layers                `protobuf:"bytes,9,opt,name=layers,proto3" json:"layers,omitempty"`
+	CaptureTime    *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=capture_time,json=captureTime,proto3" json:"capture_time,omitempty"` // when the packet was captured, from the pcap header
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PacketEvent) Reset() {
+	*x = PacketEvent{}
+	mi := &file_agent_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PacketEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PacketEvent) ProtoMessage() {}
+
+func (x *PacketEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PacketEvent.ProtoReflect.Descriptor instead.
+func (*PacketEvent) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PacketEvent) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *PacketEvent) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *PacketEvent) GetPromiscuous() bool {
+	if x != nil {
+		return x.Promiscuous
+	}
+	return false
+}
+
+func (x *PacketEvent) GetSnapLen() int32 {
+	if x != nil {
+		return x.SnapLen
+	}
+	return 0
+}
+
+func (x *PacketEvent) GetCaptureLength() uint32 {
+	if x != nil {
+		return x.CaptureLength
+	}
+	return 0
+}
+
+func (x *PacketEvent) GetOriginalLength() uint32 {
+	if x != nil {
+		return x.OriginalLength
+	}
+	return 0
 }
 
 func (x *PacketEvent) GetInterfaceIndex() int32 {
 	if x != nil {
-		return x.InterfaceIndex
+		return x.InterfaceIndex
+	}
+	return 0
+}
+
+func (x *PacketEvent) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *PacketEvent) GetLayers() *Layers {
+	if x != nil {
+		return x.Layers
+	}
+	return nil
+}
+
+func (x *PacketEvent) GetCaptureTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CaptureTime
+	}
+	return nil
+}
+
+// PacketEventBatch carries the packet events the agent buffered over a size or time window
+type PacketEventBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*PacketEvent         `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PacketEventBatch) Reset() {
+	*x = PacketEventBatch{}
+	mi := &file_agent_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PacketEventBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PacketEventBatch) ProtoMessage() {}
+
+func (x *PacketEventBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PacketEventBatch.ProtoReflect.Descriptor instead.
+func (*PacketEventBatch) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PacketEventBatch) GetEvents() []*PacketEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type Layers struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	IpLayer  *IPLayer               `protobuf:"bytes,1,opt,name=ip_layer,json=ipLayer,proto3" json:"ip_layer,omitempty"`
+	TcpLayer *TCPLayer              `protobuf:"bytes,2,opt,name=tcp_layer,json=tcpLayer,proto3" json:"tcp_layer,omitempty"`
+	UdpLayer *UDPLayer              `protobuf:"bytes,3,opt,name=udp_layer,json=udpLayer,proto3" json:"udp_layer,omitempty"`
+	TlsLayer *TLSLayer              `protobuf:"bytes,4,opt,name=tls_layer,json=tlsLayer,proto3" json:"tls_layer,omitempty"`
+	DnsLayer *DNSLayer              `protobuf:"bytes,5,opt,name=dns_layer,json=dnsLayer,proto3" json:"dns_layer,omitempty"`
+	// set on the packet that completes the headers of HTTP/1.x messages, for captures in http mode,
+	// or on an event without packet data (zero original_length) for the messages completed when the agent flushes reassembly
+	HttpLayer     *HTTPLayer     `protobuf:"bytes,6,opt,name=http_layer,json=httpLayer,proto3" json:"http_layer,omitempty"`
+	EthernetLayer *EthernetLayer `protobuf:"bytes,7,opt,name=ethernet_layer,json=ethernetLayer,proto3" json:"ethernet_layer,omitempty"`
+	ArpLayer      *ARPLayer      `protobuf:"bytes,8,opt,name=arp_layer,json=arpLayer,proto3" json:"arp_layer,omitempty"`
+	IcmpLayer     *ICMPLayer     `protobuf:"bytes,9,opt,name=icmp_layer,json=icmpLayer,proto3" json:"icmp_layer,omitempty"`
+	// The encapsulations of a tunneled packet, outermost first, e.g. ["VLAN", "VXLAN"].
+	// When set, the ip, tcp, udp and icmp layers are those of the innermost packet.
+	TunnelTypes   []string  `protobuf:"bytes,10,rep,name=tunnel_types,json=tunnelTypes,proto3" json:"tunnel_types,omitempty"`
+	Tunnels       []*Tunnel `protobuf:"bytes,11,rep,name=tunnels,proto3" json:"tunnels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Layers) Reset() {
+	*x = Layers{}
+	mi := &file_agent_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Layers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Layers) ProtoMessage() {}
+
+func (x *Layers) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Layers.ProtoReflect.Descriptor instead.
+func (*Layers) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Layers) GetIpLayer() *IPLayer {
+	if x != nil {
+		return x.IpLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetTcpLayer() *TCPLayer {
+	if x != nil {
+		return x.TcpLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetUdpLayer() *UDPLayer {
+	if x != nil {
+		return x.UdpLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetTlsLayer() *TLSLayer {
+	if x != nil {
+		return x.TlsLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetDnsLayer() *DNSLayer {
+	if x != nil {
+		return x.DnsLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetHttpLayer() *HTTPLayer {
+	if x != nil {
+		return x.HttpLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetEthernetLayer() *EthernetLayer {
+	if x != nil {
+		return x.EthernetLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetArpLayer() *ARPLayer {
+	if x != nil {
+		return x.ArpLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetIcmpLayer() *ICMPLayer {
+	if x != nil {
+		return x.IcmpLayer
+	}
+	return nil
+}
+
+func (x *Layers) GetTunnelTypes() []string {
+	if x != nil {
+		return x.TunnelTypes
+	}
+	return nil
+}
+
+func (x *Layers) GetTunnels() []*Tunnel {
+	if x != nil {
+		return x.Tunnels
+	}
+	return nil
+}
+
+type Tunnel struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "VLAN", "GRE", "VXLAN", "GENEVE" or "IPIP" for IP in IP of either version
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// the VLAN id, GRE key or VXLAN/GENEVE network identifier
+	Id uint32 `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	// the IP header carrying the tunnel, unset for VLAN
+	OuterIpLayer *IPLayer `protobuf:"bytes,3,opt,name=outer_ip_layer,json=outerIpLayer,proto3" json:"outer_ip_layer,omitempty"`
+	// the UDP header carrying VXLAN and GENEVE
+	OuterUdpLayer *UDPLayer `protobuf:"bytes,4,opt,name=outer_udp_layer,json=outerUdpLayer,proto3" json:"outer_udp_layer,omitempty"`
+	// the encapsulated Ethernet header of VXLAN, GENEVE and GRE transparent Ethernet bridging
+	InnerEthernetLayer *EthernetLayer `protobuf:"bytes,5,opt,name=inner_ethernet_layer,json=innerEthernetLayer,proto3" json:"inner_ethernet_layer,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Tunnel) Reset() {
+	*x = Tunnel{}
+	mi := &file_agent_agent_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tunnel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tunnel) ProtoMessage() {}
+
+func (x *Tunnel) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tunnel.ProtoReflect.Descriptor instead.
+func (*Tunnel) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Tunnel) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Tunnel) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Tunnel) GetOuterIpLayer() *IPLayer {
+	if x != nil {
+		return x.OuterIpLayer
+	}
+	return nil
+}
+
+func (x *Tunnel) GetOuterUdpLayer() *UDPLayer {
+	if x != nil {
+		return x.OuterUdpLayer
+	}
+	return nil
+}
+
+func (x *Tunnel) GetInnerEthernetLayer() *EthernetLayer {
+	if x != nil {
+		return x.InnerEthernetLayer
+	}
+	return nil
+}
+
+type EthernetLayer struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	SrcMac string                 `protobuf:"bytes,1,opt,name=src_mac,json=srcMac,proto3" json:"src_mac,omitempty"`
+	DstMac string                 `protobuf:"bytes,2,opt,name=dst_mac,json=dstMac,proto3" json:"dst_mac,omitempty"`
+	// the EtherType of the payload, after any VLAN tags, e.g. "IPv4" or "ARP"
+	EtherType string `protobuf:"bytes,3,opt,name=ether_type,json=etherType,proto3" json:"ether_type,omitempty"`
+	// outermost first, more than one for QinQ
+	VlanTags      []*VLANTag `protobuf:"bytes,4,rep,name=vlan_tags,json=vlanTags,proto3" json:"vlan_tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EthernetLayer) Reset() {
+	*x = EthernetLayer{}
+	mi := &file_agent_agent_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EthernetLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EthernetLayer) ProtoMessage() {}
+
+func (x *EthernetLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EthernetLayer.ProtoReflect.Descriptor instead.
+func (*EthernetLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *EthernetLayer) GetSrcMac() string {
+	if x != nil {
+		return x.SrcMac
+	}
+	return ""
+}
+
+func (x *EthernetLayer) GetDstMac() string {
+	if x != nil {
+		return x.DstMac
+	}
+	return ""
+}
+
+func (x *EthernetLayer) GetEtherType() string {
+	if x != nil {
+		return x.EtherType
+	}
+	return ""
+}
+
+func (x *EthernetLayer) GetVlanTags() []*VLANTag {
+	if x != nil {
+		return x.VlanTags
+	}
+	return nil
+}
+
+type VLANTag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Priority      uint32                 `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	DropEligible  bool                   `protobuf:"varint,3,opt,name=drop_eligible,json=dropEligible,proto3" json:"drop_eligible,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VLANTag) Reset() {
+	*x = VLANTag{}
+	mi := &file_agent_agent_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VLANTag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VLANTag) ProtoMessage() {}
+
+func (x *VLANTag) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VLANTag.ProtoReflect.Descriptor instead.
+func (*VLANTag) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *VLANTag) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *VLANTag) GetPriority() uint32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *VLANTag) GetDropEligible() bool {
+	if x != nil {
+		return x.DropEligible
+	}
+	return false
+}
+
+type ARPLayer struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "request" or "reply"
+	Operation     string `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	SenderMac     string `protobuf:"bytes,2,opt,name=sender_mac,json=senderMac,proto3" json:"sender_mac,omitempty"`
+	SenderIp      string `protobuf:"bytes,3,opt,name=sender_ip,json=senderIp,proto3" json:"sender_ip,omitempty"`
+	TargetMac     string `protobuf:"bytes,4,opt,name=target_mac,json=targetMac,proto3" json:"target_mac,omitempty"`
+	TargetIp      string `protobuf:"bytes,5,opt,name=target_ip,json=targetIp,proto3" json:"target_ip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ARPLayer) Reset() {
+	*x = ARPLayer{}
+	mi := &file_agent_agent_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ARPLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ARPLayer) ProtoMessage() {}
+
+func (x *ARPLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ARPLayer.ProtoReflect.Descriptor instead.
+func (*ARPLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ARPLayer) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *ARPLayer) GetSenderMac() string {
+	if x != nil {
+		return x.SenderMac
+	}
+	return ""
+}
+
+func (x *ARPLayer) GetSenderIp() string {
+	if x != nil {
+		return x.SenderIp
+	}
+	return ""
+}
+
+func (x *ARPLayer) GetTargetMac() string {
+	if x != nil {
+		return x.TargetMac
+	}
+	return ""
+}
+
+func (x *ARPLayer) GetTargetIp() string {
+	if x != nil {
+		return x.TargetIp
+	}
+	return ""
+}
+
+type ICMPLayer struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Version string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"` // "ICMPv4" or "ICMPv6"
+	Type    uint32                 `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	Code    uint32                 `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	// e.g. "EchoRequest" or "DestinationUnreachable(Port)"
+	TypeCode string `protobuf:"bytes,4,opt,name=type_code,json=typeCode,proto3" json:"type_code,omitempty"`
+	// echo request and reply fields
+	Id            uint32 `protobuf:"varint,5,opt,name=id,proto3" json:"id,omitempty"`
+	Seq           uint32 `protobuf:"varint,6,opt,name=seq,proto3" json:"seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ICMPLayer) Reset() {
+	*x = ICMPLayer{}
+	mi := &file_agent_agent_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ICMPLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ICMPLayer) ProtoMessage() {}
+
+func (x *ICMPLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ICMPLayer.ProtoReflect.Descriptor instead.
+func (*ICMPLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ICMPLayer) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ICMPLayer) GetType() uint32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *ICMPLayer) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ICMPLayer) GetTypeCode() string {
+	if x != nil {
+		return x.TypeCode
+	}
+	return ""
+}
+
+func (x *ICMPLayer) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ICMPLayer) GetSeq() uint32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+type IPLayer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"` // "IPv4" or "IPv6"
+	SrcIp         string                 `protobuf:"bytes,2,opt,name=src_ip,json=srcIp,proto3" json:"src_ip,omitempty"`
+	DstIp         string                 `protobuf:"bytes,3,opt,name=dst_ip,json=dstIp,proto3" json:"dst_ip,omitempty"`
+	Ttl           uint32                 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`                           // For IPv4
+	HopLimit      uint32                 `protobuf:"varint,5,opt,name=hop_limit,json=hopLimit,proto3" json:"hop_limit,omitempty"` // For IPv6
+	Protocol      uint32                 `protobuf:"varint,6,opt,name=protocol,proto3" json:"protocol,omitempty"`                 // L4 protocol number
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IPLayer) Reset() {
+	*x = IPLayer{}
+	mi := &file_agent_agent_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPLayer) ProtoMessage() {}
+
+func (x *IPLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPLayer.ProtoReflect.Descriptor instead.
+func (*IPLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *IPLayer) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *IPLayer) GetSrcIp() string {
+	if x != nil {
+		return x.SrcIp
+	}
+	return ""
+}
+
+func (x *IPLayer) GetDstIp() string {
+	if x != nil {
+		return x.DstIp
+	}
+	return ""
+}
+
+func (x *IPLayer) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *IPLayer) GetHopLimit() uint32 {
+	if x != nil {
+		return x.HopLimit
+	}
+	return 0
+}
+
+func (x *IPLayer) GetProtocol() uint32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+type TCPLayer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SrcPort       uint32                 `protobuf:"varint,1,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
+	DstPort       uint32                 `protobuf:"varint,2,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
+	Seq           uint32                 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	Ack           uint32                 `protobuf:"varint,4,opt,name=ack,proto3" json:"ack,omitempty"`
+	Fin           bool                   `protobuf:"varint,5,opt,name=fin,proto3" json:"fin,omitempty"`
+	Syn           bool                   `protobuf:"varint,6,opt,name=syn,proto3" json:"syn,omitempty"`
+	Rst           bool                   `protobuf:"varint,7,opt,name=rst,proto3" json:"rst,omitempty"`
+	Psh           bool                   `protobuf:"varint,8,opt,name=psh,proto3" json:"psh,omitempty"`
+	AckFlag       bool                   `protobuf:"varint,9,opt,name=ack_flag,json=ackFlag,proto3" json:"ack_flag,omitempty"`
+	Urg           bool                   `protobuf:"varint,10,opt,name=urg,proto3" json:"urg,omitempty"`
+	Window        uint32                 `protobuf:"varint,11,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TCPLayer) Reset() {
+	*x = TCPLayer{}
+	mi := &file_agent_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TCPLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TCPLayer) ProtoMessage() {}
+
+func (x *TCPLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TCPLayer.ProtoReflect.Descriptor instead.
+func (*TCPLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TCPLayer) GetSrcPort() uint32 {
+	if x != nil {
+		return x.SrcPort
+	}
+	return 0
+}
+
+func (x *TCPLayer) GetDstPort() uint32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+func (x *TCPLayer) GetSeq() uint32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *TCPLayer) GetAck() uint32 {
+	if x != nil {
+		return x.Ack
+	}
+	return 0
+}
+
+func (x *TCPLayer) GetFin() bool {
+	if x != nil {
+		return x.Fin
+	}
+	return false
+}
+
+func (x *TCPLayer) GetSyn() bool {
+	if x != nil {
+		return x.Syn
+	}
+	return false
+}
+
+func (x *TCPLayer) GetRst() bool {
+	if x != nil {
+		return x.Rst
+	}
+	return false
+}
+
+func (x *TCPLayer) GetPsh() bool {
+	if x != nil {
+		return x.Psh
+	}
+	return false
+}
+
+func (x *TCPLayer) GetAckFlag() bool {
+	if x != nil {
+		return x.AckFlag
+	}
+	return false
+}
+
+func (x *TCPLayer) GetUrg() bool {
+	if x != nil {
+		return x.Urg
+	}
+	return false
+}
+
+func (x *TCPLayer) GetWindow() uint32 {
+	if x != nil {
+		return x.Window
+	}
+	return 0
+}
+
+type UDPLayer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SrcPort       uint32                 `protobuf:"varint,1,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
+	DstPort       uint32                 `protobuf:"varint,2,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
+	Length        uint32                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UDPLayer) Reset() {
+	*x = UDPLayer{}
+	mi := &file_agent_agent_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UDPLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UDPLayer) ProtoMessage() {}
+
+func (x *UDPLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UDPLayer.ProtoReflect.Descriptor instead.
+func (*UDPLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UDPLayer) GetSrcPort() uint32 {
+	if x != nil {
+		return x.SrcPort
+	}
+	return 0
+}
+
+func (x *UDPLayer) GetDstPort() uint32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+func (x *UDPLayer) GetLength() uint32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type TLSLayer struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Records []*TLSRecord           `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	// set when the packet carries a complete plaintext ClientHello
+	ClientHello *TLSClientHello `protobuf:"bytes,2,opt,name=client_hello,json=clientHello,proto3" json:"client_hello,omitempty"`
+	// set when the packet carries a complete plaintext ServerHello
+	ServerHello   *TLSServerHello `protobuf:"bytes,3,opt,name=server_hello,json=serverHello,proto3" json:"server_hello,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TLSLayer) Reset() {
+	*x = TLSLayer{}
+	mi := &file_agent_agent_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLSLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSLayer) ProtoMessage() {}
+
+func (x *TLSLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSLayer.ProtoReflect.Descriptor instead.
+func (*TLSLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TLSLayer) GetRecords() []*TLSRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *TLSLayer) GetClientHello() *TLSClientHello {
+	if x != nil {
+		return x.ClientHello
+	}
+	return nil
+}
+
+func (x *TLSLayer) GetServerHello() *TLSServerHello {
+	if x != nil {
+		return x.ServerHello
+	}
+	return nil
+}
+
+// Cipher suites, extensions, groups and algorithms are the IANA registry values, in the order they were sent.
+type TLSClientHello struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Version             string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Sni                 string                 `protobuf:"bytes,2,opt,name=sni,proto3" json:"sni,omitempty"`
+	Alpn                []string               `protobuf:"bytes,3,rep,name=alpn,proto3" json:"alpn,omitempty"`
+	CipherSuites        []uint32               `protobuf:"varint,4,rep,packed,name=cipher_suites,json=cipherSuites,proto3" json:"cipher_suites,omitempty"`
+	Extensions          []uint32               `protobuf:"varint,5,rep,packed,name=extensions,proto3" json:"extensions,omitempty"`
+	SupportedGroups     []uint32               `protobuf:"varint,6,rep,packed,name=supported_groups,json=supportedGroups,proto3" json:"supported_groups,omitempty"`
+	EcPointFormats      []uint32               `protobuf:"varint,7,rep,packed,name=ec_point_formats,json=ecPointFormats,proto3" json:"ec_point_formats,omitempty"`
+	SignatureAlgorithms []uint32               `protobuf:"varint,8,rep,packed,name=signature_algorithms,json=signatureAlgorithms,proto3" json:"signature_algorithms,omitempty"`
+	SupportedVersions   []uint32               `protobuf:"varint,9,rep,packed,name=supported_versions,json=supportedVersions,proto3" json:"supported_versions,omitempty"`
+	Ja3                 string                 `protobuf:"bytes,10,opt,name=ja3,proto3" json:"ja3,omitempty"`
+	Ja3Hash             string                 `protobuf:"bytes,11,opt,name=ja3_hash,json=ja3Hash,proto3" json:"ja3_hash,omitempty"`
+	Ja4                 string                 `protobuf:"bytes,12,opt,name=ja4,proto3" json:"ja4,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *TLSClientHello) Reset() {
+	*x = TLSClientHello{}
+	mi := &file_agent_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLSClientHello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSClientHello) ProtoMessage() {}
+
+func (x *TLSClientHello) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSClientHello.ProtoReflect.Descriptor instead.
+func (*TLSClientHello) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TLSClientHello) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *TLSClientHello) GetSni() string {
+	if x != nil {
+		return x.Sni
+	}
+	return ""
+}
+
+func (x *TLSClientHello) GetAlpn() []string {
+	if x != nil {
+		return x.Alpn
+	}
+	return nil
+}
+
+func (x *TLSClientHello) GetCipherSuites() []uint32 {
+	if x != nil {
+		return x.CipherSuites
+	}
+	return nil
+}
+
+func (x *TLSClientHello) GetExtensions() []uint32 {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *TLSClientHello) GetSupportedGroups() []uint32 {
+	if x != nil {
+		return x.SupportedGroups
+	}
+	return nil
+}
+
+func (x *TLSClientHello) GetEcPointFormats() []uint32 {
+	if x != nil {
+		return x.EcPointFormats
+	}
+	return nil
+}
+
+func (x *TLSClientHello) GetSignatureAlgorithms() []uint32 {
+	if x != nil {
+		return x.SignatureAlgorithms
+	}
+	return nil
+}
+
+func (x *TLSClientHello) GetSupportedVersions() []uint32 {
+	if x != nil {
+		return x.SupportedVersions
+	}
+	return nil
+}
+
+func (x *TLSClientHello) GetJa3() string {
+	if x != nil {
+		return x.Ja3
+	}
+	return ""
+}
+
+func (x *TLSClientHello) GetJa3Hash() string {
+	if x != nil {
+		return x.Ja3Hash
+	}
+	return ""
+}
+
+func (x *TLSClientHello) GetJa4() string {
+	if x != nil {
+		return x.Ja4
+	}
+	return ""
+}
+
+type TLSServerHello struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Version     string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	CipherSuite uint32                 `protobuf:"varint,2,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`
+	Extensions  []uint32               `protobuf:"varint,3,rep,packed,name=extensions,proto3" json:"extensions,omitempty"`
+	Alpn        string                 `protobuf:"bytes,4,opt,name=alpn,proto3" json:"alpn,omitempty"`
+	// the version selected by the supported_versions extension, e.g. for TLS 1.3
+	SupportedVersion string `protobuf:"bytes,5,opt,name=supported_version,json=supportedVersion,proto3" json:"supported_version,omitempty"`
+	Ja3S             string `protobuf:"bytes,6,opt,name=ja3s,proto3" json:"ja3s,omitempty"`
+	Ja3SHash         string `protobuf:"bytes,7,opt,name=ja3s_hash,json=ja3sHash,proto3" json:"ja3s_hash,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TLSServerHello) Reset() {
+	*x = TLSServerHello{}
+	mi := &file_agent_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLSServerHello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSServerHello) ProtoMessage() {}
+
+func (x *TLSServerHello) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSServerHello.ProtoReflect.Descriptor instead.
+func (*TLSServerHello) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TLSServerHello) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *TLSServerHello) GetCipherSuite() uint32 {
+	if x != nil {
+		return x.CipherSuite
+	}
+	return 0
+}
+
+func (x *TLSServerHello) GetExtensions() []uint32 {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *TLSServerHello) GetAlpn() string {
+	if x != nil {
+		return x.Alpn
+	}
+	return ""
+}
+
+func (x *TLSServerHello) GetSupportedVersion() string {
+	if x != nil {
+		return x.SupportedVersion
+	}
+	return ""
+}
+
+func (x *TLSServerHello) GetJa3S() string {
+	if x != nil {
+		return x.Ja3S
+	}
+	return ""
+}
+
+func (x *TLSServerHello) GetJa3SHash() string {
+	if x != nil {
+		return x.Ja3SHash
+	}
+	return ""
+}
+
+type TLSRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Length        uint32                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TLSRecord) Reset() {
+	*x = TLSRecord{}
+	mi := &file_agent_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLSRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSRecord) ProtoMessage() {}
+
+func (x *TLSRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSRecord.ProtoReflect.Descriptor instead.
+func (*TLSRecord) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *TLSRecord) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TLSRecord) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *TLSRecord) GetLength() uint32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type DNSLayer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`             // transaction id, shared by a query and its response
+	Response      bool                   `protobuf:"varint,2,opt,name=response,proto3" json:"response,omitempty"` // the QR bit, false for queries
+	Opcode        string                 `protobuf:"bytes,3,opt,name=opcode,proto3" json:"opcode,omitempty"`
+	ResponseCode  string                 `protobuf:"bytes,4,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"` // e.g. "No Error", "Non-Existent Domain"
+	Authoritative bool                   `protobuf:"varint,5,opt,name=authoritative,proto3" json:"authoritative,omitempty"`
+	Truncated     bool                   `protobuf:"varint,6,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	Questions     []*DNSQuestion         `protobuf:"bytes,7,rep,name=questions,proto3" json:"questions,omitempty"`
+	Answers       []*DNSResourceRecord   `protobuf:"bytes,8,rep,name=answers,proto3" json:"answers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSLayer) Reset() {
+	*x = DNSLayer{}
+	mi := &file_agent_agent_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSLayer) ProtoMessage() {}
+
+func (x *DNSLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSLayer.ProtoReflect.Descriptor instead.
+func (*DNSLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DNSLayer) GetId() uint32 {
+	if x != nil {
+		return x.Id
 	}
 	return 0
 }
 
-func (x *PacketEvent) GetTruncated() bool {
+func (x *DNSLayer) GetResponse() bool {
+	if x != nil {
+		return x.Response
+	}
+	return false
+}
+
+func (x *DNSLayer) GetOpcode() string {
+	if x != nil {
+		return x.Opcode
+	}
+	return ""
+}
+
+func (x *DNSLayer) GetResponseCode() string {
+	if x != nil {
+		return x.ResponseCode
+	}
+	return ""
+}
+
+func (x *DNSLayer) GetAuthoritative() bool {
+	if x != nil {
+		return x.Authoritative
+	}
+	return false
+}
+
+func (x *DNSLayer) GetTruncated() bool {
 	if x != nil {
 		return x.Truncated
 	}
 	return false
 }
 
-func (x *PacketEvent) GetLayers() *Layers {
+func (x *DNSLayer) GetQuestions() []*DNSQuestion {
 	if x != nil {
-		return x.Layers
+		return x.Questions
 	}
 	return nil
 }
 
-type Layers struct {
+func (x *DNSLayer) GetAnswers() []*DNSResourceRecord {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+type DNSQuestion struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	IpLayer       *IPLayer               `protobuf:"bytes,1,opt,name=ip_layer,json=ipLayer,proto3" json:"ip_layer,omitempty"`
-	TcpLayer      *TCPLayer              `protobuf:"bytes,2,opt,name=tcp_layer,json=tcpLayer,proto3" json:"tcp_layer,omitempty"`
-	UdpLayer      *UDPLayer              `protobuf:"bytes,3,opt,name=udp_layer,json=udpLayer,proto3" json:"udp_layer,omitempty"`
-	TlsLayer      *TLSLayer              `protobuf:"bytes,4,opt,name=tls_layer,json=tlsLayer,proto3" json:"tls_layer,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // e.g. "A", "AAAA", "CNAME"
+	Class         string                 `protobuf:"bytes,3,opt,name=class,proto3" json:"class,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Layers) Reset() {
-	*x = Layers{}
-	mi := &file_agent_agent_proto_msgTypes[9]
+func (x *DNSQuestion) Reset() {
+	*x = DNSQuestion{}
+	mi := &file_agent_agent_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Layers) String() string {
+func (x *DNSQuestion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Layers) ProtoMessage() {}
+func (*DNSQuestion) ProtoMessage() {}
 
-func (x *Layers) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[9]
+func (x *DNSQuestion) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -564,66 +2839,332 @@ func (x *Layers) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Layers.ProtoReflect.Descriptor instead.
-func (*Layers) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use DNSQuestion.ProtoReflect.Descriptor instead.
+func (*DNSQuestion) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *Layers) GetIpLayer() *IPLayer {
+func (x *DNSQuestion) GetName() string {
 	if x != nil {
-		return x.IpLayer
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DNSQuestion) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DNSQuestion) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+type DNSResourceRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Class         string                 `protobuf:"bytes,3,opt,name=class,proto3" json:"class,omitempty"`
+	Ttl           uint32                 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Data          string                 `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"` // the record data in presentation format, e.g. the address of an A record
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSResourceRecord) Reset() {
+	*x = DNSResourceRecord{}
+	mi := &file_agent_agent_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSResourceRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSResourceRecord) ProtoMessage() {}
+
+func (x *DNSResourceRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSResourceRecord.ProtoReflect.Descriptor instead.
+func (*DNSResourceRecord) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *DNSResourceRecord) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DNSResourceRecord) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DNSResourceRecord) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *DNSResourceRecord) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *DNSResourceRecord) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+type FlowRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bpf           string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,2,opt,name=deviceName,proto3" json:"deviceName,omitempty"`
+	IpVersion     string                 `protobuf:"bytes,3,opt,name=ip_version,json=ipVersion,proto3" json:"ip_version,omitempty"` // "IPv4" or "IPv6"
+	SrcIp         string                 `protobuf:"bytes,4,opt,name=src_ip,json=srcIp,proto3" json:"src_ip,omitempty"`
+	DstIp         string                 `protobuf:"bytes,5,opt,name=dst_ip,json=dstIp,proto3" json:"dst_ip,omitempty"`
+	SrcPort       uint32                 `protobuf:"varint,6,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
+	DstPort       uint32                 `protobuf:"varint,7,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
+	Protocol      uint32                 `protobuf:"varint,8,opt,name=protocol,proto3" json:"protocol,omitempty"` // L4 protocol number
+	Packets       uint64                 `protobuf:"varint,9,opt,name=packets,proto3" json:"packets,omitempty"`
+	Bytes         uint64                 `protobuf:"varint,10,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	TcpFlags      uint32                 `protobuf:"varint,11,opt,name=tcp_flags,json=tcpFlags,proto3" json:"tcp_flags,omitempty"` // union of the TCP flags seen over the flow's lifetime
+	FirstSeen     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	EndReason     string                 `protobuf:"bytes,14,opt,name=end_reason,json=endReason,proto3" json:"end_reason,omitempty"` // "active_timeout", "idle_timeout", "tcp_close" or "capture_stop"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowRecord) Reset() {
+	*x = FlowRecord{}
+	mi := &file_agent_agent_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowRecord) ProtoMessage() {}
+
+func (x *FlowRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowRecord.ProtoReflect.Descriptor instead.
+func (*FlowRecord) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *FlowRecord) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetIpVersion() string {
+	if x != nil {
+		return x.IpVersion
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetSrcIp() string {
+	if x != nil {
+		return x.SrcIp
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetDstIp() string {
+	if x != nil {
+		return x.DstIp
+	}
+	return ""
+}
+
+func (x *FlowRecord) GetSrcPort() uint32 {
+	if x != nil {
+		return x.SrcPort
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetDstPort() uint32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetProtocol() uint32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetPackets() uint64 {
+	if x != nil {
+		return x.Packets
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetTcpFlags() uint32 {
+	if x != nil {
+		return x.TcpFlags
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetFirstSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeen
 	}
 	return nil
 }
 
-func (x *Layers) GetTcpLayer() *TCPLayer {
+func (x *FlowRecord) GetLastSeen() *timestamppb.Timestamp {
 	if x != nil {
-		return x.TcpLayer
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *FlowRecord) GetEndReason() string {
+	if x != nil {
+		return x.EndReason
+	}
+	return ""
+}
+
+type HTTPLayer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*HTTPMessage         `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HTTPLayer) Reset() {
+	*x = HTTPLayer{}
+	mi := &file_agent_agent_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HTTPLayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTTPLayer) ProtoMessage() {}
+
+func (x *HTTPLayer) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *Layers) GetUdpLayer() *UDPLayer {
-	if x != nil {
-		return x.UdpLayer
-	}
-	return nil
+// Deprecated: Use HTTPLayer.ProtoReflect.Descriptor instead.
+func (*HTTPLayer) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *Layers) GetTlsLayer() *TLSLayer {
+func (x *HTTPLayer) GetMessages() []*HTTPMessage {
 	if x != nil {
-		return x.TlsLayer
+		return x.Messages
 	}
 	return nil
 }
 
-type IPLayer struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"` // "IPv4" or "IPv6"
-	SrcIp         string                 `protobuf:"bytes,2,opt,name=src_ip,json=srcIp,proto3" json:"src_ip,omitempty"`
-	DstIp         string                 `protobuf:"bytes,3,opt,name=dst_ip,json=dstIp,proto3" json:"dst_ip,omitempty"`
-	Ttl           uint32                 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`                           // For IPv4
-	HopLimit      uint32                 `protobuf:"varint,5,opt,name=hop_limit,json=hopLimit,proto3" json:"hop_limit,omitempty"` // For IPv6
-	Protocol      uint32                 `protobuf:"varint,6,opt,name=protocol,proto3" json:"protocol,omitempty"`                 // L4 protocol number
+type HTTPMessage struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Response bool                   `protobuf:"varint,1,opt,name=response,proto3" json:"response,omitempty"`
+	Version  string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// request fields
+	Method    string `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Host      string `protobuf:"bytes,4,opt,name=host,proto3" json:"host,omitempty"`
+	Uri       string `protobuf:"bytes,5,opt,name=uri,proto3" json:"uri,omitempty"`
+	UserAgent string `protobuf:"bytes,6,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	// response fields
+	StatusCode int32 `protobuf:"varint,7,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	// -1 when unknown, e.g. for chunked bodies
+	ContentLength int64 `protobuf:"varint,8,opt,name=content_length,json=contentLength,proto3" json:"content_length,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IPLayer) Reset() {
-	*x = IPLayer{}
-	mi := &file_agent_agent_proto_msgTypes[10]
+func (x *HTTPMessage) Reset() {
+	*x = HTTPMessage{}
+	mi := &file_agent_agent_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IPLayer) String() string {
+func (x *HTTPMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IPLayer) ProtoMessage() {}
+func (*HTTPMessage) ProtoMessage() {}
 
-func (x *IPLayer) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[10]
+func (x *HTTPMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -634,85 +3175,106 @@ func (x *IPLayer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IPLayer.ProtoReflect.Descriptor instead.
-func (*IPLayer) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use HTTPMessage.ProtoReflect.Descriptor instead.
+func (*HTTPMessage) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *IPLayer) GetVersion() string {
+func (x *HTTPMessage) GetResponse() bool {
+	if x != nil {
+		return x.Response
+	}
+	return false
+}
+
+func (x *HTTPMessage) GetVersion() string {
 	if x != nil {
 		return x.Version
 	}
 	return ""
 }
 
-func (x *IPLayer) GetSrcIp() string {
+func (x *HTTPMessage) GetMethod() string {
 	if x != nil {
-		return x.SrcIp
+		return x.Method
 	}
 	return ""
 }
 
-func (x *IPLayer) GetDstIp() string {
+func (x *HTTPMessage) GetHost() string {
 	if x != nil {
-		return x.DstIp
+		return x.Host
 	}
 	return ""
 }
 
-func (x *IPLayer) GetTtl() uint32 {
+func (x *HTTPMessage) GetUri() string {
 	if x != nil {
-		return x.Ttl
+		return x.Uri
 	}
-	return 0
+	return ""
 }
 
-func (x *IPLayer) GetHopLimit() uint32 {
+func (x *HTTPMessage) GetUserAgent() string {
 	if x != nil {
-		return x.HopLimit
+		return x.UserAgent
 	}
-	return 0
+	return ""
 }
 
-func (x *IPLayer) GetProtocol() uint32 {
+func (x *HTTPMessage) GetStatusCode() int32 {
 	if x != nil {
-		return x.Protocol
+		return x.StatusCode
 	}
 	return 0
 }
 
-type TCPLayer struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SrcPort       uint32                 `protobuf:"varint,1,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
-	DstPort       uint32                 `protobuf:"varint,2,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
-	Seq           uint32                 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
-	Ack           uint32                 `protobuf:"varint,4,opt,name=ack,proto3" json:"ack,omitempty"`
-	Fin           bool                   `protobuf:"varint,5,opt,name=fin,proto3" json:"fin,omitempty"`
-	Syn           bool                   `protobuf:"varint,6,opt,name=syn,proto3" json:"syn,omitempty"`
-	Rst           bool                   `protobuf:"varint,7,opt,name=rst,proto3" json:"rst,omitempty"`
-	Psh           bool                   `protobuf:"varint,8,opt,name=psh,proto3" json:"psh,omitempty"`
-	AckFlag       bool                   `protobuf:"varint,9,opt,name=ack_flag,json=ackFlag,proto3" json:"ack_flag,omitempty"`
-	Urg           bool                   `protobuf:"varint,10,opt,name=urg,proto3" json:"urg,omitempty"`
-	Window        uint32                 `protobuf:"varint,11,opt,name=window,proto3" json:"window,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *HTTPMessage) GetContentLength() int64 {
+	if x != nil {
+		return x.ContentLength
+	}
+	return 0
 }
 
-func (x *TCPLayer) Reset() {
-	*x = TCPLayer{}
-	mi := &file_agent_agent_proto_msgTypes[11]
+// HeartbeatRequest is sent by the agent every minute, so the server knows it is alive and how it is doing
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentVersion  string                 `protobuf:"bytes,1,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	CommitHash    string                 `protobuf:"bytes,2,opt,name=commit_hash,json=commitHash,proto3" json:"commit_hash,omitempty"`
+	UptimeSeconds uint64                 `protobuf:"varint,3,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	// the agent's GOOS and GOARCH, e.g. linux and amd64
+	Os   string `protobuf:"bytes,4,opt,name=os,proto3" json:"os,omitempty"`
+	Arch string `protobuf:"bytes,5,opt,name=arch,proto3" json:"arch,omitempty"`
+	// the OS's name and version, e.g. "Ubuntu 24.04.2 LTS", and the kernel's release, e.g. "6.8.0-60-generic"
+	OsVersion     string `protobuf:"bytes,6,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"`
+	KernelVersion string `protobuf:"bytes,7,opt,name=kernel_version,json=kernelVersion,proto3" json:"kernel_version,omitempty"`
+	// the CPU time the agent used since the previous heartbeat, in percent of one core
+	CpuPercent float64 `protobuf:"fixed64,8,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	// the memory the agent's Go runtime has mapped from the OS
+	MemoryBytes uint64 `protobuf:"varint,9,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	// the number of captures in each capture state
+	CapturesRunning uint32 `protobuf:"varint,10,opt,name=captures_running,json=capturesRunning,proto3" json:"captures_running,omitempty"`
+	CapturesFailed  uint32 `protobuf:"varint,11,opt,name=captures_failed,json=capturesFailed,proto3" json:"captures_failed,omitempty"`
+	CapturesStopped uint32 `protobuf:"varint,12,opt,name=captures_stopped,json=capturesStopped,proto3" json:"captures_stopped,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_agent_agent_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TCPLayer) String() string {
+func (x *HeartbeatRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TCPLayer) ProtoMessage() {}
+func (*HeartbeatRequest) ProtoMessage() {}
 
-func (x *TCPLayer) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[11]
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -723,112 +3285,121 @@ func (x *TCPLayer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TCPLayer.ProtoReflect.Descriptor instead.
-func (*TCPLayer) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *TCPLayer) GetSrcPort() uint32 {
+func (x *HeartbeatRequest) GetAgentVersion() string {
 	if x != nil {
-		return x.SrcPort
+		return x.AgentVersion
 	}
-	return 0
+	return ""
 }
 
-func (x *TCPLayer) GetDstPort() uint32 {
+func (x *HeartbeatRequest) GetCommitHash() string {
 	if x != nil {
-		return x.DstPort
+		return x.CommitHash
 	}
-	return 0
+	return ""
 }
 
-func (x *TCPLayer) GetSeq() uint32 {
+func (x *HeartbeatRequest) GetUptimeSeconds() uint64 {
 	if x != nil {
-		return x.Seq
+		return x.UptimeSeconds
 	}
 	return 0
 }
 
-func (x *TCPLayer) GetAck() uint32 {
+func (x *HeartbeatRequest) GetOs() string {
 	if x != nil {
-		return x.Ack
+		return x.Os
 	}
-	return 0
+	return ""
 }
 
-func (x *TCPLayer) GetFin() bool {
+func (x *HeartbeatRequest) GetArch() string {
 	if x != nil {
-		return x.Fin
+		return x.Arch
 	}
-	return false
+	return ""
 }
 
-func (x *TCPLayer) GetSyn() bool {
+func (x *HeartbeatRequest) GetOsVersion() string {
 	if x != nil {
-		return x.Syn
+		return x.OsVersion
 	}
-	return false
+	return ""
 }
 
-func (x *TCPLayer) GetRst() bool {
+func (x *HeartbeatRequest) GetKernelVersion() string {
 	if x != nil {
-		return x.Rst
+		return x.KernelVersion
 	}
-	return false
+	return ""
 }
 
-func (x *TCPLayer) GetPsh() bool {
+func (x *HeartbeatRequest) GetCpuPercent() float64 {
 	if x != nil {
-		return x.Psh
+		return x.CpuPercent
 	}
-	return false
+	return 0
 }
 
-func (x *TCPLayer) GetAckFlag() bool {
+func (x *HeartbeatRequest) GetMemoryBytes() uint64 {
 	if x != nil {
-		return x.AckFlag
+		return x.MemoryBytes
 	}
-	return false
+	return 0
 }
 
-func (x *TCPLayer) GetUrg() bool {
+func (x *HeartbeatRequest) GetCapturesRunning() uint32 {
 	if x != nil {
-		return x.Urg
+		return x.CapturesRunning
 	}
-	return false
+	return 0
 }
 
-func (x *TCPLayer) GetWindow() uint32 {
+func (x *HeartbeatRequest) GetCapturesFailed() uint32 {
 	if x != nil {
-		return x.Window
+		return x.CapturesFailed
 	}
 	return 0
 }
 
-type UDPLayer struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SrcPort       uint32                 `protobuf:"varint,1,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
-	DstPort       uint32                 `protobuf:"varint,2,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
-	Length        uint32                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+func (x *HeartbeatRequest) GetCapturesStopped() uint32 {
+	if x != nil {
+		return x.CapturesStopped
+	}
+	return 0
+}
+
+// DownloadAgentReleaseRequest asks for the agent binary of a release of the artifact registry
+type DownloadAgentReleaseRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Version string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// the agent's GOOS and GOARCH, e.g. linux and amd64
+	Os            string `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Arch          string `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UDPLayer) Reset() {
-	*x = UDPLayer{}
-	mi := &file_agent_agent_proto_msgTypes[12]
+func (x *DownloadAgentReleaseRequest) Reset() {
+	*x = DownloadAgentReleaseRequest{}
+	mi := &file_agent_agent_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UDPLayer) String() string {
+func (x *DownloadAgentReleaseRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UDPLayer) ProtoMessage() {}
+func (*DownloadAgentReleaseRequest) ProtoMessage() {}
 
-func (x *UDPLayer) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[12]
+func (x *DownloadAgentReleaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -839,54 +3410,55 @@ func (x *UDPLayer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UDPLayer.ProtoReflect.Descriptor instead.
-func (*UDPLayer) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use DownloadAgentReleaseRequest.ProtoReflect.Descriptor instead.
+func (*DownloadAgentReleaseRequest) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *UDPLayer) GetSrcPort() uint32 {
+func (x *DownloadAgentReleaseRequest) GetVersion() string {
 	if x != nil {
-		return x.SrcPort
+		return x.Version
 	}
-	return 0
+	return ""
 }
 
-func (x *UDPLayer) GetDstPort() uint32 {
+func (x *DownloadAgentReleaseRequest) GetOs() string {
 	if x != nil {
-		return x.DstPort
+		return x.Os
 	}
-	return 0
+	return ""
 }
 
-func (x *UDPLayer) GetLength() uint32 {
+func (x *DownloadAgentReleaseRequest) GetArch() string {
 	if x != nil {
-		return x.Length
+		return x.Arch
 	}
-	return 0
+	return ""
 }
 
-type TLSLayer struct {
+// AgentReleaseChunk is a piece of an agent binary, sent in order
+type AgentReleaseChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Records       []*TLSRecord           `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TLSLayer) Reset() {
-	*x = TLSLayer{}
-	mi := &file_agent_agent_proto_msgTypes[13]
+func (x *AgentReleaseChunk) Reset() {
+	*x = AgentReleaseChunk{}
+	mi := &file_agent_agent_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TLSLayer) String() string {
+func (x *AgentReleaseChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TLSLayer) ProtoMessage() {}
+func (*AgentReleaseChunk) ProtoMessage() {}
 
-func (x *TLSLayer) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[13]
+func (x *AgentReleaseChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_agent_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -897,95 +3469,80 @@ func (x *TLSLayer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TLSLayer.ProtoReflect.Descriptor instead.
-func (*TLSLayer) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use AgentReleaseChunk.ProtoReflect.Descriptor instead.
+func (*AgentReleaseChunk) Descriptor() ([]byte, []int) {
+	return file_agent_agent_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *TLSLayer) GetRecords() []*TLSRecord {
+func (x *AgentReleaseChunk) GetData() []byte {
 	if x != nil {
-		return x.Records
+		return x.Data
 	}
 	return nil
 }
 
-type TLSRecord struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Length        uint32                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *TLSRecord) Reset() {
-	*x = TLSRecord{}
-	mi := &file_agent_agent_proto_msgTypes[14]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *TLSRecord) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*TLSRecord) ProtoMessage() {}
-
-func (x *TLSRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_agent_proto_msgTypes[14]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use TLSRecord.ProtoReflect.Descriptor instead.
-func (*TLSRecord) Descriptor() ([]byte, []int) {
-	return file_agent_agent_proto_rawDescGZIP(), []int{14}
-}
-
-func (x *TLSRecord) GetType() string {
-	if x != nil {
-		return x.Type
-	}
-	return ""
-}
-
-func (x *TLSRecord) GetVersion() string {
-	if x != nil {
-		return x.Version
-	}
-	return ""
-}
-
-func (x *TLSRecord) GetLength() uint32 {
-	if x != nil {
-		return x.Length
-	}
-	return 0
-}
-
 var File_agent_agent_proto protoreflect.FileDescriptor
 
 const file_agent_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x11agent/agent.proto\x12\x05agent\"\a\n" +
-	"\x05Empty\"&\n" +
+	"\x11agent/agent.proto\x12\x05agent\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\"|\n" +
 	"\x10InterfaceDetails\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\"t\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1c\n" +
+	"\taddresses\x18\x03 \x03(\tR\taddresses\x12\x14\n" +
+	"\x05flags\x18\x04 \x03(\tR\x05flags\"t\n" +
 	"\x17ReportInterfacesRequest\x127\n" +
 	"\n" +
 	"interfaces\x18\x01 \x03(\v2\x17.agent.InterfaceDetailsR\n" +
 	"interfaces\x12 \n" +
-	"\vpcapVersion\x18\x02 \x01(\tR\vpcapVersion\"\x1d\n" +
+	"\vpcapVersion\x18\x02 \x01(\tR\vpcapVersion\"\xef\x03\n" +
 	"\aCommand\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\">\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\x12\x1b\n" +
+	"\tissued_by\x18\x04 \x01(\tR\bissuedBy\x127\n" +
+	"\tissued_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bissuedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12N\n" +
+	"\x13upload_packet_slice\x18\a \x01(\v2\x1c.agent.UploadPacketSliceArgsH\x00R\x11uploadPacketSlice\x12<\n" +
+	"\rset_log_level\x18\b \x01(\v2\x16.agent.SetLogLevelArgsH\x00R\vsetLogLevel\x12P\n" +
+	"\x13collect_diagnostics\x18\t \x01(\v2\x1d.agent.CollectDiagnosticsArgsH\x00R\x12collectDiagnostics\x12;\n" +
+	"\fupdate_agent\x18\n" +
+	" \x01(\v2\x16.agent.UpdateAgentArgsH\x00R\vupdateAgentB\x06\n" +
+	"\x04argsJ\x04\b\x02\x10\x03R\x04args\"\xdd\x01\n" +
+	"\x15UploadPacketSliceArgs\x12\x19\n" +
+	"\bslice_id\x18\x01 \x01(\tR\asliceId\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x10\n" +
+	"\x03bpf\x18\x03 \x01(\tR\x03bpf\x12\x16\n" +
+	"\x06filter\x18\x04 \x01(\tR\x06filter\x120\n" +
+	"\x05start\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\"Y\n" +
+	"\x0fSetLogLevelArgs\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x120\n" +
+	"\x14revert_after_seconds\x18\x02 \x01(\x03R\x12revertAfterSeconds\"5\n" +
+	"\x16CollectDiagnosticsArgs\x12\x1b\n" +
+	"\tbundle_id\x18\x01 \x01(\tR\bbundleId\"\x80\x01\n" +
+	"\x0fUpdateAgentArgs\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x04R\tsizeBytes\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\fR\tsignature\"\xe8\x01\n" +
+	"\rCommandResult\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\x01 \x01(\tR\tcommandId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"<\n" +
+	"\x14CommandStreamRequest\x12$\n" +
+	"\x0eack_command_id\x18\x01 \x01(\tR\fackCommandId\">\n" +
 	"\x10CommandsResponse\x12*\n" +
-	"\bcommands\x18\x01 \x03(\v2\x0e.agent.CommandR\bcommands\"\x97\x01\n" +
+	"\bcommands\x18\x01 \x03(\v2\x0e.agent.CommandR\bcommands\"\xef\x01\n" +
 	"\rCaptureConfig\x12\x10\n" +
 	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1e\n" +
 	"\n" +
@@ -993,11 +3550,54 @@ const file_agent_agent_proto_rawDesc = "" +
 	"deviceName\x12 \n" +
 	"\vpromiscuous\x18\x03 \x01(\bR\vpromiscuous\x12\x18\n" +
 	"\asnapLen\x18\x04 \x01(\x05R\asnapLen\x12\x18\n" +
-	"\atimeout\x18\x05 \x01(\x03R\atimeout\"\xb2\x03\n" +
+	"\atimeout\x18\x05 \x01(\x03R\atimeout\x12\x1a\n" +
+	"\bflowMode\x18\x06 \x01(\bR\bflowMode\x12\x1a\n" +
+	"\bhttpMode\x18\a \x01(\bR\bhttpMode\x12\x1e\n" +
+	"\n" +
+	"ringBuffer\x18\b \x01(\bR\n" +
+	"ringBuffer\"\x9f\x02\n" +
+	"\fCaptureStats\x12\x10\n" +
+	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1e\n" +
+	"\n" +
+	"deviceName\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x1a\n" +
+	"\breceived\x18\x03 \x01(\x04R\breceived\x12*\n" +
+	"\x11dropped_by_kernel\x18\x04 \x01(\x04R\x0fdroppedByKernel\x120\n" +
+	"\x14dropped_by_interface\x18\x05 \x01(\x04R\x12droppedByInterface\x12(\n" +
+	"\x10dropped_by_agent\x18\x06 \x01(\x04R\x0edroppedByAgent\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\"\xb2\x01\n" +
+	"\x19ReportCaptureStatsRequest\x12/\n" +
+	"\bcaptures\x18\x01 \x03(\v2\x13.agent.CaptureStatsR\bcaptures\x12%\n" +
+	"\x0eevents_dropped\x18\x02 \x01(\x04R\reventsDropped\x12=\n" +
+	"\fcollected_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vcollectedAt\"\xc5\x01\n" +
+	"\rCaptureResult\x12\x10\n" +
+	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1e\n" +
+	"\n" +
+	"deviceName\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1b\n" +
+	"\tlink_type\x18\x04 \x01(\tR\blinkType\x129\n" +
+	"\n" +
+	"applied_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tappliedAt\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\"M\n" +
+	"\x1bReportCaptureResultsRequest\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.agent.CaptureResultR\aresults\"Y\n" +
+	"\x10DiagnosticsChunk\x12\x1b\n" +
+	"\tbundle_id\x18\x01 \x01(\tR\bbundleId\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x98\x01\n" +
+	"\x10PacketSliceChunk\x12\x19\n" +
+	"\bslice_id\x18\x01 \x01(\tR\asliceId\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12!\n" +
+	"\fpacket_count\x18\x04 \x01(\x04R\vpacketCount\x12\x1c\n" +
+	"\ttruncated\x18\x05 \x01(\bR\ttruncated\"\xc3\x04\n" +
 	"\tBPFConfig\x124\n" +
 	"\x06create\x18\x01 \x03(\v2\x1c.agent.BPFConfig.CreateEntryR\x06create\x124\n" +
 	"\x06update\x18\x02 \x03(\v2\x1c.agent.BPFConfig.UpdateEntryR\x06update\x124\n" +
-	"\x06delete\x18\x03 \x03(\v2\x1c.agent.BPFConfig.DeleteEntryR\x06delete\x1aU\n" +
+	"\x06delete\x18\x03 \x03(\v2\x1c.agent.BPFConfig.DeleteEntryR\x06delete\x127\n" +
+	"\adesired\x18\x04 \x03(\v2\x1d.agent.BPFConfig.DesiredEntryR\adesired\x1aU\n" +
 	"\vCreateEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
 	"\x05value\x18\x02 \x01(\v2\x1a.agent.InterfaceCaptureMapR\x05value:\x028\x01\x1aU\n" +
@@ -1006,12 +3606,15 @@ const file_agent_agent_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\v2\x1a.agent.InterfaceCaptureMapR\x05value:\x028\x01\x1aU\n" +
 	"\vDeleteEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
+	"\x05value\x18\x02 \x01(\v2\x1a.agent.InterfaceCaptureMapR\x05value:\x028\x01\x1aV\n" +
+	"\fDesiredEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
 	"\x05value\x18\x02 \x01(\v2\x1a.agent.InterfaceCaptureMapR\x05value:\x028\x01\"\xae\x01\n" +
 	"\x13InterfaceCaptureMap\x12D\n" +
 	"\bcaptures\x18\x01 \x03(\v2(.agent.InterfaceCaptureMap.CapturesEntryR\bcaptures\x1aQ\n" +
 	"\rCapturesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x04R\x03key\x12*\n" +
-	"\x05value\x18\x02 \x01(\v2\x14.agent.CaptureConfigR\x05value:\x028\x01\"\xb9\x02\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.agent.CaptureConfigR\x05value:\x028\x01\"\xf8\x02\n" +
 	"\vPacketEvent\x12\x10\n" +
 	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1e\n" +
 	"\n" +
@@ -1023,12 +3626,57 @@ const file_agent_agent_proto_rawDesc = "" +
 	"\x0foriginal_length\x18\x06 \x01(\rR\x0eoriginalLength\x12'\n" +
 	"\x0finterface_index\x18\a \x01(\x05R\x0einterfaceIndex\x12\x1c\n" +
 	"\ttruncated\x18\b \x01(\bR\ttruncated\x12%\n" +
-	"\x06layers\x18\t \x01(\v2\r.agent.LayersR\x06layers\"\xbd\x01\n" +
+	"\x06layers\x18\t \x01(\v2\r.agent.LayersR\x06layers\x12=\n" +
+	"\fcapture_time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vcaptureTime\">\n" +
+	"\x10PacketEventBatch\x12*\n" +
+	"\x06events\x18\x01 \x03(\v2\x12.agent.PacketEventR\x06events\"\x84\x04\n" +
 	"\x06Layers\x12)\n" +
 	"\bip_layer\x18\x01 \x01(\v2\x0e.agent.IPLayerR\aipLayer\x12,\n" +
 	"\ttcp_layer\x18\x02 \x01(\v2\x0f.agent.TCPLayerR\btcpLayer\x12,\n" +
 	"\tudp_layer\x18\x03 \x01(\v2\x0f.agent.UDPLayerR\budpLayer\x12,\n" +
-	"\ttls_layer\x18\x04 \x01(\v2\x0f.agent.TLSLayerR\btlsLayer\"\x9c\x01\n" +
+	"\ttls_layer\x18\x04 \x01(\v2\x0f.agent.TLSLayerR\btlsLayer\x12,\n" +
+	"\tdns_layer\x18\x05 \x01(\v2\x0f.agent.DNSLayerR\bdnsLayer\x12/\n" +
+	"\n" +
+	"http_layer\x18\x06 \x01(\v2\x10.agent.HTTPLayerR\thttpLayer\x12;\n" +
+	"\x0eethernet_layer\x18\a \x01(\v2\x14.agent.EthernetLayerR\rethernetLayer\x12,\n" +
+	"\tarp_layer\x18\b \x01(\v2\x0f.agent.ARPLayerR\barpLayer\x12/\n" +
+	"\n" +
+	"icmp_layer\x18\t \x01(\v2\x10.agent.ICMPLayerR\ticmpLayer\x12!\n" +
+	"\ftunnel_types\x18\n" +
+	" \x03(\tR\vtunnelTypes\x12'\n" +
+	"\atunnels\x18\v \x03(\v2\r.agent.TunnelR\atunnels\"\xe3\x01\n" +
+	"\x06Tunnel\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\rR\x02id\x124\n" +
+	"\x0eouter_ip_layer\x18\x03 \x01(\v2\x0e.agent.IPLayerR\fouterIpLayer\x127\n" +
+	"\x0fouter_udp_layer\x18\x04 \x01(\v2\x0f.agent.UDPLayerR\routerUdpLayer\x12F\n" +
+	"\x14inner_ethernet_layer\x18\x05 \x01(\v2\x14.agent.EthernetLayerR\x12innerEthernetLayer\"\x8d\x01\n" +
+	"\rEthernetLayer\x12\x17\n" +
+	"\asrc_mac\x18\x01 \x01(\tR\x06srcMac\x12\x17\n" +
+	"\adst_mac\x18\x02 \x01(\tR\x06dstMac\x12\x1d\n" +
+	"\n" +
+	"ether_type\x18\x03 \x01(\tR\tetherType\x12+\n" +
+	"\tvlan_tags\x18\x04 \x03(\v2\x0e.agent.VLANTagR\bvlanTags\"Z\n" +
+	"\aVLANTag\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1a\n" +
+	"\bpriority\x18\x02 \x01(\rR\bpriority\x12#\n" +
+	"\rdrop_eligible\x18\x03 \x01(\bR\fdropEligible\"\xa0\x01\n" +
+	"\bARPLayer\x12\x1c\n" +
+	"\toperation\x18\x01 \x01(\tR\toperation\x12\x1d\n" +
+	"\n" +
+	"sender_mac\x18\x02 \x01(\tR\tsenderMac\x12\x1b\n" +
+	"\tsender_ip\x18\x03 \x01(\tR\bsenderIp\x12\x1d\n" +
+	"\n" +
+	"target_mac\x18\x04 \x01(\tR\ttargetMac\x12\x1b\n" +
+	"\ttarget_ip\x18\x05 \x01(\tR\btargetIp\"\x8c\x01\n" +
+	"\tICMPLayer\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\rR\x04type\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\rR\x04code\x12\x1b\n" +
+	"\ttype_code\x18\x04 \x01(\tR\btypeCode\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\rR\x02id\x12\x10\n" +
+	"\x03seq\x18\x06 \x01(\rR\x03seq\"\x9c\x01\n" +
 	"\aIPLayer\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\tR\aversion\x12\x15\n" +
 	"\x06src_ip\x18\x02 \x01(\tR\x05srcIp\x12\x15\n" +
@@ -1052,18 +3700,133 @@ const file_agent_agent_proto_rawDesc = "" +
 	"\bUDPLayer\x12\x19\n" +
 	"\bsrc_port\x18\x01 \x01(\rR\asrcPort\x12\x19\n" +
 	"\bdst_port\x18\x02 \x01(\rR\adstPort\x12\x16\n" +
-	"\x06length\x18\x03 \x01(\rR\x06length\"6\n" +
+	"\x06length\x18\x03 \x01(\rR\x06length\"\xaa\x01\n" +
 	"\bTLSLayer\x12*\n" +
-	"\arecords\x18\x01 \x03(\v2\x10.agent.TLSRecordR\arecords\"Q\n" +
+	"\arecords\x18\x01 \x03(\v2\x10.agent.TLSRecordR\arecords\x128\n" +
+	"\fclient_hello\x18\x02 \x01(\v2\x15.agent.TLSClientHelloR\vclientHello\x128\n" +
+	"\fserver_hello\x18\x03 \x01(\v2\x15.agent.TLSServerHelloR\vserverHello\"\x8b\x03\n" +
+	"\x0eTLSClientHello\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x10\n" +
+	"\x03sni\x18\x02 \x01(\tR\x03sni\x12\x12\n" +
+	"\x04alpn\x18\x03 \x03(\tR\x04alpn\x12#\n" +
+	"\rcipher_suites\x18\x04 \x03(\rR\fcipherSuites\x12\x1e\n" +
+	"\n" +
+	"extensions\x18\x05 \x03(\rR\n" +
+	"extensions\x12)\n" +
+	"\x10supported_groups\x18\x06 \x03(\rR\x0fsupportedGroups\x12(\n" +
+	"\x10ec_point_formats\x18\a \x03(\rR\x0eecPointFormats\x121\n" +
+	"\x14signature_algorithms\x18\b \x03(\rR\x13signatureAlgorithms\x12-\n" +
+	"\x12supported_versions\x18\t \x03(\rR\x11supportedVersions\x12\x10\n" +
+	"\x03ja3\x18\n" +
+	" \x01(\tR\x03ja3\x12\x19\n" +
+	"\bja3_hash\x18\v \x01(\tR\aja3Hash\x12\x10\n" +
+	"\x03ja4\x18\f \x01(\tR\x03ja4\"\xdf\x01\n" +
+	"\x0eTLSServerHello\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12!\n" +
+	"\fcipher_suite\x18\x02 \x01(\rR\vcipherSuite\x12\x1e\n" +
+	"\n" +
+	"extensions\x18\x03 \x03(\rR\n" +
+	"extensions\x12\x12\n" +
+	"\x04alpn\x18\x04 \x01(\tR\x04alpn\x12+\n" +
+	"\x11supported_version\x18\x05 \x01(\tR\x10supportedVersion\x12\x12\n" +
+	"\x04ja3s\x18\x06 \x01(\tR\x04ja3s\x12\x1b\n" +
+	"\tja3s_hash\x18\a \x01(\tR\bja3sHash\"Q\n" +
 	"\tTLSRecord\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
-	"\x06length\x18\x03 \x01(\rR\x06length2\xed\x01\n" +
+	"\x06length\x18\x03 \x01(\rR\x06length\"\x9d\x02\n" +
+	"\bDNSLayer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1a\n" +
+	"\bresponse\x18\x02 \x01(\bR\bresponse\x12\x16\n" +
+	"\x06opcode\x18\x03 \x01(\tR\x06opcode\x12#\n" +
+	"\rresponse_code\x18\x04 \x01(\tR\fresponseCode\x12$\n" +
+	"\rauthoritative\x18\x05 \x01(\bR\rauthoritative\x12\x1c\n" +
+	"\ttruncated\x18\x06 \x01(\bR\ttruncated\x120\n" +
+	"\tquestions\x18\a \x03(\v2\x12.agent.DNSQuestionR\tquestions\x122\n" +
+	"\aanswers\x18\b \x03(\v2\x18.agent.DNSResourceRecordR\aanswers\"K\n" +
+	"\vDNSQuestion\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05class\x18\x03 \x01(\tR\x05class\"w\n" +
+	"\x11DNSResourceRecord\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05class\x18\x03 \x01(\tR\x05class\x12\x10\n" +
+	"\x03ttl\x18\x04 \x01(\rR\x03ttl\x12\x12\n" +
+	"\x04data\x18\x05 \x01(\tR\x04data\"\xbd\x03\n" +
+	"\n" +
+	"FlowRecord\x12\x10\n" +
+	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1e\n" +
+	"\n" +
+	"deviceName\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x1d\n" +
+	"\n" +
+	"ip_version\x18\x03 \x01(\tR\tipVersion\x12\x15\n" +
+	"\x06src_ip\x18\x04 \x01(\tR\x05srcIp\x12\x15\n" +
+	"\x06dst_ip\x18\x05 \x01(\tR\x05dstIp\x12\x19\n" +
+	"\bsrc_port\x18\x06 \x01(\rR\asrcPort\x12\x19\n" +
+	"\bdst_port\x18\a \x01(\rR\adstPort\x12\x1a\n" +
+	"\bprotocol\x18\b \x01(\rR\bprotocol\x12\x18\n" +
+	"\apackets\x18\t \x01(\x04R\apackets\x12\x14\n" +
+	"\x05bytes\x18\n" +
+	" \x01(\x04R\x05bytes\x12\x1b\n" +
+	"\ttcp_flags\x18\v \x01(\rR\btcpFlags\x129\n" +
+	"\n" +
+	"first_seen\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tfirstSeen\x127\n" +
+	"\tlast_seen\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x1d\n" +
+	"\n" +
+	"end_reason\x18\x0e \x01(\tR\tendReason\";\n" +
+	"\tHTTPLayer\x12.\n" +
+	"\bmessages\x18\x01 \x03(\v2\x12.agent.HTTPMessageR\bmessages\"\xe8\x01\n" +
+	"\vHTTPMessage\x12\x1a\n" +
+	"\bresponse\x18\x01 \x01(\bR\bresponse\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x12\n" +
+	"\x04host\x18\x04 \x01(\tR\x04host\x12\x10\n" +
+	"\x03uri\x18\x05 \x01(\tR\x03uri\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x06 \x01(\tR\tuserAgent\x12\x1f\n" +
+	"\vstatus_code\x18\a \x01(\x05R\n" +
+	"statusCode\x12%\n" +
+	"\x0econtent_length\x18\b \x01(\x03R\rcontentLength\"\xac\x03\n" +
+	"\x10HeartbeatRequest\x12#\n" +
+	"\ragent_version\x18\x01 \x01(\tR\fagentVersion\x12\x1f\n" +
+	"\vcommit_hash\x18\x02 \x01(\tR\n" +
+	"commitHash\x12%\n" +
+	"\x0euptime_seconds\x18\x03 \x01(\x04R\ruptimeSeconds\x12\x0e\n" +
+	"\x02os\x18\x04 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x05 \x01(\tR\x04arch\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\x06 \x01(\tR\tosVersion\x12%\n" +
+	"\x0ekernel_version\x18\a \x01(\tR\rkernelVersion\x12\x1f\n" +
+	"\vcpu_percent\x18\b \x01(\x01R\n" +
+	"cpuPercent\x12!\n" +
+	"\fmemory_bytes\x18\t \x01(\x04R\vmemoryBytes\x12)\n" +
+	"\x10captures_running\x18\n" +
+	" \x01(\rR\x0fcapturesRunning\x12'\n" +
+	"\x0fcaptures_failed\x18\v \x01(\rR\x0ecapturesFailed\x12)\n" +
+	"\x10captures_stopped\x18\f \x01(\rR\x0fcapturesStopped\"[\n" +
+	"\x1bDownloadAgentReleaseRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x0e\n" +
+	"\x02os\x18\x02 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x03 \x01(\tR\x04arch\"'\n" +
+	"\x11AgentReleaseChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data2\xf8\x06\n" +
 	"\fAgentService\x12@\n" +
 	"\x10ReportInterfaces\x12\x1e.agent.ReportInterfacesRequest\x1a\f.agent.Empty\x125\n" +
-	"\x0fSendPacketEvent\x12\x12.agent.PacketEvent\x1a\f.agent.Empty(\x01\x124\n" +
-	"\vPollCommand\x12\f.agent.Empty\x1a\x17.agent.CommandsResponse\x12.\n" +
-	"\fGetBPFConfig\x12\f.agent.Empty\x1a\x10.agent.BPFConfigB@Z>github.com/danielhoward314/packet-sentry/protogen/golang/agentb\x06proto3"
+	"\x0fSendPacketEvent\x12\x12.agent.PacketEvent\x1a\f.agent.Empty(\x01\x12?\n" +
+	"\x14SendPacketEventBatch\x12\x17.agent.PacketEventBatch\x1a\f.agent.Empty(\x01\x123\n" +
+	"\x0eSendFlowRecord\x12\x11.agent.FlowRecord\x1a\f.agent.Empty(\x01\x124\n" +
+	"\vPollCommand\x12\f.agent.Empty\x1a\x17.agent.CommandsResponse\x12@\n" +
+	"\rCommandStream\x12\x1b.agent.CommandStreamRequest\x1a\x0e.agent.Command(\x010\x01\x12.\n" +
+	"\fGetBPFConfig\x12\f.agent.Empty\x1a\x10.agent.BPFConfig\x12<\n" +
+	"\x11UploadPacketSlice\x12\x17.agent.PacketSliceChunk\x1a\f.agent.Empty(\x01\x12<\n" +
+	"\x11UploadDiagnostics\x12\x17.agent.DiagnosticsChunk\x1a\f.agent.Empty(\x01\x12D\n" +
+	"\x12ReportCaptureStats\x12 .agent.ReportCaptureStatsRequest\x1a\f.agent.Empty\x12H\n" +
+	"\x14ReportCaptureResults\x12\".agent.ReportCaptureResultsRequest\x1a\f.agent.Empty\x129\n" +
+	"\x13ReportCommandResult\x12\x14.agent.CommandResult\x1a\f.agent.Empty\x122\n" +
+	"\tHeartbeat\x12\x17.agent.HeartbeatRequest\x1a\f.agent.Empty\x12V\n" +
+	"\x14DownloadAgentRelease\x12\".agent.DownloadAgentReleaseRequest\x1a\x18.agent.AgentReleaseChunk0\x01B@Z>github.com/danielhoward314/packet-sentry/protogen/golang/agentb\x06proto3"
 
 var (
 	file_agent_agent_proto_rawDescOnce sync.Once
@@ -1077,58 +3840,145 @@ func file_agent_agent_proto_rawDescGZIP() []byte {
 	return file_agent_agent_proto_rawDescData
 }
 
-var file_agent_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_agent_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 49)
 var file_agent_agent_proto_goTypes = []any{
-	(*Empty)(nil),                   // 0: agent.Empty
-	(*InterfaceDetails)(nil),        // 1: agent.InterfaceDetails
-	(*ReportInterfacesRequest)(nil), // 2: agent.ReportInterfacesRequest
-	(*Command)(nil),                 // 3: agent.Command
-	(*CommandsResponse)(nil),        // 4: agent.CommandsResponse
-	(*CaptureConfig)(nil),           // 5: agent.CaptureConfig
-	(*BPFConfig)(nil),               // 6: agent.BPFConfig
-	(*InterfaceCaptureMap)(nil),     // 7: agent.InterfaceCaptureMap
-	(*PacketEvent)(nil),             // 8: agent.PacketEvent
-	(*Layers)(nil),                  // 9: agent.Layers
-	(*IPLayer)(nil),                 // 10: agent.IPLayer
-	(*TCPLayer)(nil),                // 11: agent.TCPLayer
-	(*UDPLayer)(nil),                // 12: agent.UDPLayer
-	(*TLSLayer)(nil),                // 13: agent.TLSLayer
-	(*TLSRecord)(nil),               // 14: agent.TLSRecord
-	nil,                             // 15: agent.BPFConfig.CreateEntry
-	nil,                             // 16: agent.BPFConfig.UpdateEntry
-	nil,                             // 17: agent.BPFConfig.DeleteEntry
-	nil,                             // 18: agent.InterfaceCaptureMap.CapturesEntry
+	(*Empty)(nil),                       // 0: agent.Empty
+	(*InterfaceDetails)(nil),            // 1: agent.InterfaceDetails
+	(*ReportInterfacesRequest)(nil),     // 2: agent.ReportInterfacesRequest
+	(*Command)(nil),                     // 3: agent.Command
+	(*UploadPacketSliceArgs)(nil),       // 4: agent.UploadPacketSliceArgs
+	(*SetLogLevelArgs)(nil),             // 5: agent.SetLogLevelArgs
+	(*CollectDiagnosticsArgs)(nil),      // 6: agent.CollectDiagnosticsArgs
+	(*UpdateAgentArgs)(nil),             // 7: agent.UpdateAgentArgs
+	(*CommandResult)(nil),               // 8: agent.CommandResult
+	(*CommandStreamRequest)(nil),        // 9: agent.CommandStreamRequest
+	(*CommandsResponse)(nil),            // 10: agent.CommandsResponse
+	(*CaptureConfig)(nil),               // 11: agent.CaptureConfig
+	(*CaptureStats)(nil),                // 12: agent.CaptureStats
+	(*ReportCaptureStatsRequest)(nil),   // 13: agent.ReportCaptureStatsRequest
+	(*CaptureResult)(nil),               // 14: agent.CaptureResult
+	(*ReportCaptureResultsRequest)(nil), // 15: agent.ReportCaptureResultsRequest
+	(*DiagnosticsChunk)(nil),            // 16: agent.DiagnosticsChunk
+	(*PacketSliceChunk)(nil),            // 17: agent.PacketSliceChunk
+	(*BPFConfig)(nil),                   // 18: agent.BPFConfig
+	(*InterfaceCaptureMap)(nil),         // 19: agent.InterfaceCaptureMap
+	(*PacketEvent)(nil),                 // 20: agent.PacketEvent
+	(*PacketEventBatch)(nil),            // 21: agent.PacketEventBatch
+	(*Layers)(nil),                      // 22: agent.Layers
+	(*Tunnel)(nil),                      // 23: agent.Tunnel
+	(*EthernetLayer)(nil),               // 24: agent.EthernetLayer
+	(*VLANTag)(nil),                     // 25: agent.VLANTag
+	(*ARPLayer)(nil),                    // 26: agent.ARPLayer
+	(*ICMPLayer)(nil),                   // 27: agent.ICMPLayer
+	(*IPLayer)(nil),                     // 28: agent.IPLayer
+	(*TCPLayer)(nil),                    // 29: agent.TCPLayer
+	(*UDPLayer)(nil),                    // 30: agent.UDPLayer
+	(*TLSLayer)(nil),                    // 31: agent.TLSLayer
+	(*TLSClientHello)(nil),              // 32: agent.TLSClientHello
+	(*TLSServerHello)(nil),              // 33: agent.TLSServerHello
+	(*TLSRecord)(nil),                   // 34: agent.TLSRecord
+	(*DNSLayer)(nil),                    // 35: agent.DNSLayer
+	(*DNSQuestion)(nil),                 // 36: agent.DNSQuestion
+	(*DNSResourceRecord)(nil),           // 37: agent.DNSResourceRecord
+	(*FlowRecord)(nil),                  // 38: agent.FlowRecord
+	(*HTTPLayer)(nil),                   // 39: agent.HTTPLayer
+	(*HTTPMessage)(nil),                 // 40: agent.HTTPMessage
+	(*HeartbeatRequest)(nil),            // 41: agent.HeartbeatRequest
+	(*DownloadAgentReleaseRequest)(nil), // 42: agent.DownloadAgentReleaseRequest
+	(*AgentReleaseChunk)(nil),           // 43: agent.AgentReleaseChunk
+	nil,                                 // 44: agent.BPFConfig.CreateEntry
+	nil,                                 // 45: agent.BPFConfig.UpdateEntry
+	nil,                                 // 46: agent.BPFConfig.DeleteEntry
+	nil,                                 // 47: agent.BPFConfig.DesiredEntry
+	nil,                                 // 48: agent.InterfaceCaptureMap.CapturesEntry
+	(*timestamppb.Timestamp)(nil),       // 49: google.protobuf.Timestamp
 }
 var file_agent_agent_proto_depIdxs = []int32{
 	1,  // 0: agent.ReportInterfacesRequest.interfaces:type_name -> agent.InterfaceDetails
-	3,  // 1: agent.CommandsResponse.commands:type_name -> agent.Command
-	15, // 2: agent.BPFConfig.create:type_name -> agent.BPFConfig.CreateEntry
-	16, // 3: agent.BPFConfig.update:type_name -> agent.BPFConfig.UpdateEntry
-	17, // 4: agent.BPFConfig.delete:type_name -> agent.BPFConfig.DeleteEntry
-	18, // 5: agent.InterfaceCaptureMap.captures:type_name -> agent.InterfaceCaptureMap.CapturesEntry
-	9,  // 6: agent.PacketEvent.layers:type_name -> agent.Layers
-	10, // 7: agent.Layers.ip_layer:type_name -> agent.IPLayer
-	11, // 8: agent.Layers.tcp_layer:type_name -> agent.TCPLayer
-	12, // 9: agent.Layers.udp_layer:type_name -> agent.UDPLayer
-	13, // 10: agent.Layers.tls_layer:type_name -> agent.TLSLayer
-	14, // 11: agent.TLSLayer.records:type_name -> agent.TLSRecord
-	7,  // 12: agent.BPFConfig.CreateEntry.value:type_name -> agent.InterfaceCaptureMap
-	7,  // 13: agent.BPFConfig.UpdateEntry.value:type_name -> agent.InterfaceCaptureMap
-	7,  // 14: agent.BPFConfig.DeleteEntry.value:type_name -> agent.InterfaceCaptureMap
-	5,  // 15: agent.InterfaceCaptureMap.CapturesEntry.value:type_name -> agent.CaptureConfig
-	2,  // 16: agent.AgentService.ReportInterfaces:input_type -> agent.ReportInterfacesRequest
-	8,  // 17: agent.AgentService.SendPacketEvent:input_type -> agent.PacketEvent
-	0,  // 18: agent.AgentService.PollCommand:input_type -> agent.Empty
-	0,  // 19: agent.AgentService.GetBPFConfig:input_type -> agent.Empty
-	0,  // 20: agent.AgentService.ReportInterfaces:output_type -> agent.Empty
-	0,  // 21: agent.AgentService.SendPacketEvent:output_type -> agent.Empty
-	4,  // 22: agent.AgentService.PollCommand:output_type -> agent.CommandsResponse
-	6,  // 23: agent.AgentService.GetBPFConfig:output_type -> agent.BPFConfig
-	20, // [20:24] is the sub-list for method output_type
-	16, // [16:20] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	49, // 1: agent.Command.issued_at:type_name -> google.protobuf.Timestamp
+	49, // 2: agent.Command.expires_at:type_name -> google.protobuf.Timestamp
+	4,  // 3: agent.Command.upload_packet_slice:type_name -> agent.UploadPacketSliceArgs
+	5,  // 4: agent.Command.set_log_level:type_name -> agent.SetLogLevelArgs
+	6,  // 5: agent.Command.collect_diagnostics:type_name -> agent.CollectDiagnosticsArgs
+	7,  // 6: agent.Command.update_agent:type_name -> agent.UpdateAgentArgs
+	49, // 7: agent.UploadPacketSliceArgs.start:type_name -> google.protobuf.Timestamp
+	49, // 8: agent.UploadPacketSliceArgs.end:type_name -> google.protobuf.Timestamp
+	49, // 9: agent.CommandResult.started_at:type_name -> google.protobuf.Timestamp
+	49, // 10: agent.CommandResult.finished_at:type_name -> google.protobuf.Timestamp
+	3,  // 11: agent.CommandsResponse.commands:type_name -> agent.Command
+	49, // 12: agent.CaptureStats.started_at:type_name -> google.protobuf.Timestamp
+	12, // 13: agent.ReportCaptureStatsRequest.captures:type_name -> agent.CaptureStats
+	49, // 14: agent.ReportCaptureStatsRequest.collected_at:type_name -> google.protobuf.Timestamp
+	49, // 15: agent.CaptureResult.applied_at:type_name -> google.protobuf.Timestamp
+	14, // 16: agent.ReportCaptureResultsRequest.results:type_name -> agent.CaptureResult
+	44, // 17: agent.BPFConfig.create:type_name -> agent.BPFConfig.CreateEntry
+	45, // 18: agent.BPFConfig.update:type_name -> agent.BPFConfig.UpdateEntry
+	46, // 19: agent.BPFConfig.delete:type_name -> agent.BPFConfig.DeleteEntry
+	47, // 20: agent.BPFConfig.desired:type_name -> agent.BPFConfig.DesiredEntry
+	48, // 21: agent.InterfaceCaptureMap.captures:type_name -> agent.InterfaceCaptureMap.CapturesEntry
+	22, // 22: agent.PacketEvent.layers:type_name -> agent.Layers
+	49, // 23: agent.PacketEvent.capture_time:type_name -> google.protobuf.Timestamp
+	20, // 24: agent.PacketEventBatch.events:type_name -> agent.PacketEvent
+	28, // 25: agent.Layers.ip_layer:type_name -> agent.IPLayer
+	29, // 26: agent.Layers.tcp_layer:type_name -> agent.TCPLayer
+	30, // 27: agent.Layers.udp_layer:type_name -> agent.UDPLayer
+	31, // 28: agent.Layers.tls_layer:type_name -> agent.TLSLayer
+	35, // 29: agent.Layers.dns_layer:type_name -> agent.DNSLayer
+	39, // 30: agent.Layers.http_layer:type_name -> agent.HTTPLayer
+	24, // 31: agent.Layers.ethernet_layer:type_name -> agent.EthernetLayer
+	26, // 32: agent.Layers.arp_layer:type_name -> agent.ARPLayer
+	27, // 33: agent.Layers.icmp_layer:type_name -> agent.ICMPLayer
+	23, // 34: agent.Layers.tunnels:type_name -> agent.Tunnel
+	28, // 35: agent.Tunnel.outer_ip_layer:type_name -> agent.IPLayer
+	30, // 36: agent.Tunnel.outer_udp_layer:type_name -> agent.UDPLayer
+	24, // 37: agent.Tunnel.inner_ethernet_layer:type_name -> agent.EthernetLayer
+	25, // 38: agent.EthernetLayer.vlan_tags:type_name -> agent.VLANTag
+	34, // 39: agent.TLSLayer.records:type_name -> agent.TLSRecord
+	32, // 40: agent.TLSLayer.client_hello:type_name -> agent.TLSClientHello
+	33, // 41: agent.TLSLayer.server_hello:type_name -> agent.TLSServerHello
+	36, // 42: agent.DNSLayer.questions:type_name -> agent.DNSQuestion
+	37, // 43: agent.DNSLayer.answers:type_name -> agent.DNSResourceRecord
+	49, // 44: agent.FlowRecord.first_seen:type_name -> google.protobuf.Timestamp
+	49, // 45: agent.FlowRecord.last_seen:type_name -> google.protobuf.Timestamp
+	40, // 46: agent.HTTPLayer.messages:type_name -> agent.HTTPMessage
+	19, // 47: agent.BPFConfig.CreateEntry.value:type_name -> agent.InterfaceCaptureMap
+	19, // 48: agent.BPFConfig.UpdateEntry.value:type_name -> agent.InterfaceCaptureMap
+	19, // 49: agent.BPFConfig.DeleteEntry.value:type_name -> agent.InterfaceCaptureMap
+	19, // 50: agent.BPFConfig.DesiredEntry.value:type_name -> agent.InterfaceCaptureMap
+	11, // 51: agent.InterfaceCaptureMap.CapturesEntry.value:type_name -> agent.CaptureConfig
+	2,  // 52: agent.AgentService.ReportInterfaces:input_type -> agent.ReportInterfacesRequest
+	20, // 53: agent.AgentService.SendPacketEvent:input_type -> agent.PacketEvent
+	21, // 54: agent.AgentService.SendPacketEventBatch:input_type -> agent.PacketEventBatch
+	38, // 55: agent.AgentService.SendFlowRecord:input_type -> agent.FlowRecord
+	0,  // 56: agent.AgentService.PollCommand:input_type -> agent.Empty
+	9,  // 57: agent.AgentService.CommandStream:input_type -> agent.CommandStreamRequest
+	0,  // 58: agent.AgentService.GetBPFConfig:input_type -> agent.Empty
+	17, // 59: agent.AgentService.UploadPacketSlice:input_type -> agent.PacketSliceChunk
+	16, // 60: agent.AgentService.UploadDiagnostics:input_type -> agent.DiagnosticsChunk
+	13, // 61: agent.AgentService.ReportCaptureStats:input_type -> agent.ReportCaptureStatsRequest
+	15, // 62: agent.AgentService.ReportCaptureResults:input_type -> agent.ReportCaptureResultsRequest
+	8,  // 63: agent.AgentService.ReportCommandResult:input_type -> agent.CommandResult
+	41, // 64: agent.AgentService.Heartbeat:input_type -> agent.HeartbeatRequest
+	42, // 65: agent.AgentService.DownloadAgentRelease:input_type -> agent.DownloadAgentReleaseRequest
+	0,  // 66: agent.AgentService.ReportInterfaces:output_type -> agent.Empty
+	0,  // 67: agent.AgentService.SendPacketEvent:output_type -> agent.Empty
+	0,  // 68: agent.AgentService.SendPacketEventBatch:output_type -> agent.Empty
+	0,  // 69: agent.AgentService.SendFlowRecord:output_type -> agent.Empty
+	10, // 70: agent.AgentService.PollCommand:output_type -> agent.CommandsResponse
+	3,  // 71: agent.AgentService.CommandStream:output_type -> agent.Command
+	18, // 72: agent.AgentService.GetBPFConfig:output_type -> agent.BPFConfig
+	0,  // 73: agent.AgentService.UploadPacketSlice:output_type -> agent.Empty
+	0,  // 74: agent.AgentService.UploadDiagnostics:output_type -> agent.Empty
+	0,  // 75: agent.AgentService.ReportCaptureStats:output_type -> agent.Empty
+	0,  // 76: agent.AgentService.ReportCaptureResults:output_type -> agent.Empty
+	0,  // 77: agent.AgentService.ReportCommandResult:output_type -> agent.Empty
+	0,  // 78: agent.AgentService.Heartbeat:output_type -> agent.Empty
+	43, // 79: agent.AgentService.DownloadAgentRelease:output_type -> agent.AgentReleaseChunk
+	66, // [66:80] is the sub-list for method output_type
+	52, // [52:66] is the sub-list for method input_type
+	52, // [52:52] is the sub-list for extension type_name
+	52, // [52:52] is the sub-list for extension extendee
+	0,  // [0:52] is the sub-list for field type_name
 }
 
 func init() { file_agent_agent_proto_init() }
@@ -1136,13 +3986,19 @@ func file_agent_agent_proto_init() {
 	if File_agent_agent_proto != nil {
 		return
 	}
+	file_agent_agent_proto_msgTypes[3].OneofWrappers = []any{
+		(*Command_UploadPacketSlice)(nil),
+		(*Command_SetLogLevel)(nil),
+		(*Command_CollectDiagnostics)(nil),
+		(*Command_UpdateAgent)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_agent_proto_rawDesc), len(file_agent_agent_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   19,
+			NumMessages:   49,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
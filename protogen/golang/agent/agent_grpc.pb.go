@@ -19,10 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AgentService_ReportInterfaces_FullMethodName = "/agent.AgentService/ReportInterfaces"
-	AgentService_SendPacketEvent_FullMethodName  = "/agent.AgentService/SendPacketEvent"
-	AgentService_PollCommand_FullMethodName      = "/agent.AgentService/PollCommand"
-	AgentService_GetBPFConfig_FullMethodName     = "/agent.AgentService/GetBPFConfig"
+	AgentService_ReportInterfaces_FullMethodName     = "/agent.AgentService/ReportInterfaces"
+	AgentService_SendPacketEvent_FullMethodName      = "/agent.AgentService/SendPacketEvent"
+	AgentService_SendPacketEventBatch_FullMethodName = "/agent.AgentService/SendPacketEventBatch"
+	AgentService_SendFlowRecord_FullMethodName       = "/agent.AgentService/SendFlowRecord"
+	AgentService_PollCommand_FullMethodName          = "/agent.AgentService/PollCommand"
+	AgentService_CommandStream_FullMethodName        = "/agent.AgentService/CommandStream"
+	AgentService_GetBPFConfig_FullMethodName         = "/agent.AgentService/GetBPFConfig"
+	AgentService_UploadPacketSlice_FullMethodName    = "/agent.AgentService/UploadPacketSlice"
+	AgentService_UploadDiagnostics_FullMethodName    = "/agent.AgentService/UploadDiagnostics"
+	AgentService_ReportCaptureStats_FullMethodName   = "/agent.AgentService/ReportCaptureStats"
+	AgentService_ReportCaptureResults_FullMethodName = "/agent.AgentService/ReportCaptureResults"
+	AgentService_ReportCommandResult_FullMethodName  = "/agent.AgentService/ReportCommandResult"
+	AgentService_Heartbeat_FullMethodName            = "/agent.AgentService/Heartbeat"
+	AgentService_DownloadAgentRelease_FullMethodName = "/agent.AgentService/DownloadAgentRelease"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -31,8 +41,19 @@ const (
 type AgentServiceClient interface {
 	ReportInterfaces(ctx context.Context, in *ReportInterfacesRequest, opts ...grpc.CallOption) (*Empty, error)
 	SendPacketEvent(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PacketEvent, Empty], error)
+	SendPacketEventBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PacketEventBatch, Empty], error)
+	SendFlowRecord(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FlowRecord, Empty], error)
 	PollCommand(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandsResponse, error)
+	// CommandStream pushes the device's commands as they are published, the agent polls with PollCommand while it is down
+	CommandStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CommandStreamRequest, Command], error)
 	GetBPFConfig(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BPFConfig, error)
+	UploadPacketSlice(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PacketSliceChunk, Empty], error)
+	UploadDiagnostics(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DiagnosticsChunk, Empty], error)
+	ReportCaptureStats(ctx context.Context, in *ReportCaptureStatsRequest, opts ...grpc.CallOption) (*Empty, error)
+	ReportCaptureResults(ctx context.Context, in *ReportCaptureResultsRequest, opts ...grpc.CallOption) (*Empty, error)
+	ReportCommandResult(ctx context.Context, in *CommandResult, opts ...grpc.CallOption) (*Empty, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Empty, error)
+	DownloadAgentRelease(ctx context.Context, in *DownloadAgentReleaseRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentReleaseChunk], error)
 }
 
 type agentServiceClient struct {
@@ -66,6 +87,32 @@ func (c *agentServiceClient) SendPacketEvent(ctx context.Context, opts ...grpc.C
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type AgentService_SendPacketEventClient = grpc.ClientStreamingClient[PacketEvent, Empty]
 
+func (c *agentServiceClient) SendPacketEventBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PacketEventBatch, Empty], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_SendPacketEventBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PacketEventBatch, Empty]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_SendPacketEventBatchClient = grpc.ClientStreamingClient[PacketEventBatch, Empty]
+
+func (c *agentServiceClient) SendFlowRecord(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FlowRecord, Empty], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[2], AgentService_SendFlowRecord_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FlowRecord, Empty]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_SendFlowRecordClient = grpc.ClientStreamingClient[FlowRecord, Empty]
+
 func (c *agentServiceClient) PollCommand(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CommandsResponse)
@@ -76,6 +123,19 @@ func (c *agentServiceClient) PollCommand(ctx context.Context, in *Empty, opts ..
 	return out, nil
 }
 
+func (c *agentServiceClient) CommandStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CommandStreamRequest, Command], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[3], AgentService_CommandStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CommandStreamRequest, Command]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_CommandStreamClient = grpc.BidiStreamingClient[CommandStreamRequest, Command]
+
 func (c *agentServiceClient) GetBPFConfig(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BPFConfig, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BPFConfig)
@@ -86,14 +146,110 @@ func (c *agentServiceClient) GetBPFConfig(ctx context.Context, in *Empty, opts .
 	return out, nil
 }
 
+func (c *agentServiceClient) UploadPacketSlice(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PacketSliceChunk, Empty], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[4], AgentService_UploadPacketSlice_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PacketSliceChunk, Empty]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadPacketSliceClient = grpc.ClientStreamingClient[PacketSliceChunk, Empty]
+
+func (c *agentServiceClient) UploadDiagnostics(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DiagnosticsChunk, Empty], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[5], AgentService_UploadDiagnostics_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DiagnosticsChunk, Empty]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadDiagnosticsClient = grpc.ClientStreamingClient[DiagnosticsChunk, Empty]
+
+func (c *agentServiceClient) ReportCaptureStats(ctx context.Context, in *ReportCaptureStatsRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AgentService_ReportCaptureStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportCaptureResults(ctx context.Context, in *ReportCaptureResultsRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AgentService_ReportCaptureResults_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportCommandResult(ctx context.Context, in *CommandResult, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AgentService_ReportCommandResult_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AgentService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) DownloadAgentRelease(ctx context.Context, in *DownloadAgentReleaseRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentReleaseChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[6], AgentService_DownloadAgentRelease_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadAgentReleaseRequest, AgentReleaseChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_DownloadAgentReleaseClient = grpc.ServerStreamingClient[AgentReleaseChunk]
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
 type AgentServiceServer interface {
 	ReportInterfaces(context.Context, *ReportInterfacesRequest) (*Empty, error)
 	SendPacketEvent(grpc.ClientStreamingServer[PacketEvent, Empty]) error
+	SendPacketEventBatch(grpc.ClientStreamingServer[PacketEventBatch, Empty]) error
+	SendFlowRecord(grpc.ClientStreamingServer[FlowRecord, Empty]) error
 	PollCommand(context.Context, *Empty) (*CommandsResponse, error)
+	// CommandStream pushes the device's commands as they are published, the agent polls with PollCommand while it is down
+	CommandStream(grpc.BidiStreamingServer[CommandStreamRequest, Command]) error
 	GetBPFConfig(context.Context, *Empty) (*BPFConfig, error)
+	UploadPacketSlice(grpc.ClientStreamingServer[PacketSliceChunk, Empty]) error
+	UploadDiagnostics(grpc.ClientStreamingServer[DiagnosticsChunk, Empty]) error
+	ReportCaptureStats(context.Context, *ReportCaptureStatsRequest) (*Empty, error)
+	ReportCaptureResults(context.Context, *ReportCaptureResultsRequest) (*Empty, error)
+	ReportCommandResult(context.Context, *CommandResult) (*Empty, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*Empty, error)
+	DownloadAgentRelease(*DownloadAgentReleaseRequest, grpc.ServerStreamingServer[AgentReleaseChunk]) error
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -110,12 +266,42 @@ func (UnimplementedAgentServiceServer) ReportInterfaces(context.Context, *Report
 func (UnimplementedAgentServiceServer) SendPacketEvent(grpc.ClientStreamingServer[PacketEvent, Empty]) error {
 	return status.Errorf(codes.Unimplemented, "method SendPacketEvent not implemented")
 }
+func (UnimplementedAgentServiceServer) SendPacketEventBatch(grpc.ClientStreamingServer[PacketEventBatch, Empty]) error {
+	return status.Errorf(codes.Unimplemented, "method SendPacketEventBatch not implemented")
+}
+func (UnimplementedAgentServiceServer) SendFlowRecord(grpc.ClientStreamingServer[FlowRecord, Empty]) error {
+	return status.Errorf(codes.Unimplemented, "method SendFlowRecord not implemented")
+}
 func (UnimplementedAgentServiceServer) PollCommand(context.Context, *Empty) (*CommandsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PollCommand not implemented")
 }
+func (UnimplementedAgentServiceServer) CommandStream(grpc.BidiStreamingServer[CommandStreamRequest, Command]) error {
+	return status.Errorf(codes.Unimplemented, "method CommandStream not implemented")
+}
 func (UnimplementedAgentServiceServer) GetBPFConfig(context.Context, *Empty) (*BPFConfig, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBPFConfig not implemented")
 }
+func (UnimplementedAgentServiceServer) UploadPacketSlice(grpc.ClientStreamingServer[PacketSliceChunk, Empty]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadPacketSlice not implemented")
+}
+func (UnimplementedAgentServiceServer) UploadDiagnostics(grpc.ClientStreamingServer[DiagnosticsChunk, Empty]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadDiagnostics not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportCaptureStats(context.Context, *ReportCaptureStatsRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportCaptureStats not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportCaptureResults(context.Context, *ReportCaptureResultsRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportCaptureResults not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportCommandResult(context.Context, *CommandResult) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportCommandResult not implemented")
+}
+func (UnimplementedAgentServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedAgentServiceServer) DownloadAgentRelease(*DownloadAgentReleaseRequest, grpc.ServerStreamingServer[AgentReleaseChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAgentRelease not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -162,6 +348,20 @@ func _AgentService_SendPacketEvent_Handler(srv interface{}, stream grpc.ServerSt
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type AgentService_SendPacketEventServer = grpc.ClientStreamingServer[PacketEvent, Empty]
 
+func _AgentService_SendPacketEventBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).SendPacketEventBatch(&grpc.GenericServerStream[PacketEventBatch, Empty]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_SendPacketEventBatchServer = grpc.ClientStreamingServer[PacketEventBatch, Empty]
+
+func _AgentService_SendFlowRecord_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).SendFlowRecord(&grpc.GenericServerStream[FlowRecord, Empty]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_SendFlowRecordServer = grpc.ClientStreamingServer[FlowRecord, Empty]
+
 func _AgentService_PollCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -180,6 +380,13 @@ func _AgentService_PollCommand_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_CommandStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).CommandStream(&grpc.GenericServerStream[CommandStreamRequest, Command]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_CommandStreamServer = grpc.BidiStreamingServer[CommandStreamRequest, Command]
+
 func _AgentService_GetBPFConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -198,6 +405,103 @@ func _AgentService_GetBPFConfig_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_UploadPacketSlice_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).UploadPacketSlice(&grpc.GenericServerStream[PacketSliceChunk, Empty]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadPacketSliceServer = grpc.ClientStreamingServer[PacketSliceChunk, Empty]
+
+func _AgentService_UploadDiagnostics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).UploadDiagnostics(&grpc.GenericServerStream[DiagnosticsChunk, Empty]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadDiagnosticsServer = grpc.ClientStreamingServer[DiagnosticsChunk, Empty]
+
+func _AgentService_ReportCaptureStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportCaptureStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportCaptureStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportCaptureStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportCaptureStats(ctx, req.(*ReportCaptureStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportCaptureResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportCaptureResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportCaptureResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportCaptureResults_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportCaptureResults(ctx, req.(*ReportCaptureResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportCommandResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandResult)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportCommandResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportCommandResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportCommandResult(ctx, req.(*CommandResult))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_DownloadAgentRelease_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadAgentReleaseRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).DownloadAgentRelease(m, &grpc.GenericServerStream[DownloadAgentReleaseRequest, AgentReleaseChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_DownloadAgentReleaseServer = grpc.ServerStreamingServer[AgentReleaseChunk]
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -217,6 +521,22 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBPFConfig",
 			Handler:    _AgentService_GetBPFConfig_Handler,
 		},
+		{
+			MethodName: "ReportCaptureStats",
+			Handler:    _AgentService_ReportCaptureStats_Handler,
+		},
+		{
+			MethodName: "ReportCaptureResults",
+			Handler:    _AgentService_ReportCaptureResults_Handler,
+		},
+		{
+			MethodName: "ReportCommandResult",
+			Handler:    _AgentService_ReportCommandResult_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _AgentService_Heartbeat_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -224,6 +544,37 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _AgentService_SendPacketEvent_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "SendPacketEventBatch",
+			Handler:       _AgentService_SendPacketEventBatch_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SendFlowRecord",
+			Handler:       _AgentService_SendFlowRecord_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "CommandStream",
+			Handler:       _AgentService_CommandStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadPacketSlice",
+			Handler:       _AgentService_UploadPacketSlice_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadDiagnostics",
+			Handler:       _AgentService_UploadDiagnostics_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAgentRelease",
+			Handler:       _AgentService_DownloadAgentRelease_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "agent/agent.proto",
 }
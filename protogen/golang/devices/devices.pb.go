@@ -8,8 +8,10 @@ package devices
 
 import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -237,6 +239,9 @@ type CaptureConfig struct {
 	Promiscuous   bool                   `protobuf:"varint,3,opt,name=promiscuous,proto3" json:"promiscuous,omitempty"`
 	SnapLen       int32                  `protobuf:"varint,4,opt,name=snapLen,proto3" json:"snapLen,omitempty"`
 	Timeout       int64                  `protobuf:"varint,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	FlowMode      bool                   `protobuf:"varint,6,opt,name=flowMode,proto3" json:"flowMode,omitempty"`
+	HttpMode      bool                   `protobuf:"varint,7,opt,name=httpMode,proto3" json:"httpMode,omitempty"`
+	RingBuffer    bool                   `protobuf:"varint,8,opt,name=ringBuffer,proto3" json:"ringBuffer,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -306,6 +311,27 @@ func (x *CaptureConfig) GetTimeout() int64 {
 	return 0
 }
 
+func (x *CaptureConfig) GetFlowMode() bool {
+	if x != nil {
+		return x.FlowMode
+	}
+	return false
+}
+
+func (x *CaptureConfig) GetHttpMode() bool {
+	if x != nil {
+		return x.HttpMode
+	}
+	return false
+}
+
+func (x *CaptureConfig) GetRingBuffer() bool {
+	if x != nil {
+		return x.RingBuffer
+	}
+	return false
+}
+
 type InterfaceCaptureMap struct {
 	state         protoimpl.MessageState    `protogen:"open.v1"`
 	Captures      map[uint64]*CaptureConfig `protobuf:"bytes,1,rep,name=captures,proto3" json:"captures,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
@@ -405,8 +431,24 @@ type GetDeviceResponse struct {
 	PreviousAssociations     map[string]*InterfaceCaptureMap `protobuf:"bytes,7,rep,name=previous_associations,json=previousAssociations,proto3" json:"previous_associations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	PcapVersion              string                          `protobuf:"bytes,8,opt,name=pcap_version,json=pcapVersion,proto3" json:"pcap_version,omitempty"`
 	Interfaces               []string                        `protobuf:"bytes,9,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
-	unknownFields            protoimpl.UnknownFields
-	sizeCache                protoimpl.SizeCache
+	// the packet counters of the device's live captures, from its latest report
+	CaptureStats          []*CaptureStats        `protobuf:"bytes,10,rep,name=capture_stats,json=captureStats,proto3" json:"capture_stats,omitempty"`
+	EventsDropped         uint64                 `protobuf:"varint,11,opt,name=events_dropped,json=eventsDropped,proto3" json:"events_dropped,omitempty"`
+	CaptureStatsUpdatedAt *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=capture_stats_updated_at,json=captureStatsUpdatedAt,proto3" json:"capture_stats_updated_at,omitempty"`
+	// the state of each capture of the device, as last reported by the device
+	CaptureResults []*CaptureResult `protobuf:"bytes,13,rep,name=capture_results,json=captureResults,proto3" json:"capture_results,omitempty"`
+	// the desired captures next to the state the device reported for them, plus any captures the device has that aren't desired
+	Captures []*CaptureStatus `protobuf:"bytes,14,rep,name=captures,proto3" json:"captures,omitempty"`
+	// the interfaces of the device with their addresses and flags, as last reported by the device
+	InterfaceDetails []*InterfaceDetails `protobuf:"bytes,15,rep,name=interface_details,json=interfaceDetails,proto3" json:"interface_details,omitempty"`
+	// whether the device's agent sent a heartbeat recently, false once it has been silent for longer than the offline threshold
+	Online       bool                   `protobuf:"varint,16,opt,name=online,proto3" json:"online,omitempty"`
+	LastSeenAt   *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=last_seen_at,json=lastSeenAt,proto3" json:"last_seen_at,omitempty"`
+	AgentVersion string                 `protobuf:"bytes,18,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	// the device's last heartbeat, unset until its agent sends one
+	Heartbeat     *Heartbeat `protobuf:"bytes,19,opt,name=heartbeat,proto3" json:"heartbeat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetDeviceResponse) Reset() {
@@ -502,27 +544,110 @@ func (x *GetDeviceResponse) GetInterfaces() []string {
 	return nil
 }
 
-type ListDevicesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Devices       []*GetDeviceResponse   `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *GetDeviceResponse) GetCaptureStats() []*CaptureStats {
+	if x != nil {
+		return x.CaptureStats
+	}
+	return nil
 }
 
-func (x *ListDevicesResponse) Reset() {
-	*x = ListDevicesResponse{}
+func (x *GetDeviceResponse) GetEventsDropped() uint64 {
+	if x != nil {
+		return x.EventsDropped
+	}
+	return 0
+}
+
+func (x *GetDeviceResponse) GetCaptureStatsUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CaptureStatsUpdatedAt
+	}
+	return nil
+}
+
+func (x *GetDeviceResponse) GetCaptureResults() []*CaptureResult {
+	if x != nil {
+		return x.CaptureResults
+	}
+	return nil
+}
+
+func (x *GetDeviceResponse) GetCaptures() []*CaptureStatus {
+	if x != nil {
+		return x.Captures
+	}
+	return nil
+}
+
+func (x *GetDeviceResponse) GetInterfaceDetails() []*InterfaceDetails {
+	if x != nil {
+		return x.InterfaceDetails
+	}
+	return nil
+}
+
+func (x *GetDeviceResponse) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *GetDeviceResponse) GetLastSeenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeenAt
+	}
+	return nil
+}
+
+func (x *GetDeviceResponse) GetAgentVersion() string {
+	if x != nil {
+		return x.AgentVersion
+	}
+	return ""
+}
+
+func (x *GetDeviceResponse) GetHeartbeat() *Heartbeat {
+	if x != nil {
+		return x.Heartbeat
+	}
+	return nil
+}
+
+// Heartbeat is the health of a device's agent, as last reported by the agent
+type Heartbeat struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AgentVersion    string                 `protobuf:"bytes,1,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	CommitHash      string                 `protobuf:"bytes,2,opt,name=commit_hash,json=commitHash,proto3" json:"commit_hash,omitempty"`
+	UptimeSeconds   uint64                 `protobuf:"varint,3,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	Os              string                 `protobuf:"bytes,4,opt,name=os,proto3" json:"os,omitempty"`
+	Arch            string                 `protobuf:"bytes,5,opt,name=arch,proto3" json:"arch,omitempty"`
+	OsVersion       string                 `protobuf:"bytes,6,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"`
+	KernelVersion   string                 `protobuf:"bytes,7,opt,name=kernel_version,json=kernelVersion,proto3" json:"kernel_version,omitempty"`
+	CpuPercent      float64                `protobuf:"fixed64,8,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	MemoryBytes     uint64                 `protobuf:"varint,9,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	CapturesRunning uint32                 `protobuf:"varint,10,opt,name=captures_running,json=capturesRunning,proto3" json:"captures_running,omitempty"`
+	CapturesFailed  uint32                 `protobuf:"varint,11,opt,name=captures_failed,json=capturesFailed,proto3" json:"captures_failed,omitempty"`
+	CapturesStopped uint32                 `protobuf:"varint,12,opt,name=captures_stopped,json=capturesStopped,proto3" json:"captures_stopped,omitempty"`
+	ReceivedAt      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=received_at,json=receivedAt,proto3" json:"received_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
 	mi := &file_devices_devices_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListDevicesResponse) String() string {
+func (x *Heartbeat) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListDevicesResponse) ProtoMessage() {}
+func (*Heartbeat) ProtoMessage() {}
 
-func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
 	mi := &file_devices_devices_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -534,82 +659,1982 @@ func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
-func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
 	return file_devices_devices_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ListDevicesResponse) GetDevices() []*GetDeviceResponse {
+func (x *Heartbeat) GetAgentVersion() string {
 	if x != nil {
-		return x.Devices
+		return x.AgentVersion
+	}
+	return ""
+}
+
+func (x *Heartbeat) GetCommitHash() string {
+	if x != nil {
+		return x.CommitHash
+	}
+	return ""
+}
+
+func (x *Heartbeat) GetUptimeSeconds() uint64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *Heartbeat) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *Heartbeat) GetOsVersion() string {
+	if x != nil {
+		return x.OsVersion
+	}
+	return ""
+}
+
+func (x *Heartbeat) GetKernelVersion() string {
+	if x != nil {
+		return x.KernelVersion
+	}
+	return ""
+}
+
+func (x *Heartbeat) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetMemoryBytes() uint64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetCapturesRunning() uint32 {
+	if x != nil {
+		return x.CapturesRunning
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetCapturesFailed() uint32 {
+	if x != nil {
+		return x.CapturesFailed
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetCapturesStopped() uint32 {
+	if x != nil {
+		return x.CapturesStopped
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetReceivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReceivedAt
 	}
 	return nil
 }
 
-var File_devices_devices_proto protoreflect.FileDescriptor
+type InterfaceDetails struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// addresses in CIDR notation, or as a bare IP when the netmask is unknown
+	Addresses []string `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	// libpcap flags, e.g. UP, RUNNING, LOOPBACK
+	Flags         []string `protobuf:"bytes,4,rep,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_devices_devices_proto_rawDesc = "" +
-	"\n" +
-	"\x15devices/devices.proto\x12\adevices\x1a\x1cgoogle/api/annotations.proto\"\a\n" +
-	"\x05Empty\"\"\n" +
-	"\x10GetDeviceRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"=\n" +
-	"\x12ListDevicesRequest\x12'\n" +
-	"\x0forganization_id\x18\x01 \x01(\tR\x0eorganizationId\"\xb3\x03\n" +
-	"\x13UpdateDeviceRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
-	"\fpcap_version\x18\x02 \x01(\tR\vpcapVersion\x12\x1e\n" +
-	"\n" +
-	"interfaces\x18\x03 \x03(\tR\n" +
-	"interfaces\x12&\n" +
-	"\x0fclient_cert_pem\x18\x04 \x01(\tR\rclientCertPem\x126\n" +
-	"\x17client_cert_fingerprint\x18\x05 \x01(\tR\x15clientCertFingerprint\x12x\n" +
-	"\x1ainterface_bpf_associations\x18\x06 \x03(\v2:.devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntryR\x18interfaceBpfAssociations\x1ao\n" +
-	"\x1dInterfaceBpfAssociationsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x128\n" +
-	"\x05value\x18\x02 \x01(\v2\".devices.InterfaceCaptureMapUpdateR\x05value:\x028\x01\"\x97\x01\n" +
-	"\rCaptureConfig\x12\x10\n" +
-	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1e\n" +
-	"\n" +
-	"deviceName\x18\x02 \x01(\tR\n" +
-	"deviceName\x12 \n" +
-	"\vpromiscuous\x18\x03 \x01(\bR\vpromiscuous\x12\x18\n" +
-	"\asnapLen\x18\x04 \x01(\x05R\asnapLen\x12\x18\n" +
-	"\atimeout\x18\x05 \x01(\x03R\atimeout\"\xb2\x01\n" +
-	"\x13InterfaceCaptureMap\x12F\n" +
-	"\bcaptures\x18\x01 \x03(\v2*.devices.InterfaceCaptureMap.CapturesEntryR\bcaptures\x1aS\n" +
-	"\rCapturesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\x04R\x03key\x12,\n" +
-	"\x05value\x18\x02 \x01(\v2\x16.devices.CaptureConfigR\x05value:\x028\x01\"\xbe\x01\n" +
-	"\x19InterfaceCaptureMapUpdate\x12L\n" +
-	"\bcaptures\x18\x01 \x03(\v20.devices.InterfaceCaptureMapUpdate.CapturesEntryR\bcaptures\x1aS\n" +
-	"\rCapturesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
-	"\x05value\x18\x02 \x01(\v2\x16.devices.CaptureConfigR\x05value:\x028\x01\"\xd6\x05\n" +
-	"\x11GetDeviceResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
-	"\x0forganization_id\x18\x02 \x01(\tR\x0eorganizationId\x120\n" +
-	"\x14os_unique_identifier\x18\x03 \x01(\tR\x12osUniqueIdentifier\x12&\n" +
-	"\x0fclient_cert_pem\x18\x04 \x01(\tR\rclientCertPem\x126\n" +
-	"\x17client_cert_fingerprint\x18\x05 \x01(\tR\x15clientCertFingerprint\x12v\n" +
-	"\x1ainterface_bpf_associations\x18\x06 \x03(\v28.devices.GetDeviceResponse.InterfaceBpfAssociationsEntryR\x18interfaceBpfAssociations\x12i\n" +
-	"\x15previous_associations\x18\a \x03(\v24.devices.GetDeviceResponse.PreviousAssociationsEntryR\x14previousAssociations\x12!\n" +
-	"\fpcap_version\x18\b \x01(\tR\vpcapVersion\x12\x1e\n" +
-	"\n" +
-	"interfaces\x18\t \x03(\tR\n" +
-	"interfaces\x1ai\n" +
-	"\x1dInterfaceBpfAssociationsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
-	"\x05value\x18\x02 \x01(\v2\x1c.devices.InterfaceCaptureMapR\x05value:\x028\x01\x1ae\n" +
-	"\x19PreviousAssociationsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
-	"\x05value\x18\x02 \x01(\v2\x1c.devices.InterfaceCaptureMapR\x05value:\x028\x01\"K\n" +
-	"\x13ListDevicesResponse\x124\n" +
-	"\adevices\x18\x01 \x03(\v2\x1a.devices.GetDeviceResponseR\adevices2\x95\x02\n" +
-	"\x0eDevicesService\x12V\n" +
-	"\x03Get\x12\x19.devices.GetDeviceRequest\x1a\x1a.devices.GetDeviceResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/devices/{id}\x12V\n" +
-	"\x04List\x12\x1b.devices.ListDevicesRequest\x1a\x1c.devices.ListDevicesResponse\"\x13\x82\xd3\xe4\x93\x02\r\x12\v/v1/devices\x12S\n" +
-	"\x06Update\x12\x1c.devices.UpdateDeviceRequest\x1a\x0e.devices.Empty\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\x1a\x10/v1/devices/{id}BBZ@github.com/danielhoward314/packet-sentry/protogen/golang/devicesb\x06proto3"
+func (x *InterfaceDetails) Reset() {
+	*x = InterfaceDetails{}
+	mi := &file_devices_devices_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InterfaceDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterfaceDetails) ProtoMessage() {}
+
+func (x *InterfaceDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterfaceDetails.ProtoReflect.Descriptor instead.
+func (*InterfaceDetails) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *InterfaceDetails) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InterfaceDetails) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *InterfaceDetails) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *InterfaceDetails) GetFlags() []string {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+// CaptureStats are the packet counters of a live capture, cumulative since the capture started.
+// Any dropped packets mean the capture's data is incomplete.
+type CaptureStats struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Bpf                string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	DeviceName         string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	Received           uint64                 `protobuf:"varint,3,opt,name=received,proto3" json:"received,omitempty"`
+	DroppedByKernel    uint64                 `protobuf:"varint,4,opt,name=dropped_by_kernel,json=droppedByKernel,proto3" json:"dropped_by_kernel,omitempty"`
+	DroppedByInterface uint64                 `protobuf:"varint,5,opt,name=dropped_by_interface,json=droppedByInterface,proto3" json:"dropped_by_interface,omitempty"`
+	DroppedByAgent     uint64                 `protobuf:"varint,6,opt,name=dropped_by_agent,json=droppedByAgent,proto3" json:"dropped_by_agent,omitempty"`
+	StartedAt          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CaptureStats) Reset() {
+	*x = CaptureStats{}
+	mi := &file_devices_devices_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureStats) ProtoMessage() {}
+
+func (x *CaptureStats) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureStats.ProtoReflect.Descriptor instead.
+func (*CaptureStats) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CaptureStats) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *CaptureStats) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *CaptureStats) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetDroppedByKernel() uint64 {
+	if x != nil {
+		return x.DroppedByKernel
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetDroppedByInterface() uint64 {
+	if x != nil {
+		return x.DroppedByInterface
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetDroppedByAgent() uint64 {
+	if x != nil {
+		return x.DroppedByAgent
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+// CaptureResult is the outcome of the device applying a capture of its BPF config
+type CaptureResult struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Bpf        string                 `protobuf:"bytes,1,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	DeviceName string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	// why the capture failed to start, empty if it started
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// the libpcap name of the interface's link type, e.g. EN10MB, which its BPFs are validated against
+	LinkType  string                 `protobuf:"bytes,4,opt,name=link_type,json=linkType,proto3" json:"link_type,omitempty"`
+	AppliedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	// running, failed or stopped
+	State         string `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureResult) Reset() {
+	*x = CaptureResult{}
+	mi := &file_devices_devices_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureResult) ProtoMessage() {}
+
+func (x *CaptureResult) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureResult.ProtoReflect.Descriptor instead.
+func (*CaptureResult) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CaptureResult) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetLinkType() string {
+	if x != nil {
+		return x.LinkType
+	}
+	return ""
+}
+
+func (x *CaptureResult) GetAppliedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return nil
+}
+
+func (x *CaptureResult) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+// CaptureStatus compares the desired state of a capture with the actual state reported by the device
+type CaptureStatus struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	DeviceName string                 `protobuf:"bytes,1,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	Bpf        string                 `protobuf:"bytes,2,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	// whether the capture is in the device's interface_bpf_associations
+	Desired bool `protobuf:"varint,3,opt,name=desired,proto3" json:"desired,omitempty"`
+	// running, failed or stopped as reported by the device, or pending if the device hasn't reported the capture yet
+	ActualState   string                 `protobuf:"bytes,4,opt,name=actual_state,json=actualState,proto3" json:"actual_state,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	AppliedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureStatus) Reset() {
+	*x = CaptureStatus{}
+	mi := &file_devices_devices_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureStatus) ProtoMessage() {}
+
+func (x *CaptureStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureStatus.ProtoReflect.Descriptor instead.
+func (*CaptureStatus) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CaptureStatus) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *CaptureStatus) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *CaptureStatus) GetDesired() bool {
+	if x != nil {
+		return x.Desired
+	}
+	return false
+}
+
+func (x *CaptureStatus) GetActualState() string {
+	if x != nil {
+		return x.ActualState
+	}
+	return ""
+}
+
+func (x *CaptureStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CaptureStatus) GetAppliedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return nil
+}
+
+type ListDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*GetDeviceResponse   `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesResponse) Reset() {
+	*x = ListDevicesResponse{}
+	mi := &file_devices_devices_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesResponse) ProtoMessage() {}
+
+func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListDevicesResponse) GetDevices() []*GetDeviceResponse {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type RequestPacketSliceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the device id
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// the interface and BPF of the ring buffer capture to read the packets of
+	DeviceName string `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	Bpf        string `protobuf:"bytes,3,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	// an optional BPF further filtering the packets of the slice
+	Filter        string                 `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestPacketSliceRequest) Reset() {
+	*x = RequestPacketSliceRequest{}
+	mi := &file_devices_devices_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestPacketSliceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestPacketSliceRequest) ProtoMessage() {}
+
+func (x *RequestPacketSliceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestPacketSliceRequest.ProtoReflect.Descriptor instead.
+func (*RequestPacketSliceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RequestPacketSliceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RequestPacketSliceRequest) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *RequestPacketSliceRequest) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *RequestPacketSliceRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *RequestPacketSliceRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *RequestPacketSliceRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type PacketSlice struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId   string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceName string                 `protobuf:"bytes,3,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	Bpf        string                 `protobuf:"bytes,4,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	Filter     string                 `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`
+	StartTime  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// "requested" until the agent uploads the slice, then "complete" or "failed"
+	Status      string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	Error       string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	PacketCount uint64                 `protobuf:"varint,10,opt,name=packet_count,json=packetCount,proto3" json:"packet_count,omitempty"`
+	SizeBytes   uint64                 `protobuf:"varint,11,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Truncated   bool                   `protobuf:"varint,12,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	RequestedAt *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// the `upload_packet_slice` command sent to the agent for the slice
+	CommandId     string `protobuf:"bytes,15,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PacketSlice) Reset() {
+	*x = PacketSlice{}
+	mi := &file_devices_devices_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PacketSlice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PacketSlice) ProtoMessage() {}
+
+func (x *PacketSlice) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PacketSlice.ProtoReflect.Descriptor instead.
+func (*PacketSlice) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PacketSlice) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PacketSlice) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *PacketSlice) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *PacketSlice) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *PacketSlice) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *PacketSlice) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *PacketSlice) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *PacketSlice) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PacketSlice) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PacketSlice) GetPacketCount() uint64 {
+	if x != nil {
+		return x.PacketCount
+	}
+	return 0
+}
+
+func (x *PacketSlice) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *PacketSlice) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *PacketSlice) GetRequestedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return nil
+}
+
+func (x *PacketSlice) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *PacketSlice) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+type ListPacketSlicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPacketSlicesRequest) Reset() {
+	*x = ListPacketSlicesRequest{}
+	mi := &file_devices_devices_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPacketSlicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPacketSlicesRequest) ProtoMessage() {}
+
+func (x *ListPacketSlicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPacketSlicesRequest.ProtoReflect.Descriptor instead.
+func (*ListPacketSlicesRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListPacketSlicesRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListPacketSlicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PacketSlices  []*PacketSlice         `protobuf:"bytes,1,rep,name=packet_slices,json=packetSlices,proto3" json:"packet_slices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPacketSlicesResponse) Reset() {
+	*x = ListPacketSlicesResponse{}
+	mi := &file_devices_devices_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPacketSlicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPacketSlicesResponse) ProtoMessage() {}
+
+func (x *ListPacketSlicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPacketSlicesResponse.ProtoReflect.Descriptor instead.
+func (*ListPacketSlicesResponse) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListPacketSlicesResponse) GetPacketSlices() []*PacketSlice {
+	if x != nil {
+		return x.PacketSlices
+	}
+	return nil
+}
+
+type DownloadPacketSliceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SliceId       string                 `protobuf:"bytes,2,opt,name=slice_id,json=sliceId,proto3" json:"slice_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadPacketSliceRequest) Reset() {
+	*x = DownloadPacketSliceRequest{}
+	mi := &file_devices_devices_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadPacketSliceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadPacketSliceRequest) ProtoMessage() {}
+
+func (x *DownloadPacketSliceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadPacketSliceRequest.ProtoReflect.Descriptor instead.
+func (*DownloadPacketSliceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DownloadPacketSliceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DownloadPacketSliceRequest) GetSliceId() string {
+	if x != nil {
+		return x.SliceId
+	}
+	return ""
+}
+
+// Command is a command issued to a device's agent, with its result once the agent reports it
+type Command struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Name     string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// the command's typed arguments as JSON, empty for commands without arguments
+	Args string `protobuf:"bytes,4,opt,name=args,proto3" json:"args,omitempty"`
+	// the id of the administrator who issued the command, or the component that issued it on its own
+	IssuedBy  string                 `protobuf:"bytes,5,opt,name=issued_by,json=issuedBy,proto3" json:"issued_by,omitempty"`
+	IssuedAt  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// "pending" until the agent fetches the command, "delivered" until it reports the result,
+	// then "succeeded" or "failed", or "expired" when it was fetched after its expiry
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	DeliveredAt   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	mi := &file_devices_devices_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Command) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Command) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Command) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Command) GetArgs() string {
+	if x != nil {
+		return x.Args
+	}
+	return ""
+}
+
+func (x *Command) GetIssuedBy() string {
+	if x != nil {
+		return x.IssuedBy
+	}
+	return ""
+}
+
+func (x *Command) GetIssuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
+}
+
+func (x *Command) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Command) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Command) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Command) GetDeliveredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliveredAt
+	}
+	return nil
+}
+
+func (x *Command) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Command) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+type ListCommandsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCommandsRequest) Reset() {
+	*x = ListCommandsRequest{}
+	mi := &file_devices_devices_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCommandsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCommandsRequest) ProtoMessage() {}
+
+func (x *ListCommandsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCommandsRequest.ProtoReflect.Descriptor instead.
+func (*ListCommandsRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListCommandsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListCommandsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*Command             `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCommandsResponse) Reset() {
+	*x = ListCommandsResponse{}
+	mi := &file_devices_devices_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCommandsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCommandsResponse) ProtoMessage() {}
+
+func (x *ListCommandsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCommandsResponse.ProtoReflect.Descriptor instead.
+func (*ListCommandsResponse) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListCommandsResponse) GetCommands() []*Command {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+type GetCommandRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CommandId     string                 `protobuf:"bytes,2,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommandRequest) Reset() {
+	*x = GetCommandRequest{}
+	mi := &file_devices_devices_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommandRequest) ProtoMessage() {}
+
+func (x *GetCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommandRequest.ProtoReflect.Descriptor instead.
+func (*GetCommandRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetCommandRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetCommandRequest) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+type SetLogLevelRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the device id
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// debug, info, warn or error
+	Level string `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	// how long until the agent reverts to its default level, one hour if unset
+	RevertAfterSeconds int64 `protobuf:"varint,3,opt,name=revert_after_seconds,json=revertAfterSeconds,proto3" json:"revert_after_seconds,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SetLogLevelRequest) Reset() {
+	*x = SetLogLevelRequest{}
+	mi := &file_devices_devices_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLogLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLogLevelRequest) ProtoMessage() {}
+
+func (x *SetLogLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLogLevelRequest.ProtoReflect.Descriptor instead.
+func (*SetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SetLogLevelRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetLogLevelRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *SetLogLevelRequest) GetRevertAfterSeconds() int64 {
+	if x != nil {
+		return x.RevertAfterSeconds
+	}
+	return 0
+}
+
+type RequestDiagnosticsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the device id
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestDiagnosticsRequest) Reset() {
+	*x = RequestDiagnosticsRequest{}
+	mi := &file_devices_devices_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestDiagnosticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestDiagnosticsRequest) ProtoMessage() {}
+
+func (x *RequestDiagnosticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestDiagnosticsRequest.ProtoReflect.Descriptor instead.
+func (*RequestDiagnosticsRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RequestDiagnosticsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// DiagnosticsBundle is the request for, and once uploaded the zip file of, an agent's logs, config, certificate metadata,
+// interfaces and capture stats
+type DiagnosticsBundle struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	// "requested" until the agent uploads the bundle, then "complete" or "failed"
+	Status      string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Error       string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	SizeBytes   uint64                 `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	RequestedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// the `collect_diagnostics` command sent to the agent for the bundle, only set in the response to the request
+	CommandId     string `protobuf:"bytes,8,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnosticsBundle) Reset() {
+	*x = DiagnosticsBundle{}
+	mi := &file_devices_devices_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnosticsBundle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnosticsBundle) ProtoMessage() {}
+
+func (x *DiagnosticsBundle) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnosticsBundle.ProtoReflect.Descriptor instead.
+func (*DiagnosticsBundle) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DiagnosticsBundle) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DiagnosticsBundle) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DiagnosticsBundle) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DiagnosticsBundle) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DiagnosticsBundle) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *DiagnosticsBundle) GetRequestedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return nil
+}
+
+func (x *DiagnosticsBundle) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *DiagnosticsBundle) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+type ListDiagnosticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDiagnosticsRequest) Reset() {
+	*x = ListDiagnosticsRequest{}
+	mi := &file_devices_devices_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDiagnosticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDiagnosticsRequest) ProtoMessage() {}
+
+func (x *ListDiagnosticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDiagnosticsRequest.ProtoReflect.Descriptor instead.
+func (*ListDiagnosticsRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListDiagnosticsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListDiagnosticsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bundles       []*DiagnosticsBundle   `protobuf:"bytes,1,rep,name=bundles,proto3" json:"bundles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDiagnosticsResponse) Reset() {
+	*x = ListDiagnosticsResponse{}
+	mi := &file_devices_devices_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDiagnosticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDiagnosticsResponse) ProtoMessage() {}
+
+func (x *ListDiagnosticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDiagnosticsResponse.ProtoReflect.Descriptor instead.
+func (*ListDiagnosticsResponse) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListDiagnosticsResponse) GetBundles() []*DiagnosticsBundle {
+	if x != nil {
+		return x.Bundles
+	}
+	return nil
+}
+
+type DownloadDiagnosticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	BundleId      string                 `protobuf:"bytes,2,opt,name=bundle_id,json=bundleId,proto3" json:"bundle_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadDiagnosticsRequest) Reset() {
+	*x = DownloadDiagnosticsRequest{}
+	mi := &file_devices_devices_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadDiagnosticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadDiagnosticsRequest) ProtoMessage() {}
+
+func (x *DownloadDiagnosticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadDiagnosticsRequest.ProtoReflect.Descriptor instead.
+func (*DownloadDiagnosticsRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DownloadDiagnosticsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DownloadDiagnosticsRequest) GetBundleId() string {
+	if x != nil {
+		return x.BundleId
+	}
+	return ""
+}
+
+type UpdateAgentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the device id
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// the version of the release to update to, which must be published for the OS and architecture of the device's last heartbeat
+	Version       string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAgentRequest) Reset() {
+	*x = UpdateAgentRequest{}
+	mi := &file_devices_devices_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAgentRequest) ProtoMessage() {}
+
+func (x *UpdateAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAgentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAgentRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UpdateAgentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateAgentRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// AgentRelease is an agent binary published to the artifact registry with its detached signature
+type AgentRelease struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// the binary's GOOS and GOARCH, e.g. linux and amd64
+	Os            string                 `protobuf:"bytes,3,opt,name=os,proto3" json:"os,omitempty"`
+	Arch          string                 `protobuf:"bytes,4,opt,name=arch,proto3" json:"arch,omitempty"`
+	Sha256        string                 `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	SizeBytes     uint64                 `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+	PublishedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentRelease) Reset() {
+	*x = AgentRelease{}
+	mi := &file_devices_devices_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentRelease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentRelease) ProtoMessage() {}
+
+func (x *AgentRelease) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentRelease.ProtoReflect.Descriptor instead.
+func (*AgentRelease) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AgentRelease) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *AgentRelease) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *AgentRelease) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+type ListAgentReleasesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentReleasesRequest) Reset() {
+	*x = ListAgentReleasesRequest{}
+	mi := &file_devices_devices_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentReleasesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentReleasesRequest) ProtoMessage() {}
+
+func (x *ListAgentReleasesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentReleasesRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentReleasesRequest) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{31}
+}
+
+type ListAgentReleasesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Releases      []*AgentRelease        `protobuf:"bytes,1,rep,name=releases,proto3" json:"releases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentReleasesResponse) Reset() {
+	*x = ListAgentReleasesResponse{}
+	mi := &file_devices_devices_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentReleasesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentReleasesResponse) ProtoMessage() {}
+
+func (x *ListAgentReleasesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_devices_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentReleasesResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentReleasesResponse) Descriptor() ([]byte, []int) {
+	return file_devices_devices_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListAgentReleasesResponse) GetReleases() []*AgentRelease {
+	if x != nil {
+		return x.Releases
+	}
+	return nil
+}
+
+var File_devices_devices_proto protoreflect.FileDescriptor
+
+const file_devices_devices_proto_rawDesc = "" +
+	"\n" +
+	"\x15devices/devices.proto\x12\adevices\x1a\x1cgoogle/api/annotations.proto\x1a\x19google/api/httpbody.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\"\"\n" +
+	"\x10GetDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"=\n" +
+	"\x12ListDevicesRequest\x12'\n" +
+	"\x0forganization_id\x18\x01 \x01(\tR\x0eorganizationId\"\xb3\x03\n" +
+	"\x13UpdateDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fpcap_version\x18\x02 \x01(\tR\vpcapVersion\x12\x1e\n" +
+	"\n" +
+	"interfaces\x18\x03 \x03(\tR\n" +
+	"interfaces\x12&\n" +
+	"\x0fclient_cert_pem\x18\x04 \x01(\tR\rclientCertPem\x126\n" +
+	"\x17client_cert_fingerprint\x18\x05 \x01(\tR\x15clientCertFingerprint\x12x\n" +
+	"\x1ainterface_bpf_associations\x18\x06 \x03(\v2:.devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntryR\x18interfaceBpfAssociations\x1ao\n" +
+	"\x1dInterfaceBpfAssociationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x128\n" +
+	"\x05value\x18\x02 \x01(\v2\".devices.InterfaceCaptureMapUpdateR\x05value:\x028\x01\"\xef\x01\n" +
+	"\rCaptureConfig\x12\x10\n" +
+	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1e\n" +
+	"\n" +
+	"deviceName\x18\x02 \x01(\tR\n" +
+	"deviceName\x12 \n" +
+	"\vpromiscuous\x18\x03 \x01(\bR\vpromiscuous\x12\x18\n" +
+	"\asnapLen\x18\x04 \x01(\x05R\asnapLen\x12\x18\n" +
+	"\atimeout\x18\x05 \x01(\x03R\atimeout\x12\x1a\n" +
+	"\bflowMode\x18\x06 \x01(\bR\bflowMode\x12\x1a\n" +
+	"\bhttpMode\x18\a \x01(\bR\bhttpMode\x12\x1e\n" +
+	"\n" +
+	"ringBuffer\x18\b \x01(\bR\n" +
+	"ringBuffer\"\xb2\x01\n" +
+	"\x13InterfaceCaptureMap\x12F\n" +
+	"\bcaptures\x18\x01 \x03(\v2*.devices.InterfaceCaptureMap.CapturesEntryR\bcaptures\x1aS\n" +
+	"\rCapturesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x04R\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.devices.CaptureConfigR\x05value:\x028\x01\"\xbe\x01\n" +
+	"\x19InterfaceCaptureMapUpdate\x12L\n" +
+	"\bcaptures\x18\x01 \x03(\v20.devices.InterfaceCaptureMapUpdate.CapturesEntryR\bcaptures\x1aS\n" +
+	"\rCapturesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.devices.CaptureConfigR\x05value:\x028\x01\"\xf8\t\n" +
+	"\x11GetDeviceResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0forganization_id\x18\x02 \x01(\tR\x0eorganizationId\x120\n" +
+	"\x14os_unique_identifier\x18\x03 \x01(\tR\x12osUniqueIdentifier\x12&\n" +
+	"\x0fclient_cert_pem\x18\x04 \x01(\tR\rclientCertPem\x126\n" +
+	"\x17client_cert_fingerprint\x18\x05 \x01(\tR\x15clientCertFingerprint\x12v\n" +
+	"\x1ainterface_bpf_associations\x18\x06 \x03(\v28.devices.GetDeviceResponse.InterfaceBpfAssociationsEntryR\x18interfaceBpfAssociations\x12i\n" +
+	"\x15previous_associations\x18\a \x03(\v24.devices.GetDeviceResponse.PreviousAssociationsEntryR\x14previousAssociations\x12!\n" +
+	"\fpcap_version\x18\b \x01(\tR\vpcapVersion\x12\x1e\n" +
+	"\n" +
+	"interfaces\x18\t \x03(\tR\n" +
+	"interfaces\x12:\n" +
+	"\rcapture_stats\x18\n" +
+	" \x03(\v2\x15.devices.CaptureStatsR\fcaptureStats\x12%\n" +
+	"\x0eevents_dropped\x18\v \x01(\x04R\reventsDropped\x12S\n" +
+	"\x18capture_stats_updated_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\x15captureStatsUpdatedAt\x12?\n" +
+	"\x0fcapture_results\x18\r \x03(\v2\x16.devices.CaptureResultR\x0ecaptureResults\x122\n" +
+	"\bcaptures\x18\x0e \x03(\v2\x16.devices.CaptureStatusR\bcaptures\x12F\n" +
+	"\x11interface_details\x18\x0f \x03(\v2\x19.devices.InterfaceDetailsR\x10interfaceDetails\x12\x16\n" +
+	"\x06online\x18\x10 \x01(\bR\x06online\x12<\n" +
+	"\flast_seen_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastSeenAt\x12#\n" +
+	"\ragent_version\x18\x12 \x01(\tR\fagentVersion\x120\n" +
+	"\theartbeat\x18\x13 \x01(\v2\x12.devices.HeartbeatR\theartbeat\x1ai\n" +
+	"\x1dInterfaceBpfAssociationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.devices.InterfaceCaptureMapR\x05value:\x028\x01\x1ae\n" +
+	"\x19PreviousAssociationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.devices.InterfaceCaptureMapR\x05value:\x028\x01\"\xe2\x03\n" +
+	"\tHeartbeat\x12#\n" +
+	"\ragent_version\x18\x01 \x01(\tR\fagentVersion\x12\x1f\n" +
+	"\vcommit_hash\x18\x02 \x01(\tR\n" +
+	"commitHash\x12%\n" +
+	"\x0euptime_seconds\x18\x03 \x01(\x04R\ruptimeSeconds\x12\x0e\n" +
+	"\x02os\x18\x04 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x05 \x01(\tR\x04arch\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\x06 \x01(\tR\tosVersion\x12%\n" +
+	"\x0ekernel_version\x18\a \x01(\tR\rkernelVersion\x12\x1f\n" +
+	"\vcpu_percent\x18\b \x01(\x01R\n" +
+	"cpuPercent\x12!\n" +
+	"\fmemory_bytes\x18\t \x01(\x04R\vmemoryBytes\x12)\n" +
+	"\x10captures_running\x18\n" +
+	" \x01(\rR\x0fcapturesRunning\x12'\n" +
+	"\x0fcaptures_failed\x18\v \x01(\rR\x0ecapturesFailed\x12)\n" +
+	"\x10captures_stopped\x18\f \x01(\rR\x0fcapturesStopped\x12;\n" +
+	"\vreceived_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"receivedAt\"|\n" +
+	"\x10InterfaceDetails\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1c\n" +
+	"\taddresses\x18\x03 \x03(\tR\taddresses\x12\x14\n" +
+	"\x05flags\x18\x04 \x03(\tR\x05flags\"\xa0\x02\n" +
+	"\fCaptureStats\x12\x10\n" +
+	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x1a\n" +
+	"\breceived\x18\x03 \x01(\x04R\breceived\x12*\n" +
+	"\x11dropped_by_kernel\x18\x04 \x01(\x04R\x0fdroppedByKernel\x120\n" +
+	"\x14dropped_by_interface\x18\x05 \x01(\x04R\x12droppedByInterface\x12(\n" +
+	"\x10dropped_by_agent\x18\x06 \x01(\x04R\x0edroppedByAgent\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\"\xc6\x01\n" +
+	"\rCaptureResult\x12\x10\n" +
+	"\x03bpf\x18\x01 \x01(\tR\x03bpf\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1b\n" +
+	"\tlink_type\x18\x04 \x01(\tR\blinkType\x129\n" +
+	"\n" +
+	"applied_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tappliedAt\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\"\xd0\x01\n" +
+	"\rCaptureStatus\x12\x1f\n" +
+	"\vdevice_name\x18\x01 \x01(\tR\n" +
+	"deviceName\x12\x10\n" +
+	"\x03bpf\x18\x02 \x01(\tR\x03bpf\x12\x18\n" +
+	"\adesired\x18\x03 \x01(\bR\adesired\x12!\n" +
+	"\factual_state\x18\x04 \x01(\tR\vactualState\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"applied_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tappliedAt\"K\n" +
+	"\x13ListDevicesResponse\x124\n" +
+	"\adevices\x18\x01 \x03(\v2\x1a.devices.GetDeviceResponseR\adevices\"\xe8\x01\n" +
+	"\x19RequestPacketSliceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x10\n" +
+	"\x03bpf\x18\x03 \x01(\tR\x03bpf\x12\x16\n" +
+	"\x06filter\x18\x04 \x01(\tR\x06filter\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\"\xa2\x04\n" +
+	"\vPacketSlice\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vdevice_name\x18\x03 \x01(\tR\n" +
+	"deviceName\x12\x10\n" +
+	"\x03bpf\x18\x04 \x01(\tR\x03bpf\x12\x16\n" +
+	"\x06filter\x18\x05 \x01(\tR\x06filter\x129\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12!\n" +
+	"\fpacket_count\x18\n" +
+	" \x01(\x04R\vpacketCount\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\v \x01(\x04R\tsizeBytes\x12\x1c\n" +
+	"\ttruncated\x18\f \x01(\bR\ttruncated\x12=\n" +
+	"\frequested_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\vrequestedAt\x12=\n" +
+	"\fcompleted_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\x0f \x01(\tR\tcommandId\")\n" +
+	"\x17ListPacketSlicesRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"U\n" +
+	"\x18ListPacketSlicesResponse\x129\n" +
+	"\rpacket_slices\x18\x01 \x03(\v2\x14.devices.PacketSliceR\fpacketSlices\"G\n" +
+	"\x1aDownloadPacketSliceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bslice_id\x18\x02 \x01(\tR\asliceId\"\xd4\x03\n" +
+	"\aCommand\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04args\x18\x04 \x01(\tR\x04args\x12\x1b\n" +
+	"\tissued_by\x18\x05 \x01(\tR\bissuedBy\x127\n" +
+	"\tissued_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bissuedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12=\n" +
+	"\fdelivered_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt\x129\n" +
+	"\n" +
+	"started_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"%\n" +
+	"\x13ListCommandsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"D\n" +
+	"\x14ListCommandsResponse\x12,\n" +
+	"\bcommands\x18\x01 \x03(\v2\x10.devices.CommandR\bcommands\"B\n" +
+	"\x11GetCommandRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\x02 \x01(\tR\tcommandId\"l\n" +
+	"\x12SetLogLevelRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x120\n" +
+	"\x14revert_after_seconds\x18\x03 \x01(\x03R\x12revertAfterSeconds\"+\n" +
+	"\x19RequestDiagnosticsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xaa\x02\n" +
+	"\x11DiagnosticsBundle\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x04R\tsizeBytes\x12=\n" +
+	"\frequested_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vrequestedAt\x12=\n" +
+	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\b \x01(\tR\tcommandId\"(\n" +
+	"\x16ListDiagnosticsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"O\n" +
+	"\x17ListDiagnosticsResponse\x124\n" +
+	"\abundles\x18\x01 \x03(\v2\x1a.devices.DiagnosticsBundleR\abundles\"I\n" +
+	"\x1aDownloadDiagnosticsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tbundle_id\x18\x02 \x01(\tR\bbundleId\">\n" +
+	"\x12UpdateAgentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"\xf0\x01\n" +
+	"\fAgentRelease\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x0e\n" +
+	"\x02os\x18\x03 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x04 \x01(\tR\x04arch\x12\x16\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x06 \x01(\x04R\tsizeBytes\x12\x1c\n" +
+	"\tsignature\x18\a \x01(\fR\tsignature\x12=\n" +
+	"\fpublished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\"\x1a\n" +
+	"\x18ListAgentReleasesRequest\"N\n" +
+	"\x19ListAgentReleasesResponse\x121\n" +
+	"\breleases\x18\x01 \x03(\v2\x15.devices.AgentReleaseR\breleases2\xbb\f\n" +
+	"\x0eDevicesService\x12V\n" +
+	"\x03Get\x12\x19.devices.GetDeviceRequest\x1a\x1a.devices.GetDeviceResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/devices/{id}\x12V\n" +
+	"\x04List\x12\x1b.devices.ListDevicesRequest\x1a\x1c.devices.ListDevicesResponse\"\x13\x82\xd3\xe4\x93\x02\r\x12\v/v1/devices\x12S\n" +
+	"\x06Update\x12\x1c.devices.UpdateDeviceRequest\x1a\x0e.devices.Empty\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\x1a\x10/v1/devices/{id}\x12y\n" +
+	"\x12RequestPacketSlice\x12\".devices.RequestPacketSliceRequest\x1a\x14.devices.PacketSlice\")\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/v1/devices/{id}/packet-slices\x12\x7f\n" +
+	"\x10ListPacketSlices\x12 .devices.ListPacketSlicesRequest\x1a!.devices.ListPacketSlicesResponse\"&\x82\xd3\xe4\x93\x02 \x12\x1e/v1/devices/{id}/packet-slices\x12\x88\x01\n" +
+	"\x13DownloadPacketSlice\x12#.devices.DownloadPacketSliceRequest\x1a\x14.google.api.HttpBody\"6\x82\xd3\xe4\x93\x020\x12./v1/devices/{id}/packet-slices/{slice_id}/pcap\x12n\n" +
+	"\fListCommands\x12\x1c.devices.ListCommandsRequest\x1a\x1d.devices.ListCommandsResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/devices/{id}/commands\x12j\n" +
+	"\n" +
+	"GetCommand\x12\x1a.devices.GetCommandRequest\x1a\x10.devices.Command\".\x82\xd3\xe4\x93\x02(\x12&/v1/devices/{id}/commands/{command_id}\x12c\n" +
+	"\vSetLogLevel\x12\x1b.devices.SetLogLevelRequest\x1a\x10.devices.Command\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/devices/{id}/log-level\x12}\n" +
+	"\x12RequestDiagnostics\x12\".devices.RequestDiagnosticsRequest\x1a\x1a.devices.DiagnosticsBundle\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/devices/{id}/diagnostics\x12z\n" +
+	"\x0fListDiagnostics\x12\x1f.devices.ListDiagnosticsRequest\x1a .devices.ListDiagnosticsResponse\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/v1/devices/{id}/diagnostics\x12\x86\x01\n" +
+	"\x13DownloadDiagnostics\x12#.devices.DownloadDiagnosticsRequest\x1a\x14.google.api.HttpBody\"4\x82\xd3\xe4\x93\x02.\x12,/v1/devices/{id}/diagnostics/{bundle_id}/zip\x12`\n" +
+	"\vUpdateAgent\x12\x1b.devices.UpdateAgentRequest\x1a\x10.devices.Command\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/devices/{id}/update\x12v\n" +
+	"\x11ListAgentReleases\x12!.devices.ListAgentReleasesRequest\x1a\".devices.ListAgentReleasesResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/agent-releasesBBZ@github.com/danielhoward314/packet-sentry/protogen/golang/devicesb\x06proto3"
 
 var (
 	file_devices_devices_proto_rawDescOnce sync.Once
@@ -623,46 +2648,123 @@ func file_devices_devices_proto_rawDescGZIP() []byte {
 	return file_devices_devices_proto_rawDescData
 }
 
-var file_devices_devices_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_devices_devices_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
 var file_devices_devices_proto_goTypes = []any{
-	(*Empty)(nil),                     // 0: devices.Empty
-	(*GetDeviceRequest)(nil),          // 1: devices.GetDeviceRequest
-	(*ListDevicesRequest)(nil),        // 2: devices.ListDevicesRequest
-	(*UpdateDeviceRequest)(nil),       // 3: devices.UpdateDeviceRequest
-	(*CaptureConfig)(nil),             // 4: devices.CaptureConfig
-	(*InterfaceCaptureMap)(nil),       // 5: devices.InterfaceCaptureMap
-	(*InterfaceCaptureMapUpdate)(nil), // 6: devices.InterfaceCaptureMapUpdate
-	(*GetDeviceResponse)(nil),         // 7: devices.GetDeviceResponse
-	(*ListDevicesResponse)(nil),       // 8: devices.ListDevicesResponse
-	nil,                               // 9: devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntry
-	nil,                               // 10: devices.InterfaceCaptureMap.CapturesEntry
-	nil,                               // 11: devices.InterfaceCaptureMapUpdate.CapturesEntry
-	nil,                               // 12: devices.GetDeviceResponse.InterfaceBpfAssociationsEntry
-	nil,                               // 13: devices.GetDeviceResponse.PreviousAssociationsEntry
+	(*Empty)(nil),                      // 0: devices.Empty
+	(*GetDeviceRequest)(nil),           // 1: devices.GetDeviceRequest
+	(*ListDevicesRequest)(nil),         // 2: devices.ListDevicesRequest
+	(*UpdateDeviceRequest)(nil),        // 3: devices.UpdateDeviceRequest
+	(*CaptureConfig)(nil),              // 4: devices.CaptureConfig
+	(*InterfaceCaptureMap)(nil),        // 5: devices.InterfaceCaptureMap
+	(*InterfaceCaptureMapUpdate)(nil),  // 6: devices.InterfaceCaptureMapUpdate
+	(*GetDeviceResponse)(nil),          // 7: devices.GetDeviceResponse
+	(*Heartbeat)(nil),                  // 8: devices.Heartbeat
+	(*InterfaceDetails)(nil),           // 9: devices.InterfaceDetails
+	(*CaptureStats)(nil),               // 10: devices.CaptureStats
+	(*CaptureResult)(nil),              // 11: devices.CaptureResult
+	(*CaptureStatus)(nil),              // 12: devices.CaptureStatus
+	(*ListDevicesResponse)(nil),        // 13: devices.ListDevicesResponse
+	(*RequestPacketSliceRequest)(nil),  // 14: devices.RequestPacketSliceRequest
+	(*PacketSlice)(nil),                // 15: devices.PacketSlice
+	(*ListPacketSlicesRequest)(nil),    // 16: devices.ListPacketSlicesRequest
+	(*ListPacketSlicesResponse)(nil),   // 17: devices.ListPacketSlicesResponse
+	(*DownloadPacketSliceRequest)(nil), // 18: devices.DownloadPacketSliceRequest
+	(*Command)(nil),                    // 19: devices.Command
+	(*ListCommandsRequest)(nil),        // 20: devices.ListCommandsRequest
+	(*ListCommandsResponse)(nil),       // 21: devices.ListCommandsResponse
+	(*GetCommandRequest)(nil),          // 22: devices.GetCommandRequest
+	(*SetLogLevelRequest)(nil),         // 23: devices.SetLogLevelRequest
+	(*RequestDiagnosticsRequest)(nil),  // 24: devices.RequestDiagnosticsRequest
+	(*DiagnosticsBundle)(nil),          // 25: devices.DiagnosticsBundle
+	(*ListDiagnosticsRequest)(nil),     // 26: devices.ListDiagnosticsRequest
+	(*ListDiagnosticsResponse)(nil),    // 27: devices.ListDiagnosticsResponse
+	(*DownloadDiagnosticsRequest)(nil), // 28: devices.DownloadDiagnosticsRequest
+	(*UpdateAgentRequest)(nil),         // 29: devices.UpdateAgentRequest
+	(*AgentRelease)(nil),               // 30: devices.AgentRelease
+	(*ListAgentReleasesRequest)(nil),   // 31: devices.ListAgentReleasesRequest
+	(*ListAgentReleasesResponse)(nil),  // 32: devices.ListAgentReleasesResponse
+	nil,                                // 33: devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntry
+	nil,                                // 34: devices.InterfaceCaptureMap.CapturesEntry
+	nil,                                // 35: devices.InterfaceCaptureMapUpdate.CapturesEntry
+	nil,                                // 36: devices.GetDeviceResponse.InterfaceBpfAssociationsEntry
+	nil,                                // 37: devices.GetDeviceResponse.PreviousAssociationsEntry
+	(*timestamppb.Timestamp)(nil),      // 38: google.protobuf.Timestamp
+	(*httpbody.HttpBody)(nil),          // 39: google.api.HttpBody
 }
 var file_devices_devices_proto_depIdxs = []int32{
-	9,  // 0: devices.UpdateDeviceRequest.interface_bpf_associations:type_name -> devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntry
-	10, // 1: devices.InterfaceCaptureMap.captures:type_name -> devices.InterfaceCaptureMap.CapturesEntry
-	11, // 2: devices.InterfaceCaptureMapUpdate.captures:type_name -> devices.InterfaceCaptureMapUpdate.CapturesEntry
-	12, // 3: devices.GetDeviceResponse.interface_bpf_associations:type_name -> devices.GetDeviceResponse.InterfaceBpfAssociationsEntry
-	13, // 4: devices.GetDeviceResponse.previous_associations:type_name -> devices.GetDeviceResponse.PreviousAssociationsEntry
-	7,  // 5: devices.ListDevicesResponse.devices:type_name -> devices.GetDeviceResponse
-	6,  // 6: devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntry.value:type_name -> devices.InterfaceCaptureMapUpdate
-	4,  // 7: devices.InterfaceCaptureMap.CapturesEntry.value:type_name -> devices.CaptureConfig
-	4,  // 8: devices.InterfaceCaptureMapUpdate.CapturesEntry.value:type_name -> devices.CaptureConfig
-	5,  // 9: devices.GetDeviceResponse.InterfaceBpfAssociationsEntry.value:type_name -> devices.InterfaceCaptureMap
-	5,  // 10: devices.GetDeviceResponse.PreviousAssociationsEntry.value:type_name -> devices.InterfaceCaptureMap
-	1,  // 11: devices.DevicesService.Get:input_type -> devices.GetDeviceRequest
-	2,  // 12: devices.DevicesService.List:input_type -> devices.ListDevicesRequest
-	3,  // 13: devices.DevicesService.Update:input_type -> devices.UpdateDeviceRequest
-	7,  // 14: devices.DevicesService.Get:output_type -> devices.GetDeviceResponse
-	8,  // 15: devices.DevicesService.List:output_type -> devices.ListDevicesResponse
-	0,  // 16: devices.DevicesService.Update:output_type -> devices.Empty
-	14, // [14:17] is the sub-list for method output_type
-	11, // [11:14] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	33, // 0: devices.UpdateDeviceRequest.interface_bpf_associations:type_name -> devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntry
+	34, // 1: devices.InterfaceCaptureMap.captures:type_name -> devices.InterfaceCaptureMap.CapturesEntry
+	35, // 2: devices.InterfaceCaptureMapUpdate.captures:type_name -> devices.InterfaceCaptureMapUpdate.CapturesEntry
+	36, // 3: devices.GetDeviceResponse.interface_bpf_associations:type_name -> devices.GetDeviceResponse.InterfaceBpfAssociationsEntry
+	37, // 4: devices.GetDeviceResponse.previous_associations:type_name -> devices.GetDeviceResponse.PreviousAssociationsEntry
+	10, // 5: devices.GetDeviceResponse.capture_stats:type_name -> devices.CaptureStats
+	38, // 6: devices.GetDeviceResponse.capture_stats_updated_at:type_name -> google.protobuf.Timestamp
+	11, // 7: devices.GetDeviceResponse.capture_results:type_name -> devices.CaptureResult
+	12, // 8: devices.GetDeviceResponse.captures:type_name -> devices.CaptureStatus
+	9,  // 9: devices.GetDeviceResponse.interface_details:type_name -> devices.InterfaceDetails
+	38, // 10: devices.GetDeviceResponse.last_seen_at:type_name -> google.protobuf.Timestamp
+	8,  // 11: devices.GetDeviceResponse.heartbeat:type_name -> devices.Heartbeat
+	38, // 12: devices.Heartbeat.received_at:type_name -> google.protobuf.Timestamp
+	38, // 13: devices.CaptureStats.started_at:type_name -> google.protobuf.Timestamp
+	38, // 14: devices.CaptureResult.applied_at:type_name -> google.protobuf.Timestamp
+	38, // 15: devices.CaptureStatus.applied_at:type_name -> google.protobuf.Timestamp
+	7,  // 16: devices.ListDevicesResponse.devices:type_name -> devices.GetDeviceResponse
+	38, // 17: devices.RequestPacketSliceRequest.start_time:type_name -> google.protobuf.Timestamp
+	38, // 18: devices.RequestPacketSliceRequest.end_time:type_name -> google.protobuf.Timestamp
+	38, // 19: devices.PacketSlice.start_time:type_name -> google.protobuf.Timestamp
+	38, // 20: devices.PacketSlice.end_time:type_name -> google.protobuf.Timestamp
+	38, // 21: devices.PacketSlice.requested_at:type_name -> google.protobuf.Timestamp
+	38, // 22: devices.PacketSlice.completed_at:type_name -> google.protobuf.Timestamp
+	15, // 23: devices.ListPacketSlicesResponse.packet_slices:type_name -> devices.PacketSlice
+	38, // 24: devices.Command.issued_at:type_name -> google.protobuf.Timestamp
+	38, // 25: devices.Command.expires_at:type_name -> google.protobuf.Timestamp
+	38, // 26: devices.Command.delivered_at:type_name -> google.protobuf.Timestamp
+	38, // 27: devices.Command.started_at:type_name -> google.protobuf.Timestamp
+	38, // 28: devices.Command.finished_at:type_name -> google.protobuf.Timestamp
+	19, // 29: devices.ListCommandsResponse.commands:type_name -> devices.Command
+	38, // 30: devices.DiagnosticsBundle.requested_at:type_name -> google.protobuf.Timestamp
+	38, // 31: devices.DiagnosticsBundle.completed_at:type_name -> google.protobuf.Timestamp
+	25, // 32: devices.ListDiagnosticsResponse.bundles:type_name -> devices.DiagnosticsBundle
+	38, // 33: devices.AgentRelease.published_at:type_name -> google.protobuf.Timestamp
+	30, // 34: devices.ListAgentReleasesResponse.releases:type_name -> devices.AgentRelease
+	6,  // 35: devices.UpdateDeviceRequest.InterfaceBpfAssociationsEntry.value:type_name -> devices.InterfaceCaptureMapUpdate
+	4,  // 36: devices.InterfaceCaptureMap.CapturesEntry.value:type_name -> devices.CaptureConfig
+	4,  // 37: devices.InterfaceCaptureMapUpdate.CapturesEntry.value:type_name -> devices.CaptureConfig
+	5,  // 38: devices.GetDeviceResponse.InterfaceBpfAssociationsEntry.value:type_name -> devices.InterfaceCaptureMap
+	5,  // 39: devices.GetDeviceResponse.PreviousAssociationsEntry.value:type_name -> devices.InterfaceCaptureMap
+	1,  // 40: devices.DevicesService.Get:input_type -> devices.GetDeviceRequest
+	2,  // 41: devices.DevicesService.List:input_type -> devices.ListDevicesRequest
+	3,  // 42: devices.DevicesService.Update:input_type -> devices.UpdateDeviceRequest
+	14, // 43: devices.DevicesService.RequestPacketSlice:input_type -> devices.RequestPacketSliceRequest
+	16, // 44: devices.DevicesService.ListPacketSlices:input_type -> devices.ListPacketSlicesRequest
+	18, // 45: devices.DevicesService.DownloadPacketSlice:input_type -> devices.DownloadPacketSliceRequest
+	20, // 46: devices.DevicesService.ListCommands:input_type -> devices.ListCommandsRequest
+	22, // 47: devices.DevicesService.GetCommand:input_type -> devices.GetCommandRequest
+	23, // 48: devices.DevicesService.SetLogLevel:input_type -> devices.SetLogLevelRequest
+	24, // 49: devices.DevicesService.RequestDiagnostics:input_type -> devices.RequestDiagnosticsRequest
+	26, // 50: devices.DevicesService.ListDiagnostics:input_type -> devices.ListDiagnosticsRequest
+	28, // 51: devices.DevicesService.DownloadDiagnostics:input_type -> devices.DownloadDiagnosticsRequest
+	29, // 52: devices.DevicesService.UpdateAgent:input_type -> devices.UpdateAgentRequest
+	31, // 53: devices.DevicesService.ListAgentReleases:input_type -> devices.ListAgentReleasesRequest
+	7,  // 54: devices.DevicesService.Get:output_type -> devices.GetDeviceResponse
+	13, // 55: devices.DevicesService.List:output_type -> devices.ListDevicesResponse
+	0,  // 56: devices.DevicesService.Update:output_type -> devices.Empty
+	15, // 57: devices.DevicesService.RequestPacketSlice:output_type -> devices.PacketSlice
+	17, // 58: devices.DevicesService.ListPacketSlices:output_type -> devices.ListPacketSlicesResponse
+	39, // 59: devices.DevicesService.DownloadPacketSlice:output_type -> google.api.HttpBody
+	21, // 60: devices.DevicesService.ListCommands:output_type -> devices.ListCommandsResponse
+	19, // 61: devices.DevicesService.GetCommand:output_type -> devices.Command
+	19, // 62: devices.DevicesService.SetLogLevel:output_type -> devices.Command
+	25, // 63: devices.DevicesService.RequestDiagnostics:output_type -> devices.DiagnosticsBundle
+	27, // 64: devices.DevicesService.ListDiagnostics:output_type -> devices.ListDiagnosticsResponse
+	39, // 65: devices.DevicesService.DownloadDiagnostics:output_type -> google.api.HttpBody
+	19, // 66: devices.DevicesService.UpdateAgent:output_type -> devices.Command
+	32, // 67: devices.DevicesService.ListAgentReleases:output_type -> devices.ListAgentReleasesResponse
+	54, // [54:68] is the sub-list for method output_type
+	40, // [40:54] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
 }
 
 func init() { file_devices_devices_proto_init() }
@@ -676,7 +2778,7 @@ func file_devices_devices_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_devices_devices_proto_rawDesc), len(file_devices_devices_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   38,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -8,6 +8,7 @@ package devices
 
 import (
 	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,9 +20,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DevicesService_Get_FullMethodName    = "/devices.DevicesService/Get"
-	DevicesService_List_FullMethodName   = "/devices.DevicesService/List"
-	DevicesService_Update_FullMethodName = "/devices.DevicesService/Update"
+	DevicesService_Get_FullMethodName                 = "/devices.DevicesService/Get"
+	DevicesService_List_FullMethodName                = "/devices.DevicesService/List"
+	DevicesService_Update_FullMethodName              = "/devices.DevicesService/Update"
+	DevicesService_RequestPacketSlice_FullMethodName  = "/devices.DevicesService/RequestPacketSlice"
+	DevicesService_ListPacketSlices_FullMethodName    = "/devices.DevicesService/ListPacketSlices"
+	DevicesService_DownloadPacketSlice_FullMethodName = "/devices.DevicesService/DownloadPacketSlice"
+	DevicesService_ListCommands_FullMethodName        = "/devices.DevicesService/ListCommands"
+	DevicesService_GetCommand_FullMethodName          = "/devices.DevicesService/GetCommand"
+	DevicesService_SetLogLevel_FullMethodName         = "/devices.DevicesService/SetLogLevel"
+	DevicesService_RequestDiagnostics_FullMethodName  = "/devices.DevicesService/RequestDiagnostics"
+	DevicesService_ListDiagnostics_FullMethodName     = "/devices.DevicesService/ListDiagnostics"
+	DevicesService_DownloadDiagnostics_FullMethodName = "/devices.DevicesService/DownloadDiagnostics"
+	DevicesService_UpdateAgent_FullMethodName         = "/devices.DevicesService/UpdateAgent"
+	DevicesService_ListAgentReleases_FullMethodName   = "/devices.DevicesService/ListAgentReleases"
 )
 
 // DevicesServiceClient is the client API for DevicesService service.
@@ -31,6 +43,17 @@ type DevicesServiceClient interface {
 	Get(ctx context.Context, in *GetDeviceRequest, opts ...grpc.CallOption) (*GetDeviceResponse, error)
 	List(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
 	Update(ctx context.Context, in *UpdateDeviceRequest, opts ...grpc.CallOption) (*Empty, error)
+	RequestPacketSlice(ctx context.Context, in *RequestPacketSliceRequest, opts ...grpc.CallOption) (*PacketSlice, error)
+	ListPacketSlices(ctx context.Context, in *ListPacketSlicesRequest, opts ...grpc.CallOption) (*ListPacketSlicesResponse, error)
+	DownloadPacketSlice(ctx context.Context, in *DownloadPacketSliceRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	ListCommands(ctx context.Context, in *ListCommandsRequest, opts ...grpc.CallOption) (*ListCommandsResponse, error)
+	GetCommand(ctx context.Context, in *GetCommandRequest, opts ...grpc.CallOption) (*Command, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*Command, error)
+	RequestDiagnostics(ctx context.Context, in *RequestDiagnosticsRequest, opts ...grpc.CallOption) (*DiagnosticsBundle, error)
+	ListDiagnostics(ctx context.Context, in *ListDiagnosticsRequest, opts ...grpc.CallOption) (*ListDiagnosticsResponse, error)
+	DownloadDiagnostics(ctx context.Context, in *DownloadDiagnosticsRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	UpdateAgent(ctx context.Context, in *UpdateAgentRequest, opts ...grpc.CallOption) (*Command, error)
+	ListAgentReleases(ctx context.Context, in *ListAgentReleasesRequest, opts ...grpc.CallOption) (*ListAgentReleasesResponse, error)
 }
 
 type devicesServiceClient struct {
@@ -71,6 +94,116 @@ func (c *devicesServiceClient) Update(ctx context.Context, in *UpdateDeviceReque
 	return out, nil
 }
 
+func (c *devicesServiceClient) RequestPacketSlice(ctx context.Context, in *RequestPacketSliceRequest, opts ...grpc.CallOption) (*PacketSlice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PacketSlice)
+	err := c.cc.Invoke(ctx, DevicesService_RequestPacketSlice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) ListPacketSlices(ctx context.Context, in *ListPacketSlicesRequest, opts ...grpc.CallOption) (*ListPacketSlicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPacketSlicesResponse)
+	err := c.cc.Invoke(ctx, DevicesService_ListPacketSlices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) DownloadPacketSlice(ctx context.Context, in *DownloadPacketSliceRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, DevicesService_DownloadPacketSlice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) ListCommands(ctx context.Context, in *ListCommandsRequest, opts ...grpc.CallOption) (*ListCommandsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCommandsResponse)
+	err := c.cc.Invoke(ctx, DevicesService_ListCommands_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) GetCommand(ctx context.Context, in *GetCommandRequest, opts ...grpc.CallOption) (*Command, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Command)
+	err := c.cc.Invoke(ctx, DevicesService_GetCommand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*Command, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Command)
+	err := c.cc.Invoke(ctx, DevicesService_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) RequestDiagnostics(ctx context.Context, in *RequestDiagnosticsRequest, opts ...grpc.CallOption) (*DiagnosticsBundle, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiagnosticsBundle)
+	err := c.cc.Invoke(ctx, DevicesService_RequestDiagnostics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) ListDiagnostics(ctx context.Context, in *ListDiagnosticsRequest, opts ...grpc.CallOption) (*ListDiagnosticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDiagnosticsResponse)
+	err := c.cc.Invoke(ctx, DevicesService_ListDiagnostics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) DownloadDiagnostics(ctx context.Context, in *DownloadDiagnosticsRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, DevicesService_DownloadDiagnostics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) UpdateAgent(ctx context.Context, in *UpdateAgentRequest, opts ...grpc.CallOption) (*Command, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Command)
+	err := c.cc.Invoke(ctx, DevicesService_UpdateAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) ListAgentReleases(ctx context.Context, in *ListAgentReleasesRequest, opts ...grpc.CallOption) (*ListAgentReleasesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAgentReleasesResponse)
+	err := c.cc.Invoke(ctx, DevicesService_ListAgentReleases_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DevicesServiceServer is the server API for DevicesService service.
 // All implementations must embed UnimplementedDevicesServiceServer
 // for forward compatibility.
@@ -78,6 +211,17 @@ type DevicesServiceServer interface {
 	Get(context.Context, *GetDeviceRequest) (*GetDeviceResponse, error)
 	List(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
 	Update(context.Context, *UpdateDeviceRequest) (*Empty, error)
+	RequestPacketSlice(context.Context, *RequestPacketSliceRequest) (*PacketSlice, error)
+	ListPacketSlices(context.Context, *ListPacketSlicesRequest) (*ListPacketSlicesResponse, error)
+	DownloadPacketSlice(context.Context, *DownloadPacketSliceRequest) (*httpbody.HttpBody, error)
+	ListCommands(context.Context, *ListCommandsRequest) (*ListCommandsResponse, error)
+	GetCommand(context.Context, *GetCommandRequest) (*Command, error)
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*Command, error)
+	RequestDiagnostics(context.Context, *RequestDiagnosticsRequest) (*DiagnosticsBundle, error)
+	ListDiagnostics(context.Context, *ListDiagnosticsRequest) (*ListDiagnosticsResponse, error)
+	DownloadDiagnostics(context.Context, *DownloadDiagnosticsRequest) (*httpbody.HttpBody, error)
+	UpdateAgent(context.Context, *UpdateAgentRequest) (*Command, error)
+	ListAgentReleases(context.Context, *ListAgentReleasesRequest) (*ListAgentReleasesResponse, error)
 	mustEmbedUnimplementedDevicesServiceServer()
 }
 
@@ -97,6 +241,39 @@ func (UnimplementedDevicesServiceServer) List(context.Context, *ListDevicesReque
 func (UnimplementedDevicesServiceServer) Update(context.Context, *UpdateDeviceRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedDevicesServiceServer) RequestPacketSlice(context.Context, *RequestPacketSliceRequest) (*PacketSlice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestPacketSlice not implemented")
+}
+func (UnimplementedDevicesServiceServer) ListPacketSlices(context.Context, *ListPacketSlicesRequest) (*ListPacketSlicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPacketSlices not implemented")
+}
+func (UnimplementedDevicesServiceServer) DownloadPacketSlice(context.Context, *DownloadPacketSliceRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DownloadPacketSlice not implemented")
+}
+func (UnimplementedDevicesServiceServer) ListCommands(context.Context, *ListCommandsRequest) (*ListCommandsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCommands not implemented")
+}
+func (UnimplementedDevicesServiceServer) GetCommand(context.Context, *GetCommandRequest) (*Command, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommand not implemented")
+}
+func (UnimplementedDevicesServiceServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*Command, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedDevicesServiceServer) RequestDiagnostics(context.Context, *RequestDiagnosticsRequest) (*DiagnosticsBundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestDiagnostics not implemented")
+}
+func (UnimplementedDevicesServiceServer) ListDiagnostics(context.Context, *ListDiagnosticsRequest) (*ListDiagnosticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDiagnostics not implemented")
+}
+func (UnimplementedDevicesServiceServer) DownloadDiagnostics(context.Context, *DownloadDiagnosticsRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DownloadDiagnostics not implemented")
+}
+func (UnimplementedDevicesServiceServer) UpdateAgent(context.Context, *UpdateAgentRequest) (*Command, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAgent not implemented")
+}
+func (UnimplementedDevicesServiceServer) ListAgentReleases(context.Context, *ListAgentReleasesRequest) (*ListAgentReleasesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAgentReleases not implemented")
+}
 func (UnimplementedDevicesServiceServer) mustEmbedUnimplementedDevicesServiceServer() {}
 func (UnimplementedDevicesServiceServer) testEmbeddedByValue()                        {}
 
@@ -172,6 +349,204 @@ func _DevicesService_Update_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DevicesService_RequestPacketSlice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPacketSliceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).RequestPacketSlice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_RequestPacketSlice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).RequestPacketSlice(ctx, req.(*RequestPacketSliceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_ListPacketSlices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPacketSlicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).ListPacketSlices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_ListPacketSlices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).ListPacketSlices(ctx, req.(*ListPacketSlicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_DownloadPacketSlice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadPacketSliceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).DownloadPacketSlice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_DownloadPacketSlice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).DownloadPacketSlice(ctx, req.(*DownloadPacketSliceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_ListCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCommandsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).ListCommands(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_ListCommands_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).ListCommands(ctx, req.(*ListCommandsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_GetCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).GetCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_GetCommand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).GetCommand(ctx, req.(*GetCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_RequestDiagnostics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestDiagnosticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).RequestDiagnostics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_RequestDiagnostics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).RequestDiagnostics(ctx, req.(*RequestDiagnosticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_ListDiagnostics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDiagnosticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).ListDiagnostics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_ListDiagnostics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).ListDiagnostics(ctx, req.(*ListDiagnosticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_DownloadDiagnostics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadDiagnosticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).DownloadDiagnostics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_DownloadDiagnostics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).DownloadDiagnostics(ctx, req.(*DownloadDiagnosticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_UpdateAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).UpdateAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_UpdateAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).UpdateAgent(ctx, req.(*UpdateAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_ListAgentReleases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentReleasesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).ListAgentReleases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_ListAgentReleases_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).ListAgentReleases(ctx, req.(*ListAgentReleasesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DevicesService_ServiceDesc is the grpc.ServiceDesc for DevicesService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +566,50 @@ var DevicesService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _DevicesService_Update_Handler,
 		},
+		{
+			MethodName: "RequestPacketSlice",
+			Handler:    _DevicesService_RequestPacketSlice_Handler,
+		},
+		{
+			MethodName: "ListPacketSlices",
+			Handler:    _DevicesService_ListPacketSlices_Handler,
+		},
+		{
+			MethodName: "DownloadPacketSlice",
+			Handler:    _DevicesService_DownloadPacketSlice_Handler,
+		},
+		{
+			MethodName: "ListCommands",
+			Handler:    _DevicesService_ListCommands_Handler,
+		},
+		{
+			MethodName: "GetCommand",
+			Handler:    _DevicesService_GetCommand_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _DevicesService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "RequestDiagnostics",
+			Handler:    _DevicesService_RequestDiagnostics_Handler,
+		},
+		{
+			MethodName: "ListDiagnostics",
+			Handler:    _DevicesService_ListDiagnostics_Handler,
+		},
+		{
+			MethodName: "DownloadDiagnostics",
+			Handler:    _DevicesService_DownloadDiagnostics_Handler,
+		},
+		{
+			MethodName: "UpdateAgent",
+			Handler:    _DevicesService_UpdateAgent_Handler,
+		},
+		{
+			MethodName: "ListAgentReleases",
+			Handler:    _DevicesService_ListAgentReleases_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "devices/devices.proto",
@@ -226,6 +226,234 @@ func (x *GetEventsResponse) GetEvents() []*Event {
 	return nil
 }
 
+type DNSAnswer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Ttl           uint32                 `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Data          string                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSAnswer) Reset() {
+	*x = DNSAnswer{}
+	mi := &file_events_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSAnswer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSAnswer) ProtoMessage() {}
+
+func (x *DNSAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_events_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSAnswer.ProtoReflect.Descriptor instead.
+func (*DNSAnswer) Descriptor() ([]byte, []int) {
+	return file_events_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DNSAnswer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DNSAnswer) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DNSAnswer) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *DNSAnswer) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+type DNSEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventTime     string                 `protobuf:"bytes,1,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	Bpf           string                 `protobuf:"bytes,2,opt,name=bpf,proto3" json:"bpf,omitempty"`
+	IpSrc         string                 `protobuf:"bytes,3,opt,name=ip_src,json=ipSrc,proto3" json:"ip_src,omitempty"`
+	IpDst         string                 `protobuf:"bytes,4,opt,name=ip_dst,json=ipDst,proto3" json:"ip_dst,omitempty"`
+	DnsId         uint32                 `protobuf:"varint,5,opt,name=dns_id,json=dnsId,proto3" json:"dns_id,omitempty"`
+	Response      bool                   `protobuf:"varint,6,opt,name=response,proto3" json:"response,omitempty"`
+	ResponseCode  string                 `protobuf:"bytes,7,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
+	QueryName     string                 `protobuf:"bytes,8,opt,name=query_name,json=queryName,proto3" json:"query_name,omitempty"`
+	QueryType     string                 `protobuf:"bytes,9,opt,name=query_type,json=queryType,proto3" json:"query_type,omitempty"`
+	Answers       []*DNSAnswer           `protobuf:"bytes,10,rep,name=answers,proto3" json:"answers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSEvent) Reset() {
+	*x = DNSEvent{}
+	mi := &file_events_events_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSEvent) ProtoMessage() {}
+
+func (x *DNSEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_events_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSEvent.ProtoReflect.Descriptor instead.
+func (*DNSEvent) Descriptor() ([]byte, []int) {
+	return file_events_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DNSEvent) GetEventTime() string {
+	if x != nil {
+		return x.EventTime
+	}
+	return ""
+}
+
+func (x *DNSEvent) GetBpf() string {
+	if x != nil {
+		return x.Bpf
+	}
+	return ""
+}
+
+func (x *DNSEvent) GetIpSrc() string {
+	if x != nil {
+		return x.IpSrc
+	}
+	return ""
+}
+
+func (x *DNSEvent) GetIpDst() string {
+	if x != nil {
+		return x.IpDst
+	}
+	return ""
+}
+
+func (x *DNSEvent) GetDnsId() uint32 {
+	if x != nil {
+		return x.DnsId
+	}
+	return 0
+}
+
+func (x *DNSEvent) GetResponse() bool {
+	if x != nil {
+		return x.Response
+	}
+	return false
+}
+
+func (x *DNSEvent) GetResponseCode() string {
+	if x != nil {
+		return x.ResponseCode
+	}
+	return ""
+}
+
+func (x *DNSEvent) GetQueryName() string {
+	if x != nil {
+		return x.QueryName
+	}
+	return ""
+}
+
+func (x *DNSEvent) GetQueryType() string {
+	if x != nil {
+		return x.QueryType
+	}
+	return ""
+}
+
+func (x *DNSEvent) GetAnswers() []*DNSAnswer {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+type GetDNSEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*DNSEvent            `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDNSEventsResponse) Reset() {
+	*x = GetDNSEventsResponse{}
+	mi := &file_events_events_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDNSEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDNSEventsResponse) ProtoMessage() {}
+
+func (x *GetDNSEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_events_events_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDNSEventsResponse.ProtoReflect.Descriptor instead.
+func (*GetDNSEventsResponse) Descriptor() ([]byte, []int) {
+	return file_events_events_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetDNSEventsResponse) GetEvents() []*DNSEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 var File_events_events_proto protoreflect.FileDescriptor
 
 const file_events_events_proto_rawDesc = "" +
@@ -249,9 +477,32 @@ const file_events_events_proto_rawDesc = "" +
 	"\n" +
 	"ip_version\x18\b \x01(\tR\tipVersion\":\n" +
 	"\x11GetEventsResponse\x12%\n" +
-	"\x06events\x18\x01 \x03(\v2\r.events.EventR\x06events2k\n" +
+	"\x06events\x18\x01 \x03(\v2\r.events.EventR\x06events\"Y\n" +
+	"\tDNSAnswer\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\rR\x03ttl\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\tR\x04data\"\xac\x02\n" +
+	"\bDNSEvent\x12\x1d\n" +
+	"\n" +
+	"event_time\x18\x01 \x01(\tR\teventTime\x12\x10\n" +
+	"\x03bpf\x18\x02 \x01(\tR\x03bpf\x12\x15\n" +
+	"\x06ip_src\x18\x03 \x01(\tR\x05ipSrc\x12\x15\n" +
+	"\x06ip_dst\x18\x04 \x01(\tR\x05ipDst\x12\x15\n" +
+	"\x06dns_id\x18\x05 \x01(\rR\x05dnsId\x12\x1a\n" +
+	"\bresponse\x18\x06 \x01(\bR\bresponse\x12#\n" +
+	"\rresponse_code\x18\a \x01(\tR\fresponseCode\x12\x1d\n" +
+	"\n" +
+	"query_name\x18\b \x01(\tR\tqueryName\x12\x1d\n" +
+	"\n" +
+	"query_type\x18\t \x01(\tR\tqueryType\x12+\n" +
+	"\aanswers\x18\n" +
+	" \x03(\v2\x11.events.DNSAnswerR\aanswers\"@\n" +
+	"\x14GetDNSEventsResponse\x12(\n" +
+	"\x06events\x18\x01 \x03(\v2\x10.events.DNSEventR\x06events2\xd1\x01\n" +
 	"\rEventsService\x12Z\n" +
-	"\x03Get\x12\x18.events.GetEventsRequest\x1a\x19.events.GetEventsResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/events/{device_id}BAZ?github.com/danielhoward314/packet-sentry/protogen/golang/eventsb\x06proto3"
+	"\x03Get\x12\x18.events.GetEventsRequest\x1a\x19.events.GetEventsResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/events/{device_id}\x12d\n" +
+	"\x06GetDNS\x12\x18.events.GetEventsRequest\x1a\x1c.events.GetDNSEventsResponse\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/v1/events/{device_id}/dnsBAZ?github.com/danielhoward314/packet-sentry/protogen/golang/eventsb\x06proto3"
 
 var (
 	file_events_events_proto_rawDescOnce sync.Once
@@ -265,21 +516,28 @@ func file_events_events_proto_rawDescGZIP() []byte {
 	return file_events_events_proto_rawDescData
 }
 
-var file_events_events_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_events_events_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_events_events_proto_goTypes = []any{
-	(*GetEventsRequest)(nil),  // 0: events.GetEventsRequest
-	(*Event)(nil),             // 1: events.Event
-	(*GetEventsResponse)(nil), // 2: events.GetEventsResponse
+	(*GetEventsRequest)(nil),     // 0: events.GetEventsRequest
+	(*Event)(nil),                // 1: events.Event
+	(*GetEventsResponse)(nil),    // 2: events.GetEventsResponse
+	(*DNSAnswer)(nil),            // 3: events.DNSAnswer
+	(*DNSEvent)(nil),             // 4: events.DNSEvent
+	(*GetDNSEventsResponse)(nil), // 5: events.GetDNSEventsResponse
 }
 var file_events_events_proto_depIdxs = []int32{
 	1, // 0: events.GetEventsResponse.events:type_name -> events.Event
-	0, // 1: events.EventsService.Get:input_type -> events.GetEventsRequest
-	2, // 2: events.EventsService.Get:output_type -> events.GetEventsResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 1: events.DNSEvent.answers:type_name -> events.DNSAnswer
+	4, // 2: events.GetDNSEventsResponse.events:type_name -> events.DNSEvent
+	0, // 3: events.EventsService.Get:input_type -> events.GetEventsRequest
+	0, // 4: events.EventsService.GetDNS:input_type -> events.GetEventsRequest
+	2, // 5: events.EventsService.Get:output_type -> events.GetEventsResponse
+	5, // 6: events.EventsService.GetDNS:output_type -> events.GetDNSEventsResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_events_events_proto_init() }
@@ -293,7 +551,7 @@ func file_events_events_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_events_events_proto_rawDesc), len(file_events_events_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,8 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	EventsService_Get_FullMethodName = "/events.EventsService/Get"
+	EventsService_Get_FullMethodName    = "/events.EventsService/Get"
+	EventsService_GetDNS_FullMethodName = "/events.EventsService/GetDNS"
 )
 
 // EventsServiceClient is the client API for EventsService service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type EventsServiceClient interface {
 	Get(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	GetDNS(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetDNSEventsResponse, error)
 }
 
 type eventsServiceClient struct {
@@ -47,11 +49,22 @@ func (c *eventsServiceClient) Get(ctx context.Context, in *GetEventsRequest, opt
 	return out, nil
 }
 
+func (c *eventsServiceClient) GetDNS(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetDNSEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDNSEventsResponse)
+	err := c.cc.Invoke(ctx, EventsService_GetDNS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EventsServiceServer is the server API for EventsService service.
 // All implementations must embed UnimplementedEventsServiceServer
 // for forward compatibility.
 type EventsServiceServer interface {
 	Get(context.Context, *GetEventsRequest) (*GetEventsResponse, error)
+	GetDNS(context.Context, *GetEventsRequest) (*GetDNSEventsResponse, error)
 	mustEmbedUnimplementedEventsServiceServer()
 }
 
@@ -65,6 +78,9 @@ type UnimplementedEventsServiceServer struct{}
 func (UnimplementedEventsServiceServer) Get(context.Context, *GetEventsRequest) (*GetEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
+func (UnimplementedEventsServiceServer) GetDNS(context.Context, *GetEventsRequest) (*GetDNSEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDNS not implemented")
+}
 func (UnimplementedEventsServiceServer) mustEmbedUnimplementedEventsServiceServer() {}
 func (UnimplementedEventsServiceServer) testEmbeddedByValue()                       {}
 
@@ -104,6 +120,24 @@ func _EventsService_Get_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventsService_GetDNS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServiceServer).GetDNS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventsService_GetDNS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServiceServer).GetDNS(ctx, req.(*GetEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EventsService_ServiceDesc is the grpc.ServiceDesc for EventsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +149,10 @@ var EventsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Get",
 			Handler:    _EventsService_Get_Handler,
 		},
+		{
+			MethodName: "GetDNS",
+			Handler:    _EventsService_GetDNS_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "events/events.proto",
@@ -33,7 +33,7 @@ function build_for_target {
     }
 
     $BuildTime = (Get-Date -Format "yyyy-MM-ddTHH:mm:ssZ")
-    $LDFLAGS = "-w -s -buildmode=exe -X `"main.Version=$Version`" -X `"main.CommitHash=$CommitHash`" -X `"main.BuildTime=$BuildTime`""
+    $LDFLAGS = "-w -s -buildmode=exe -X `"github.com/danielhoward314/packet-sentry/internal/version.Version=$Version`" -X `"github.com/danielhoward314/packet-sentry/internal/version.CommitHash=$CommitHash`" -X `"github.com/danielhoward314/packet-sentry/internal/version.BuildTime=$BuildTime`""
 
     Write-Host "Building executable: $EXECUTABLE_NAME for $GOOS $GOARCH..."
     $buildResult = $env:CGO_ENABLED="1"; & "go" "build" "-trimpath" "-ldflags" $LDFLAGS "-o" "$ROOT_DIR\build\$EXECUTABLE_NAME" "$ROOT_DIR\cmd\agent"
@@ -7,18 +7,22 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/danielhoward314/packet-sentry/dao"
 	"github.com/danielhoward314/packet-sentry/dao/postgres"
+	_ "github.com/danielhoward314/packet-sentry/internal/grpczstd"
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
+	"github.com/danielhoward314/packet-sentry/internal/streams"
 	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
 )
 
@@ -26,7 +30,7 @@ type agentService struct {
 	pbAgent.UnimplementedAgentServiceServer
 	datastore *dao.Datastore
 	logger    *slog.Logger
-	jetStream nats.JetStream
+	jetStream nats.JetStreamContext
 }
 
 func NewAgentService(js nats.JetStreamContext, datastore *dao.Datastore, logger *slog.Logger) pbAgent.AgentServiceServer {
@@ -59,16 +63,21 @@ func (as *agentService) ReportInterfaces(ctx context.Context, req *pbAgent.Repor
 	}
 
 	interfaces := make([]string, 0, len(req.Interfaces))
+	interfaceDetails := make([]dao.InterfaceDetails, 0, len(req.Interfaces))
 
 	for _, iface := range req.Interfaces {
 		logger.Info("received interface name", psLog.KeyDeviceName, iface.Name)
 		interfaces = append(interfaces, iface.Name)
+		interfaceDetails = append(interfaceDetails, dao.InterfaceDetails{
+			Name:        iface.Name,
+			Description: iface.Description,
+			Addresses:   iface.Addresses,
+			Flags:       iface.Flags,
+		})
 	}
 
-	existingDevice.Interfaces = interfaces
-	existingDevice.PCapVersion = req.PcapVersion
-
-	err = as.datastore.Devices.Update(existingDevice)
+	// only the reported columns are updated, so an administrator's concurrent BPF update isn't overwritten
+	err = as.datastore.Devices.UpdateInterfaces(existingDevice.ID, interfaces, interfaceDetails, req.PcapVersion)
 	if err != nil {
 		logger.Error("error updating device", psLog.KeyError, err)
 		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error updating device: %v", err))
@@ -77,37 +86,159 @@ func (as *agentService) ReportInterfaces(ctx context.Context, req *pbAgent.Repor
 	return &pbAgent.Empty{}, nil
 }
 
-func (as *agentService) PollCommand(ctx context.Context, req *pbAgent.Empty) (*pbAgent.CommandsResponse, error) {
-	logger := as.logger.With(psLog.KeyFunction, "agentService.PollCommand")
+// ReportCaptureStats stores the packet counters of the device's live captures, replacing the ones it last reported
+func (as *agentService) ReportCaptureStats(ctx context.Context, req *pbAgent.ReportCaptureStatsRequest) (*pbAgent.Empty, error) {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.ReportCaptureStats")
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	existingDevice, err := as.datastore.Devices.GetDeviceByPredicate(postgres.PredicateOSUniqueIdentifier, osUniqueIdentifier)
+	if err != nil {
+		logger.Error("error looking up device by os_unique_identifier", psLog.KeyError, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "%s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error looking up device by os_unique_identifier: %v", err))
+	}
+	if existingDevice == nil {
+		logger.Error("device record is nil")
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("device record nil when selected by os_unique_identifier: %s", osUniqueIdentifier))
+	}
+
+	captureStats := make([]dao.CaptureStats, 0, len(req.Captures))
+	for _, capture := range req.Captures {
+		captureStats = append(captureStats, dao.CaptureStats{
+			Bpf:                capture.Bpf,
+			DeviceName:         capture.DeviceName,
+			Received:           capture.Received,
+			DroppedByKernel:    capture.DroppedByKernel,
+			DroppedByInterface: capture.DroppedByInterface,
+			DroppedByAgent:     capture.DroppedByAgent,
+			StartedAt:          capture.StartedAt.AsTime(),
+		})
+	}
+	collectedAt := time.Now()
+	if req.CollectedAt != nil {
+		collectedAt = req.CollectedAt.AsTime()
+	}
+
+	err = as.datastore.Devices.UpdateCaptureStats(existingDevice.ID, captureStats, req.EventsDropped, collectedAt)
+	if err != nil {
+		logger.Error("error updating device capture stats", psLog.KeyError, err)
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error updating device capture stats: %v", err))
+	}
+
+	return &pbAgent.Empty{}, nil
+}
+
+// ReportCaptureResults stores the state of every capture of the device, replacing the ones it last reported
+func (as *agentService) ReportCaptureResults(ctx context.Context, req *pbAgent.ReportCaptureResultsRequest) (*pbAgent.Empty, error) {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.ReportCaptureResults")
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	existingDevice, err := as.datastore.Devices.GetDeviceByPredicate(postgres.PredicateOSUniqueIdentifier, osUniqueIdentifier)
+	if err != nil {
+		logger.Error("error looking up device by os_unique_identifier", psLog.KeyError, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "%s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error looking up device by os_unique_identifier: %v", err))
+	}
+	if existingDevice == nil {
+		logger.Error("device record is nil")
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("device record nil when selected by os_unique_identifier: %s", osUniqueIdentifier))
+	}
+
+	captureResults := make([]dao.CaptureResult, 0, len(req.Results))
+	for _, result := range req.Results {
+		captureResults = append(captureResults, dao.CaptureResult{
+			Bpf:        result.Bpf,
+			DeviceName: result.DeviceName,
+			Error:      result.Error,
+			LinkType:   result.LinkType,
+			AppliedAt:  result.AppliedAt.AsTime(),
+			State:      result.State,
+		})
+	}
+
+	err = as.datastore.Devices.UpdateCaptureResults(existingDevice.ID, captureResults)
+	if err != nil {
+		logger.Error("error updating device capture results", psLog.KeyError, err)
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error updating device capture results: %v", err))
+	}
+
+	return &pbAgent.Empty{}, nil
+}
+
+// Heartbeat stores the agent's heartbeat on its device, marking the device online
+func (as *agentService) Heartbeat(ctx context.Context, req *pbAgent.HeartbeatRequest) (*pbAgent.Empty, error) {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.Heartbeat")
 
 	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
 
-	subject := "cmds." + osUniqueIdentifier
-	durable := osUniqueIdentifier
-	stream := "COMMANDS"
+	existingDevice, err := as.datastore.Devices.GetDeviceByPredicate(postgres.PredicateOSUniqueIdentifier, osUniqueIdentifier)
+	if err != nil {
+		logger.Error("error looking up device by os_unique_identifier", psLog.KeyError, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "%s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error looking up device by os_unique_identifier: %v", err))
+	}
+	if existingDevice == nil {
+		logger.Error("device record is nil")
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("device record nil when selected by os_unique_identifier: %s", osUniqueIdentifier))
+	}
+
+	err = as.datastore.Devices.UpdateHeartbeat(existingDevice.ID, &dao.Heartbeat{
+		AgentVersion:    req.AgentVersion,
+		CommitHash:      req.CommitHash,
+		UptimeSeconds:   req.UptimeSeconds,
+		OS:              req.Os,
+		Arch:            req.Arch,
+		OSVersion:       req.OsVersion,
+		KernelVersion:   req.KernelVersion,
+		CPUPercent:      req.CpuPercent,
+		MemoryBytes:     req.MemoryBytes,
+		CapturesRunning: req.CapturesRunning,
+		CapturesFailed:  req.CapturesFailed,
+		CapturesStopped: req.CapturesStopped,
+		// the server's clock decides when a device was last seen, so a skewed agent clock can't keep it online
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error("error updating device heartbeat", psLog.KeyError, err)
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error updating device heartbeat: %v", err))
+	}
+	if !existingDevice.Online {
+		logger.Info("device is online", psLog.KeyOSUniqueIdentifier, osUniqueIdentifier)
+	}
+
+	return &pbAgent.Empty{}, nil
+}
 
-	// Try to bind to the existing durable consumer
-	sub, err := as.jetStream.PullSubscribe(subject, "",
-		nats.Bind(stream, durable),
-		nats.ManualAck(),
-		nats.PullMaxWaiting(128),
-	)
+func (as *agentService) PollCommand(ctx context.Context, req *pbAgent.Empty) (*pbAgent.CommandsResponse, error) {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.PollCommand")
 
-	if err == nats.ErrConsumerNotFound {
-		// First-time setup: create the durable consumer explicitly
-		sub, err = as.jetStream.PullSubscribe(subject, durable,
-			nats.BindStream(stream),
-			nats.ManualAck(),
-			nats.PullMaxWaiting(128),
-		)
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
 
+	sub, err := bindCommandsConsumer(as.jetStream, osUniqueIdentifier)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	defer sub.Unsubscribe()
 
 	// Pull up to 10 commands or wait 1s
 	msgs, err := sub.Fetch(10, nats.MaxWait(1*time.Second))
@@ -115,21 +246,24 @@ func (as *agentService) PollCommand(ctx context.Context, req *pbAgent.Empty) (*p
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	var commands []string
+	var pbCmds []*pbAgent.Command
 	for _, msg := range msgs {
-		commands = append(commands, string(msg.Data))
+		pbCmd := as.deliverableCommand(logger, msg)
+		if pbCmd == nil {
+			continue
+		}
+		if pbCmd.Id != "" {
+			err = as.datastore.Commands.MarkDelivered(pbCmd.Id)
+			if err != nil {
+				logger.Error("error marking command delivered", psLog.KeyCommandID, pbCmd.Id, psLog.KeyError, err)
+			}
+		}
+		pbCmds = append(pbCmds, pbCmd)
 		msg.Ack()
 	}
-	if len(commands) > 0 {
+	if len(pbCmds) > 0 {
 		logger.Info("sending commands received from NATS stream")
 
-		pbCmds := make([]*pbAgent.Command, 0, len(commands))
-		for _, cmdStr := range commands {
-			pbCmds = append(pbCmds, &pbAgent.Command{
-				Name: cmdStr,
-			})
-		}
-
 		return &pbAgent.CommandsResponse{
 			Commands: pbCmds,
 		}, nil
@@ -143,6 +277,191 @@ func (as *agentService) PollCommand(ctx context.Context, req *pbAgent.Empty) (*p
 	}
 }
 
+// CommandStream pushes the device's commands to the agent as soon as they are published.
+// The agent acknowledges each command with an id, and only then is its message acked and the command marked delivered,
+// so a command sent on a stream that breaks before the agent got it is delivered again on the next stream or poll.
+func (as *agentService) CommandStream(stream pbAgent.AgentService_CommandStreamServer) error {
+	ctx := stream.Context()
+	logger := as.logger.With(psLog.KeyFunction, "agentService.CommandStream")
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	logger = logger.With(psLog.KeyOSUniqueIdentifier, osUniqueIdentifier)
+
+	sub, err := bindCommandsConsumer(as.jetStream, osUniqueIdentifier)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer sub.Unsubscribe()
+
+	var pendingMu sync.Mutex
+	// the messages of the commands sent on the stream that the agent hasn't acknowledged yet, by command id
+	pending := make(map[string]*nats.Msg)
+	recvErrC := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErrC <- err
+				return
+			}
+			if req.AckCommandId == "" {
+				continue
+			}
+			pendingMu.Lock()
+			msg := pending[req.AckCommandId]
+			delete(pending, req.AckCommandId)
+			pendingMu.Unlock()
+			if msg == nil {
+				continue
+			}
+			err = as.datastore.Commands.MarkDelivered(req.AckCommandId)
+			if err != nil {
+				logger.Error("error marking command delivered", psLog.KeyCommandID, req.AckCommandId, psLog.KeyError, err)
+			}
+			msg.Ack()
+		}
+	}()
+
+	logger.Info("command stream opened")
+	for {
+		select {
+		case err := <-recvErrC:
+			if errors.Is(err, io.EOF) {
+				logger.Info("agent closed command stream")
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		// a fetch returns as soon as a command is published, the wait bounds how long the stream goes quiet
+		fetchCtx, cancel := context.WithTimeout(ctx, commandStreamFetchWait)
+		msgs, err := sub.Fetch(10, nats.Context(fetchCtx))
+		cancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) && !errors.Is(err, nats.ErrTimeout) {
+			logger.Error("error fetching commands", psLog.KeyError, err)
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		if len(msgs) == 0 && ctx.Err() == nil {
+			// a noop on every idle fetch lets the agent tell a quiet stream from a dead connection
+			err = stream.Send(&pbAgent.Command{Name: "noop"})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			pbCmd := as.deliverableCommand(logger, msg)
+			if pbCmd == nil {
+				continue
+			}
+			if pbCmd.Id != "" {
+				pendingMu.Lock()
+				pending[pbCmd.Id] = msg
+				pendingMu.Unlock()
+			}
+			logger.Info("pushing command", psLog.KeyCommand, pbCmd.Name, psLog.KeyCommandID, pbCmd.Id)
+			err = stream.Send(pbCmd)
+			if err != nil {
+				return err
+			}
+			if pbCmd.Id == "" {
+				// no result is tracked for commands without an id, so they aren't acknowledged either
+				msg.Ack()
+			}
+		}
+	}
+}
+
+// deliverableCommand returns the command of a message of the device's commands subject,
+// nil for a command whose expiry passed, which is acked and marked expired instead of delivered
+func (as *agentService) deliverableCommand(logger *slog.Logger, msg *nats.Msg) *pbAgent.Command {
+	pbCmd := commandFromMessage(msg.Data)
+	if pbCmd.Id == "" || pbCmd.ExpiresAt == nil || !time.Now().After(pbCmd.ExpiresAt.AsTime()) {
+		return pbCmd
+	}
+	// the agent was offline for longer than the command's expiry
+	logger.Info("command expired before delivery", psLog.KeyCommand, pbCmd.Name, psLog.KeyCommandID, pbCmd.Id)
+	err := as.datastore.Commands.Complete(pbCmd.Id, dao.CommandStatusExpired, "", nil, nil)
+	if err != nil {
+		logger.Error("error marking command expired", psLog.KeyCommandID, pbCmd.Id, psLog.KeyError, err)
+	}
+	msg.Ack()
+	return nil
+}
+
+// ReportCommandResult stores the result of a command the agent received
+func (as *agentService) ReportCommandResult(ctx context.Context, req *pbAgent.CommandResult) (*pbAgent.Empty, error) {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.ReportCommandResult")
+
+	if req.CommandId == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid command id")
+	}
+	switch req.Status {
+	case dao.CommandStatusSucceeded, dao.CommandStatusFailed, dao.CommandStatusExpired:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid command status %q", req.Status)
+	}
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	existingDevice, err := as.datastore.Devices.GetDeviceByPredicate(postgres.PredicateOSUniqueIdentifier, osUniqueIdentifier)
+	if err != nil {
+		logger.Error("error looking up device by os_unique_identifier", psLog.KeyError, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "%s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error looking up device by os_unique_identifier: %v", err))
+	}
+	if existingDevice == nil {
+		logger.Error("device record is nil")
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("device record nil when selected by os_unique_identifier: %s", osUniqueIdentifier))
+	}
+
+	command, err := as.datastore.Commands.Get(req.CommandId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "command not found: %s", req.CommandId)
+		}
+		logger.Error("error looking up command", psLog.KeyCommandID, req.CommandId, psLog.KeyError, err)
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error looking up command: %v", err))
+	}
+	// a device can only report the results of its own commands
+	if command.DeviceID != existingDevice.ID {
+		return nil, status.Errorf(codes.NotFound, "command not found: %s", req.CommandId)
+	}
+
+	var startedAt, finishedAt *time.Time
+	if req.StartedAt != nil {
+		t := req.StartedAt.AsTime()
+		startedAt = &t
+	}
+	if req.FinishedAt != nil {
+		t := req.FinishedAt.AsTime()
+		finishedAt = &t
+	}
+	err = as.datastore.Commands.Complete(command.ID, req.Status, req.Error, startedAt, finishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "result of command %s already reported", req.CommandId)
+		}
+		logger.Error("error storing command result", psLog.KeyCommandID, req.CommandId, psLog.KeyError, err)
+		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error storing command result: %v", err))
+	}
+	logger.Info("stored command result", psLog.KeyCommand, command.Name, psLog.KeyCommandID, command.ID, psLog.KeyStatus, req.Status)
+
+	return &pbAgent.Empty{}, nil
+}
+
 func (as *agentService) GetBPFConfig(ctx context.Context, req *pbAgent.Empty) (*pbAgent.BPFConfig, error) {
 	logger := as.logger.With(psLog.KeyFunction, "agentService.GetBPFConfig")
 
@@ -204,6 +523,315 @@ func (as *agentService) SendPacketEvent(stream pbAgent.AgentService_SendPacketEv
 	}
 }
 
+// SendPacketEventBatch publishes each batch of packet events received on the stream as a single NATS message.
+// The batches may be gzip or zstd compressed on the wire, the compressors are registered by this file's imports.
+func (as *agentService) SendPacketEventBatch(stream pbAgent.AgentService_SendPacketEventBatchServer) error {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.SendPacketEventBatch")
+
+	ctx := stream.Context()
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	for {
+		batch, err := stream.Recv()
+		if err != nil {
+			if err == context.Canceled || status.Code(err) == codes.Canceled {
+				logger.Info("stream context canceled (likely client disconnect)")
+				return nil
+			}
+			if err == io.EOF {
+				logger.Info("received EOF from packet event batch stream")
+				return stream.SendAndClose(&pbAgent.Empty{})
+			}
+			logger.Error("error receiving from packet event batch stream", "error", err)
+			return err
+		}
+
+		if len(batch.Events) == 0 {
+			continue
+		}
+
+		err = as.publishPacketEventBatch(osUniqueIdentifier, batch.Events)
+		if err != nil {
+			logger.Error("error publishing packet event batch to NATS", "error", err)
+			return err
+		}
+	}
+}
+
+// publishPacketEventBatch publishes the packet events as batch messages on the device's events subject,
+// splitting a batch whose data would exceed the max message size, e.g. one sent by an agent with a larger batch size
+func (as *agentService) publishPacketEventBatch(osUniqueIdentifier string, events []*pbAgent.PacketEvent) error {
+	data, err := proto.Marshal(&pbAgent.PacketEventBatch{Events: events})
+	if err != nil {
+		return fmt.Errorf("error marshaling packet event batch data in protobuf bytes: %w", err)
+	}
+	if len(data) > streams.MaxMessageBytes && len(events) > 1 {
+		half := len(events) / 2
+		err = as.publishPacketEventBatch(osUniqueIdentifier, events[:half])
+		if err != nil {
+			return err
+		}
+		return as.publishPacketEventBatch(osUniqueIdentifier, events[half:])
+	}
+
+	msg := nats.NewMsg("events." + osUniqueIdentifier)
+	msg.Header.Set(streams.HeaderMessageType, streams.MessageTypePacketEventBatch)
+	msg.Data = data
+	_, err = as.jetStream.PublishMsg(msg)
+	return err
+}
+
+func (as *agentService) SendFlowRecord(stream pbAgent.AgentService_SendFlowRecordServer) error {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.SendFlowRecord")
+
+	ctx := stream.Context()
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	for {
+		flowRecord, err := stream.Recv()
+		if err != nil {
+			if err == context.Canceled || status.Code(err) == codes.Canceled {
+				logger.Info("stream context canceled (likely client disconnect)")
+				return nil
+			}
+			if err == io.EOF {
+				logger.Info("received EOF from flow record stream")
+				return stream.SendAndClose(&pbAgent.Empty{})
+			}
+			logger.Error("error receiving from flow record stream", "error", err)
+			return err
+		}
+
+		data, err := proto.Marshal(flowRecord)
+		if err != nil {
+			logger.Error("error marshaling flow record data in protobuf bytes", "error", err)
+			return err
+		}
+
+		_, err = as.jetStream.Publish("flows."+osUniqueIdentifier, data)
+		if err != nil {
+			logger.Error("error publishing flow record to NATS", "error", err)
+			return err
+		}
+	}
+}
+
+// UploadPacketSlice stores the pcap file of the packets of a capture's ring buffer, requested with an `upload_packet_slice` command
+func (as *agentService) UploadPacketSlice(stream pbAgent.AgentService_UploadPacketSliceServer) error {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.UploadPacketSlice")
+
+	ctx := stream.Context()
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	device, err := as.datastore.Devices.GetDeviceByPredicate(postgres.PredicateOSUniqueIdentifier, osUniqueIdentifier)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	var sliceID string
+	var data []byte
+	var lastChunk *pbAgent.PacketSliceChunk
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err == context.Canceled || status.Code(err) == codes.Canceled {
+				logger.Info("stream context canceled (likely client disconnect)")
+				return nil
+			}
+			logger.Error("error receiving from packet slice stream", psLog.KeyError, err)
+			return err
+		}
+
+		if sliceID == "" {
+			sliceID = chunk.SliceId
+			packetSlice, err := as.datastore.PacketSlices.Get(sliceID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return status.Errorf(codes.NotFound, "packet slice not found: %s", sliceID)
+				}
+				return status.Errorf(codes.Internal, "failed to read packet slice: %v", err)
+			}
+			// a device can only upload the slices requested from it
+			if packetSlice.DeviceID != device.ID {
+				return status.Errorf(codes.NotFound, "packet slice not found: %s", sliceID)
+			}
+			if packetSlice.Status != dao.PacketSliceStatusRequested {
+				return status.Errorf(codes.FailedPrecondition, "packet slice %s was already uploaded", sliceID)
+			}
+		} else if chunk.SliceId != sliceID {
+			return status.Errorf(codes.InvalidArgument, "packet slice stream switched from slice %s to %s", sliceID, chunk.SliceId)
+		}
+
+		if len(data)+len(chunk.Data) > packetSliceMaxBytes {
+			return status.Errorf(codes.ResourceExhausted, "packet slice exceeds max size of %d bytes", packetSliceMaxBytes)
+		}
+		data = append(data, chunk.Data...)
+		lastChunk = chunk
+	}
+
+	if lastChunk == nil {
+		return status.Error(codes.InvalidArgument, "empty packet slice stream")
+	}
+
+	err = as.datastore.PacketSlices.Complete(sliceID, data, lastChunk.PacketCount, lastChunk.Truncated, lastChunk.Error)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Errorf(codes.FailedPrecondition, "packet slice %s was already uploaded", sliceID)
+		}
+		logger.Error("error storing packet slice", psLog.KeyError, err)
+		return status.Errorf(codes.Internal, "failed to store packet slice: %v", err)
+	}
+
+	logger.Info("stored packet slice", psLog.KeySliceID, sliceID, psLog.KeyPacketCount, lastChunk.PacketCount)
+	return stream.SendAndClose(&pbAgent.Empty{})
+}
+
+// UploadDiagnostics stores the zip file of the agent's diagnostics, requested with a `collect_diagnostics` command
+func (as *agentService) UploadDiagnostics(stream pbAgent.AgentService_UploadDiagnosticsServer) error {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.UploadDiagnostics")
+
+	ctx := stream.Context()
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	device, err := as.datastore.Devices.GetDeviceByPredicate(postgres.PredicateOSUniqueIdentifier, osUniqueIdentifier)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	var bundleID string
+	var data []byte
+	var lastChunk *pbAgent.DiagnosticsChunk
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err == context.Canceled || status.Code(err) == codes.Canceled {
+				logger.Info("stream context canceled (likely client disconnect)")
+				return nil
+			}
+			logger.Error("error receiving from diagnostics stream", psLog.KeyError, err)
+			return err
+		}
+
+		if bundleID == "" {
+			bundleID = chunk.BundleId
+			bundle, err := as.datastore.DiagnosticsBundles.Get(bundleID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return status.Errorf(codes.NotFound, "diagnostics bundle not found: %s", bundleID)
+				}
+				return status.Errorf(codes.Internal, "failed to read diagnostics bundle: %v", err)
+			}
+			// a device can only upload the bundles requested from it
+			if bundle.DeviceID != device.ID {
+				return status.Errorf(codes.NotFound, "diagnostics bundle not found: %s", bundleID)
+			}
+			if bundle.Status != dao.DiagnosticsBundleStatusRequested {
+				return status.Errorf(codes.FailedPrecondition, "diagnostics bundle %s was already uploaded", bundleID)
+			}
+		} else if chunk.BundleId != bundleID {
+			return status.Errorf(codes.InvalidArgument, "diagnostics stream switched from bundle %s to %s", bundleID, chunk.BundleId)
+		}
+
+		if len(data)+len(chunk.Data) > diagnosticsMaxBytes {
+			return status.Errorf(codes.ResourceExhausted, "diagnostics bundle exceeds max size of %d bytes", diagnosticsMaxBytes)
+		}
+		data = append(data, chunk.Data...)
+		lastChunk = chunk
+	}
+
+	if lastChunk == nil {
+		return status.Error(codes.InvalidArgument, "empty diagnostics stream")
+	}
+
+	err = as.datastore.DiagnosticsBundles.Complete(bundleID, data, lastChunk.Error)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Errorf(codes.FailedPrecondition, "diagnostics bundle %s was already uploaded", bundleID)
+		}
+		logger.Error("error storing diagnostics bundle", psLog.KeyError, err)
+		return status.Errorf(codes.Internal, "failed to store diagnostics bundle: %v", err)
+	}
+
+	logger.Info("stored diagnostics bundle", psLog.KeyBundleID, bundleID)
+	return stream.SendAndClose(&pbAgent.Empty{})
+}
+
+// DownloadAgentRelease streams the agent binary of a release of the artifact registry, for an `update_agent` command
+func (as *agentService) DownloadAgentRelease(request *pbAgent.DownloadAgentReleaseRequest, stream pbAgent.AgentService_DownloadAgentReleaseServer) error {
+	logger := as.logger.With(psLog.KeyFunction, "agentService.DownloadAgentRelease")
+
+	ctx := stream.Context()
+
+	osUniqueIdentifier, err := as.getSubjectCNFromClientCert(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	_, err = as.datastore.Devices.GetDeviceByPredicate(postgres.PredicateOSUniqueIdentifier, osUniqueIdentifier)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if request.Version == "" || request.Os == "" || request.Arch == "" {
+		return status.Error(codes.InvalidArgument, "version, os and arch are required")
+	}
+	release, err := as.datastore.AgentReleases.Get(request.Version, request.Os, request.Arch)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "no release %s published for %s/%s", request.Version, request.Os, request.Arch)
+		}
+		return status.Errorf(codes.Internal, "failed to read agent release: %v", err)
+	}
+	data, err := as.datastore.AgentReleases.ReadData(release.ID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to read agent release data: %v", err)
+	}
+
+	for len(data) > 0 {
+		n := min(len(data), agentReleaseChunkBytes)
+		err = stream.Send(&pbAgent.AgentReleaseChunk{Data: data[:n]})
+		if err != nil {
+			logger.Error("error sending agent release chunk", psLog.KeyError, err)
+			return err
+		}
+		data = data[n:]
+	}
+
+	logger.Info("sent agent release", psLog.KeyOSUniqueIdentifier, osUniqueIdentifier, psLog.KeyVersion, release.Version)
+	return nil
+}
+
 func (as *agentService) getSubjectCNFromClientCert(ctx context.Context) (string, error) {
 	logger := as.logger.With(psLog.KeyFunction, "agentService.getSubjectCNFromClientCert")
 	logger.Info("getting peer from context")
@@ -233,6 +861,9 @@ func buildBPFConfig(device *dao.Device) *pbAgent.BPFConfig {
 			DeviceName:  c.DeviceName,
 			Promiscuous: c.Promiscuous,
 			SnapLen:     c.SnapLen,
+			FlowMode:    c.FlowMode,
+			HttpMode:    c.HTTPMode,
+			RingBuffer:  c.RingBuffer,
 		}
 	}
 
@@ -302,10 +933,18 @@ func buildBPFConfig(device *dao.Device) *pbAgent.BPFConfig {
 		}
 	}
 
+	desired := make(map[string]*pbAgent.InterfaceCaptureMap)
+	for currentIface, currentCaptures := range current {
+		for currentBPFHash, currentConfig := range currentCaptures {
+			addCapture(desired, currentIface, currentBPFHash, currentConfig)
+		}
+	}
+
 	return &pbAgent.BPFConfig{
-		Create: create,
-		Update: update,
-		Delete: delete,
+		Create:  create,
+		Update:  update,
+		Delete:  delete,
+		Desired: desired,
 	}
 }
 
@@ -314,5 +953,8 @@ func configsDiffer(a, b dao.CaptureConfig) bool {
 	return a.Bpf != b.Bpf ||
 		a.DeviceName != b.DeviceName ||
 		a.Promiscuous != b.Promiscuous ||
-		a.SnapLen != b.SnapLen
+		a.SnapLen != b.SnapLen ||
+		a.FlowMode != b.FlowMode ||
+		a.HTTPMode != b.HTTPMode ||
+		a.RingBuffer != b.RingBuffer
 }
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres"
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	pbDevices "github.com/danielhoward314/packet-sentry/protogen/golang/devices"
+)
+
+// agentReleaseChunkBytes is the size of the chunks an agent binary is downloaded in
+const agentReleaseChunkBytes = 1024 * 1024
+
+// UpdateAgent sends the device the `update_agent` command to install a release of the artifact registry,
+// the one published for the OS and architecture of the device's last heartbeat
+func (ds *devicesService) UpdateAgent(ctx context.Context, request *pbDevices.UpdateAgentRequest) (*pbDevices.Command, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+	version := strings.TrimSpace(request.Version)
+	if version == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid version")
+	}
+
+	device, err := ds.datastore.Devices.GetDeviceByPredicate(postgres.PredicateID, request.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "device not found: %s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read device data: %s", err.Error())
+	}
+	if device.Heartbeat == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "device %s hasn't sent a heartbeat, its OS and architecture are unknown", device.ID)
+	}
+	if device.Heartbeat.AgentVersion == version {
+		return nil, status.Errorf(codes.FailedPrecondition, "device %s already runs version %s", device.ID, version)
+	}
+
+	release, err := ds.datastore.AgentReleases.Get(version, device.Heartbeat.OS, device.Heartbeat.Arch)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no release %s published for %s/%s", version, device.Heartbeat.OS, device.Heartbeat.Arch)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read agent release: %s", err.Error())
+	}
+
+	command := &broadcast.Command{
+		Name:     broadcast.CommandUpdateAgent,
+		IssuedBy: commandIssuer(ctx, ds.tokenDatastore),
+		UpdateAgent: &broadcast.UpdateAgentArgs{
+			Version:   release.Version,
+			SHA256:    release.SHA256,
+			SizeBytes: release.SizeBytes,
+			Signature: release.Signature,
+		},
+	}
+	err = issueCommand(ds.datastore, ds.jetStream, device, command)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "command send was not ack'd: %v", err)
+	}
+
+	record, err := ds.datastore.Commands.Get(command.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read command: %s", err.Error())
+	}
+	return toPBCommand(record), nil
+}
+
+// ListAgentReleases lists the releases published to the artifact registry, newest first
+func (ds *devicesService) ListAgentReleases(ctx context.Context, request *pbDevices.ListAgentReleasesRequest) (*pbDevices.ListAgentReleasesResponse, error) {
+	releases, err := ds.datastore.AgentReleases.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read agent releases: %s", err.Error())
+	}
+
+	response := &pbDevices.ListAgentReleasesResponse{
+		Releases: make([]*pbDevices.AgentRelease, 0, len(releases)),
+	}
+	for _, release := range releases {
+		response.Releases = append(response.Releases, toPBAgentRelease(release))
+	}
+	return response, nil
+}
+
+func toPBAgentRelease(release *dao.AgentRelease) *pbDevices.AgentRelease {
+	return &pbDevices.AgentRelease{
+		Id:          release.ID,
+		Version:     release.Version,
+		Os:          release.OS,
+		Arch:        release.Arch,
+		Sha256:      release.SHA256,
+		SizeBytes:   release.SizeBytes,
+		Signature:   release.Signature,
+		PublishedAt: timestamppb.New(release.PublishedAt),
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/danielhoward314/packet-sentry/dao"
 	"github.com/danielhoward314/packet-sentry/dao/postgres"
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
 	psLog "github.com/danielhoward314/packet-sentry/internal/log"
 	pbBootstrap "github.com/danielhoward314/packet-sentry/protogen/golang/bootstrap"
 )
@@ -97,8 +98,6 @@ func (bs *bootstrapService) RequestCertificate(ctx context.Context, req *pbBoots
 		}
 		device.ID = existingDevice.ID
 		device.OrganizationID = existingDevice.OrganizationID
-		device.PCapVersion = existingDevice.PCapVersion
-		device.Interfaces = existingDevice.Interfaces
 		device.InterfaceBPFAssociations = existingDevice.InterfaceBPFAssociations
 		device.PreviousAssociations = existingDevice.PreviousAssociations
 	} else {
@@ -171,7 +170,10 @@ func (bs *bootstrapService) RequestCertificate(ctx context.Context, req *pbBoots
 			logger.Error("error creating device", psLog.KeyError, err)
 			return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("error creating device: %v", err))
 		}
-		_, err = bs.JetStream.Publish("cmds."+csr.Subject.CommonName, []byte("send_interfaces"))
+		err = issueCommand(bs.Datastore, bs.JetStream, device, &broadcast.Command{
+			Name:     broadcast.CommandSendInterfaces,
+			IssuedBy: issuerAgentAPI,
+		})
 		if err != nil {
 			logger.Error("command send was not ack'd", psLog.KeyError, err)
 			return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("command send was not ack'd: %v", err))
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	pbAgent "github.com/danielhoward314/packet-sentry/protogen/golang/agent"
+	pbDevices "github.com/danielhoward314/packet-sentry/protogen/golang/devices"
+)
+
+const (
+	// commandTTL is how long after a command is issued the agent still runs it
+	commandTTL = 24 * time.Hour
+	// issuers of the commands the servers issue on their own
+	issuerAgentAPI = "agent-api"
+	issuerWebAPI   = "web-api"
+	// commandsStream is the NATS stream of the `cmds.<os-unique-identifier>` subjects
+	commandsStream = "COMMANDS"
+	// commandStreamFetchWait is the longest a command stream waits on the device's commands before checking the stream again
+	commandStreamFetchWait = 30 * time.Second
+)
+
+// issueCommand records a command issued to the device, then publishes it with its id and expiry
+// to the device's commands subject as JSON, so its result can be followed until the agent reports it
+func issueCommand(datastore *dao.Datastore, js nats.JetStream, device *dao.Device, command *broadcast.Command) error {
+	record := &dao.Command{
+		DeviceID:  device.ID,
+		Name:      command.Name,
+		IssuedBy:  command.IssuedBy,
+		ExpiresAt: time.Now().Add(commandTTL),
+	}
+	if args := command.Args(); args != nil {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return err
+		}
+		record.Args = data
+	}
+	err := datastore.Commands.Create(record)
+	if err != nil {
+		return fmt.Errorf("failed to record command: %w", err)
+	}
+
+	command.ID = record.ID
+	command.IssuedAt = record.IssuedAt
+	command.ExpiresAt = record.ExpiresAt
+	data, err := json.Marshal(command)
+	if err == nil {
+		_, err = js.Publish("cmds."+device.OSUniqueIdentifier, data)
+	}
+	if err != nil {
+		// the agent will never fetch the command, so it won't report a result either
+		completeErr := datastore.Commands.Complete(record.ID, dao.CommandStatusFailed, "failed to publish command: "+err.Error(), nil, nil)
+		if completeErr != nil {
+			return fmt.Errorf("%w, and failed to record the failure: %w", err, completeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// bindCommandsConsumer binds a pull subscription to the device's durable commands consumer, creating the consumer the first time.
+// Both the polls and the command stream of a device pull from the one consumer, so each command is delivered once.
+// Since the subscription only binds to the consumer, unsubscribing doesn't delete it.
+func bindCommandsConsumer(js nats.JetStreamContext, osUniqueIdentifier string) (*nats.Subscription, error) {
+	subject := "cmds." + osUniqueIdentifier
+	durable := osUniqueIdentifier
+
+	sub, err := js.PullSubscribe(subject, "",
+		nats.Bind(commandsStream, durable),
+		nats.ManualAck(),
+	)
+	if !errors.Is(err, nats.ErrConsumerNotFound) {
+		return sub, err
+	}
+
+	// First-time setup: create the durable consumer explicitly
+	_, err = js.AddConsumer(commandsStream, &nats.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: subject,
+		AckPolicy:     nats.AckExplicitPolicy,
+		MaxWaiting:    128,
+	})
+	if err != nil && !errors.Is(err, nats.ErrConsumerNameAlreadyInUse) {
+		return nil, err
+	}
+	return js.PullSubscribe(subject, "",
+		nats.Bind(commandsStream, durable),
+		nats.ManualAck(),
+	)
+}
+
+// commandIssuer returns the id of the administrator whose access token the gateway forwarded with the request,
+// falling back to the web API itself when there is none
+func commandIssuer(ctx context.Context, tokenDatastore dao.TokenDatastore) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || tokenDatastore == nil {
+		return issuerWebAPI
+	}
+	for _, authorization := range md.Get("authorization") {
+		tokenData, err := tokenDatastore.Read(strings.TrimPrefix(authorization, "Bearer "))
+		if err == nil && tokenData.AdministratorID != "" {
+			return tokenData.AdministratorID
+		}
+	}
+	return issuerWebAPI
+}
+
+// ListCommands lists the commands issued to the device with their results, newest first
+func (ds *devicesService) ListCommands(ctx context.Context, request *pbDevices.ListCommandsRequest) (*pbDevices.ListCommandsResponse, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+
+	commands, err := ds.datastore.Commands.List(request.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read commands: %s", err.Error())
+	}
+
+	response := &pbDevices.ListCommandsResponse{
+		Commands: make([]*pbDevices.Command, 0, len(commands)),
+	}
+	for _, command := range commands {
+		response.Commands = append(response.Commands, toPBCommand(command))
+	}
+	return response, nil
+}
+
+// GetCommand returns a command issued to the device with its result
+func (ds *devicesService) GetCommand(ctx context.Context, request *pbDevices.GetCommandRequest) (*pbDevices.Command, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+	if request.CommandId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid command id")
+	}
+
+	command, err := ds.datastore.Commands.Get(request.CommandId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "command not found: %s", request.CommandId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read command: %s", err.Error())
+	}
+	if command.DeviceID != request.Id {
+		return nil, status.Errorf(codes.NotFound, "command not found: %s", request.CommandId)
+	}
+	return toPBCommand(command), nil
+}
+
+// commandFromMessage returns the command of a message of a device's commands subject,
+// either a command's name or a JSON command envelope
+func commandFromMessage(data []byte) *pbAgent.Command {
+	if bytes.HasPrefix(data, []byte("{")) {
+		var command broadcast.Command
+		err := json.Unmarshal(data, &command)
+		if err == nil {
+			return toPBAgentCommand(&command)
+		}
+	}
+	return &pbAgent.Command{
+		Name: string(data),
+	}
+}
+
+func toPBAgentCommand(command *broadcast.Command) *pbAgent.Command {
+	pbCommand := &pbAgent.Command{
+		Name:     command.Name,
+		Id:       command.ID,
+		IssuedBy: command.IssuedBy,
+	}
+	if !command.IssuedAt.IsZero() {
+		pbCommand.IssuedAt = timestamppb.New(command.IssuedAt)
+	}
+	if !command.ExpiresAt.IsZero() {
+		pbCommand.ExpiresAt = timestamppb.New(command.ExpiresAt)
+	}
+	if args := command.UploadPacketSlice; args != nil {
+		pbCommand.Args = &pbAgent.Command_UploadPacketSlice{
+			UploadPacketSlice: &pbAgent.UploadPacketSliceArgs{
+				SliceId:    args.SliceID,
+				DeviceName: args.DeviceName,
+				Bpf:        args.BPF,
+				Filter:     args.Filter,
+				Start:      timestamppb.New(args.Start),
+				End:        timestamppb.New(args.End),
+			},
+		}
+	}
+	if args := command.SetLogLevel; args != nil {
+		pbCommand.Args = &pbAgent.Command_SetLogLevel{
+			SetLogLevel: &pbAgent.SetLogLevelArgs{
+				Level:              args.Level,
+				RevertAfterSeconds: int64(args.RevertAfter / time.Second),
+			},
+		}
+	}
+	if args := command.CollectDiagnostics; args != nil {
+		pbCommand.Args = &pbAgent.Command_CollectDiagnostics{
+			CollectDiagnostics: &pbAgent.CollectDiagnosticsArgs{
+				BundleId: args.BundleID,
+			},
+		}
+	}
+	if args := command.UpdateAgent; args != nil {
+		pbCommand.Args = &pbAgent.Command_UpdateAgent{
+			UpdateAgent: &pbAgent.UpdateAgentArgs{
+				Version:   args.Version,
+				Sha256:    args.SHA256,
+				SizeBytes: args.SizeBytes,
+				Signature: args.Signature,
+			},
+		}
+	}
+	return pbCommand
+}
+
+func toPBCommand(command *dao.Command) *pbDevices.Command {
+	pbCommand := &pbDevices.Command{
+		Id:        command.ID,
+		DeviceId:  command.DeviceID,
+		Name:      command.Name,
+		Args:      string(command.Args),
+		IssuedBy:  command.IssuedBy,
+		IssuedAt:  timestamppb.New(command.IssuedAt),
+		ExpiresAt: timestamppb.New(command.ExpiresAt),
+		Status:    command.Status,
+		Error:     command.Error,
+	}
+	if command.DeliveredAt != nil {
+		pbCommand.DeliveredAt = timestamppb.New(*command.DeliveredAt)
+	}
+	if command.StartedAt != nil {
+		pbCommand.StartedAt = timestamppb.New(*command.StartedAt)
+	}
+	if command.FinishedAt != nil {
+		pbCommand.FinishedAt = timestamppb.New(*command.FinishedAt)
+	}
+	return pbCommand
+}
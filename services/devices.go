@@ -1,44 +1,60 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
 	"github.com/cespare/xxhash/v2"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/danielhoward314/packet-sentry/dao"
 	"github.com/danielhoward314/packet-sentry/dao/postgres"
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
 	pbDevices "github.com/danielhoward314/packet-sentry/protogen/golang/devices"
 	"github.com/nats-io/nats.go"
 )
 
 const (
 	svcNameDevices = "devices"
+	// captureSnapLen is the snap length of every capture
+	captureSnapLen = 65535
 )
 
+// BPFValidator compiles a BPF for an interface's link type, returning the compiler's error for an invalid filter
+type BPFValidator func(filter, linkType string, snapLen int) error
+
 // devicesService implements the devices gRPC service
 type devicesService struct {
 	pbDevices.UnimplementedDevicesServiceServer
-	datastore *dao.Datastore
-	jetStream nats.JetStream
-	logger    *slog.Logger
+	datastore      *dao.Datastore
+	jetStream      nats.JetStream
+	tokenDatastore dao.TokenDatastore
+	validateBPF    BPFValidator
+	logger         *slog.Logger
 }
 
 func NewDevicesService(
 	datastore *dao.Datastore,
 	js nats.JetStreamContext,
+	tokenDatastore dao.TokenDatastore,
+	validateBPF BPFValidator,
 	baseLogger *slog.Logger,
 ) pbDevices.DevicesServiceServer {
 	childLogger := baseLogger.With(slog.String("service", svcNameDevices))
 
 	return &devicesService{
-		datastore: datastore,
-		jetStream: js,
-		logger:    childLogger,
+		datastore:      datastore,
+		jetStream:      js,
+		tokenDatastore: tokenDatastore,
+		validateBPF:    validateBPF,
+		logger:         childLogger,
 	}
 }
 
@@ -77,6 +93,9 @@ func (ds *devicesService) Get(ctx context.Context, request *pbDevices.GetDeviceR
 				DeviceName:  daoCaptureConfig.DeviceName,
 				Promiscuous: daoCaptureConfig.Promiscuous,
 				SnapLen:     int32(daoCaptureConfig.SnapLen),
+				FlowMode:    daoCaptureConfig.FlowMode,
+				HttpMode:    daoCaptureConfig.HTTPMode,
+				RingBuffer:  daoCaptureConfig.RingBuffer,
 			}
 		}
 	}
@@ -96,6 +115,9 @@ func (ds *devicesService) Get(ctx context.Context, request *pbDevices.GetDeviceR
 				DeviceName:  daoPreviousCaptureConfig.DeviceName,
 				Promiscuous: daoPreviousCaptureConfig.Promiscuous,
 				SnapLen:     int32(daoPreviousCaptureConfig.SnapLen),
+				FlowMode:    daoPreviousCaptureConfig.FlowMode,
+				HttpMode:    daoPreviousCaptureConfig.HTTPMode,
+				RingBuffer:  daoPreviousCaptureConfig.RingBuffer,
 			}
 		}
 	}
@@ -109,6 +131,16 @@ func (ds *devicesService) Get(ctx context.Context, request *pbDevices.GetDeviceR
 		PreviousAssociations:     pbPreviousAssociations,
 		PcapVersion:              device.PCapVersion,
 		Interfaces:               device.Interfaces,
+		CaptureStats:             toPBCaptureStats(device.CaptureStats),
+		EventsDropped:            device.EventsDropped,
+		CaptureStatsUpdatedAt:    toPBTimestamp(device.CaptureStatsUpdatedAt),
+		CaptureResults:           toPBCaptureResults(device.CaptureResults),
+		Captures:                 toPBCaptureStatuses(device),
+		InterfaceDetails:         toPBInterfaceDetails(device.InterfaceDetails),
+		Online:                   device.Online,
+		LastSeenAt:               toPBTimestamp(device.LastSeenAt),
+		AgentVersion:             device.AgentVersion,
+		Heartbeat:                toPBHeartbeat(device.Heartbeat),
 	}, nil
 }
 
@@ -150,6 +182,9 @@ func (ds *devicesService) List(ctx context.Context, request *pbDevices.ListDevic
 					DeviceName:  daoCaptureConfig.DeviceName,
 					Promiscuous: daoCaptureConfig.Promiscuous,
 					SnapLen:     int32(daoCaptureConfig.SnapLen),
+					FlowMode:    daoCaptureConfig.FlowMode,
+					HttpMode:    daoCaptureConfig.HTTPMode,
+					RingBuffer:  daoCaptureConfig.RingBuffer,
 				}
 			}
 		}
@@ -169,6 +204,9 @@ func (ds *devicesService) List(ctx context.Context, request *pbDevices.ListDevic
 					DeviceName:  daoPreviousCaptureConfig.DeviceName,
 					Promiscuous: daoPreviousCaptureConfig.Promiscuous,
 					SnapLen:     int32(daoPreviousCaptureConfig.SnapLen),
+					FlowMode:    daoPreviousCaptureConfig.FlowMode,
+					HttpMode:    daoPreviousCaptureConfig.HTTPMode,
+					RingBuffer:  daoPreviousCaptureConfig.RingBuffer,
 				}
 			}
 		}
@@ -182,6 +220,16 @@ func (ds *devicesService) List(ctx context.Context, request *pbDevices.ListDevic
 			PreviousAssociations:     pbPreviousAssociations,
 			PcapVersion:              device.PCapVersion,
 			Interfaces:               device.Interfaces,
+			CaptureStats:             toPBCaptureStats(device.CaptureStats),
+			EventsDropped:            device.EventsDropped,
+			CaptureStatsUpdatedAt:    toPBTimestamp(device.CaptureStatsUpdatedAt),
+			CaptureResults:           toPBCaptureResults(device.CaptureResults),
+			Captures:                 toPBCaptureStatuses(device),
+			InterfaceDetails:         toPBInterfaceDetails(device.InterfaceDetails),
+			Online:                   device.Online,
+			LastSeenAt:               toPBTimestamp(device.LastSeenAt),
+			AgentVersion:             device.AgentVersion,
+			Heartbeat:                toPBHeartbeat(device.Heartbeat),
 		})
 	}
 
@@ -213,12 +261,7 @@ func (ds *devicesService) Update(ctx context.Context, request *pbDevices.UpdateD
 	if request.ClientCertFingerprint != "" {
 		device.ClientCertFingerprint = request.ClientCertFingerprint
 	}
-	if len(request.Interfaces) > 0 {
-		device.Interfaces = request.Interfaces
-	}
-	if request.PcapVersion != "" {
-		device.PCapVersion = request.PcapVersion
-	}
+	// the interfaces and pcap version are only written by the agent's ReportInterfaces, so the request's are ignored
 
 	device.PreviousAssociations = device.InterfaceBPFAssociations
 
@@ -233,12 +276,20 @@ func (ds *devicesService) Update(ctx context.Context, request *pbDevices.UpdateD
 			if daoAssociations[ifaceName] == nil {
 				daoAssociations[ifaceName] = make(map[uint64]dao.CaptureConfig)
 			}
+			// a filter that doesn't compile would only fail on the device, where the admin can't see why
+			err = ds.validateBPF(pbCaptureConfig.Bpf, interfaceLinkType(device, ifaceName), captureSnapLen)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid BPF %q for interface %s: %s", pbCaptureConfig.Bpf, ifaceName, err.Error())
+			}
 			pbBPFHash := xxhash.Sum64([]byte(pbBPF))
 			daoAssociations[ifaceName][pbBPFHash] = dao.CaptureConfig{
 				Bpf:         pbCaptureConfig.Bpf,
 				DeviceName:  pbCaptureConfig.DeviceName,
 				Promiscuous: pbCaptureConfig.Promiscuous,
-				SnapLen:     int32(65535),
+				SnapLen:     int32(captureSnapLen),
+				FlowMode:    pbCaptureConfig.FlowMode,
+				HTTPMode:    pbCaptureConfig.HttpMode,
+				RingBuffer:  pbCaptureConfig.RingBuffer,
 			}
 		}
 	}
@@ -250,9 +301,152 @@ func (ds *devicesService) Update(ctx context.Context, request *pbDevices.UpdateD
 		return nil, status.Errorf(codes.Internal, "%s", err.Error())
 	}
 
-	_, err = ds.jetStream.Publish("cmds."+device.OSUniqueIdentifier, []byte("get_bpf_config"))
+	err = issueCommand(ds.datastore, ds.jetStream, device, &broadcast.Command{
+		Name:     broadcast.CommandGetBPFConfig,
+		IssuedBy: commandIssuer(ctx, ds.tokenDatastore),
+	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "%s", fmt.Sprintf("command send was not ack'd: %v", err))
 	}
 	return &pbDevices.Empty{}, nil
 }
+
+func toPBCaptureStats(captureStats []dao.CaptureStats) []*pbDevices.CaptureStats {
+	pbCaptureStats := make([]*pbDevices.CaptureStats, 0, len(captureStats))
+	for _, stats := range captureStats {
+		pbCaptureStats = append(pbCaptureStats, &pbDevices.CaptureStats{
+			Bpf:                stats.Bpf,
+			DeviceName:         stats.DeviceName,
+			Received:           stats.Received,
+			DroppedByKernel:    stats.DroppedByKernel,
+			DroppedByInterface: stats.DroppedByInterface,
+			DroppedByAgent:     stats.DroppedByAgent,
+			StartedAt:          timestamppb.New(stats.StartedAt),
+		})
+	}
+	return pbCaptureStats
+}
+
+// toPBTimestamp converts an optional time, leaving the timestamp unset for nil
+func toPBTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func toPBCaptureResults(captureResults []dao.CaptureResult) []*pbDevices.CaptureResult {
+	pbCaptureResults := make([]*pbDevices.CaptureResult, 0, len(captureResults))
+	for _, result := range captureResults {
+		pbCaptureResults = append(pbCaptureResults, &pbDevices.CaptureResult{
+			Bpf:        result.Bpf,
+			DeviceName: result.DeviceName,
+			Error:      result.Error,
+			LinkType:   result.LinkType,
+			AppliedAt:  timestamppb.New(result.AppliedAt),
+			State:      result.State,
+		})
+	}
+	return pbCaptureResults
+}
+
+func toPBHeartbeat(heartbeat *dao.Heartbeat) *pbDevices.Heartbeat {
+	if heartbeat == nil {
+		return nil
+	}
+	return &pbDevices.Heartbeat{
+		AgentVersion:    heartbeat.AgentVersion,
+		CommitHash:      heartbeat.CommitHash,
+		UptimeSeconds:   heartbeat.UptimeSeconds,
+		Os:              heartbeat.OS,
+		Arch:            heartbeat.Arch,
+		OsVersion:       heartbeat.OSVersion,
+		KernelVersion:   heartbeat.KernelVersion,
+		CpuPercent:      heartbeat.CPUPercent,
+		MemoryBytes:     heartbeat.MemoryBytes,
+		CapturesRunning: heartbeat.CapturesRunning,
+		CapturesFailed:  heartbeat.CapturesFailed,
+		CapturesStopped: heartbeat.CapturesStopped,
+		ReceivedAt:      timestamppb.New(heartbeat.ReceivedAt),
+	}
+}
+
+func toPBInterfaceDetails(interfaceDetails []dao.InterfaceDetails) []*pbDevices.InterfaceDetails {
+	pbInterfaceDetails := make([]*pbDevices.InterfaceDetails, 0, len(interfaceDetails))
+	for _, iface := range interfaceDetails {
+		pbInterfaceDetails = append(pbInterfaceDetails, &pbDevices.InterfaceDetails{
+			Name:        iface.Name,
+			Description: iface.Description,
+			Addresses:   iface.Addresses,
+			Flags:       iface.Flags,
+		})
+	}
+	return pbInterfaceDetails
+}
+
+// toPBCaptureStatuses lists the desired captures of the device next to the state it reported for them,
+// followed by the captures it reported that aren't desired, e.g. ones it failed to stop
+func toPBCaptureStatuses(device *dao.Device) []*pbDevices.CaptureStatus {
+	type captureKey struct {
+		deviceName string
+		bpf        string
+	}
+	reported := make(map[captureKey]dao.CaptureResult, len(device.CaptureResults))
+	for _, result := range device.CaptureResults {
+		reported[captureKey{result.DeviceName, result.Bpf}] = result
+	}
+
+	captureStatuses := make([]*pbDevices.CaptureStatus, 0, len(device.CaptureResults))
+	for ifaceName, captures := range device.InterfaceBPFAssociations {
+		for _, captureConfig := range captures {
+			key := captureKey{ifaceName, captureConfig.Bpf}
+			captureStatus := &pbDevices.CaptureStatus{
+				DeviceName:  ifaceName,
+				Bpf:         captureConfig.Bpf,
+				Desired:     true,
+				ActualState: dao.CaptureStatePending,
+			}
+			result, ok := reported[key]
+			if ok {
+				captureStatus.ActualState = result.State
+				captureStatus.Error = result.Error
+				captureStatus.AppliedAt = timestamppb.New(result.AppliedAt)
+				delete(reported, key)
+			}
+			captureStatuses = append(captureStatuses, captureStatus)
+		}
+	}
+	for _, result := range reported {
+		captureStatuses = append(captureStatuses, &pbDevices.CaptureStatus{
+			DeviceName:  result.DeviceName,
+			Bpf:         result.Bpf,
+			ActualState: result.State,
+			Error:       result.Error,
+			AppliedAt:   timestamppb.New(result.AppliedAt),
+		})
+	}
+
+	slices.SortFunc(captureStatuses, func(a, b *pbDevices.CaptureStatus) int {
+		if a.Desired != b.Desired {
+			if a.Desired {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.DeviceName, b.DeviceName), cmp.Compare(a.Bpf, b.Bpf))
+	})
+	return captureStatuses
+}
+
+// interfaceLinkType returns the link type the device last reported for the interface, empty if it never opened it
+func interfaceLinkType(device *dao.Device, ifaceName string) string {
+	linkType := ""
+	var appliedAt time.Time
+	for _, result := range device.CaptureResults {
+		if result.DeviceName == ifaceName && result.LinkType != "" && !result.AppliedAt.Before(appliedAt) {
+			linkType = result.LinkType
+			appliedAt = result.AppliedAt
+		}
+	}
+	return linkType
+}
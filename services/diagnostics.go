@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres"
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	pbDevices "github.com/danielhoward314/packet-sentry/protogen/golang/devices"
+)
+
+const (
+	// logLevelRevertAfter is how long the log level set for a device lasts when the request doesn't say
+	logLevelRevertAfter = 1 * time.Hour
+	// logLevelMaxRevertAfter is the longest the log level set for a device lasts, so a forgotten debug level doesn't fill the disk
+	logLevelMaxRevertAfter = 24 * time.Hour
+	// diagnosticsMaxBytes is the max size of an uploaded diagnostics bundle, above the agent's max size of the logs in it
+	diagnosticsMaxBytes = 64 * 1024 * 1024
+	// diagnosticsContentType is the media type of diagnostics bundle downloads
+	diagnosticsContentType = "application/zip"
+)
+
+// logLevels are the levels the agent's log level can be set to
+var logLevels = map[string]bool{
+	"debug": true,
+	"info":  true,
+	"warn":  true,
+	"error": true,
+}
+
+// SetLogLevel sends the device the `set_log_level` command to change its log level until it reverts to the default
+func (ds *devicesService) SetLogLevel(ctx context.Context, request *pbDevices.SetLogLevelRequest) (*pbDevices.Command, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+	level := strings.ToLower(strings.TrimSpace(request.Level))
+	if !logLevels[level] {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid log level %q, must be one of debug, info, warn or error", request.Level)
+	}
+	if request.RevertAfterSeconds < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "revert_after_seconds must not be negative")
+	}
+	revertAfter := time.Duration(request.RevertAfterSeconds) * time.Second
+	if revertAfter == 0 {
+		revertAfter = logLevelRevertAfter
+	}
+	if revertAfter > logLevelMaxRevertAfter {
+		return nil, status.Errorf(codes.InvalidArgument, "revert_after_seconds must be at most %d", int64(logLevelMaxRevertAfter/time.Second))
+	}
+
+	device, err := ds.datastore.Devices.GetDeviceByPredicate(postgres.PredicateID, request.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "device not found: %s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read device data: %s", err.Error())
+	}
+
+	command := &broadcast.Command{
+		Name:     broadcast.CommandSetLogLevel,
+		IssuedBy: commandIssuer(ctx, ds.tokenDatastore),
+		SetLogLevel: &broadcast.SetLogLevelArgs{
+			Level:       level,
+			RevertAfter: revertAfter,
+		},
+	}
+	err = issueCommand(ds.datastore, ds.jetStream, device, command)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "command send was not ack'd: %v", err)
+	}
+
+	record, err := ds.datastore.Commands.Get(command.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read command: %s", err.Error())
+	}
+	return toPBCommand(record), nil
+}
+
+// RequestDiagnostics records a request for a diagnostics bundle of the device,
+// and sends the device the `collect_diagnostics` command to upload it
+func (ds *devicesService) RequestDiagnostics(ctx context.Context, request *pbDevices.RequestDiagnosticsRequest) (*pbDevices.DiagnosticsBundle, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+
+	device, err := ds.datastore.Devices.GetDeviceByPredicate(postgres.PredicateID, request.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "device not found: %s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read device data: %s", err.Error())
+	}
+
+	bundle := &dao.DiagnosticsBundle{
+		DeviceID: device.ID,
+	}
+	err = ds.datastore.DiagnosticsBundles.Create(bundle)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create diagnostics bundle: %s", err.Error())
+	}
+
+	command := &broadcast.Command{
+		Name:     broadcast.CommandCollectDiagnostics,
+		IssuedBy: commandIssuer(ctx, ds.tokenDatastore),
+		CollectDiagnostics: &broadcast.CollectDiagnosticsArgs{
+			BundleID: bundle.ID,
+		},
+	}
+	err = issueCommand(ds.datastore, ds.jetStream, device, command)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "command send was not ack'd: %v", err)
+	}
+
+	pbBundle := toPBDiagnosticsBundle(bundle)
+	pbBundle.CommandId = command.ID
+	return pbBundle, nil
+}
+
+// ListDiagnostics lists the diagnostics bundles requested from the device, newest first
+func (ds *devicesService) ListDiagnostics(ctx context.Context, request *pbDevices.ListDiagnosticsRequest) (*pbDevices.ListDiagnosticsResponse, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+
+	bundles, err := ds.datastore.DiagnosticsBundles.List(request.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read diagnostics bundles: %s", err.Error())
+	}
+
+	response := &pbDevices.ListDiagnosticsResponse{
+		Bundles: make([]*pbDevices.DiagnosticsBundle, 0, len(bundles)),
+	}
+	for _, bundle := range bundles {
+		response.Bundles = append(response.Bundles, toPBDiagnosticsBundle(bundle))
+	}
+	return response, nil
+}
+
+// DownloadDiagnostics returns the zip file of a complete diagnostics bundle
+func (ds *devicesService) DownloadDiagnostics(ctx context.Context, request *pbDevices.DownloadDiagnosticsRequest) (*httpbody.HttpBody, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+	if request.BundleId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid diagnostics bundle id")
+	}
+
+	bundle, err := ds.datastore.DiagnosticsBundles.Get(request.BundleId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "diagnostics bundle not found: %s", request.BundleId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read diagnostics bundle: %s", err.Error())
+	}
+	if bundle.DeviceID != request.Id {
+		return nil, status.Errorf(codes.NotFound, "diagnostics bundle not found: %s", request.BundleId)
+	}
+	if bundle.Status != dao.DiagnosticsBundleStatusComplete {
+		return nil, status.Errorf(codes.FailedPrecondition, "diagnostics bundle %s is %s", request.BundleId, bundle.Status)
+	}
+
+	data, err := ds.datastore.DiagnosticsBundles.ReadData(request.BundleId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read diagnostics bundle data: %s", err.Error())
+	}
+	return &httpbody.HttpBody{
+		ContentType: diagnosticsContentType,
+		Data:        data,
+	}, nil
+}
+
+func toPBDiagnosticsBundle(bundle *dao.DiagnosticsBundle) *pbDevices.DiagnosticsBundle {
+	pbBundle := &pbDevices.DiagnosticsBundle{
+		Id:          bundle.ID,
+		DeviceId:    bundle.DeviceID,
+		Status:      bundle.Status,
+		Error:       bundle.Error,
+		SizeBytes:   bundle.SizeBytes,
+		RequestedAt: timestamppb.New(bundle.RequestedAt),
+	}
+	if bundle.CompletedAt != nil {
+		pbBundle.CompletedAt = timestamppb.New(*bundle.CompletedAt)
+	}
+	return pbBundle
+}
@@ -83,3 +83,57 @@ func (es *eventsService) Get(ctx context.Context, request *pbEvents.GetEventsReq
 		Events: resEvents,
 	}, nil
 }
+
+func (es *eventsService) GetDNS(ctx context.Context, request *pbEvents.GetEventsRequest) (*pbEvents.GetDNSEventsResponse, error) {
+	if request.DeviceId == "" {
+		es.logger.Error("invalid device id")
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+	if request.End == "" {
+		es.logger.Error("invalid end datetime query string")
+		return nil, status.Errorf(codes.InvalidArgument, "invalid end datetime query string")
+	}
+	if request.Start == "" {
+		es.logger.Error("invalid start datetime query string")
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start datetime query string")
+	}
+
+	es.logger.Info("querying dns events", "os_unique_identifier", request.DeviceId, "start", request.Start, "end", request.End)
+	dnsEvents, err := es.datastore.Events.ReadDNS(
+		request.DeviceId,
+		request.Start,
+		request.End,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read dns events data: %s", err.Error())
+	}
+
+	resEvents := make([]*pbEvents.DNSEvent, 0, len(dnsEvents))
+	for _, dnsEvent := range dnsEvents {
+		answers := make([]*pbEvents.DNSAnswer, 0, len(dnsEvent.Answers))
+		for _, answer := range dnsEvent.Answers {
+			answers = append(answers, &pbEvents.DNSAnswer{
+				Name: answer.Name,
+				Type: answer.Type,
+				Ttl:  answer.TTL,
+				Data: answer.Data,
+			})
+		}
+		resEvents = append(resEvents, &pbEvents.DNSEvent{
+			EventTime:    dnsEvent.EventTime,
+			Bpf:          dnsEvent.Bpf,
+			IpSrc:        dnsEvent.IpSrc,
+			IpDst:        dnsEvent.IpDst,
+			DnsId:        dnsEvent.DNSID,
+			Response:     dnsEvent.Response,
+			ResponseCode: dnsEvent.ResponseCode,
+			QueryName:    dnsEvent.QueryName,
+			QueryType:    dnsEvent.QueryType,
+			Answers:      answers,
+		})
+	}
+
+	return &pbEvents.GetDNSEventsResponse{
+		Events: resEvents,
+	}, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/danielhoward314/packet-sentry/dao"
+	"github.com/danielhoward314/packet-sentry/dao/postgres"
+	"github.com/danielhoward314/packet-sentry/internal/broadcast"
+	pbDevices "github.com/danielhoward314/packet-sentry/protogen/golang/devices"
+)
+
+const (
+	// packetSliceMaxBytes is the max size of an uploaded packet slice, above the agent's max slice size
+	packetSliceMaxBytes = 64 * 1024 * 1024
+	// packetSliceContentType is the media type of the pcap files of packet slice downloads
+	packetSliceContentType = "application/vnd.tcpdump.pcap"
+)
+
+// RequestPacketSlice records a request for the packets of a capture's ring buffer in a time range,
+// and sends the device the `upload_packet_slice` command to upload them
+func (ds *devicesService) RequestPacketSlice(ctx context.Context, request *pbDevices.RequestPacketSliceRequest) (*pbDevices.PacketSlice, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+	if request.DeviceName == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid interface name")
+	}
+	if request.StartTime == nil || request.EndTime == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "start_time and end_time are required")
+	}
+	startTime := request.StartTime.AsTime()
+	endTime := request.EndTime.AsTime()
+	if !endTime.After(startTime) {
+		return nil, status.Errorf(codes.InvalidArgument, "end_time must be after start_time")
+	}
+
+	device, err := ds.datastore.Devices.GetDeviceByPredicate(postgres.PredicateID, request.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "device not found: %s", err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read device data: %s", err.Error())
+	}
+	// the agent keeps the ring buffer of a deleted capture until it ages out, so previous associations count too
+	if !hasRingBufferCapture(device.InterfaceBPFAssociations, request.DeviceName, request.Bpf) &&
+		!hasRingBufferCapture(device.PreviousAssociations, request.DeviceName, request.Bpf) {
+		return nil, status.Errorf(codes.FailedPrecondition, "no capture with a ring buffer for BPF %q on interface %s", request.Bpf, request.DeviceName)
+	}
+	if request.Filter != "" {
+		err = ds.validateBPF(request.Filter, interfaceLinkType(device, request.DeviceName), captureSnapLen)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid filter %q for interface %s: %s", request.Filter, request.DeviceName, err.Error())
+		}
+	}
+
+	packetSlice := &dao.PacketSlice{
+		DeviceID:   device.ID,
+		DeviceName: request.DeviceName,
+		Bpf:        request.Bpf,
+		Filter:     request.Filter,
+		StartTime:  startTime,
+		EndTime:    endTime,
+	}
+	err = ds.datastore.PacketSlices.Create(packetSlice)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create packet slice: %s", err.Error())
+	}
+
+	command := &broadcast.Command{
+		Name:     broadcast.CommandUploadPacketSlice,
+		IssuedBy: commandIssuer(ctx, ds.tokenDatastore),
+		UploadPacketSlice: &broadcast.UploadPacketSliceArgs{
+			SliceID:    packetSlice.ID,
+			DeviceName: packetSlice.DeviceName,
+			BPF:        packetSlice.Bpf,
+			Filter:     packetSlice.Filter,
+			Start:      startTime,
+			End:        endTime,
+		},
+	}
+	err = issueCommand(ds.datastore, ds.jetStream, device, command)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "command send was not ack'd: %v", err)
+	}
+
+	pbPacketSlice := toPBPacketSlice(packetSlice)
+	pbPacketSlice.CommandId = command.ID
+	return pbPacketSlice, nil
+}
+
+// ListPacketSlices lists the packet slices requested from the device, newest first
+func (ds *devicesService) ListPacketSlices(ctx context.Context, request *pbDevices.ListPacketSlicesRequest) (*pbDevices.ListPacketSlicesResponse, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+
+	packetSlices, err := ds.datastore.PacketSlices.List(request.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read packet slices: %s", err.Error())
+	}
+
+	response := &pbDevices.ListPacketSlicesResponse{
+		PacketSlices: make([]*pbDevices.PacketSlice, 0, len(packetSlices)),
+	}
+	for _, packetSlice := range packetSlices {
+		response.PacketSlices = append(response.PacketSlices, toPBPacketSlice(packetSlice))
+	}
+	return response, nil
+}
+
+// DownloadPacketSlice returns the pcap file of a complete packet slice
+func (ds *devicesService) DownloadPacketSlice(ctx context.Context, request *pbDevices.DownloadPacketSliceRequest) (*httpbody.HttpBody, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device id")
+	}
+	if request.SliceId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid packet slice id")
+	}
+
+	packetSlice, err := ds.datastore.PacketSlices.Get(request.SliceId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "packet slice not found: %s", request.SliceId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read packet slice: %s", err.Error())
+	}
+	if packetSlice.DeviceID != request.Id {
+		return nil, status.Errorf(codes.NotFound, "packet slice not found: %s", request.SliceId)
+	}
+	if packetSlice.Status != dao.PacketSliceStatusComplete {
+		return nil, status.Errorf(codes.FailedPrecondition, "packet slice %s is %s", request.SliceId, packetSlice.Status)
+	}
+
+	data, err := ds.datastore.PacketSlices.ReadData(request.SliceId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read packet slice data: %s", err.Error())
+	}
+	return &httpbody.HttpBody{
+		ContentType: packetSliceContentType,
+		Data:        data,
+	}, nil
+}
+
+// hasRingBufferCapture reports whether the associations have a capture with a ring buffer for the BPF on the interface
+func hasRingBufferCapture(associations map[string]map[uint64]dao.CaptureConfig, ifaceName, bpf string) bool {
+	for _, captureConfig := range associations[ifaceName] {
+		if captureConfig.Bpf == bpf && captureConfig.RingBuffer {
+			return true
+		}
+	}
+	return false
+}
+
+func toPBPacketSlice(packetSlice *dao.PacketSlice) *pbDevices.PacketSlice {
+	pbPacketSlice := &pbDevices.PacketSlice{
+		Id:          packetSlice.ID,
+		DeviceId:    packetSlice.DeviceID,
+		DeviceName:  packetSlice.DeviceName,
+		Bpf:         packetSlice.Bpf,
+		Filter:      packetSlice.Filter,
+		StartTime:   timestamppb.New(packetSlice.StartTime),
+		EndTime:     timestamppb.New(packetSlice.EndTime),
+		Status:      packetSlice.Status,
+		Error:       packetSlice.Error,
+		PacketCount: packetSlice.PacketCount,
+		SizeBytes:   packetSlice.SizeBytes,
+		Truncated:   packetSlice.Truncated,
+		RequestedAt: timestamppb.New(packetSlice.RequestedAt),
+	}
+	if packetSlice.CompletedAt != nil {
+		pbPacketSlice.CompletedAt = timestamppb.New(*packetSlice.CompletedAt)
+	}
+	return pbPacketSlice
+}